@@ -557,8 +557,81 @@ func TestLoadLokitFileProviderAndLocaleValidation(t *testing.T) {
 		if err != nil {
 			t.Fatalf("LoadLokitFile error: %v", err)
 		}
-		if lf.Provider == nil || lf.Provider.ID != "ollama" {
-			t.Fatalf("provider not loaded correctly: %#v", lf.Provider)
+		if lf.Provider == nil || lf.Provider.ID != "ollama" || len(lf.Providers) != 1 {
+			t.Fatalf("provider not loaded correctly: %#v", lf.Providers)
+		}
+	})
+
+	t.Run("accepts provider fallback list and target overrides", func(t *testing.T) {
+		dir := t.TempDir()
+		yaml := "provider:\n" +
+			"  - id: copilot\n" +
+			"    model: gpt-4o\n" +
+			"  - id: ollama\n" +
+			"    model: llama3.2\n" +
+			"targets:\n" +
+			"  - name: app\n" +
+			"    format: i18next\n" +
+			"    dir: i18n\n" +
+			"    pattern: '{lang}.json'\n" +
+			"    provider:\n" +
+			"      id: groq\n" +
+			"      model: small\n" +
+			"  - name: docs\n" +
+			"    surfaces:\n" +
+			"      - name: man\n" +
+			"        format: po4a\n" +
+			"        config: po4a.cfg\n" +
+			"        provider:\n" +
+			"          - id: anthropic\n" +
+			"            model: large\n"
+		if err := os.WriteFile(filepath.Join(dir, LokitFileName), []byte(yaml), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		lf, err := LoadLokitFile(dir)
+		if err != nil {
+			t.Fatalf("LoadLokitFile error: %v", err)
+		}
+		if len(lf.Providers) != 2 || lf.Providers[1].ID != "ollama" {
+			t.Fatalf("provider chain = %#v", lf.Providers)
+		}
+		if lf.Provider == nil || lf.Provider.ID != "copilot" {
+			t.Fatalf("primary provider = %#v", lf.Provider)
+		}
+		resolved, err := lf.Resolve(dir)
+		if err != nil {
+			t.Fatalf("Resolve error: %v", err)
+		}
+		got := make(map[string]string)
+		for _, rt := range resolved {
+			got[rt.Target.Name] = rt.Target.Provider.Primary().ID
+		}
+		if got["app"] != "groq" || got["docs/man"] != "anthropic" {
+			t.Fatalf("target providers = %v", got)
+		}
+	})
+
+	t.Run("rejects prompt on fallback provider", func(t *testing.T) {
+		dir := t.TempDir()
+		yaml := "provider:\n" +
+			"  - id: copilot\n" +
+			"    model: gpt-4o\n" +
+			"  - id: ollama\n" +
+			"    model: llama3.2\n" +
+			"    prompt: Translate.\n" +
+			"targets:\n" +
+			"  - name: app\n" +
+			"    format: i18next\n" +
+			"    dir: i18n\n" +
+			"    pattern: '{lang}.json'\n"
+		if err := os.WriteFile(filepath.Join(dir, LokitFileName), []byte(yaml), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		_, err := LoadLokitFile(dir)
+		if err == nil || !strings.Contains(err.Error(), "provider[1].prompt") {
+			t.Fatalf("err = %v, want provider[1].prompt error", err)
 		}
 	})
 
@@ -918,3 +991,152 @@ func TestResolveIndexSourcePropagatesLoadError(t *testing.T) {
 		t.Fatalf("expected source index error, got: %v", err)
 	}
 }
+
+func TestLoadLokitFileGlossaryInheritance(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `languages: [ru]
+glossary:
+  do_not_translate: [MiniOS]
+  terms:
+    ru:
+      file manager: файловый менеджер
+      folder: папка
+targets:
+  - name: ui
+    format: i18next
+    dir: i18n
+    pattern: '{lang}.json'
+    glossary:
+      do_not_translate: [lokit]
+      terms:
+        ru:
+          folder: каталог
+  - name: app
+    surfaces:
+      - name: web
+        format: i18next
+        dir: web
+        pattern: '{lang}.json'
+`
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	lf, err := LoadLokitFile(dir)
+	if err != nil {
+		t.Fatalf("LoadLokitFile() error = %v", err)
+	}
+	ui := lf.Targets[0].Glossary
+	if ui == nil {
+		t.Fatal("target glossary is nil")
+	}
+	if !reflect.DeepEqual(ui.DoNotTranslate, []string{"MiniOS", "lokit"}) {
+		t.Fatalf("do_not_translate = %v", ui.DoNotTranslate)
+	}
+	if got := ui.Terms["ru"]["folder"]; got != "каталог" {
+		t.Fatalf("target term override = %q, want каталог", got)
+	}
+	if got := ui.Terms["ru"]["file manager"]; got != "файловый менеджер" {
+		t.Fatalf("inherited term = %q", got)
+	}
+	if got := lf.Glossary.Terms["ru"]["folder"]; got != "папка" {
+		t.Fatalf("top-level glossary was modified: folder = %q", got)
+	}
+
+	resolved, err := lf.Resolve(dir)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for _, rt := range resolved {
+		if rt.Target.Name != "app/web" {
+			continue
+		}
+		if rt.Target.Glossary == nil || rt.Target.Glossary.Terms["ru"]["folder"] != "папка" {
+			t.Fatalf("surface did not inherit top-level glossary: %+v", rt.Target.Glossary)
+		}
+	}
+}
+
+func TestLoadLokitFileGlossaryRejectsEmptyTerms(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "glossary:\n  terms:\n    ru:\n      folder: ''\ntargets:\n  - name: ui\n    format: i18next\n    dir: i18n\n    pattern: '{lang}.json'\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := LoadLokitFile(dir); err == nil || !strings.Contains(err.Error(), "glossary.terms.ru") {
+		t.Fatalf("LoadLokitFile() error = %v, want empty glossary term error", err)
+	}
+}
+
+func TestLoadLokitFileLengthLimits(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "targets:\n  - name: ui\n    length_limits:\n      - keys: '^menu\\.'\n        max: 20\n    surfaces:\n      - format: i18next\n        dir: i18n\n        pattern: '{lang}.json'\n        length_limits:\n          - ratio: 1.3\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	lf, err := LoadLokitFile(dir)
+	if err != nil {
+		t.Fatalf("LoadLokitFile() error = %v", err)
+	}
+	resolved, err := lf.Resolve(dir)
+	if err != nil || len(resolved) != 1 {
+		t.Fatalf("Resolve() = %v, %v", resolved, err)
+	}
+	got := resolved[0].Target.LengthLimits
+	if len(got) != 2 || got[0].Keys != `^menu\.` || got[0].Max != 20 || got[1].Ratio != 1.3 {
+		t.Fatalf("length_limits = %+v, want target rule followed by surface rule", got)
+	}
+
+	for _, bad := range []string{"      - keys: '('\n        max: 5\n", "      - keys: 'x'\n", "      - max: -1\n"} {
+		yaml := "targets:\n  - name: ui\n    format: i18next\n    dir: i18n\n    pattern: '{lang}.json'\n    length_limits:\n" + bad
+		if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := LoadLokitFile(dir); err == nil || !strings.Contains(err.Error(), "length_limits[0]") {
+			t.Errorf("LoadLokitFile(%q) error = %v, want length_limits error", bad, err)
+		}
+	}
+}
+
+func TestLoadLokitFilePropertiesEncoding(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "targets:\n  - name: java\n    encoding: ascii\n    surfaces:\n      - format: properties\n        from: [messages_en.properties]\n        to: messages_{lang}.properties\n      - name: utf\n        format: properties\n        from: [other_en.properties]\n        to: other_{lang}.properties\n        encoding: utf-8\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	lf, err := LoadLokitFile(dir)
+	if err != nil {
+		t.Fatalf("LoadLokitFile() error = %v", err)
+	}
+	resolved, err := lf.Resolve(dir)
+	if err != nil || len(resolved) != 2 {
+		t.Fatalf("Resolve() = %v, %v", resolved, err)
+	}
+	if resolved[0].Target.Encoding != PropertiesEncodingASCII || resolved[1].Target.Encoding != PropertiesEncodingUTF8 {
+		t.Fatalf("encodings = %q, %q; want inherited ascii and surface utf-8", resolved[0].Target.Encoding, resolved[1].Target.Encoding)
+	}
+
+	yaml = "targets:\n  - name: java\n    format: properties\n    from: [messages_en.properties]\n    to: messages_{lang}.properties\n    encoding: latin1\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := LoadLokitFile(dir); err == nil || !strings.Contains(err.Error(), `unknown encoding "latin1"`) {
+		t.Fatalf("LoadLokitFile() error = %v, want unknown encoding error", err)
+	}
+}
+
+func TestLokitFileProviderChain(t *testing.T) {
+	lf := &LokitFile{Provider: &ProviderConfig{ID: "ollama", Model: "llama3.2"}}
+	if chain := lf.ProviderChain(); len(chain) != 1 || chain[0].ID != "ollama" {
+		t.Fatalf("chain from Provider = %#v", chain)
+	}
+
+	lf.Providers = ProviderList{{ID: "copilot"}, {ID: "ollama"}}
+	if chain := lf.ProviderChain(); len(chain) != 2 || chain[0].ID != "copilot" {
+		t.Fatalf("chain from Providers = %#v", chain)
+	}
+
+	if chain := (&LokitFile{}).ProviderChain(); chain != nil {
+		t.Fatalf("empty chain = %#v", chain)
+	}
+}
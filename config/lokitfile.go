@@ -6,6 +6,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,7 +28,15 @@ type LokitFile struct {
 	// SourceLang is the source language code (default "en").
 	SourceLang string `yaml:"source_lang,omitempty"`
 	// Provider configures default AI provider/model for translate command.
-	Provider *ProviderConfig `yaml:"provider,omitempty"`
+	// When loaded from lokit.yaml it is the first entry of Providers.
+	Provider *ProviderConfig `yaml:"-"`
+	// Providers is the provider fallback chain tried in order. The
+	// "provider" key accepts a single mapping or a list.
+	Providers ProviderList `yaml:"provider,omitempty"`
+	// Glossary is the project-wide terminology inherited by all targets.
+	Glossary *Glossary `yaml:"glossary,omitempty"`
+	// Review configures the review workflow for machine translations.
+	Review *ReviewConfig `yaml:"review,omitempty"`
 	// Targets is the list of translation targets.
 	Targets []Target `yaml:"targets"`
 }
@@ -46,12 +55,126 @@ type ProviderConfig struct {
 	Settings ProviderSettings `yaml:"settings,omitempty"`
 }
 
+// ProviderList is an ordered provider fallback chain. In lokit.yaml it is
+// either a single provider mapping or a list of them:
+//
+//	provider:
+//	  - id: copilot
+//	    model: MODEL_NAME
+//	  - id: ollama
+//	    model: MODEL_NAME
+//
+// When a provider fails with quota exhaustion, an authentication error or
+// repeated invalid responses, translation continues with the next one.
+type ProviderList []ProviderConfig
+
+func (l *ProviderList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.MappingNode:
+		var p ProviderConfig
+		if err := value.Decode(&p); err != nil {
+			return err
+		}
+		*l = ProviderList{p}
+		return nil
+	case yaml.SequenceNode:
+		var list []ProviderConfig
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*l = list
+		return nil
+	default:
+		return fmt.Errorf("provider must be either an object or a list of objects")
+	}
+}
+
+// Primary returns the first provider of the chain, or nil if it is empty.
+func (l ProviderList) Primary() *ProviderConfig {
+	if len(l) == 0 {
+		return nil
+	}
+	return &l[0]
+}
+
+// ProviderChain returns the top-level provider fallback chain. A LokitFile
+// built in code with only Provider set yields a single-entry chain.
+func (lf *LokitFile) ProviderChain() ProviderList {
+	if len(lf.Providers) > 0 {
+		return lf.Providers
+	}
+	if lf.Provider != nil {
+		return ProviderList{*lf.Provider}
+	}
+	return nil
+}
+
 // ProviderSettings contains optional model-specific tuning values.
 type ProviderSettings struct {
 	// Temperature controls randomness (0..2).
 	Temperature *float64 `yaml:"temperature,omitempty"`
 }
 
+// ReviewConfig configures how machine translations are marked for review.
+type ReviewConfig struct {
+	// Disabled turns review marking off: machine-translated PO entries get
+	// no "# lokit: machine" comment and lokit.review is not updated.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// MarkFuzzy also flags machine-translated PO entries as fuzzy, so they
+	// are left out of compiled catalogs until accepted with lokit review.
+	MarkFuzzy bool `yaml:"mark_fuzzy,omitempty"`
+}
+
+// Glossary declares terminology that AI translations must follow.
+type Glossary struct {
+	// DoNotTranslate lists terms (product names, commands) that must appear
+	// verbatim in every translation whose source contains them.
+	DoNotTranslate []string `yaml:"do_not_translate,omitempty"`
+	// Terms maps a language code to source term -> required translation.
+	Terms map[string]map[string]string `yaml:"terms,omitempty"`
+}
+
+// LengthLimit caps the length of translations, e.g. for menu entries and
+// buttons with little room. A translation must satisfy every rule matching
+// its key.
+type LengthLimit struct {
+	// Keys is a regex selecting keys (msgids for gettext and po4a); empty
+	// selects all keys.
+	Keys string `yaml:"keys,omitempty"`
+	// Max is the maximum number of characters.
+	Max int `yaml:"max,omitempty"`
+	// Ratio is the maximum length relative to the source, e.g. 1.3.
+	Ratio float64 `yaml:"ratio,omitempty"`
+}
+
+// MergeGlossary returns a glossary combining base and override. Terms from
+// override replace base terms with the same source; do-not-translate lists
+// are concatenated. Returns nil if both are nil.
+func MergeGlossary(base, override *Glossary) *Glossary {
+	if base == nil && override == nil {
+		return nil
+	}
+	out := &Glossary{}
+	for _, g := range []*Glossary{base, override} {
+		if g == nil {
+			continue
+		}
+		out.DoNotTranslate = mergeStringSlices(out.DoNotTranslate, g.DoNotTranslate)
+		for lang, terms := range g.Terms {
+			if out.Terms == nil {
+				out.Terms = make(map[string]map[string]string)
+			}
+			if out.Terms[lang] == nil {
+				out.Terms[lang] = make(map[string]string, len(terms))
+			}
+			for src, dst := range terms {
+				out.Terms[lang][src] = dst
+			}
+		}
+	}
+	return out
+}
+
 // Surface describes one translation surface inside a multi-surface target.
 type Surface struct {
 	Name string `yaml:"name,omitempty"`
@@ -73,8 +196,12 @@ type Surface struct {
 	Exclude  []string `yaml:"exclude,omitempty"`
 	Keywords []string `yaml:"keywords,omitempty"`
 
+	SourceContext int `yaml:"source_context,omitempty"`
+
 	Config string `yaml:"config,omitempty"`
 
+	Encoding string `yaml:"encoding,omitempty"`
+
 	Languages  []string `yaml:"languages,omitempty"`
 	SourceLang string   `yaml:"source_lang,omitempty"`
 	Prompt     string   `yaml:"prompt,omitempty"`
@@ -82,6 +209,12 @@ type Surface struct {
 	LockedKeys     []string `yaml:"locked_keys,omitempty"`
 	IgnoredKeys    []string `yaml:"ignored_keys,omitempty"`
 	LockedPatterns []string `yaml:"locked_patterns,omitempty"`
+
+	Glossary *Glossary `yaml:"glossary,omitempty"`
+
+	LengthLimits []LengthLimit `yaml:"length_limits,omitempty"`
+
+	Provider ProviderList `yaml:"provider,omitempty"`
 }
 
 // Target describes a single translation unit.
@@ -127,6 +260,10 @@ type Target struct {
 	Exclude []string `yaml:"exclude,omitempty"`
 	// Keywords are xgettext keyword options (default "_,N_,gettext,eval_gettext").
 	Keywords []string `yaml:"keywords,omitempty"`
+	// SourceContext is the number of source lines sent to the provider on
+	// each side of a message's references (also po4a and qt; 0 = paths
+	// only).
+	SourceContext int `yaml:"source_context,omitempty"`
 	// SourceLang overrides the source language for xgettext.
 	SourceLang string `yaml:"source_lang,omitempty"`
 
@@ -135,6 +272,14 @@ type Target struct {
 	// Config is the path to po4a.cfg relative to Root.
 	Config string `yaml:"config,omitempty"`
 
+	// --- properties options ---
+
+	// Encoding is the output encoding of .properties translations:
+	// PropertiesEncodingUTF8 or PropertiesEncodingASCII (\uXXXX escapes).
+	// Empty keeps the encoding of each file, or of the source file for new
+	// and pure-ASCII ones.
+	Encoding string `yaml:"encoding,omitempty"`
+
 	// --- overrides ---
 
 	// Languages overrides the global language list for this target.
@@ -154,6 +299,23 @@ type Target struct {
 	// LockedPatterns lists regex patterns; keys matching any pattern are treated as locked.
 	LockedPatterns []string `yaml:"locked_patterns,omitempty"`
 
+	// --- terminology ---
+
+	// Glossary adds target-specific terms on top of the top-level glossary.
+	Glossary *Glossary `yaml:"glossary,omitempty"`
+
+	// --- UI constraints ---
+
+	// LengthLimits caps translation lengths per key pattern. Surfaces add
+	// their rules to the target's.
+	LengthLimits []LengthLimit `yaml:"length_limits,omitempty"`
+
+	// --- provider ---
+
+	// Provider overrides the top-level provider chain for this target,
+	// e.g. a cheaper model for UI strings and a stronger one for manpages.
+	Provider ProviderList `yaml:"provider,omitempty"`
+
 	// Surfaces defines multiple translation surfaces under one logical target.
 	Surfaces []Surface `yaml:"surfaces,omitempty"`
 }
@@ -194,6 +356,31 @@ const TargetTypeDesktop = "desktop"
 // TargetTypePolkit is used for polkit .policy single-file translations.
 const TargetTypePolkit = "polkit"
 
+// TargetTypeAppleStrings is used for Apple .strings and .stringsdict files.
+const TargetTypeAppleStrings = "apple-strings"
+
+// TargetTypeXCStrings is used for Xcode String Catalog (.xcstrings)
+// single-file translations.
+const TargetTypeXCStrings = "xcstrings"
+
+// TargetTypeQt is used for Qt Linguist .ts translation files.
+const TargetTypeQt = "qt"
+
+// Output encodings of .properties targets (Target.Encoding).
+const (
+	PropertiesEncodingUTF8  = "utf-8"
+	PropertiesEncodingASCII = "ascii"
+)
+
+// validateEncoding checks a target or surface encoding.
+func validateEncoding(path, where, encoding string) error {
+	switch encoding {
+	case "", PropertiesEncodingUTF8, PropertiesEncodingASCII:
+		return nil
+	}
+	return fmt.Errorf("%s: %s: unknown encoding %q (valid: %s, %s)", path, where, encoding, PropertiesEncodingUTF8, PropertiesEncodingASCII)
+}
+
 type targetFormatMeta struct {
 	requiresDir           bool
 	requiresPattern       bool
@@ -312,10 +499,40 @@ var targetFormatRegistry = map[string]targetFormatMeta{
 		patternExample:   "org.example.policy",
 		patternNeedsLang: false,
 	},
+	TargetTypeAppleStrings: {
+		requiresDir:       true,
+		requiresPattern:   true,
+		patternNeedsLang:  true,
+		dirExample:        "ios/MyApp",
+		patternExample:    "{lang}.lproj/Localizable.strings",
+		detectUsesPattern: true,
+		detectFunc:        detectLanguagesLproj,
+	},
+	TargetTypeXCStrings: {
+		requiresDir:      true,
+		requiresPattern:  true,
+		dirExample:       "MyApp",
+		patternExample:   "Localizable.xcstrings",
+		patternNeedsLang: false,
+		detectCustomLanguages: func(t Target, absRoot string) []string {
+			if t.TargetPath != "" {
+				return detectLanguagesXCStrings(filepath.Join(absRoot, t.TargetPath))
+			}
+			return detectLanguagesXCStrings(filepath.Join(absRoot, t.Dir, t.Pattern))
+		},
+	},
+	TargetTypeQt: {
+		requiresDir:       true,
+		requiresPattern:   true,
+		patternNeedsLang:  true,
+		dirExample:        "translations",
+		patternExample:    "myapp_{lang}.ts",
+		detectUsesPattern: true,
+	},
 }
 
 func validTargetTypes() string {
-	return "gettext, po4a, i18next, vue-i18n, android, yaml, markdown, properties, flutter, js-kv, desktop, polkit"
+	return "gettext, po4a, i18next, vue-i18n, android, yaml, markdown, properties, flutter, js-kv, desktop, polkit, apple-strings, xcstrings, qt"
 }
 
 // ---------------------------------------------------------------------------
@@ -402,40 +619,97 @@ func validateLocaleList(path, field string, locales []string) error {
 	return nil
 }
 
-func validateProviderConfig(path string, provider *ProviderConfig) error {
+// validateProviderList validates every entry of a provider chain. field is
+// the YAML location used in error messages, e.g. "provider" or
+// "target \"web\": provider". Only the first top-level entry may set a prompt.
+func validateProviderList(path, field string, list ProviderList, allowPrompt bool) error {
+	for i := range list {
+		name := field
+		if len(list) > 1 {
+			name = fmt.Sprintf("%s[%d]", field, i)
+		}
+		if err := validateProviderConfig(path, name, &list[i]); err != nil {
+			return err
+		}
+		if list[i].Prompt != "" && (!allowPrompt || i > 0) {
+			return fmt.Errorf("%s: %s.prompt is only supported on the first top-level provider; use the target prompt instead", path, name)
+		}
+	}
+	return nil
+}
+
+func validateProviderConfig(path, field string, provider *ProviderConfig) error {
 	if provider == nil {
 		return nil
 	}
 	if provider.ID == "" {
-		return fmt.Errorf("%s: provider.id is required", path)
+		return fmt.Errorf("%s: %s.id is required", path, field)
 	}
 	if provider.Model == "" {
-		return fmt.Errorf("%s: provider.model is required", path)
+		return fmt.Errorf("%s: %s.model is required", path, field)
 	}
 	supportedProviders := map[string]struct{}{
 		"google":        {},
 		"gemini":        {},
+		"anthropic":     {},
 		"groq":          {},
 		"opencode":      {},
 		"copilot":       {},
 		"openai":        {},
 		"ollama":        {},
 		"custom-openai": {},
+		"mock":          {},
 	}
 	if _, ok := supportedProviders[provider.ID]; !ok {
-		return fmt.Errorf("%s: provider.id %q is not supported", path, provider.ID)
+		return fmt.Errorf("%s: %s.id %q is not supported", path, field, provider.ID)
 	}
 	if provider.BaseURL != "" {
 		switch provider.ID {
 		case "custom-openai", "ollama":
 		default:
-			return fmt.Errorf("%s: provider.base_url is only supported for provider.id custom-openai or ollama", path)
+			return fmt.Errorf("%s: %s.base_url is only supported for provider.id custom-openai or ollama", path, field)
 		}
 	}
 	if provider.Settings.Temperature != nil {
 		t := *provider.Settings.Temperature
 		if t < 0 || t > 2 {
-			return fmt.Errorf("%s: provider.settings.temperature must be between 0 and 2", path)
+			return fmt.Errorf("%s: %s.settings.temperature must be between 0 and 2", path, field)
+		}
+	}
+	return nil
+}
+
+func validateGlossary(path, field string, g *Glossary) error {
+	if g == nil {
+		return nil
+	}
+	for i, term := range g.DoNotTranslate {
+		if strings.TrimSpace(term) == "" {
+			return fmt.Errorf("%s: %s.do_not_translate[%d] is empty", path, field, i)
+		}
+	}
+	for lang, terms := range g.Terms {
+		for src, dst := range terms {
+			if strings.TrimSpace(src) == "" || strings.TrimSpace(dst) == "" {
+				return fmt.Errorf("%s: %s.terms.%s has an empty term", path, field, lang)
+			}
+		}
+	}
+	return nil
+}
+
+func validateLengthLimits(path, field string, limits []LengthLimit) error {
+	for i, l := range limits {
+		if l.Keys != "" {
+			if _, err := regexp.Compile(l.Keys); err != nil {
+				return fmt.Errorf("%s: %s[%d].keys: %v", path, field, i, err)
+			}
+		}
+		if l.Max < 0 || l.Ratio < 0 {
+			return fmt.Errorf("%s: %s[%d] must not be negative", path, field, i)
+		}
+		if l.Max == 0 && l.Ratio == 0 {
+			return fmt.Errorf("%s: %s[%d] needs max or ratio", path, field, i)
 		}
 	}
 	return nil
@@ -473,7 +747,11 @@ func LoadLokitFile(rootDir string) (*LokitFile, error) {
 	if err := validateLocaleList(path, "languages", lf.Languages); err != nil {
 		return nil, err
 	}
-	if err := validateProviderConfig(path, lf.Provider); err != nil {
+	lf.Provider = lf.Providers.Primary()
+	if err := validateProviderList(path, "provider", lf.Providers, true); err != nil {
+		return nil, err
+	}
+	if err := validateGlossary(path, "glossary", lf.Glossary); err != nil {
 		return nil, err
 	}
 
@@ -490,6 +768,23 @@ func LoadLokitFile(rootDir string) (*LokitFile, error) {
 			return nil, fmt.Errorf("%s: duplicate target name %q", path, t.Name)
 		}
 		targetNames[t.Name] = struct{}{}
+		if err := validateGlossary(path, fmt.Sprintf("target %q: glossary", t.Name), t.Glossary); err != nil {
+			return nil, err
+		}
+		if err := validateProviderList(path, fmt.Sprintf("target %q: provider", t.Name), t.Provider, false); err != nil {
+			return nil, err
+		}
+		if t.SourceContext < 0 {
+			return nil, fmt.Errorf("%s: target %q: source_context must not be negative", path, t.Name)
+		}
+		if err := validateEncoding(path, fmt.Sprintf("target %q", t.Name), t.Encoding); err != nil {
+			return nil, err
+		}
+		if err := validateLengthLimits(path, fmt.Sprintf("target %q: length_limits", t.Name), t.LengthLimits); err != nil {
+			return nil, err
+		}
+		// Inherit the top-level glossary (surfaces inherit it from the target)
+		t.Glossary = MergeGlossary(lf.Glossary, t.Glossary)
 		if len(t.Surfaces) > 0 {
 			normalizeTargetSchema(t)
 			if t.Format != "" {
@@ -505,6 +800,21 @@ func LoadLokitFile(rootDir string) (*LokitFile, error) {
 					return nil, fmt.Errorf("%s: target %q surface #%d has no format", path, t.Name, si+1)
 				}
 				s.Type = s.Format
+				if err := validateGlossary(path, fmt.Sprintf("target %q surface #%d: glossary", t.Name, si+1), s.Glossary); err != nil {
+					return nil, err
+				}
+				if err := validateProviderList(path, fmt.Sprintf("target %q surface #%d: provider", t.Name, si+1), s.Provider, false); err != nil {
+					return nil, err
+				}
+				if s.SourceContext < 0 {
+					return nil, fmt.Errorf("%s: target %q surface #%d: source_context must not be negative", path, t.Name, si+1)
+				}
+				if err := validateEncoding(path, fmt.Sprintf("target %q surface #%d", t.Name, si+1), s.Encoding); err != nil {
+					return nil, err
+				}
+				if err := validateLengthLimits(path, fmt.Sprintf("target %q surface #%d: length_limits", t.Name, si+1), s.LengthLimits); err != nil {
+					return nil, err
+				}
 				meta, ok := targetFormatRegistry[s.Type]
 				if !ok {
 					return nil, fmt.Errorf("%s: target %q surface #%d has unknown type %q (valid: %s)", path, t.Name, si+1, s.Type, validTargetTypes())
@@ -651,13 +961,18 @@ func (lf *LokitFile) Resolve(projectRoot string) ([]ResolvedTarget, error) {
 				Sources:        s.Sources,
 				Exclude:        mergeStringSlices(t.Exclude, s.Exclude),
 				Keywords:       s.Keywords,
+				SourceContext:  s.SourceContext,
 				SourceLang:     coalesceString(s.SourceLang, t.SourceLang),
 				Config:         s.Config,
+				Encoding:       coalesceString(s.Encoding, t.Encoding),
 				Languages:      s.Languages,
 				Prompt:         coalesceString(s.Prompt, t.Prompt),
 				LockedKeys:     mergeStringSlices(t.LockedKeys, s.LockedKeys),
 				IgnoredKeys:    mergeStringSlices(t.IgnoredKeys, s.IgnoredKeys),
 				LockedPatterns: mergeStringSlices(t.LockedPatterns, s.LockedPatterns),
+				Glossary:       MergeGlossary(t.Glossary, s.Glossary),
+				LengthLimits:   append(append([]LengthLimit(nil), t.LengthLimits...), s.LengthLimits...),
+				Provider:       firstProviderList(s.Provider, t.Provider),
 			}
 			if st.Type == "" {
 				st.Type = st.Format
@@ -699,6 +1014,13 @@ func (lf *LokitFile) Resolve(projectRoot string) ([]ResolvedTarget, error) {
 	return resolved, nil
 }
 
+func firstProviderList(v, fallback ProviderList) ProviderList {
+	if len(v) > 0 {
+		return v
+	}
+	return fallback
+}
+
 func coalesceString(v, fallback string) string {
 	if v != "" {
 		return v
@@ -1098,6 +1420,57 @@ func detectLanguagesFlutter(dir string) []string {
 	return langs
 }
 
+// detectLanguagesLproj finds language codes from the LANG.lproj directories
+// of an Apple project (e.g. en.lproj, pt-BR.lproj). Base.lproj is skipped.
+func detectLanguagesLproj(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var langs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		lang, ok := strings.CutSuffix(entry.Name(), ".lproj")
+		if ok && (isLangCode(lang) || isI18NextLangCode(lang)) {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// detectLanguagesXCStrings finds the languages localized in a string
+// catalog.
+func detectLanguagesXCStrings(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var catalog struct {
+		Strings map[string]struct {
+			Localizations map[string]json.RawMessage `json:"localizations"`
+		} `json:"strings"`
+	}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var langs []string
+	for _, entry := range catalog.Strings {
+		for lang := range entry.Localizations {
+			if !seen[lang] && (isLangCode(lang) || isI18NextLangCode(lang)) {
+				seen[lang] = true
+				langs = append(langs, lang)
+			}
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
 // AbsPODir returns the absolute PO directory for a gettext target.
 func (rt *ResolvedTarget) AbsPODir() string {
 	return filepath.Join(rt.AbsRoot, rt.Target.Dir)
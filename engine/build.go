@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/po"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+)
+
+// BuildOptions selects what Build compiles.
+type BuildOptions struct {
+	// Targets names the targets to compile (see Engine.Select); empty
+	// selects all gettext targets.
+	Targets []string
+	// Languages limits compiling to these languages; empty selects all
+	// languages of each target.
+	Languages []string
+	// OutputDir, when set, receives the MO files in the gettext install
+	// layout OutputDir/{locale}/LC_MESSAGES/{domain}.mo. A relative
+	// directory is relative to the project root. When empty, each MO file
+	// is written next to its PO file.
+	OutputDir string
+}
+
+// MOFile is an MO file written by Build.
+type MOFile struct {
+	Target string `json:"target"`
+	Lang   string `json:"lang"`
+	Path   string `json:"path"`
+	// Translated is the number of translated messages compiled; fuzzy and
+	// untranslated messages are left out.
+	Translated int `json:"translated"`
+	Total      int `json:"total"`
+}
+
+// BuildResult is the outcome of Build.
+type BuildResult struct {
+	// Targets holds one result per compiled target.
+	Targets []TargetResult
+	// Files lists the MO files written.
+	Files []MOFile
+}
+
+// Build compiles the PO files of the selected gettext targets into MO
+// files, without calling msgfmt. The gettext domain of a target is the
+// name of its POT file without extension. Languages without a PO file are
+// skipped with a warning; other targets are ignored.
+//
+// An error selecting the targets is returned with a nil result, as is an
+// error if no gettext target is selected. Otherwise the result lists every
+// compiled target and the error joins the errors of failed targets.
+func (e *Engine) Build(opts BuildOptions) (*BuildResult, error) {
+	resolved, err := e.Select(opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+	var gettext []config.ResolvedTarget
+	for _, rt := range resolved {
+		if rt.Target.Type == config.TargetTypeGettext {
+			gettext = append(gettext, rt)
+		}
+	}
+	if len(gettext) == 0 {
+		return nil, withKind(ErrConfig, errors.New(T("No gettext targets to build")))
+	}
+
+	outDir := opts.OutputDir
+	if outDir != "" && !filepath.IsAbs(outDir) {
+		outDir = filepath.Join(e.Root, outDir)
+	}
+
+	result := &BuildResult{}
+	for _, rt := range gettext {
+		langs := e.targetLanguages(rt, opts.Languages)
+		e.targetStart(rt)
+		files, err := e.buildTarget(rt, langs, outDir)
+		if err != nil {
+			e.logError(T("[%s] %v"), rt.Target.Name, err)
+		}
+		result.Files = append(result.Files, files...)
+		res := TargetResult{Target: rt, Languages: langs, Err: err}
+		result.Targets = append(result.Targets, res)
+		e.targetDone(res)
+	}
+	return result, targetErrors(result.Targets)
+}
+
+// buildTarget compiles the PO files of rt for langs into outDir, or next
+// to the PO files if outDir is empty.
+func (e *Engine) buildTarget(rt config.ResolvedTarget, langs []string, outDir string) ([]MOFile, error) {
+	domain := moDomain(rt)
+	var files []MOFile
+	var errs []error
+	for _, lang := range langs {
+		poPath := rt.POPath(lang)
+		if _, err := os.Stat(poPath); err != nil {
+			e.logWarning(T("[%s] %s: no PO file, skipping"), rt.Target.Name, lang)
+			continue
+		}
+		poFile, err := po.ParseFile(poPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(T("parsing %s: %w"), poPath, err))
+			continue
+		}
+
+		moPath := strings.TrimSuffix(poPath, ".po") + ".mo"
+		if outDir != "" {
+			moPath = filepath.Join(outDir, moLocale(lang), "LC_MESSAGES", domain+".mo")
+			if err := os.MkdirAll(filepath.Dir(moPath), 0o755); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := poFile.WriteMOFile(moPath); err != nil {
+			errs = append(errs, fmt.Errorf(T("writing %s: %w"), moPath, err))
+			continue
+		}
+
+		total, translated, _, _ := poFile.Stats()
+		e.logInfo(T("%s: %d/%d messages → %s"), lang, translated, total, layout.RelPath(e.Root, moPath))
+		files = append(files, MOFile{
+			Target:     rt.Target.Name,
+			Lang:       lang,
+			Path:       moPath,
+			Translated: translated,
+			Total:      total,
+		})
+	}
+	return files, errors.Join(errs...)
+}
+
+// moDomain returns the gettext domain of rt: its POT file name without
+// extension, or the target name.
+func moDomain(rt config.ResolvedTarget) string {
+	if domain := strings.TrimSuffix(filepath.Base(rt.Target.POT), filepath.Ext(rt.Target.POT)); domain != "" && domain != "." {
+		return domain
+	}
+	return rt.Target.Name
+}
+
+// moLocale returns the locale directory name gettext looks up for lang in
+// the install layout, e.g. pt_BR for pt-BR and sr@latin for sr-Latn.
+// gettext has no script component, so a script subtag becomes an @modifier;
+// Chinese scripts map to the region that conventionally uses them.
+func moLocale(lang string) string {
+	parts := strings.FieldsFunc(lang, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return lang
+	}
+	base := parts[0]
+	var script, region, modifier string
+	if i := strings.IndexByte(base, '@'); i >= 0 {
+		base, modifier = base[:i], base[i+1:]
+	}
+	for _, p := range parts[1:] {
+		if i := strings.IndexByte(p, '@'); i >= 0 {
+			p, modifier = p[:i], p[i+1:]
+		}
+		switch {
+		case len(p) == 4 && script == "" && region == "":
+			script = p
+		case region == "":
+			region = strings.ToUpper(p)
+		}
+	}
+	if script != "" && modifier == "" {
+		if strings.EqualFold(base, "zh") {
+			if region == "" {
+				region = zhScriptRegions[strings.ToLower(script)]
+			}
+		} else if m, ok := scriptModifiers[strings.ToLower(script)]; ok {
+			modifier = m
+		} else {
+			modifier = strings.ToLower(script)
+		}
+	}
+	out := base
+	if region != "" {
+		out += "_" + region
+	}
+	if modifier != "" {
+		out += "@" + modifier
+	}
+	return out
+}
+
+// scriptModifiers maps ISO 15924 script codes to the @modifier names glibc
+// locales use for them.
+var scriptModifiers = map[string]string{
+	"latn": "latin",
+	"cyrl": "cyrillic",
+	"arab": "arabic",
+	"deva": "devanagari",
+}
+
+// zhScriptRegions maps Chinese script subtags to the region gettext catalogs
+// are conventionally named after.
+var zhScriptRegions = map[string]string{
+	"hans": "CN",
+	"hant": "TW",
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leonelquinteros/gotext"
+	"github.com/minios-linux/lokit/config"
+)
+
+func TestBuildInstallLayout(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "source_lang: en\nlanguages: [pt-BR, fr]\ntargets:\n  - name: app\n    format: gettext\n    dir: po\n    pot: myapp.pot\n  - name: ui\n    format: i18next\n    dir: i18n\n    pattern: '{lang}.json'\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "po"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	ptBR := "msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=UTF-8\\n\"\n\nmsgid \"Open\"\nmsgstr \"Abrir\"\n\nmsgid \"Close\"\nmsgstr \"\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "po", "pt_BR.po"), []byte(ptBR), 0644); err != nil {
+		t.Fatalf("write po: %v", err)
+	}
+
+	e, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var log strings.Builder
+	e.OnLog = func(level Level, format string, args ...any) {
+		fmt.Fprintf(&log, format+"\n", args...)
+	}
+	res, err := e.Build(BuildOptions{OutputDir: "locale"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(res.Targets) != 1 || res.Targets[0].Target.Target.Name != "app" {
+		t.Fatalf("targets = %+v, want only the gettext target", res.Targets)
+	}
+	if len(res.Files) != 1 {
+		t.Fatalf("files = %+v, want pt-BR only (fr has no PO file)", res.Files)
+	}
+	f := res.Files[0]
+	want := filepath.Join(dir, "locale", "pt_BR", "LC_MESSAGES", "myapp.mo")
+	if f.Path != want || f.Translated != 1 || f.Total != 2 {
+		t.Fatalf("file = %+v, want %s with 1 of 2 translated", f, want)
+	}
+	if !strings.Contains(log.String(), "fr: no PO file") {
+		t.Fatalf("missing fr warning in log:\n%s", log.String())
+	}
+
+	mo := gotext.NewMo()
+	mo.ParseFile(want)
+	if got := mo.Get("Open"); got != "Abrir" {
+		t.Fatalf("Get(Open) = %q", got)
+	}
+}
+
+func TestMOLocale(t *testing.T) {
+	tests := map[string]string{
+		"fr":         "fr",
+		"pt-BR":      "pt_BR",
+		"pt_BR":      "pt_BR",
+		"sr-Latn":    "sr@latin",
+		"sr-Latn-RS": "sr_RS@latin",
+		"uz-Cyrl":    "uz@cyrillic",
+		"sr@latin":   "sr@latin",
+		"zh-Hans":    "zh_CN",
+		"zh-Hant":    "zh_TW",
+		"zh-Hant-HK": "zh_HK",
+		"zh-TW":      "zh_TW",
+	}
+	for lang, want := range tests {
+		if got := moLocale(lang); got != want {
+			t.Errorf("moLocale(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestBuildInstallLayoutScript(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "source_lang: en\nlanguages: [sr-Latn, zh-Hans]\ntargets:\n  - name: app\n    format: gettext\n    dir: po\n    pot: myapp.pot\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "po"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	po := "msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=UTF-8\\n\"\n\nmsgid \"Open\"\nmsgstr \"Otvori\"\n"
+	for _, name := range []string{"sr_Latn.po", "zh_Hans.po"} {
+		if err := os.WriteFile(filepath.Join(dir, "po", name), []byte(po), 0644); err != nil {
+			t.Fatalf("write po: %v", err)
+		}
+	}
+
+	e, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	res, err := e.Build(BuildOptions{OutputDir: "locale"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range res.Files {
+		got[f.Lang] = f.Path
+	}
+	want := map[string]string{
+		"sr-Latn": filepath.Join(dir, "locale", "sr@latin", "LC_MESSAGES", "myapp.mo"),
+		"zh-Hans": filepath.Join(dir, "locale", "zh_CN", "LC_MESSAGES", "myapp.mo"),
+	}
+	for lang, path := range want {
+		if got[lang] != path {
+			t.Errorf("%s installed at %q, want %q", lang, got[lang], path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("stat %s: %v", path, err)
+		}
+	}
+}
+
+func TestBuildWithoutGettextTargets(t *testing.T) {
+	e := &Engine{Root: t.TempDir(), Config: &config.LokitFile{}}
+	if res, err := e.Build(BuildOptions{}); res != nil || !errors.Is(err, ErrConfig) {
+		t.Fatalf("Build() = %v, %v; want ErrConfig", res, err)
+	}
+}
@@ -0,0 +1,465 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/minios-linux/lokit/config"
+	formatfile "github.com/minios-linux/lokit/format"
+	"github.com/minios-linux/lokit/format/android"
+	"github.com/minios-linux/lokit/format/applestrings"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/desktop"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/format/jskv"
+	mdfile "github.com/minios-linux/lokit/format/markdown"
+	"github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/polkit"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	"github.com/minios-linux/lokit/format/qt"
+	"github.com/minios-linux/lokit/format/vuei18n"
+	"github.com/minios-linux/lokit/format/xcstrings"
+	yamlfile "github.com/minios-linux/lokit/format/yaml"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/translate"
+)
+
+// Issue is a problem found in a translation.
+type Issue struct {
+	Target string `json:"target"`
+	Lang   string `json:"lang"`
+	File   string `json:"file"`
+	Key    string `json:"key"`
+	// Rule is the check that found the issue, e.g. translate.CheckLength.
+	Rule string `json:"rule"`
+	// Severity is translate.SeverityError or translate.SeverityWarning.
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// CheckReport is the outcome of Check, as printed by lokit check --json.
+type CheckReport struct {
+	// Checked is the number of translated strings checked.
+	Checked  int     `json:"checked"`
+	Errors   int     `json:"errors"`
+	Warnings int     `json:"warnings"`
+	Issues   []Issue `json:"issues"`
+}
+
+// CheckOptions selects what Check checks.
+type CheckOptions struct {
+	// Targets names the targets to check (see Engine.Select); empty
+	// selects all targets.
+	Targets []string
+	// Languages limits checking to these languages; empty selects all
+	// languages of each target.
+	Languages []string
+}
+
+// Check checks the existing translations of the selected targets for
+// common problems such as mismatched placeholders, without calling any
+// provider. Files that cannot be read are reported as warnings through
+// OnLog and skipped.
+func (e *Engine) Check(opts CheckOptions) (*CheckReport, error) {
+	resolved, err := e.Select(opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CheckReport{Issues: []Issue{}}
+	for _, rt := range resolved {
+		for _, lang := range e.targetLanguages(rt, opts.Languages) {
+			units, extra, err := e.collectCheckUnits(rt, lang)
+			if err != nil {
+				e.logWarning(T("[%s] %s: %v"), rt.Target.Name, lang, err)
+				continue
+			}
+			report.Checked += len(units)
+			issues := append(extra, checkUnits(rt, lang, units)...)
+			for i := range issues {
+				issues[i].Target = rt.Target.Name
+				issues[i].Lang = lang
+			}
+			report.Issues = append(report.Issues, issues...)
+		}
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity == translate.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	return report, nil
+}
+
+// checkUnit is one translated string found in a target file.
+type checkUnit struct {
+	file        string
+	key         string
+	source      string
+	translation string
+	entry       *po.Entry // set for gettext/po4a units only
+	maxLength   int       // length declared by the file (Android, ARB)
+}
+
+// lengthLimit returns the maximum length of u's translation, or 0.
+// length_limits patterns match msgids for PO units and keys otherwise.
+func (u checkUnit) lengthLimit(limits *translate.LengthLimits) int {
+	key := u.key
+	if u.entry != nil {
+		key = u.entry.MsgID
+	}
+	return limits.Limit(key, u.source, u.maxLength)
+}
+
+func checkUnits(rt config.ResolvedTarget, lang string, units []checkUnit) []Issue {
+	markdown := rt.Target.Type == config.TargetTypeMarkdown
+	limits := layout.LengthLimits(&rt.Target)
+	var issues []Issue
+	for _, u := range units {
+		var found []translate.CheckIssue
+		if u.entry != nil {
+			found = translate.CheckPOTranslation(u.entry, u.source, u.translation)
+		} else if rt.Target.Type == config.TargetTypeFlutter {
+			found = translate.CheckARBTranslation(u.source, u.translation, lang)
+		} else {
+			found = translate.CheckKVTranslation(u.source, u.translation, markdown)
+		}
+		found = append(found, translate.CheckTranslationLength(u.translation, u.lengthLimit(limits))...)
+		for _, f := range found {
+			issues = append(issues, Issue{File: u.file, Key: u.key, Rule: f.Rule, Severity: f.Severity, Message: f.Message})
+		}
+	}
+	return issues
+}
+
+// collectCheckUnits returns the translated strings of one target language.
+// Format-specific issues that cannot be detected from the parsed values
+// (such as Android apostrophe escaping) are returned separately.
+func (e *Engine) collectCheckUnits(rt config.ResolvedTarget, lang string) ([]checkUnit, []Issue, error) {
+	switch rt.Target.Type {
+	case config.TargetTypeGettext:
+		units, err := e.collectPOCheckUnits(rt.POPath(lang))
+		return units, nil, err
+	case config.TargetTypePo4a:
+		// A broken PO file is reported without skipping the documents
+		// that can be checked.
+		var units []checkUnit
+		for _, file := range rt.DocsPOFiles(lang) {
+			fileUnits, err := e.collectPOCheckUnits(file.Path)
+			if err != nil {
+				e.logWarning(T("[%s] %s: %v"), rt.Target.Name, lang, err)
+				continue
+			}
+			units = append(units, fileUnits...)
+		}
+		return units, nil, nil
+	case config.TargetTypeI18Next:
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			f, err := i18next.ParseFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return i18nextCheckFile{f}, nil
+		})
+		return units, nil, err
+	case config.TargetTypeVueI18n:
+		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+			units, err := e.collectIndexCheckUnits(rt, lang)
+			return units, nil, err
+		}
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			return vuei18n.ParseFile(path)
+		})
+		return units, nil, err
+	case config.TargetTypeAndroid:
+		return e.collectAndroidCheckUnits(rt, lang)
+	case config.TargetTypeYAML:
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			return yamlfile.ParseFile(path)
+		})
+		return units, nil, err
+	case config.TargetTypeMarkdown:
+		units, err := e.collectMarkdownCheckUnits(rt, lang)
+		return units, nil, err
+	case config.TargetTypeProperties:
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			return propfile.ParseFile(path)
+		})
+		return units, nil, err
+	case config.TargetTypeFlutter:
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			return arbfile.ParseFile(path)
+		})
+		return units, nil, err
+	case config.TargetTypeJSKV:
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			return jskv.ParseFile(path)
+		})
+		return units, nil, err
+	case config.TargetTypeDesktop:
+		units, err := e.collectInlineCheckUnits(rt.SourcePath(), func(path string) (checkKVFile, error) {
+			return desktop.ParseFile(path, lang)
+		})
+		return units, nil, err
+	case config.TargetTypePolkit:
+		units, err := e.collectInlineCheckUnits(rt.SourcePath(), func(path string) (checkKVFile, error) {
+			return polkit.ParseFile(path, lang)
+		})
+		return units, nil, err
+	case config.TargetTypeAppleStrings:
+		units, err := e.collectKVCheckUnits(rt, lang, func(path string) (checkKVFile, error) {
+			return applestrings.ParseFile(path)
+		})
+		return units, nil, err
+	case config.TargetTypeXCStrings:
+		units, err := e.collectInlineCheckUnits(rt.SourcePath(), func(path string) (checkKVFile, error) {
+			return xcstrings.ParseFile(path, lang)
+		})
+		return units, nil, err
+	case config.TargetTypeQt:
+		units, err := e.collectQtCheckUnits(rt, lang)
+		return units, nil, err
+	default:
+		return nil, nil, fmt.Errorf(T("unsupported target type %q"), rt.Target.Type)
+	}
+}
+
+func (e *Engine) collectPOCheckUnits(path string) ([]checkUnit, error) {
+	catalog, err := po.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read PO %s: %v"), path, err)
+	}
+	return catalogCheckUnits(layout.RelPath(e.Root, path), catalog), nil
+}
+
+// collectQtCheckUnits checks a Qt Linguist file through its PO view, so its
+// placeholders are checked by their qt-format flags.
+func (e *Engine) collectQtCheckUnits(rt config.ResolvedTarget, lang string) ([]checkUnit, error) {
+	path := rt.ExistingTranslationPath(lang)
+	if path == "" {
+		return nil, nil
+	}
+	f, err := qt.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return catalogCheckUnits(layout.RelPath(e.Root, path), f.POFile(lang)), nil
+}
+
+// catalogCheckUnits returns the translated entries of a PO catalog read
+// from file. Plural entries give one unit per form.
+func catalogCheckUnits(file string, catalog *po.File) []checkUnit {
+	var units []checkUnit
+	for _, e := range catalog.Entries {
+		if e.MsgID == "" || e.Obsolete || e.IsFuzzy() {
+			continue
+		}
+		key := e.MsgID
+		if e.MsgCtxt != "" {
+			key = e.MsgCtxt + "|" + e.MsgID
+		}
+		if e.MsgIDPlural == "" {
+			if e.MsgStr != "" {
+				units = append(units, checkUnit{file: file, key: key, source: e.MsgID, translation: e.MsgStr, entry: e})
+			}
+			continue
+		}
+		forms := make([]int, 0, len(e.MsgStrPlural))
+		for form := range e.MsgStrPlural {
+			forms = append(forms, form)
+		}
+		sort.Ints(forms)
+		for _, form := range forms {
+			value := e.MsgStrPlural[form]
+			if value == "" {
+				continue
+			}
+			source := e.MsgIDPlural
+			if form == 0 {
+				source = e.MsgID
+			}
+			units = append(units, checkUnit{file: file, key: fmt.Sprintf("%s[%d]", key, form), source: source, translation: value, entry: e})
+		}
+	}
+	return units
+}
+
+// checkKVFile is the subset of key-value file methods used by check.
+type checkKVFile interface {
+	Keys() []string
+	Get(key string) (string, bool)
+	SourceValues() map[string]string
+}
+
+// i18nextCheckFile uses i18next values rather than keys as source values.
+type i18nextCheckFile struct{ *i18next.File }
+
+func (f i18nextCheckFile) SourceValues() map[string]string {
+	vals := make(map[string]string, len(f.Translations))
+	for key, v := range f.Translations {
+		if v == "" {
+			v = key
+		}
+		vals[key] = v
+	}
+	return vals
+}
+
+// collectKVCheckUnits pairs a separate source file with a translation file.
+func (e *Engine) collectKVCheckUnits(rt config.ResolvedTarget, lang string, parse func(path string) (checkKVFile, error)) ([]checkUnit, error) {
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := parse(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read source file %s: %v"), srcPath, err)
+	}
+	path := rt.ExistingTranslationPath(lang)
+	if path == "" {
+		return nil, nil
+	}
+	file, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+	units := pairCheckUnits(layout.RelPath(e.Root, path), file.Keys(), srcFile.SourceValues(), file.Get)
+	if kv, ok := srcFile.(formatfile.KVFile); ok {
+		setCheckMaxLengths(units, translate.KVMaxLengths(kv))
+	}
+	return units, nil
+}
+
+// setCheckMaxLengths sets the file-declared maximum lengths of units.
+func setCheckMaxLengths(units []checkUnit, limits map[string]int) {
+	for i := range units {
+		units[i].maxLength = limits[units[i].key]
+	}
+}
+
+// collectInlineCheckUnits reads formats that keep all languages in the
+// source file (desktop, polkit).
+func (e *Engine) collectInlineCheckUnits(path string, parse func(path string) (checkKVFile, error)) ([]checkUnit, error) {
+	file, err := parse(path)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read source file %s: %v"), path, err)
+	}
+	return pairCheckUnits(layout.RelPath(e.Root, path), file.Keys(), file.SourceValues(), file.Get), nil
+}
+
+func pairCheckUnits(file string, keys []string, srcVals map[string]string, get func(string) (string, bool)) []checkUnit {
+	var units []checkUnit
+	for _, key := range keys {
+		source, ok := srcVals[key]
+		if !ok || source == "" {
+			continue
+		}
+		value, ok := get(key)
+		if !ok || value == "" {
+			continue
+		}
+		units = append(units, checkUnit{file: file, key: key, source: source, translation: value})
+	}
+	return units
+}
+
+func (e *Engine) collectAndroidCheckUnits(rt config.ResolvedTarget, lang string) ([]checkUnit, []Issue, error) {
+	srcPath := android.SourceStringsXMLPath(rt.AbsResDir())
+	srcFile, err := android.ParseFile(srcPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf(T("cannot read source strings.xml %s: %v"), srcPath, err)
+	}
+	path := android.StringsXMLPath(rt.AbsResDir(), lang)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	file, err := android.Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rel := layout.RelPath(e.Root, path)
+	srcVals := layout.AndroidSourceValues(srcFile)
+	vals := layout.AndroidSourceValues(file)
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	units := pairCheckUnits(rel, keys, srcVals, func(key string) (string, bool) {
+		v, ok := vals[key]
+		return v, ok
+	})
+	setCheckMaxLengths(units, translate.KVMaxLengths(translate.NewAndroidKVFile(file, srcFile, lang)))
+
+	var issues []Issue
+	invalid, err := android.InvalidApostrophes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range invalid {
+		issues = append(issues, Issue{
+			File:     rel,
+			Key:      key,
+			Rule:     translate.CheckAndroidApostrophes,
+			Severity: translate.SeverityError,
+			Message:  T(`unescaped apostrophe; use \' or wrap the string in double quotes`),
+		})
+	}
+	return units, issues, nil
+}
+
+func (e *Engine) collectMarkdownCheckUnits(rt config.ResolvedTarget, lang string) ([]checkUnit, error) {
+	srcDir := layout.MarkdownSourceDir(rt)
+	srcFiles, err := layout.MarkdownSourceFiles(rt)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read markdown files in %s: %v"), srcDir, err)
+	}
+	var units []checkUnit
+	for _, srcPath := range srcFiles {
+		relPath, err := filepath.Rel(srcDir, srcPath)
+		if err != nil {
+			continue
+		}
+		srcFile, err := mdfile.ParseFile(srcPath)
+		if err != nil {
+			continue
+		}
+		targetPath := filepath.Join(layout.MarkdownLangDir(rt, lang), relPath)
+		mf, err := mdfile.ParseFile(targetPath)
+		if err != nil {
+			continue
+		}
+		units = append(units, pairCheckUnits(layout.RelPath(e.Root, targetPath), mf.Keys(), srcFile.SourceValues(), mf.Get)...)
+	}
+	return units, nil
+}
+
+func (e *Engine) collectIndexCheckUnits(rt config.ResolvedTarget, lang string) ([]checkUnit, error) {
+	item, err := layout.LoadIndexItem(rt)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	path := rt.TranslationPath(lang)
+	f, err := layout.ParseIndexFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(item.Fields))
+	for key := range item.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return pairCheckUnits(layout.RelPath(e.Root, path), keys, item.Fields, func(key string) (string, bool) {
+		return f.Get(key), true
+	}), nil
+}
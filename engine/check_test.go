@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/translate"
+)
+
+func TestCollectCheckUnitsJSKV(t *testing.T) {
+	dir := t.TempDir()
+	translationsDir := filepath.Join(dir, "translations")
+	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
+		t.Fatalf("mkdir translations: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "en.js"), []byte("window.translations = {\n    \"Hello, %s\": \"Hello, %s\",\n    \"Bye\": \"Bye\",\n    \"OK\": \"OK\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "de.js"), []byte("window.translations = {\n    \"Hello, %s\": \"Hallo\",\n    \"Bye\": \"Tschüss\",\n    \"OK\": \"\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write translation: %v", err)
+	}
+
+	rt := config.ResolvedTarget{
+		Target: config.Target{
+			Name:       "welcome",
+			Type:       config.TargetTypeJSKV,
+			Format:     config.TargetTypeJSKV,
+			Dir:        "translations",
+			Pattern:    "{lang}.js",
+			SourceLang: "en",
+		},
+		AbsRoot: dir,
+	}
+
+	e := &Engine{Root: dir}
+	units, extra, err := e.collectCheckUnits(rt, "de")
+	if err != nil {
+		t.Fatalf("collectCheckUnits: %v", err)
+	}
+	if len(extra) != 0 {
+		t.Fatalf("unexpected format issues: %+v", extra)
+	}
+	if len(units) != 2 {
+		t.Fatalf("units = %+v, want 2 translated units", units)
+	}
+
+	issues := checkUnits(rt, "de", units)
+	if len(issues) != 1 {
+		t.Fatalf("issues = %+v, want 1", issues)
+	}
+	if issues[0].Key != "Hello, %s" || issues[0].Rule != translate.CheckPlaceholders || issues[0].Severity != translate.SeverityError {
+		t.Fatalf("unexpected issue: %+v", issues[0])
+	}
+}
+
+func TestCollectCheckUnitsPo4aReportsBrokenPO(t *testing.T) {
+	dir := t.TempDir()
+	langDir := filepath.Join(dir, "po", "de")
+	if err := os.MkdirAll(langDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "po4a.cfg"), nil, 0o644); err != nil {
+		t.Fatalf("write po4a.cfg: %v", err)
+	}
+	good := "msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n\nmsgid \"Hello\"\nmsgstr \"Hallo\"\n"
+	if err := os.WriteFile(filepath.Join(langDir, "a.po"), []byte(good), 0o644); err != nil {
+		t.Fatalf("write a.po: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(langDir, "b.po"), []byte("msgid \"Bye\"\nmsgid_plural \"Byes\"\nmsgstr[x] \"Tschüss\"\n"), 0o644); err != nil {
+		t.Fatalf("write b.po: %v", err)
+	}
+
+	rt := config.ResolvedTarget{
+		Target:  config.Target{Name: "docs", Type: config.TargetTypePo4a, Config: "po4a.cfg", SourceLang: "en"},
+		AbsRoot: dir,
+	}
+	var warnings []string
+	e := &Engine{Root: dir, OnLog: func(level Level, format string, args ...any) {
+		if level == LevelWarning {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		}
+	}}
+	units, _, err := e.collectCheckUnits(rt, "de")
+	if err != nil {
+		t.Fatalf("collectCheckUnits: %v", err)
+	}
+	if len(units) != 1 {
+		t.Fatalf("units = %+v, want the entry of a.po", units)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "b.po") {
+		t.Fatalf("warnings = %q, want one for b.po", warnings)
+	}
+}
@@ -0,0 +1,205 @@
+// Package engine runs lokit programmatically: it loads lokit.yaml, resolves
+// its targets and reports their status, initializes translation files,
+// translates them and compiles gettext catalogs, like the lokit commands do.
+//
+// Progress is reported through the callbacks of an Engine rather than
+// printed, and failures are returned as errors:
+//
+//	e, err := engine.Load(".")
+//	if err != nil {
+//		return err
+//	}
+//	e.OnLog = func(level engine.Level, format string, args ...any) {
+//		log.Printf(format, args...)
+//	}
+//	res, err := e.Translate(ctx, engine.TranslateOptions{
+//		Provider: "ollama",
+//		Model:    "llama3",
+//	})
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/minios-linux/lokit/config"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+)
+
+// ErrNoConfig is returned by Load when the project root has no lokit.yaml.
+var ErrNoConfig = errors.New("no lokit.yaml found")
+
+// ErrConfig is wrapped by errors caused by the configuration: an invalid
+// lokit.yaml, unknown target names or incomplete provider settings.
+// Missing or rejected provider credentials wrap
+// translate.ErrAuthentication instead.
+var ErrConfig = errors.New("invalid configuration")
+
+// kindError marks an error as matching a sentinel such as ErrConfig
+// without changing its message.
+type kindError struct {
+	kind, err error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// withKind returns err marked as matching kind, or nil if err is nil.
+func withKind(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelSuccess
+	LevelWarning
+	LevelError
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelSuccess:
+		return "success"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Engine runs lokit operations on one project. Its callbacks may be set
+// before an operation starts; they are called from the goroutine running
+// it, or concurrently from provider requests during translation.
+type Engine struct {
+	// Root is the project root holding lokit.yaml.
+	Root string
+	// Config is the parsed lokit.yaml.
+	Config *config.LokitFile
+	// Targets are the resolved targets of Config, in configuration order.
+	Targets []config.ResolvedTarget
+
+	// OnLog receives progress messages; nil discards them.
+	OnLog func(level Level, format string, args ...any)
+	// OnTargetStart is called before a target is initialized or
+	// translated.
+	OnTargetStart func(rt config.ResolvedTarget)
+	// OnTargetDone is called with the result of each target after it has
+	// been initialized or translated.
+	OnTargetDone func(res TargetResult)
+	// Output receives the output of external tools such as po4a; nil
+	// discards it.
+	Output io.Writer
+
+	// logged counts the errors logged, including those a target recovered
+	// from.
+	logged atomic.Int64
+}
+
+// Load reads lokit.yaml from root and resolves its targets. It returns
+// ErrNoConfig if root has no lokit.yaml.
+func Load(root string) (*Engine, error) {
+	lf, err := config.LoadLokitFile(root)
+	if err != nil {
+		return nil, withKind(ErrConfig, fmt.Errorf(T("Config error: %v"), err))
+	}
+	if lf == nil {
+		return nil, fmt.Errorf("%w in %s", ErrNoConfig, root)
+	}
+	return New(root, lf)
+}
+
+// New returns an engine for the parsed configuration lf of the project at
+// root.
+func New(root string, lf *config.LokitFile) (*Engine, error) {
+	resolved, err := lf.Resolve(root)
+	if err != nil {
+		return nil, withKind(ErrConfig, fmt.Errorf(T("Config resolve error: %v"), err))
+	}
+	return &Engine{Root: root, Config: lf, Targets: resolved}, nil
+}
+
+// Select returns the targets named in names. Names may hold
+// comma-separated lists, and a name selects the targets expanded from it
+// (name/...) when no target has that exact name. All targets are returned
+// if names is empty.
+func (e *Engine) Select(names []string) ([]config.ResolvedTarget, error) {
+	resolved, err := layout.SelectTargets(e.Targets, names)
+	return resolved, withKind(ErrConfig, err)
+}
+
+// absRoot returns the absolute project root.
+func (e *Engine) absRoot() string {
+	abs, err := filepath.Abs(e.Root)
+	if err != nil {
+		return e.Root
+	}
+	return abs
+}
+
+// output returns the writer for external tool output.
+func (e *Engine) output() io.Writer {
+	if e.Output == nil {
+		return io.Discard
+	}
+	return e.Output
+}
+
+func (e *Engine) log(level Level, format string, args ...any) {
+	if level == LevelError {
+		e.logged.Add(1)
+	}
+	if e.OnLog != nil {
+		e.OnLog(level, format, args...)
+	}
+}
+
+func (e *Engine) logInfo(format string, args ...any)    { e.log(LevelInfo, format, args...) }
+func (e *Engine) logSuccess(format string, args ...any) { e.log(LevelSuccess, format, args...) }
+func (e *Engine) logWarning(format string, args ...any) { e.log(LevelWarning, format, args...) }
+func (e *Engine) logError(format string, args ...any)   { e.log(LevelError, format, args...) }
+
+func (e *Engine) targetStart(rt config.ResolvedTarget) {
+	if e.OnTargetStart != nil {
+		e.OnTargetStart(rt)
+	}
+}
+
+func (e *Engine) targetDone(res TargetResult) {
+	if e.OnTargetDone != nil {
+		e.OnTargetDone(res)
+	}
+}
+
+// TargetResult is the outcome of initializing or translating one target.
+type TargetResult struct {
+	Target config.ResolvedTarget
+	// Languages are the languages processed.
+	Languages []string
+	// Err is the error the target failed with, or nil.
+	Err error
+}
+
+// targetErrors joins the errors of failed targets, each prefixed with the
+// target name.
+func targetErrors(results []TargetResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Target.Target.Name, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/translate"
+)
+
+func TestLoadWithoutConfig(t *testing.T) {
+	if _, err := Load(t.TempDir()); !errors.Is(err, ErrNoConfig) {
+		t.Fatalf("Load() error = %v, want ErrNoConfig", err)
+	}
+}
+
+func TestTranslateWithMockProvider(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "source_lang: en\nlanguages: [de]\ntargets:\n  - name: ui\n    format: i18next\n    dir: i18n\n    pattern: '{lang}.json'\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "i18n"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "i18n", "en.json"), []byte(`{"translations": {"hello": "Hello", "bye": "Bye"}}`), 0644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	e, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var started, done []string
+	e.OnTargetStart = func(rt config.ResolvedTarget) { started = append(started, rt.Target.Name) }
+	e.OnTargetDone = func(res TargetResult) { done = append(done, res.Target.Target.Name) }
+
+	res, err := e.Translate(context.Background(), TranslateOptions{
+		Provider: translate.ProviderMock,
+		Model:    translate.MockModelPseudo,
+		NoMemory: true,
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if len(res.Targets) != 1 || res.Targets[0].Err != nil || strings.Join(res.Targets[0].Languages, ",") != "de" {
+		t.Fatalf("results = %+v", res.Targets)
+	}
+	if strings.Join(started, ",") != "ui" || strings.Join(done, ",") != "ui" {
+		t.Fatalf("callbacks: started %v, done %v", started, done)
+	}
+	if res.Usage == nil {
+		t.Fatalf("no usage tracker in result")
+	}
+	if res.Translated != 2 {
+		t.Fatalf("Translated = %d, want 2", res.Translated)
+	}
+
+	de, err := i18next.ParseFile(filepath.Join(dir, "i18n", "de.json"))
+	if err != nil {
+		t.Fatalf("read translation: %v", err)
+	}
+	if _, translated, _ := de.Stats(); translated != 2 {
+		t.Fatalf("translated = %d, want 2", translated)
+	}
+
+	res, err = e.Translate(context.Background(), TranslateOptions{
+		Provider: translate.ProviderMock,
+		Model:    translate.MockModelPseudo,
+		NoMemory: true,
+	})
+	if err != nil {
+		t.Fatalf("second run: Translate() error = %v", err)
+	}
+	if res.Translated != 0 {
+		t.Fatalf("second run: Translated = %d, want 0", res.Translated)
+	}
+}
+
+func TestTranslateReviewDisabled(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "source_lang: en\nlanguages: [de]\nreview:\n  disabled: true\ntargets:\n  - name: ui\n    format: i18next\n    dir: i18n\n    pattern: '{lang}.json'\n  - name: app\n    format: gettext\n    dir: po\n    pot: app.pot\n"
+	files := map[string]string{
+		"lokit.yaml":   yaml,
+		"i18n/en.json": `{"translations": {"hello": "Hello"}}`,
+		"po/app.pot":   "msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=UTF-8\\n\"\n\nmsgid \"Open\"\nmsgstr \"\"\n",
+		"po/de.po":     "msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=UTF-8\\nLanguage: de\\n\"\n\nmsgid \"Open\"\nmsgstr \"\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	e, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	res, err := e.Translate(context.Background(), TranslateOptions{
+		Provider: translate.ProviderMock,
+		Model:    translate.MockModelPseudo,
+		NoMemory: true,
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if res.Translated != 2 {
+		t.Fatalf("Translated = %d, want 2", res.Translated)
+	}
+	if _, err := os.Stat(filepath.Join(dir, review.FileName)); !os.IsNotExist(err) {
+		t.Errorf("%s written with review disabled (stat error %v)", review.FileName, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "po", "de.po"))
+	if err != nil {
+		t.Fatalf("read de.po: %v", err)
+	}
+	if strings.Contains(string(data), "lokit: machine") {
+		t.Errorf("de.po has a review comment with review disabled:\n%s", data)
+	}
+}
+
+func TestTranslateUnknownTarget(t *testing.T) {
+	e := &Engine{Root: t.TempDir(), Config: &config.LokitFile{}}
+	res, err := e.Translate(context.Background(), TranslateOptions{Targets: []string{"missing"}})
+	if res != nil || !errors.Is(err, ErrConfig) {
+		t.Fatalf("Translate() = %v, %v; want ErrConfig", res, err)
+	}
+}
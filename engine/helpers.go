@@ -1,4 +1,4 @@
-package cli
+package engine
 
 import (
 	"fmt"
@@ -12,10 +12,11 @@ import (
 	"github.com/minios-linux/lokit/config"
 	"github.com/minios-linux/lokit/copilot"
 	"github.com/minios-linux/lokit/extract"
+	"github.com/minios-linux/lokit/format/desktop"
+	po "github.com/minios-linux/lokit/format/po"
 	"github.com/minios-linux/lokit/gemini"
 	. "github.com/minios-linux/lokit/i18n"
-	"github.com/minios-linux/lokit/internal/format/desktop"
-	po "github.com/minios-linux/lokit/internal/format/po"
+	"github.com/minios-linux/lokit/internal/layout"
 	"github.com/minios-linux/lokit/merge"
 	"github.com/minios-linux/lokit/openai"
 	"github.com/minios-linux/lokit/settings"
@@ -26,7 +27,7 @@ import (
 // list of .desktop / .nemo_action files found during the scan as a byproduct.
 // Callers receive the desktop file list for free, avoiding a second
 // FindSources walk when seeding PO files.
-func doExtract(proj *config.Project) ([]string, error) {
+func (e *Engine) doExtract(proj *config.Project) ([]string, error) {
 	// If no source dirs configured, scan the project root
 	scanDirs := proj.SourceDirs
 	if len(scanDirs) == 0 {
@@ -34,7 +35,7 @@ func doExtract(proj *config.Project) ([]string, error) {
 		scanDirs = []string{absRoot}
 	}
 
-	logInfo(T("Scanning for source files in: %s"), strings.Join(scanDirs, ", "))
+	e.logInfo(T("Scanning for source files in: %s"), strings.Join(scanDirs, ", "))
 
 	var explicitFiles []string
 	var scanRoots []string
@@ -99,7 +100,7 @@ func doExtract(proj *config.Project) ([]string, error) {
 		baseDir = absBase
 	}
 
-	logInfo(T("Found %d source files (%s)"), len(allFiles), extract.DescribeFiles(allFiles))
+	e.logInfo(T("Found %d source files (%s)"), len(allFiles), extract.DescribeFiles(allFiles))
 
 	// Split into Go files (need xgotext) and everything else (need xgettext)
 	goFiles, otherFiles := extract.SplitGoFiles(allFiles)
@@ -131,18 +132,18 @@ func doExtract(proj *config.Project) ([]string, error) {
 		}
 
 		if len(proj.Keywords) > 0 {
-			logInfo(T("Extracting from %d Go files (AST, keywords: %s)..."),
+			e.logInfo(T("Extracting from %d Go files (AST, keywords: %s)..."),
 				len(goFiles), strings.Join(proj.Keywords, ", "))
 			return extract.RunGoExtract(goDirs, outPotFile, proj.Name, proj.Keywords, baseDir)
 		}
-		logInfo(T("Extracting from %d Go files with xgotext..."), len(goFiles))
+		e.logInfo(T("Extracting from %d Go files with xgotext..."), len(goFiles))
 		return extract.RunXgotext(goDirs, outPotFile, proj.Name)
 	}
 
 	switch {
 	case len(otherFiles) > 0 && len(goFiles) > 0:
 		// Both Go and non-Go files: extract separately, then merge
-		logInfo(T("Extracting from %d non-Go files with xgettext..."), len(otherFiles))
+		e.logInfo(T("Extracting from %d non-Go files with xgettext..."), len(otherFiles))
 		xgettextResult, err := extract.RunXgettext(otherFilesForXgettext, potFile, "", "", "", proj.Keywords, baseDir)
 		if err != nil {
 			// Return desktopFiles even on failure: the scan succeeded and callers
@@ -156,15 +157,15 @@ func doExtract(proj *config.Project) ([]string, error) {
 
 		goResult, err := extractGo(goFiles, goPotFile)
 		if err != nil {
-			logError(T("Go extraction failed: %v"), err)
-			logInfo(T("Continuing with xgettext results only"))
+			e.logError(T("Go extraction failed: %v"), err)
+			e.logInfo(T("Continuing with xgettext results only"))
 			finalPOT = xgettextResult.POTFile
 			break
 		}
 		_ = goResult
 
 		// Merge the two POT files
-		logInfo(T("Merging POT files..."))
+		e.logInfo(T("Merging POT files..."))
 		if err := extract.MergePOTFiles(xgettextResult.POTFile, goPotFile, potFile); err != nil {
 			return desktopFiles, fmt.Errorf(T("merging POT files: %w"), err)
 		}
@@ -205,33 +206,27 @@ func doExtract(proj *config.Project) ([]string, error) {
 				count++
 			}
 		}
-		logSuccess(T("Extracted %d strings to %s"), count, finalPOT)
+		e.logSuccess(T("Extracted %d strings to %s"), count, finalPOT)
 	} else {
-		logSuccess(T("Extracted strings to %s"), finalPOT)
+		e.logSuccess(T("Extracted strings to %s"), finalPOT)
 	}
 
 	return desktopFiles, nil
 }
 
-// fileExists returns true if the file exists and is not a directory.
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	return err == nil && !info.IsDir()
-}
-
 // createPOFromPOT creates a new PO file from the POT template for the given
 // language. If root and desktopFiles are provided, inline desktop translations
 // are seeded into the PO before it is written to disk — keeping the behaviour
 // consistent with runInitCode and the translate pre-extract merge step.
 // Returns nil if the POT file can't be found or read.
-func createPOFromPOT(proj *config.Project, lang, poPath, root string, desktopFiles []string) *po.File {
+func (e *Engine) createPOFromPOT(proj *config.Project, lang, poPath, root string, desktopFiles []string) *po.File {
 	potPath := proj.POTPathResolved()
-	if potPath == "" || !fileExists(potPath) {
+	if potPath == "" || !layout.FileExists(potPath) {
 		// No POT template — try auto-extracting first
-		logInfo(T("No POT template found, running extraction..."))
-		extracted, err := doExtract(proj)
+		e.logInfo(T("No POT template found, running extraction..."))
+		extracted, err := e.doExtract(proj)
 		if err != nil {
-			logError(T("Auto-extraction failed: %v"), err)
+			e.logError(T("Auto-extraction failed: %v"), err)
 			return nil
 		}
 		// Use desktop files from auto-extraction when caller didn't supply any.
@@ -241,9 +236,9 @@ func createPOFromPOT(proj *config.Project, lang, poPath, root string, desktopFil
 		// Re-resolve POT path after extraction
 		proj.POTFile = proj.POTPathResolved()
 		potPath = proj.POTFile
-		if potPath == "" || !fileExists(potPath) {
-			logError(T("Cannot auto-create %s: extraction produced no POT template"), poPath)
-			logInfo(T("Check that source files contain translatable strings (_(), N_(), etc.)"))
+		if potPath == "" || !layout.FileExists(potPath) {
+			e.logError(T("Cannot auto-create %s: extraction produced no POT template"), poPath)
+			e.logInfo(T("Check that source files contain translatable strings (_(), N_(), etc.)"))
 			return nil
 		}
 	}
@@ -258,7 +253,7 @@ func createPOFromPOT(proj *config.Project, lang, poPath, root string, desktopFil
 
 	potPO, err := po.ParseFile(potPath)
 	if err != nil {
-		logError(T("Cannot read POT template %s: %v"), potPath, err)
+		e.logError(T("Cannot read POT template %s: %v"), potPath, err)
 		return nil
 	}
 
@@ -282,20 +277,20 @@ func createPOFromPOT(proj *config.Project, lang, poPath, root string, desktopFil
 
 	// Seed inline desktop translations before writing so the new PO is as
 	// complete as init-created PO files.
-	seedDesktopTranslations(newPO, lang, root, desktopFiles)
+	e.seedDesktopTranslations(newPO, lang, root, desktopFiles)
 
 	// Ensure parent directory exists
 	if err := os.MkdirAll(filepath.Dir(poPath), 0755); err != nil {
-		logError(T("Creating directory for %s: %v"), poPath, err)
+		e.logError(T("Creating directory for %s: %v"), poPath, err)
 		return nil
 	}
 
 	if err := newPO.WriteFile(poPath); err != nil {
-		logError(T("Creating %s: %v"), poPath, err)
+		e.logError(T("Creating %s: %v"), poPath, err)
 		return nil
 	}
 
-	logSuccess(T("Auto-created %s from %s (%d entries)"), poPath, potPath, len(newPO.Entries))
+	e.logSuccess(T("Auto-created %s from %s (%d entries)"), poPath, potPath, len(newPO.Entries))
 	return newPO
 }
 
@@ -315,26 +310,26 @@ func copyFlags(flags []string) []string {
 // mergeAndSeedPO merges potPO into existingPO and seeds desktop translations
 // in one step. This is the canonical post-extract PO update used by both
 // runInitCode and translateGettextTarget, ensuring the two paths stay in sync.
-func mergeAndSeedPO(existingPO, potPO *po.File, lang, root string, desktopFiles []string) *po.File {
+func (e *Engine) mergeAndSeedPO(existingPO, potPO *po.File, lang, root string, desktopFiles []string) *po.File {
 	merged := merge.Merge(existingPO, potPO)
-	seedDesktopTranslations(merged, lang, root, desktopFiles)
+	e.seedDesktopTranslations(merged, lang, root, desktopFiles)
 	return merged
 }
 
 // seedDesktopTranslations fills PO entries from inline .desktop translations.
 // desktopFiles must come from the doExtract return value (single scan per run).
 // root is the project root used to compute relative paths.
-func seedDesktopTranslations(poFile *po.File, lang, root string, desktopFiles []string) {
+func (e *Engine) seedDesktopTranslations(poFile *po.File, lang, root string, desktopFiles []string) {
 	if len(desktopFiles) == 0 {
 		return
 	}
 	n, err := desktop.SeedPO(poFile, lang, root, desktopFiles)
 	if err != nil {
-		logWarning(T("Desktop seeding for %s: %v"), lang, err)
+		e.logWarning(T("Desktop seeding for %s: %v"), lang, err)
 		// Don't return early: n entries may still have been seeded.
 	}
 	if n > 0 {
-		logInfo(T("Seeded %d desktop translations for %s"), n, lang)
+		e.logInfo(T("Seeded %d desktop translations for %s"), n, lang)
 	}
 }
 
@@ -382,18 +377,22 @@ func resolveProvider(name, baseURL, apiKey, model, proxy string, timeout time.Du
 	return prov
 }
 
+// validateProvider checks that prov can be used. Missing credentials wrap
+// translate.ErrAuthentication, incomplete settings wrap ErrConfig.
 func validateProvider(prov translate.Provider, apiKey string) error {
 	// Check if model is specified
 	if prov.Model == "" {
 		modelGuidance := map[string]string{
 			translate.ProviderGoogle:       T("choose a model available through Google AI Studio"),
 			translate.ProviderGemini:       T("choose a model available through Gemini CLI OAuth"),
+			translate.ProviderAnthropic:    T("choose a Claude model available through the Anthropic API"),
 			translate.ProviderGroq:         T("choose a model available in your Groq account"),
 			translate.ProviderOpenCode:     T("choose a model available through OpenCode Zen"),
 			translate.ProviderCopilot:      T("choose a model available in your GitHub Copilot plan"),
 			translate.ProviderOpenAI:       T("choose a model available through your OpenAI authentication method"),
 			translate.ProviderOllama:       T("choose a model installed on your Ollama server"),
 			translate.ProviderCustomOpenAI: T("choose a model supported by your endpoint"),
+			translate.ProviderMock:         T("use 'pseudo' (accented, bracketed) or 'echo' (source text)"),
 		}
 
 		guidance := modelGuidance[prov.ID]
@@ -401,10 +400,10 @@ func validateProvider(prov translate.Provider, apiKey string) error {
 			guidance = T("check provider documentation")
 		}
 
-		return fmt.Errorf(T("--model is required for provider '%s'\n\n"+
+		return withKind(ErrConfig, fmt.Errorf(T("--model is required for provider '%s'\n\n"+
 			"Model guidance for %s:\n  %s\n\n"+
 			"Usage: --provider %s --model MODEL_NAME"),
-			prov.ID, prov.Name, guidance, prov.ID)
+			prov.ID, prov.Name, guidance, prov.ID))
 	}
 
 	switch prov.ID {
@@ -415,61 +414,71 @@ func validateProvider(prov translate.Provider, apiKey string) error {
 				// OAuth token available, no API key needed
 				break
 			}
-			return fmt.Errorf(T("provider 'google' requires an API key or Gemini OAuth login\n\n" +
-				"Option 1: Store an API key:\n" +
-				"  lokit auth login --provider google\n\n" +
-				"Option 2: Login with Google OAuth:\n" +
-				"  lokit auth login --provider gemini\n\n" +
-				"Option 3: Pass key directly:\n" +
-				"  --api-key YOUR_KEY or export GOOGLE_API_KEY=YOUR_KEY\n\n" +
-				"Get an API key from: https://aistudio.google.com/apikey"))
+			return withKind(translate.ErrAuthentication, fmt.Errorf(T("provider 'google' requires an API key or Gemini OAuth login\n\n"+
+				"Option 1: Store an API key:\n"+
+				"  lokit auth login --provider google\n\n"+
+				"Option 2: Login with Google OAuth:\n"+
+				"  lokit auth login --provider gemini\n\n"+
+				"Option 3: Pass key directly:\n"+
+				"  --api-key YOUR_KEY or export GOOGLE_API_KEY=YOUR_KEY\n\n"+
+				"Get an API key from: https://aistudio.google.com/apikey")))
 		}
 
 	case translate.ProviderGemini:
 		if gemini.LoadToken() == nil {
-			return fmt.Errorf(T("provider 'gemini' requires Google OAuth login\n\n" +
-				"Login with your Google account:\n" +
-				"  lokit auth login --provider gemini\n\n" +
-				"This uses Gemini CLI OAuth.\n" +
-				"For API key access, use --provider google instead."))
+			return withKind(translate.ErrAuthentication, fmt.Errorf(T("provider 'gemini' requires Google OAuth login\n\n"+
+				"Login with your Google account:\n"+
+				"  lokit auth login --provider gemini\n\n"+
+				"This uses Gemini CLI OAuth.\n"+
+				"For API key access, use --provider google instead.")))
+		}
+
+	case translate.ProviderAnthropic:
+		if apiKey == "" {
+			return withKind(translate.ErrAuthentication, fmt.Errorf(T("provider 'anthropic' requires an API key\n\n"+
+				"Option 1: Store your API key:\n"+
+				"  lokit auth login --provider anthropic\n\n"+
+				"Option 2: Pass key directly:\n"+
+				"  --api-key YOUR_KEY or export ANTHROPIC_API_KEY=YOUR_KEY\n\n"+
+				"Get an API key from: https://console.anthropic.com/settings/keys")))
 		}
 
 	case translate.ProviderGroq:
 		if apiKey == "" {
-			return fmt.Errorf(T("provider 'groq' requires an API key\n\n" +
-				"Option 1: Store your API key:\n" +
-				"  lokit auth login --provider groq\n\n" +
-				"Option 2: Pass key directly:\n" +
-				"  --api-key YOUR_KEY or export GROQ_API_KEY=YOUR_KEY\n\n" +
-				"Get an API key from: https://console.groq.com/keys"))
+			return withKind(translate.ErrAuthentication, fmt.Errorf(T("provider 'groq' requires an API key\n\n"+
+				"Option 1: Store your API key:\n"+
+				"  lokit auth login --provider groq\n\n"+
+				"Option 2: Pass key directly:\n"+
+				"  --api-key YOUR_KEY or export GROQ_API_KEY=YOUR_KEY\n\n"+
+				"Get an API key from: https://console.groq.com/keys")))
 		}
 
 	case translate.ProviderOpenAI:
 		baseURL := strings.TrimRight(prov.BaseURL, "/")
 		if baseURL != "" && baseURL != "https://api.openai.com/v1" {
-			return fmt.Errorf(T("provider 'openai' does not support custom provider.base_url\n\n" +
-				"How to fix:\n" +
-				"  - Remove provider.base_url from config, or\n" +
-				"  - Switch to provider 'custom-openai' for custom endpoints."))
+			return withKind(ErrConfig, fmt.Errorf(T("provider 'openai' does not support custom provider.base_url\n\n"+
+				"How to fix:\n"+
+				"  - Remove provider.base_url from config, or\n"+
+				"  - Switch to provider 'custom-openai' for custom endpoints.")))
 		}
 		if apiKey == "" && openai.LoadToken() == nil {
-			return fmt.Errorf(T("provider 'openai' requires authentication\n\n" +
-				"Option 1: Login interactively:\n" +
-				"  lokit auth login --provider openai\n\n" +
-				"Option 2: Choose a specific method:\n" +
-				"  lokit auth login --provider openai --auth-method oauth\n" +
-				"  lokit auth login --provider openai --auth-method device\n" +
-				"  lokit auth login --provider openai --auth-method api-key\n\n" +
-				"Option 3: Pass key directly:\n" +
-				"  --api-key YOUR_KEY or export OPENAI_API_KEY=YOUR_KEY\n\n" +
-				"Get an API key from: https://platform.openai.com/api-keys"))
+			return withKind(translate.ErrAuthentication, fmt.Errorf(T("provider 'openai' requires authentication\n\n"+
+				"Option 1: Login interactively:\n"+
+				"  lokit auth login --provider openai\n\n"+
+				"Option 2: Choose a specific method:\n"+
+				"  lokit auth login --provider openai --auth-method oauth\n"+
+				"  lokit auth login --provider openai --auth-method device\n"+
+				"  lokit auth login --provider openai --auth-method api-key\n\n"+
+				"Option 3: Pass key directly:\n"+
+				"  --api-key YOUR_KEY or export OPENAI_API_KEY=YOUR_KEY\n\n"+
+				"Get an API key from: https://platform.openai.com/api-keys")))
 		}
 		if apiKey == "" && openai.LoadToken() != nil &&
 			!openai.IsOAuthModel(prov.Model) {
-			return fmt.Errorf(T("provider 'openai' via OAuth/device auth only supports OpenAI models available through the OAuth Responses API\n\n" +
-				"How to fix:\n" +
-				"  - Choose a model supported by your OpenAI OAuth session, or\n" +
-				"  - Use an OpenAI API key for models that are not available via OAuth."))
+			return withKind(ErrConfig, fmt.Errorf(T("provider 'openai' via OAuth/device auth only supports OpenAI models available through the OAuth Responses API\n\n"+
+				"How to fix:\n"+
+				"  - Choose a model supported by your OpenAI OAuth session, or\n"+
+				"  - Use an OpenAI API key for models that are not available via OAuth.")))
 		}
 
 	case translate.ProviderOpenCode:
@@ -477,19 +486,19 @@ func validateProvider(prov translate.Provider, apiKey string) error {
 
 	case translate.ProviderCopilot:
 		if copilot.LoadToken() == nil {
-			return fmt.Errorf(T("provider 'copilot' requires GitHub Copilot authentication\n\n" +
-				"Login with your GitHub account:\n" +
-				"  lokit auth login --provider copilot\n\n" +
-				"This uses GitHub Copilot. Available models depend on your Copilot plan."))
+			return withKind(translate.ErrAuthentication, fmt.Errorf(T("provider 'copilot' requires GitHub Copilot authentication\n\n"+
+				"Login with your GitHub account:\n"+
+				"  lokit auth login --provider copilot\n\n"+
+				"This uses GitHub Copilot. Available models depend on your Copilot plan.")))
 		}
 
 	case translate.ProviderCustomOpenAI:
 		if prov.BaseURL == "" {
-			return fmt.Errorf(T("provider 'custom-openai' requires an endpoint URL\n\n" +
-				"Option 1: Configure via auth:\n" +
-				"  lokit auth login --provider custom-openai\n\n" +
-				"Option 2: Pass directly:\n" +
-				"  --base-url https://api.example.com/v1"))
+			return withKind(ErrConfig, fmt.Errorf(T("provider 'custom-openai' requires an endpoint URL\n\n"+
+				"Option 1: Configure via auth:\n"+
+				"  lokit auth login --provider custom-openai\n\n"+
+				"Option 2: Pass directly:\n"+
+				"  --base-url https://api.example.com/v1")))
 		}
 
 	case translate.ProviderOllama:
@@ -508,71 +517,15 @@ func validateProvider(prov translate.Provider, apiKey string) error {
 				"  --provider google          (requires API key)"))
 		}
 		resp.Body.Close()
-	}
-
-	return nil
-}
 
-func filterResolvedTargetsByNames(resolved []config.ResolvedTarget, targets []string) ([]config.ResolvedTarget, error) {
-	if len(targets) == 0 {
-		return resolved, nil
-	}
-
-	selected := make([]string, 0, len(targets))
-	seenInput := make(map[string]struct{}, len(targets))
-	for _, raw := range targets {
-		for _, part := range strings.Split(raw, ",") {
-			name := strings.TrimSpace(part)
-			if name == "" {
-				continue
-			}
-			if _, ok := seenInput[name]; ok {
-				continue
-			}
-			seenInput[name] = struct{}{}
-			selected = append(selected, name)
+	case translate.ProviderMock:
+		if prov.Model != translate.MockModelPseudo && prov.Model != translate.MockModelEcho {
+			return withKind(ErrConfig, fmt.Errorf(T("provider 'mock' does not support model '%s'\n\n"+
+				"Available models:\n"+
+				"  pseudo   bracketed, accented, length-expanded pseudo-translations\n"+
+				"  echo     copy the source text unchanged"), prov.Model))
 		}
 	}
 
-	if len(selected) == 0 {
-		return resolved, nil
-	}
-
-	result := make([]config.ResolvedTarget, 0, len(resolved))
-	added := make(map[string]struct{}, len(resolved))
-
-	appendTarget := func(rt config.ResolvedTarget) {
-		if _, ok := added[rt.Target.Name]; ok {
-			return
-		}
-		added[rt.Target.Name] = struct{}{}
-		result = append(result, rt)
-	}
-
-	for _, target := range selected {
-		exactFound := false
-		for _, rt := range resolved {
-			if rt.Target.Name == target {
-				appendTarget(rt)
-				exactFound = true
-				break
-			}
-		}
-		if exactFound {
-			continue
-		}
-
-		prefixFound := false
-		for _, rt := range resolved {
-			if strings.HasPrefix(rt.Target.Name, target+"/") {
-				appendTarget(rt)
-				prefixFound = true
-			}
-		}
-		if !prefixFound {
-			return nil, fmt.Errorf(T("Target %q not found in lokit.yaml"), target)
-		}
-	}
-
-	return result, nil
+	return nil
 }
@@ -0,0 +1,434 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/format/po"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+)
+
+// InitOptions selects what Init initializes.
+type InitOptions struct {
+	// Targets names the targets to initialize (see Engine.Select); empty
+	// selects all targets.
+	Targets []string
+	// Languages replaces the languages of each target; empty selects all
+	// languages but the source language.
+	Languages []string
+}
+
+// InitResult is the outcome of Init.
+type InitResult struct {
+	// Targets holds one result per selected target.
+	Targets []TargetResult
+}
+
+// Init creates or updates the translation files of the selected targets
+// from their sources, without translating anything: gettext targets are
+// extracted and merged, po4a targets run po4a, and key-value targets get
+// the keys missing from their translation files.
+//
+// An error selecting the targets is returned with a nil result. Otherwise
+// the result lists every target and the error joins the errors of failed
+// targets.
+func (e *Engine) Init(opts InitOptions) (*InitResult, error) {
+	resolved, err := e.Select(opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InitResult{}
+	for _, rt := range resolved {
+		langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
+		if len(opts.Languages) > 0 {
+			langs = opts.Languages
+		}
+
+		e.targetStart(rt)
+		err := e.initTarget(rt, langs)
+		if err != nil {
+			e.logError(T("[%s] %v"), rt.Target.Name, err)
+		}
+		res := TargetResult{Target: rt, Languages: langs, Err: err}
+		result.Targets = append(result.Targets, res)
+		e.targetDone(res)
+	}
+	return result, targetErrors(result.Targets)
+}
+
+// initTarget initializes the translation files of one target into langs.
+func (e *Engine) initTarget(rt config.ResolvedTarget, langs []string) error {
+	switch rt.Target.Type {
+	case config.TargetTypeGettext:
+		proj := &config.Project{
+			Root:        rt.AbsRoot,
+			Name:        rt.Target.Name,
+			PODir:       rt.AbsPODir(),
+			POTFile:     rt.AbsPOTFile(),
+			POStructure: config.POStructureFlat,
+			Languages:   langs,
+			Keywords:    rt.Target.Keywords,
+			SourceLang:  rt.Target.SourceLang,
+		}
+		if len(rt.Target.Sources) > 0 {
+			for _, src := range rt.Target.Sources {
+				proj.SourceDirs = append(proj.SourceDirs, filepath.Join(rt.AbsRoot, src))
+			}
+		} else {
+			proj.SourceDirs = []string{rt.AbsRoot}
+		}
+		return e.runInitCode(proj)
+
+	case config.TargetTypePo4a:
+		proj := &config.Project{
+			Name:        rt.Target.Name,
+			POStructure: config.POStructurePo4a,
+			Po4aConfig:  rt.AbsPo4aConfig(),
+			Languages:   langs,
+			SourceLang:  rt.Target.SourceLang,
+		}
+		proj.PODir = filepath.Join(filepath.Dir(proj.Po4aConfig), "po")
+		// Check for docs directory for manpage generation
+		for _, candidate := range []string{"docs", "doc"} {
+			docsDir := filepath.Join(e.absRoot(), candidate)
+			if info, err := os.Stat(docsDir); err == nil && info.IsDir() {
+				proj.DocsDir = docsDir
+				break
+			}
+		}
+		proj.ManpagesDir = filepath.Dir(proj.Po4aConfig)
+		return e.doPo4aInit(proj)
+
+	case config.TargetTypeI18Next:
+		proj := &config.Project{
+			Name:               rt.Target.Name,
+			Type:               config.ProjectTypeI18Next,
+			I18NextDir:         rt.AbsTranslationsDir(),
+			I18NextPathPattern: rt.Target.Pattern,
+			Languages:          langs,
+			SourceLang:         rt.Target.SourceLang,
+		}
+		return e.runInitI18Next(proj)
+
+	case config.TargetTypeVueI18n:
+		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+			return e.runInitIndex(rt, langs)
+		}
+		return e.runInitVueI18n(rt, langs)
+
+	case config.TargetTypeAndroid:
+		e.logInfo(T("Android targets do not require init — use 'lokit translate' directly."))
+
+	case config.TargetTypeYAML:
+		return e.runInitYAML(rt, langs)
+
+	case config.TargetTypeMarkdown:
+		return e.runInitMarkdown(rt, langs)
+
+	case config.TargetTypeProperties:
+		return e.runInitProperties(rt, langs)
+
+	case config.TargetTypeFlutter:
+		return e.runInitFlutter(rt, langs)
+
+	case config.TargetTypeJSKV:
+		return e.runInitJSKV(rt, langs)
+
+	case config.TargetTypeDesktop:
+		e.logInfo(T("Desktop targets do not require init — use 'lokit translate' directly."))
+
+	case config.TargetTypePolkit:
+		e.logInfo(T("Polkit targets do not require init — use 'lokit translate' directly."))
+
+	case config.TargetTypeAppleStrings:
+		return e.runInitAppleStrings(rt, langs)
+
+	case config.TargetTypeXCStrings:
+		e.logInfo(T("String catalog targets do not require init — use 'lokit translate' directly."))
+
+	case config.TargetTypeQt:
+		return e.runInitQt(rt, langs)
+
+	default:
+		e.logWarning(T("[%s] Unknown target type %q, skipping"), rt.Target.Name, rt.Target.Type)
+	}
+	return nil
+}
+
+func (e *Engine) runInitI18Next(proj *config.Project) error {
+	srcPath := proj.I18NextPath(proj.SourceLang)
+	srcFile, err := i18next.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source language file %s: %v"), srcPath, err)
+	}
+
+	srcKeys := srcFile.Keys()
+	e.logInfo(T("Source language (%s): %d keys"), proj.SourceLang, len(srcKeys))
+
+	created, updated := 0, 0
+
+	for _, lang := range proj.Languages {
+		if lang == proj.SourceLang {
+			continue
+		}
+
+		filePath := proj.I18NextPath(lang)
+		file, err := i18next.ParseFile(filePath)
+
+		if err != nil {
+			// Create new file with all keys empty
+			file = i18next.NewTranslationFile(srcFile, lang)
+			if err := file.WriteFile(filePath); err != nil {
+				e.logError(T("Creating %s: %v"), filePath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), filePath, len(file.Keys()))
+			created++
+			continue
+		}
+
+		// Sync keys: add missing, don't remove extras (they may be intentional)
+		added := file.SyncKeys(srcFile, lang)
+
+		if added > 0 {
+			if err := file.WriteFile(filePath); err != nil {
+				e.logError(T("Updating %s: %v"), filePath, err)
+				continue
+			}
+			e.logSuccess(T("Updated: %s (+%d new keys)"), filePath, added)
+			updated++
+		} else {
+			e.logInfo(T("%s: up to date"), lang)
+		}
+	}
+
+	e.logInfo(T("Summary: %d created, %d updated"), created, updated)
+	return nil
+}
+
+// generateManpagesFromMarkdown generates man pages from markdown files if they don't exist.
+// This is needed for po4a projects that reference .1, .7 files in po4a.cfg but only have .md sources.
+func (e *Engine) generateManpagesFromMarkdown(proj *config.Project) error {
+	// Only process if we have both po4a config and docs directory
+	if proj.Po4aConfig == "" || proj.DocsDir == "" {
+		return nil
+	}
+
+	// Check if pandoc is available
+	if _, err := exec.LookPath("pandoc"); err != nil {
+		e.logInfo(T("pandoc not found, skipping manpage generation from markdown"))
+		return nil
+	}
+
+	// Check if docs directory exists
+	if _, err := os.Stat(proj.DocsDir); os.IsNotExist(err) {
+		return nil // No docs directory, nothing to generate
+	}
+
+	manpageDir := filepath.Dir(proj.Po4aConfig)
+
+	// Find all markdown files in docs that look like manpage sources (name.section.md)
+	// Example: myapp.1.md -> myapp.1
+	mdFiles, err := filepath.Glob(filepath.Join(proj.DocsDir, "*.*.md"))
+	if err != nil {
+		return fmt.Errorf(T("failed to list markdown files: %w"), err)
+	}
+
+	generated := 0
+	for _, mdPath := range mdFiles {
+		mdFile := filepath.Base(mdPath)
+
+		// Extract manpage name (remove .md extension)
+		// Example: myapp.1.md -> myapp.1
+		if !strings.HasSuffix(mdFile, ".md") {
+			continue
+		}
+		manFile := strings.TrimSuffix(mdFile, ".md")
+
+		// Check if this looks like a manpage (has section number)
+		// Format should be: name.section where section is a digit
+		parts := strings.Split(manFile, ".")
+		if len(parts) < 2 {
+			continue
+		}
+		lastPart := parts[len(parts)-1]
+		if len(lastPart) != 1 || lastPart[0] < '0' || lastPart[0] > '9' {
+			continue
+		}
+
+		manPath := filepath.Join(manpageDir, manFile)
+
+		// Skip if manpage already exists
+		if _, err := os.Stat(manPath); err == nil {
+			continue
+		}
+
+		// Generate manpage from markdown
+		e.logInfo(T("Generating %s from %s"), manFile, mdFile)
+		cmd := exec.Command("pandoc", "-s", "-t", "man", mdPath, "-o", manPath)
+		cmd.Stdout = e.output()
+		cmd.Stderr = e.output()
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf(T("pandoc failed for %s: %w"), mdFile, err)
+		}
+
+		// Post-process: remove \c sequences that cause po4a issues
+		if err := removeBackslashCSequences(manPath); err != nil {
+			e.logWarning(T("Failed to post-process %s: %v"), manFile, err)
+		}
+
+		generated++
+	}
+
+	if generated > 0 {
+		e.logSuccess(T("Generated %d manpage(s) from markdown"), generated)
+	}
+
+	return nil
+}
+
+// removeBackslashCSequences removes \c escape sequences from manpage files.
+// These are generated by pandoc but cause issues with po4a.
+func removeBackslashCSequences(filePath string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	// Remove " \c" sequences (space + backslash + c)
+	// This is safe because \c is just a line continuation that po4a doesn't like
+	modified := strings.ReplaceAll(string(content), " \\c\n", "\n")
+
+	if modified != string(content) {
+		return os.WriteFile(filePath, []byte(modified), 0644)
+	}
+
+	return nil
+}
+
+// scanPo4aLanguages scans a PO directory for language subdirectories containing .po files.
+func scanPo4aLanguages(poDir string) []string {
+	return config.DetectLanguagesNested(poDir)
+}
+
+// doPo4aInit runs po4a --no-translations to generate/update POT and PO files.
+// Returns nil on success.
+func (e *Engine) doPo4aInit(proj *config.Project) error {
+	if _, err := exec.LookPath("po4a"); err != nil {
+		return fmt.Errorf(T("po4a is not installed. Install with: sudo apt install po4a"))
+	}
+
+	// Check if manpages need to be generated from markdown
+	if err := e.generateManpagesFromMarkdown(proj); err != nil {
+		e.logWarning(T("Failed to generate manpages from markdown: %v"), err)
+		e.logWarning(T("Continuing anyway, po4a might fail if source files don't exist"))
+	}
+
+	e.logInfo(T("Running po4a --no-translations %s ..."), proj.Po4aConfig)
+	cmd := exec.Command("po4a", "--no-translations", proj.Po4aConfig)
+	cmd.Dir = filepath.Dir(proj.Po4aConfig)
+	cmd.Stdout = e.output()
+	cmd.Stderr = e.output()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(T("po4a failed: %v"), err)
+	}
+	e.logSuccess(T("po4a updated POT and PO files"))
+
+	// Re-scan languages from PO files after po4a ran (it may have created new ones)
+	poDir := proj.PODir
+	if poDir != "" {
+		if scannedLangs := scanPo4aLanguages(poDir); len(scannedLangs) > 0 {
+			proj.Languages = scannedLangs
+		}
+	}
+
+	// Re-resolve POT file (po4a may have generated it)
+	proj.POTFile = proj.POTPathResolved()
+	return nil
+}
+
+func (e *Engine) runInitCode(proj *config.Project) error {
+	// Step 1: Extract strings; desktop files are a byproduct of the scan —
+	// no second FindSources walk is needed for seeding.
+	desktopFiles, err := e.doExtract(proj)
+	if err != nil {
+		return err
+	}
+
+	// Step 2: Update PO files from POT
+	potPO, err := po.ParseFile(proj.POTFile)
+	if err != nil {
+		return fmt.Errorf(T("Reading %s: %v"), proj.POTFile, err)
+	}
+
+	e.logInfo(T("Updating PO files for: %s"), strings.Join(proj.Languages, ", "))
+
+	root := proj.Root
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+
+	created, updated := 0, 0
+
+	for _, lang := range proj.Languages {
+		poPath := proj.POPath(lang)
+
+		if err := os.MkdirAll(filepath.Dir(poPath), 0755); err != nil {
+			e.logError(T("Creating directory for %s: %v"), poPath, err)
+			continue
+		}
+
+		if _, err := os.Stat(poPath); os.IsNotExist(err) {
+			newPO := po.NewFile()
+			newPO.Header = po.MakeHeader(proj.Name, proj.Version, proj.BugsEmail, proj.CopyrightHolder, lang)
+			newPO.SetHeaderField("Plural-Forms", po.PluralFormsForLang(lang))
+
+			for _, pe := range potPO.Entries {
+				entry := &po.Entry{
+					ExtractedComments: pe.ExtractedComments,
+					References:        pe.References,
+					Flags:             copyFlags(pe.Flags),
+					MsgCtxt:           pe.MsgCtxt,
+					MsgID:             pe.MsgID,
+					MsgIDPlural:       pe.MsgIDPlural,
+					MsgStr:            "",
+					MsgStrPlural:      make(map[int]string),
+				}
+				newPO.Entries = append(newPO.Entries, entry)
+			}
+
+			e.seedDesktopTranslations(newPO, lang, root, desktopFiles)
+
+			if err := newPO.WriteFile(poPath); err != nil {
+				e.logError(T("Creating %s: %v"), poPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s"), poPath)
+			created++
+		} else {
+			existingPO, err := po.ParseFile(poPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), poPath, err)
+				continue
+			}
+
+			merged := e.mergeAndSeedPO(existingPO, potPO, lang, root, desktopFiles)
+			if err := merged.WriteFile(poPath); err != nil {
+				e.logError(T("Writing %s: %v"), poPath, err)
+				continue
+			}
+			e.logSuccess(T("Updated: %s"), poPath)
+			updated++
+		}
+	}
+
+	e.logInfo(T("Summary: %d created, %d updated"), created, updated)
+	return nil
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/settings"
+	"github.com/minios-linux/lokit/translate"
+)
+
+// providerResolver builds the provider fallback chain for each target.
+// Command-line --provider/--model/--base-url replace every configured
+// chain; otherwise a target's own provider list wins over the top-level
+// one. Targets with identical lists share a chain, so a provider that
+// failed once is skipped for the rest of the run.
+type providerResolver struct {
+	e         *Engine
+	global    config.ProviderList
+	override  bool
+	apiKey    string
+	apiKeyFor string
+	a         translateArgs
+	chains    map[string]*translate.ProviderChain
+}
+
+func (e *Engine) newProviderResolver(lf *config.LokitFile, a translateArgs) *providerResolver {
+	r := &providerResolver{
+		e:      e,
+		global: lf.ProviderChain(),
+		apiKey: a.apiKey,
+		a:      a,
+		chains: make(map[string]*translate.ProviderChain),
+	}
+	if a.provider != "" || a.model != "" || a.baseURL != "" {
+		// Flags override the first configured provider, as a single entry.
+		var p config.ProviderConfig
+		if primary := r.global.Primary(); primary != nil {
+			p = *primary
+		}
+		if a.provider != "" {
+			p.ID = a.provider
+		}
+		if a.model != "" {
+			p.Model = a.model
+		}
+		if a.baseURL != "" {
+			p.BaseURL = a.baseURL
+		}
+		r.global = config.ProviderList{p}
+		r.override = true
+	}
+	// --api-key belongs to the primary provider; others use their
+	// environment variable or stored credential.
+	if p := r.global.Primary(); p != nil {
+		r.apiKeyFor = p.ID
+	}
+	return r
+}
+
+// list returns the provider list configured for a target.
+func (r *providerResolver) list(rt config.ResolvedTarget) config.ProviderList {
+	if !r.override && len(rt.Target.Provider) > 0 {
+		return rt.Target.Provider
+	}
+	return r.global
+}
+
+// forTarget returns the provider chain for a target. Providers failing
+// validation (e.g. missing credentials) are skipped with a warning as long
+// as another provider of the chain remains usable.
+func (r *providerResolver) forTarget(rt config.ResolvedTarget) (*translate.ProviderChain, error) {
+	list := r.list(rt)
+	if len(list) == 0 || list[0].ID == "" {
+		return nil, withKind(ErrConfig, fmt.Errorf(T("No provider specified. Use --provider to choose an AI translation service.\n\n")+
+			"Example: lokit translate --provider copilot --model MODEL_NAME"))
+	}
+	cacheKey, _ := json.Marshal(list)
+	if chain, ok := r.chains[string(cacheKey)]; ok {
+		return chain, nil
+	}
+
+	var provs []translate.Provider
+	var firstErr error
+	for _, p := range list {
+		key := settings.ResolveAPIKey(p.ID, "")
+		if r.apiKey != "" && p.ID == r.apiKeyFor {
+			key = r.apiKey
+		}
+		prov := resolveProvider(p.ID, p.BaseURL, key, p.Model, r.a.proxy, r.a.timeout)
+		if p.Settings.Temperature != nil {
+			prov.Temperature = *p.Settings.Temperature
+		}
+		if err := validateProvider(prov, key); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			if len(list) > 1 {
+				reason, _, _ := strings.Cut(err.Error(), "\n")
+				r.e.logWarning(T("Skipping provider %s (%s): %s"), p.ID, p.Model, reason)
+			}
+			continue
+		}
+		provs = append(provs, prov)
+	}
+	if len(provs) == 0 {
+		return nil, firstErr
+	}
+
+	chain := translate.NewProviderChain(provs...)
+	chain.OnSwitch = func(from, to translate.Provider, reason error) {
+		r.e.logWarning(T("%s (%s) failed: %v"), from.Name, from.Model, reason)
+		r.e.logWarning(T("Falling back to %s (%s)"), to.Name, to.Model)
+	}
+	r.chains[string(cacheKey)] = chain
+	return chain, nil
+}
+
+// activeFor returns the active provider of a target's chain and a copy of
+// a using that chain. Chains are validated before translation starts, so
+// this only fails on errors already reported there.
+func (r *providerResolver) activeFor(rt config.ResolvedTarget, a translateArgs) (translate.Provider, translateArgs, error) {
+	chain, err := r.forTarget(rt)
+	if err != nil {
+		return translate.Provider{}, a, err
+	}
+	a.providers = chain
+	return chain.Active(), a, nil
+}
@@ -0,0 +1,452 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/minios-linux/lokit/config"
+	formatfile "github.com/minios-linux/lokit/format"
+	"github.com/minios-linux/lokit/format/android"
+	"github.com/minios-linux/lokit/format/applestrings"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/desktop"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/format/jskv"
+	mdfile "github.com/minios-linux/lokit/format/markdown"
+	"github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/polkit"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	"github.com/minios-linux/lokit/format/qt"
+	"github.com/minios-linux/lokit/format/vuei18n"
+	"github.com/minios-linux/lokit/format/xcstrings"
+	yamlfile "github.com/minios-linux/lokit/format/yaml"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/translate"
+)
+
+// States of a translation file in LangStatus.
+const (
+	StatusOK      = "ok"
+	StatusMissing = "missing"
+	StatusError   = "error"
+)
+
+// LangStatus holds the translation counts of one target language.
+type LangStatus struct {
+	Lang string `json:"lang"`
+	// Status is StatusOK, StatusMissing or StatusError.
+	Status       string `json:"status"`
+	Total        int    `json:"total"`
+	Translated   int    `json:"translated"`
+	Fuzzy        int    `json:"fuzzy"`
+	Untranslated int    `json:"untranslated"`
+	Percent      int    `json:"percent"`
+	// Locked is the number of keys recorded in the lock file.
+	Locked int `json:"locked"`
+	// TooLong is the number of translations over their length limit.
+	TooLong int    `json:"too_long"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TargetStatus holds the translation status of one target.
+type TargetStatus struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Root       string `json:"root"`
+	SourceLang string `json:"source_lang"`
+	// Source is the number of source strings.
+	Source int `json:"source"`
+	// Error is set when the source cannot be read. The languages are then
+	// listed with StatusError and no counts.
+	Error string `json:"error,omitempty"`
+	// SourceMissing is set when the source file does not exist;
+	// SourcePath names it.
+	SourceMissing bool         `json:"source_missing,omitempty"`
+	SourcePath    string       `json:"source_path,omitempty"`
+	Languages     []LangStatus `json:"languages"`
+}
+
+// LockStatus describes the lock file.
+type LockStatus struct {
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Targets int    `json:"targets"`
+	Keys    int    `json:"keys"`
+}
+
+// StatusReport is the translation status of a project, as printed by
+// lokit status --json.
+type StatusReport struct {
+	Root       string         `json:"root"`
+	SourceLang string         `json:"source_lang"`
+	Lock       LockStatus     `json:"lock"`
+	Targets    []TargetStatus `json:"targets"`
+}
+
+// Status reports the translation status of the selected targets (see
+// Engine.Select) without modifying any file.
+func (e *Engine) Status(targets []string) (*StatusReport, error) {
+	resolved, err := e.Select(targets)
+	if err != nil {
+		return nil, err
+	}
+	report := &StatusReport{
+		Root:       e.absRoot(),
+		SourceLang: e.Config.SourceLang,
+		Targets:    []TargetStatus{},
+	}
+
+	lockF, err := lockfile.Load(e.Root)
+	if err != nil {
+		return nil, fmt.Errorf(T("Could not load lock file: %v"), err)
+	}
+	report.Lock.Path = lockF.Path()
+	if _, err := os.Stat(lockF.Path()); err == nil {
+		report.Lock.Exists = true
+	}
+	report.Lock.Targets, report.Lock.Keys = lockF.Stats()
+
+	for _, rt := range resolved {
+		report.Targets = append(report.Targets, e.collectStatusTarget(rt, lockF))
+	}
+	return report, nil
+}
+
+// langCounts holds translation counts for one language of a target.
+type langCounts struct {
+	total, translated, fuzzy int
+}
+
+func (e *Engine) collectStatusTarget(rt config.ResolvedTarget, lockF *lockfile.LockFile) TargetStatus {
+	langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
+	report := TargetStatus{
+		Name:       rt.Target.Name,
+		Type:       rt.Target.Type,
+		Root:       rt.Target.Root,
+		SourceLang: rt.Target.SourceLang,
+		Languages:  make([]LangStatus, 0, len(langs)),
+	}
+
+	locked := func(lang string) int {
+		n := 0
+		for _, lockTarget := range layout.LockTargetKeys(rt, lang) {
+			n += lockF.TargetKeyCount(lockTarget)
+		}
+		return n
+	}
+
+	source, count, err := statusCounter(rt)
+	if err != nil {
+		report.Error = err.Error()
+		if errors.Is(err, os.ErrNotExist) {
+			report.SourceMissing = true
+			report.SourcePath = statusSourcePath(rt, err)
+		}
+		for _, lang := range langs {
+			report.Languages = append(report.Languages, LangStatus{Lang: lang, Status: StatusError, Locked: locked(lang)})
+		}
+		return report
+	}
+	report.Source = source
+
+	for _, lang := range langs {
+		lr := LangStatus{Lang: lang, Status: StatusOK, Locked: locked(lang)}
+		counts, err := count(lang)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			lr.Status = StatusMissing
+			counts = langCounts{total: source}
+		case err != nil:
+			lr.Status = StatusError
+			lr.Error = err.Error()
+			counts = langCounts{total: source}
+		}
+		lr.Total = counts.total
+		lr.Translated = counts.translated
+		lr.Fuzzy = counts.fuzzy
+		lr.Untranslated = max(counts.total-counts.translated-counts.fuzzy, 0)
+		if counts.total > 0 {
+			lr.Percent = counts.translated * 100 / counts.total
+		}
+		if lr.Status == StatusOK {
+			lr.TooLong = e.TooLong(rt, lang)
+		}
+		report.Languages = append(report.Languages, lr)
+	}
+	return report
+}
+
+// statusSourcePath returns the path of the missing source file named by
+// err, or the configured source path.
+func statusSourcePath(rt config.ResolvedTarget, err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Path
+	}
+	if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+		return rt.Target.Source.Index
+	}
+	return rt.SourcePath()
+}
+
+// TooLong returns the number of translations of rt into lang exceeding
+// their length limit, or 0 if the target has no length limits.
+func (e *Engine) TooLong(rt config.ResolvedTarget, lang string) int {
+	if !hasLengthLimits(rt) {
+		return 0
+	}
+	units, _, err := e.collectCheckUnits(rt, lang)
+	if err != nil {
+		return 0
+	}
+	limits := layout.LengthLimits(&rt.Target)
+	n := 0
+	for _, u := range units {
+		if len(translate.CheckTranslationLength(u.translation, u.lengthLimit(limits))) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// hasLengthLimits reports whether translations of a target may have a
+// length limit: configured length_limits or formats declaring maxLength.
+func hasLengthLimits(rt config.ResolvedTarget) bool {
+	switch rt.Target.Type {
+	case config.TargetTypeAndroid, config.TargetTypeFlutter:
+		return true
+	}
+	return len(rt.Target.LengthLimits) > 0
+}
+
+// statusCounter returns the number of source strings of a target and a
+// function counting translations for one language. The counting function
+// returns an error matching os.ErrNotExist when the language file is missing.
+func statusCounter(rt config.ResolvedTarget) (int, func(lang string) (langCounts, error), error) {
+	switch rt.Target.Type {
+	case config.TargetTypeGettext:
+		pot, err := po.ParseFile(rt.AbsPOTFile())
+		if err != nil {
+			return 0, nil, err
+		}
+		source := 0
+		for _, e := range pot.Entries {
+			if e.MsgID != "" && !e.Obsolete {
+				source++
+			}
+		}
+		return source, func(lang string) (langCounts, error) {
+			catalog, err := po.ParseFile(rt.POPath(lang))
+			if err != nil {
+				return langCounts{}, err
+			}
+			_, translated, fuzzy, _ := catalog.Stats()
+			return langCounts{total: source, translated: translated, fuzzy: fuzzy}, nil
+		}, nil
+	case config.TargetTypePo4a:
+		return 0, func(lang string) (langCounts, error) {
+			paths := rt.DocsPOPaths(lang)
+			if len(paths) == 0 {
+				return langCounts{}, os.ErrNotExist
+			}
+			var c langCounts
+			for _, path := range paths {
+				catalog, err := po.ParseFile(path)
+				if err != nil {
+					return langCounts{}, err
+				}
+				total, translated, fuzzy, _ := catalog.Stats()
+				c.total += total
+				c.translated += translated
+				c.fuzzy += fuzzy
+			}
+			return c, nil
+		}, nil
+	case config.TargetTypeI18Next:
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return i18next.ParseFile(path) })
+	case config.TargetTypeVueI18n:
+		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+			return statusIndexCounter(rt)
+		}
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return vuei18n.ParseFile(path) })
+	case config.TargetTypeAndroid:
+		src, err := android.ParseFile(android.SourceStringsXMLPath(rt.AbsResDir()))
+		if err != nil {
+			return 0, nil, err
+		}
+		// Array items and plural quantities are counted one by one, with
+		// the quantities of each language.
+		source, _, _ := translate.NewAndroidKVFile(src, src, rt.Target.SourceLang).Stats()
+		return source, func(lang string) (langCounts, error) {
+			f, err := android.ParseFile(android.StringsXMLPath(rt.AbsResDir(), lang))
+			if err != nil {
+				return langCounts{}, err
+			}
+			total, translated, _ := translate.NewAndroidKVFile(f, src, lang).Stats()
+			return langCounts{total: total, translated: translated}, nil
+		}, nil
+	case config.TargetTypeYAML:
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return yamlfile.ParseFile(path) })
+	case config.TargetTypeMarkdown:
+		return statusMarkdownCounter(rt)
+	case config.TargetTypeProperties:
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return propfile.ParseFile(path) })
+	case config.TargetTypeFlutter:
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return arbfile.ParseFile(path) })
+	case config.TargetTypeJSKV:
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return jskv.ParseFile(path) })
+	case config.TargetTypeDesktop:
+		return statusInlineCounter(rt, func(lang string) (formatfile.KVFile, error) { return desktop.ParseFile(rt.SourcePath(), lang) })
+	case config.TargetTypePolkit:
+		return statusInlineCounter(rt, func(lang string) (formatfile.KVFile, error) { return polkit.ParseFile(rt.SourcePath(), lang) })
+	case config.TargetTypeAppleStrings:
+		return statusKVCounter(rt, func(path string) (formatfile.KVFile, error) { return applestrings.ParseFile(path) })
+	case config.TargetTypeXCStrings:
+		return statusInlineCounter(rt, func(lang string) (formatfile.KVFile, error) { return xcstrings.ParseFile(rt.SourcePath(), lang) })
+	case config.TargetTypeQt:
+		return statusQtCounter(rt)
+	default:
+		return 0, nil, fmt.Errorf(T("unknown target type %q"), rt.Target.Type)
+	}
+}
+
+// statusKVCounter counts formats with one translation file per language.
+func statusKVCounter(rt config.ResolvedTarget, parse func(path string) (formatfile.KVFile, error)) (int, func(string) (langCounts, error), error) {
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	src, err := parse(srcPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	source, _, _ := src.Stats()
+	return source, func(lang string) (langCounts, error) {
+		path := rt.ExistingTranslationPath(lang)
+		if path == "" {
+			return langCounts{}, os.ErrNotExist
+		}
+		f, err := parse(path)
+		if err != nil {
+			return langCounts{}, err
+		}
+		_, translated, _ := f.Stats()
+		return langCounts{total: source, translated: min(translated, source)}, nil
+	}, nil
+}
+
+// statusInlineCounter counts formats that keep all languages in one file.
+func statusInlineCounter(rt config.ResolvedTarget, parse func(lang string) (formatfile.KVFile, error)) (int, func(string) (langCounts, error), error) {
+	src, err := parse(rt.Target.SourceLang)
+	if err != nil {
+		return 0, nil, err
+	}
+	source, _, _ := src.Stats()
+	return source, func(lang string) (langCounts, error) {
+		f, err := parse(lang)
+		if err != nil {
+			return langCounts{}, err
+		}
+		total, translated, _ := f.Stats()
+		return langCounts{total: total, translated: translated}, nil
+	}, nil
+}
+
+// statusQtCounter counts Qt Linguist files. Unfinished translations count
+// as fuzzy.
+func statusQtCounter(rt config.ResolvedTarget) (int, func(string) (langCounts, error), error) {
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	src, err := qt.ParseFile(srcPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	source := len(src.Messages())
+	return source, func(lang string) (langCounts, error) {
+		path := rt.ExistingTranslationPath(lang)
+		if path == "" {
+			return langCounts{}, os.ErrNotExist
+		}
+		f, err := qt.ParseFile(path)
+		if err != nil {
+			return langCounts{}, err
+		}
+		_, translated, unfinished, _ := f.Stats()
+		return langCounts{total: source, translated: min(translated, source), fuzzy: unfinished}, nil
+	}, nil
+}
+
+func statusMarkdownCounter(rt config.ResolvedTarget) (int, func(string) (langCounts, error), error) {
+	srcDir := layout.MarkdownSourceDir(rt)
+	srcFiles, err := layout.MarkdownSourceFiles(rt)
+	if err != nil {
+		return 0, nil, err
+	}
+	source := 0
+	srcByRelPath := make(map[string]*mdfile.File, len(srcFiles))
+	for _, p := range srcFiles {
+		f, err := mdfile.ParseFile(p)
+		if err != nil {
+			continue
+		}
+		total, _, _ := f.Stats()
+		source += total
+		if rel, err := filepath.Rel(srcDir, p); err == nil {
+			srcByRelPath[filepath.ToSlash(rel)] = f
+		}
+	}
+	return source, func(lang string) (langCounts, error) {
+		langDir := layout.MarkdownLangDir(rt, lang)
+		files, _ := layout.MarkdownTargetFiles(rt, lang)
+		if len(files) == 0 {
+			return langCounts{}, os.ErrNotExist
+		}
+		c := langCounts{total: source}
+		for _, p := range files {
+			f, err := mdfile.ParseFile(p)
+			if err != nil {
+				continue
+			}
+			if rel, err := filepath.Rel(langDir, p); err == nil && !layout.MarkdownTargetIsFile(rt) {
+				if srcFile, ok := srcByRelPath[filepath.ToSlash(rel)]; ok {
+					mdfile.SyncKeys(srcFile, f)
+				}
+			}
+			_, translated, _ := f.Stats()
+			c.translated += translated
+		}
+		c.translated = min(c.translated, source)
+		return c, nil
+	}, nil
+}
+
+func statusIndexCounter(rt config.ResolvedTarget) (int, func(string) (langCounts, error), error) {
+	item, err := layout.LoadIndexItem(rt)
+	if err != nil {
+		return 0, nil, err
+	}
+	if item == nil {
+		return 0, nil, os.ErrNotExist
+	}
+	source := len(item.Fields)
+	return source, func(lang string) (langCounts, error) {
+		f, err := layout.ParseIndexFile(rt.TranslationPath(lang))
+		if err != nil {
+			return langCounts{}, err
+		}
+		c := langCounts{total: source}
+		for key := range item.Fields {
+			if f.Get(key) != "" {
+				c.translated++
+			}
+		}
+		return c, nil
+	}, nil
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/lockfile"
+)
+
+func TestCollectStatusTargetJSKV(t *testing.T) {
+	dir := t.TempDir()
+	translationsDir := filepath.Join(dir, "translations")
+	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
+		t.Fatalf("mkdir translations: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "en.js"), []byte("window.translations = {\n    \"Hello\": \"Hello\",\n    \"Bye\": \"Bye\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "de.js"), []byte("window.translations = {\n    \"Hello\": \"Hallo\",\n    \"Bye\": \"\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write translation: %v", err)
+	}
+
+	rt := testJSKVResolvedTarget(dir)
+	rt.Languages = []string{"en", "de", "fr"}
+	rt.Target.LengthLimits = []config.LengthLimit{{Max: 4}}
+	lf := &lockfile.LockFile{Version: lockfile.Version, Checksums: map[string]map[string]string{}}
+	lf.Update(lockfile.LockTargetKey("welcome", "de"), "Hello", "Hello")
+
+	report := (&Engine{Root: dir}).collectStatusTarget(rt, lf)
+	if report.Error != "" {
+		t.Fatalf("unexpected error: %s", report.Error)
+	}
+	if report.Source != 2 || len(report.Languages) != 2 {
+		t.Fatalf("report = %+v, want 2 source strings and 2 languages", report)
+	}
+
+	de := report.Languages[0]
+	if de.Lang != "de" || de.Status != StatusOK || de.Total != 2 || de.Translated != 1 || de.Untranslated != 1 || de.Percent != 50 || de.Locked != 1 || de.TooLong != 1 {
+		t.Fatalf("de = %+v", de)
+	}
+	fr := report.Languages[1]
+	if fr.Lang != "fr" || fr.Status != StatusMissing || fr.Total != 2 || fr.Translated != 0 || fr.Untranslated != 2 {
+		t.Fatalf("fr = %+v", fr)
+	}
+}
+
+func TestCollectStatusTargetJSKVParseErrors(t *testing.T) {
+	dir := t.TempDir()
+	translationsDir := filepath.Join(dir, "translations")
+	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
+		t.Fatalf("mkdir translations: %v", err)
+	}
+	srcPath := filepath.Join(translationsDir, "en.js")
+	if err := os.WriteFile(srcPath, []byte("not valid js-kv"), 0o644); err != nil {
+		t.Fatalf("write invalid source: %v", err)
+	}
+	rt := testJSKVResolvedTarget(dir)
+	rt.Languages = []string{"de"}
+	lf := &lockfile.LockFile{Version: lockfile.Version, Checksums: map[string]map[string]string{}}
+	e := &Engine{Root: dir}
+
+	if report := e.collectStatusTarget(rt, lf); report.Error == "" {
+		t.Fatalf("invalid source not reported: %+v", report)
+	}
+
+	if err := os.WriteFile(srcPath, []byte("window.translations = {\n    \"Hello\": \"Hello\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "de.js"), []byte("not valid js-kv"), 0o644); err != nil {
+		t.Fatalf("write invalid translation: %v", err)
+	}
+	report := e.collectStatusTarget(rt, lf)
+	if len(report.Languages) != 1 || report.Languages[0].Status != StatusError {
+		t.Fatalf("invalid translation file not reported as an error: %+v", report)
+	}
+}
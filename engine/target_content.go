@@ -0,0 +1,1513 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/applestrings"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/desktop"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/format/jskv"
+	mdfile "github.com/minios-linux/lokit/format/markdown"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/polkit"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	"github.com/minios-linux/lokit/format/qt"
+	"github.com/minios-linux/lokit/format/vuei18n"
+	"github.com/minios-linux/lokit/format/xcstrings"
+	yamlfile "github.com/minios-linux/lokit/format/yaml"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/translate"
+)
+
+func (e *Engine) runInitYAML(rt config.ResolvedTarget, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		e.logInfo(T("Expected: %s"), rt.SourcePath())
+		return fmt.Errorf(T("Cannot find source YAML file for language %q in %s"), srcLang, transDir)
+	}
+
+	srcFile, err := yamlfile.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source YAML file %s: %v"), srcPath, err)
+	}
+
+	e.logInfo(T("Source language (%s): %d keys"), srcLang, len(srcFile.Keys()))
+
+	created, updated := 0, 0
+
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+
+		targetPath := rt.ExistingTranslationPath(lang)
+
+		if targetPath == "" {
+			targetPath = rt.TranslationPath(lang)
+			newFile := yamlfile.NewTranslationFile(srcFile, lang)
+			if err := os.MkdirAll(transDir, 0o755); err != nil {
+				e.logError(T("Creating directory %s: %v"), transDir, err)
+				continue
+			}
+			if err := newFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), targetPath, len(srcFile.Keys()))
+			created++
+			continue
+		}
+
+		targetFile, err := yamlfile.ParseFile(targetPath)
+		if err != nil {
+			e.logError(T("Reading %s: %v"), targetPath, err)
+			continue
+		}
+
+		yamlfile.SyncKeys(srcFile, targetFile)
+		if err := targetFile.WriteFile(targetPath); err != nil {
+			e.logError(T("Writing %s: %v"), targetPath, err)
+			continue
+		}
+		e.logSuccess(T("Updated: %s"), targetPath)
+		updated++
+	}
+
+	e.logInfo(T("YAML init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translateYAMLTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		return fmt.Errorf(T("cannot find source YAML file for language %q in %s"), srcLang, transDir)
+	}
+
+	srcFile, err := yamlfile.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source YAML %s: %w"), srcPath, err)
+	}
+	srcTotal, _, _ := srcFile.Stats()
+	e.logInfo(T("Source strings: %d"), srcTotal)
+
+	if a.dryRun {
+		for _, lang := range langs {
+			filePath := rt.ExistingTranslationPath(lang)
+			langName := i18next.ResolveMeta(lang).Name
+
+			if filePath == "" {
+				e.logInfo(T("%s (%s): %d strings to translate (file will be auto-created)"), lang, langName, srcTotal)
+				continue
+			}
+
+			file, err := yamlfile.ParseFile(filePath)
+			if err != nil {
+				e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, srcTotal)
+				continue
+			}
+
+			count := len(file.UntranslatedKeys())
+			if a.retranslate || a.force {
+				count = srcTotal
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	var tasks []translate.YAMLLangTask
+	for _, lang := range langs {
+		langName := i18next.ResolveMeta(lang).Name
+
+		filePath := rt.ExistingTranslationPath(lang)
+
+		var targetFile *yamlfile.File
+		if filePath == "" {
+			filePath = rt.TranslationPath(lang)
+			targetFile = yamlfile.NewTranslationFile(srcFile, lang)
+		} else {
+			targetFile, err = yamlfile.ParseFile(filePath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), filePath, err)
+				continue
+			}
+			yamlfile.SyncKeys(srcFile, targetFile)
+		}
+
+		tasks = append(tasks, translate.YAMLLangTask{
+			Lang:       lang,
+			LangName:   langName,
+			FilePath:   filePath,
+			File:       targetFile,
+			SourceFile: srcFile,
+		})
+	}
+
+	if len(tasks) == 0 {
+		e.logInfo(T("No YAML files to translate"))
+		return nil
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		RetranslateExisting: a.retranslate,
+		ChunkSize:           a.chunkSize,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog:               func(format string, args ...any) { e.logInfo(format, args...) },
+		OnError:             func(format string, args ...any) { e.logError(format, args...) },
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d strings"), lang, done, total)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	return translate.TranslateAllYAML(ctx, tasks, opts)
+}
+
+func (e *Engine) runInitMarkdown(rt config.ResolvedTarget, langs []string) error {
+	srcLang := rt.Target.SourceLang
+	srcDir := layout.MarkdownSourceDir(rt)
+
+	srcFiles, _ := layout.MarkdownSourceFiles(rt)
+	if len(srcFiles) == 0 {
+		e.logInfo(T("Expected markdown files under: %s"), srcDir)
+		return fmt.Errorf(T("Cannot find source Markdown files in %s"), srcDir)
+	}
+
+	e.logInfo(T("Source language (%s): %d files"), srcLang, len(srcFiles))
+
+	created, updated := 0, 0
+
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+
+		langDir := layout.MarkdownLangDir(rt, lang)
+		if err := os.MkdirAll(langDir, 0o755); err != nil {
+			e.logError(T("Creating directory %s: %v"), langDir, err)
+			continue
+		}
+
+		for _, srcPath := range srcFiles {
+			relPath, err := filepath.Rel(srcDir, srcPath)
+			if err != nil {
+				e.logError(T("Computing relative path for %s: %v"), srcPath, err)
+				continue
+			}
+			targetPath := layout.MarkdownTargetPath(rt, lang, relPath)
+
+			srcFile, err := mdfile.ParseFile(srcPath)
+			if err != nil {
+				e.logError(T("Cannot read source Markdown file %s: %v"), srcPath, err)
+				continue
+			}
+
+			if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+				newFile := mdfile.NewTranslationFile(srcFile, lang)
+				if err := newFile.WriteFile(targetPath); err != nil {
+					e.logError(T("Creating %s: %v"), targetPath, err)
+					continue
+				}
+				e.logSuccess(T("Created: %s (%d segments)"), targetPath, len(srcFile.Keys()))
+				created++
+			} else {
+				targetFile, err := mdfile.ParseFile(targetPath)
+				if err != nil {
+					e.logError(T("Reading %s: %v"), targetPath, err)
+					continue
+				}
+				mdfile.SyncKeys(srcFile, targetFile)
+				if err := targetFile.WriteFile(targetPath); err != nil {
+					e.logError(T("Writing %s: %v"), targetPath, err)
+					continue
+				}
+				e.logSuccess(T("Updated: %s"), targetPath)
+				updated++
+			}
+		}
+	}
+
+	e.logInfo(T("Markdown init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translateMarkdownTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcDir := layout.MarkdownSourceDir(rt)
+	srcFiles, _ := layout.MarkdownSourceFiles(rt)
+	if len(srcFiles) == 0 {
+		return fmt.Errorf(T("cannot find source Markdown files in %s"), srcDir)
+	}
+
+	totalSrcSegs := 0
+	for _, p := range srcFiles {
+		if f, err := mdfile.ParseFile(p); err == nil {
+			t, _, _ := f.Stats()
+			totalSrcSegs += t
+		}
+	}
+	e.logInfo(T("Source segments: %d (%d files)"), totalSrcSegs, len(srcFiles))
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			count := 0
+			for _, srcPath := range srcFiles {
+				relPath, err := filepath.Rel(srcDir, srcPath)
+				if err != nil {
+					e.logError(T("Computing relative path for %s: %v"), srcPath, err)
+					continue
+				}
+				targetPath := layout.MarkdownTargetPath(rt, lang, relPath)
+				srcFile, err := mdfile.ParseFile(srcPath)
+				if err != nil {
+					continue
+				}
+				if a.retranslate || a.force {
+					count += len(srcFile.Keys())
+					continue
+				}
+				if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+					count += len(srcFile.Keys())
+					continue
+				}
+				tf, err := mdfile.ParseFile(targetPath)
+				if err != nil {
+					count += len(srcFile.Keys())
+					continue
+				}
+				mdfile.SyncKeys(srcFile, tf)
+				count += len(tf.UntranslatedKeys())
+			}
+			e.logInfo(T("%s (%s): %d segments to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	var tasks []translate.MarkdownLangTask
+	for _, lang := range langs {
+		langName := i18next.ResolveMeta(lang).Name
+		langDir := layout.MarkdownLangDir(rt, lang)
+		if err := os.MkdirAll(langDir, 0o755); err != nil {
+			e.logError(T("Creating directory %s: %v"), langDir, err)
+			continue
+		}
+
+		for _, srcPath := range srcFiles {
+			relPath, err := filepath.Rel(srcDir, srcPath)
+			if err != nil {
+				e.logError(T("Computing relative path for %s: %v"), srcPath, err)
+				continue
+			}
+			targetPath := layout.MarkdownTargetPath(rt, lang, relPath)
+
+			srcFile, err := mdfile.ParseFile(srcPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), srcPath, err)
+				continue
+			}
+
+			var targetFile *mdfile.File
+			if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+				targetFile = mdfile.NewTranslationFile(srcFile, lang)
+			} else {
+				targetFile, err = mdfile.ParseFile(targetPath)
+				if err != nil {
+					e.logError(T("Reading %s: %v"), targetPath, err)
+					continue
+				}
+				mdfile.SyncKeys(srcFile, targetFile)
+			}
+
+			lockKeyPrefix := filepath.ToSlash(relPath)
+			tasks = append(tasks, translate.MarkdownLangTask{Lang: lang, LangName: langName, FilePath: targetPath, File: targetFile, SourceFile: srcFile, LockKeyPrefix: lockKeyPrefix})
+		}
+	}
+
+	if len(tasks) == 0 {
+		e.logInfo(T("No Markdown files to translate"))
+		return nil
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		RetranslateExisting: a.retranslate,
+		ChunkSize:           a.chunkSize,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog:               func(format string, args ...any) { e.logInfo(format, args...) },
+		OnError:             func(format string, args ...any) { e.logError(format, args...) },
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d segments"), lang, done, total)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	return translate.TranslateAllMarkdown(ctx, tasks, opts)
+}
+
+func (e *Engine) runInitProperties(rt config.ResolvedTarget, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+
+	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+		return fmt.Errorf(T("Cannot find source .properties file: %s"), srcPath)
+	}
+
+	srcFile, err := propfile.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source .properties file %s: %v"), srcPath, err)
+	}
+
+	e.logInfo(T("Source language (%s): %d keys"), srcLang, len(srcFile.Keys()))
+
+	created, updated := 0, 0
+
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+		targetPath := rt.TranslationPath(lang)
+
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			newFile := propfile.NewTranslationFile(srcFile, lang)
+			layout.SetPropertiesEncoding(rt, newFile)
+			if err := os.MkdirAll(transDir, 0o755); err != nil {
+				e.logError(T("Creating directory %s: %v"), transDir, err)
+				continue
+			}
+			if err := newFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), targetPath, len(srcFile.Keys()))
+			created++
+		} else {
+			targetFile, err := propfile.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			propfile.SyncKeys(srcFile, targetFile)
+			layout.SetPropertiesEncoding(rt, targetFile)
+			if err := targetFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Writing %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Updated: %s"), targetPath)
+			updated++
+		}
+	}
+
+	e.logInfo(T("Properties init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translatePropertiesTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := propfile.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source .properties file %s: %w"), srcPath, err)
+	}
+	srcTotal, _, _ := srcFile.Stats()
+	e.logInfo(T("Source strings: %d"), srcTotal)
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			targetPath := rt.TranslationPath(lang)
+			count := srcTotal
+			if !a.retranslate && !a.force {
+				if _, err := os.Stat(targetPath); err == nil {
+					if tf, err := propfile.ParseFile(targetPath); err == nil {
+						count = len(tf.UntranslatedKeys())
+					}
+				}
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	var tasks []translate.PropertiesLangTask
+	for _, lang := range langs {
+		langName := i18next.ResolveMeta(lang).Name
+		targetPath := rt.TranslationPath(lang)
+
+		var targetFile *propfile.File
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			targetFile = propfile.NewTranslationFile(srcFile, lang)
+		} else {
+			targetFile, err = propfile.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			propfile.SyncKeys(srcFile, targetFile)
+		}
+		layout.SetPropertiesEncoding(rt, targetFile)
+
+		tasks = append(tasks, translate.PropertiesLangTask{Lang: lang, LangName: langName, FilePath: targetPath, File: targetFile, SourceFile: srcFile})
+	}
+
+	if len(tasks) == 0 {
+		e.logInfo(T("No .properties files to translate"))
+		return nil
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		RetranslateExisting: a.retranslate,
+		ChunkSize:           a.chunkSize,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog:               func(format string, args ...any) { e.logInfo(format, args...) },
+		OnError:             func(format string, args ...any) { e.logError(format, args...) },
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d strings"), lang, done, total)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	return translate.TranslateAllProperties(ctx, tasks, opts)
+}
+
+func (e *Engine) runInitFlutter(rt config.ResolvedTarget, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+
+	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+		return fmt.Errorf(T("Cannot find source ARB file: %s"), srcPath)
+	}
+
+	srcFile, err := arbfile.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source ARB file %s: %v"), srcPath, err)
+	}
+
+	e.logInfo(T("Source language (%s): %d keys"), srcLang, len(srcFile.Keys()))
+
+	created, updated := 0, 0
+
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+		targetPath := rt.TranslationPath(lang)
+
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			newFile := arbfile.NewTranslationFile(srcFile, lang)
+			if err := os.MkdirAll(transDir, 0o755); err != nil {
+				e.logError(T("Creating directory %s: %v"), transDir, err)
+				continue
+			}
+			if err := newFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), targetPath, len(srcFile.Keys()))
+			created++
+		} else {
+			targetFile, err := arbfile.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			arbfile.SyncKeys(srcFile, targetFile)
+			if err := targetFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Writing %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Updated: %s"), targetPath)
+			updated++
+		}
+	}
+
+	e.logInfo(T("Flutter ARB init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translateFlutterTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := arbfile.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source ARB file %s: %w"), srcPath, err)
+	}
+	srcTotal, _, _ := srcFile.Stats()
+	e.logInfo(T("Source strings: %d"), srcTotal)
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			targetPath := rt.TranslationPath(lang)
+			count := srcTotal
+			if !a.retranslate && !a.force {
+				if _, err := os.Stat(targetPath); err == nil {
+					if tf, err := arbfile.ParseFile(targetPath); err == nil {
+						count = len(tf.UntranslatedKeys())
+					}
+				}
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	var tasks []translate.ARBLangTask
+	for _, lang := range langs {
+		langName := i18next.ResolveMeta(lang).Name
+		targetPath := rt.TranslationPath(lang)
+
+		var targetFile *arbfile.File
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			targetFile = arbfile.NewTranslationFile(srcFile, lang)
+		} else {
+			targetFile, err = arbfile.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			arbfile.SyncKeys(srcFile, targetFile)
+		}
+
+		tasks = append(tasks, translate.ARBLangTask{Lang: lang, LangName: langName, FilePath: targetPath, File: targetFile, SourceFile: srcFile})
+	}
+
+	if len(tasks) == 0 {
+		e.logInfo(T("No ARB files to translate"))
+		return nil
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		RetranslateExisting: a.retranslate,
+		ChunkSize:           a.chunkSize,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog:               func(format string, args ...any) { e.logInfo(format, args...) },
+		OnError:             func(format string, args ...any) { e.logError(format, args...) },
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d strings"), lang, done, total)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	return translate.TranslateAllARB(ctx, tasks, opts)
+}
+
+// runInitVueI18n creates or syncs nested JSON translation files from source.
+func (e *Engine) runInitVueI18n(rt config.ResolvedTarget, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+
+	srcFile, err := vuei18n.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source nested JSON file %s: %v"), srcPath, err)
+	}
+
+	e.logInfo(T("Source language (%s): %d keys"), srcLang, len(srcFile.Keys()))
+
+	created, updated := 0, 0
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+
+		targetPath := rt.TranslationPath(lang)
+		targetFile, err := vuei18n.ParseFile(targetPath)
+		if err != nil {
+			targetFile = vuei18n.NewTranslationFile(srcFile, lang)
+			if err := os.MkdirAll(transDir, 0o755); err != nil {
+				e.logError(T("Creating directory %s: %v"), transDir, err)
+				continue
+			}
+			if err := targetFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), targetPath, len(srcFile.Keys()))
+			created++
+			continue
+		}
+
+		vuei18n.SyncKeys(srcFile, targetFile)
+		if err := targetFile.WriteFile(targetPath); err != nil {
+			e.logError(T("Writing %s: %v"), targetPath, err)
+			continue
+		}
+		e.logSuccess(T("Updated: %s"), targetPath)
+		updated++
+	}
+
+	e.logInfo(T("Vue i18n init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translateVueI18nTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+
+	srcFile, err := vuei18n.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source nested JSON file %s: %w"), srcPath, err)
+	}
+
+	srcTotal, _, _ := srcFile.Stats()
+	e.logInfo(T("Source strings: %d"), srcTotal)
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			targetPath := rt.TranslationPath(lang)
+			count := srcTotal
+			if !a.retranslate && !a.force {
+				if tf, err := vuei18n.ParseFile(targetPath); err == nil {
+					count = len(tf.UntranslatedKeys())
+				}
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	var tasks []translate.VueI18nLangTask
+	for _, lang := range langs {
+		langName := i18next.ResolveMeta(lang).Name
+		targetPath := rt.TranslationPath(lang)
+
+		targetFile, err := vuei18n.ParseFile(targetPath)
+		if err != nil {
+			targetFile = vuei18n.NewTranslationFile(srcFile, lang)
+		} else {
+			vuei18n.SyncKeys(srcFile, targetFile)
+		}
+
+		tasks = append(tasks, translate.VueI18nLangTask{
+			Lang:       lang,
+			LangName:   langName,
+			FilePath:   targetPath,
+			File:       targetFile,
+			SourceFile: srcFile,
+		})
+	}
+
+	if len(tasks) == 0 {
+		e.logInfo(T("No nested JSON files to translate"))
+		return nil
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		RetranslateExisting: a.retranslate,
+		ChunkSize:           a.chunkSize,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog: func(format string, args ...any) {
+			e.logInfo(format, args...)
+		},
+		OnError: func(format string, args ...any) {
+			e.logError(format, args...)
+		},
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d strings"), lang, done, total)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	return translate.TranslateAllVueI18n(ctx, tasks, opts)
+}
+
+func (e *Engine) runInitJSKV(rt config.ResolvedTarget, langs []string) error {
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := jskv.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source JS file %s: %v"), srcPath, err)
+	}
+	for _, lang := range langs {
+		if lang == rt.Target.SourceLang {
+			continue
+		}
+		targetPath := rt.TranslationPath(lang)
+		existingPath := rt.ExistingTranslationPath(lang)
+		if existingPath == "" {
+			f := jskv.NewTranslationFile(srcFile)
+			if err := f.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s"), targetPath)
+			continue
+		}
+
+		f, err := jskv.ParseFile(existingPath)
+		if err != nil {
+			e.logError(T("Cannot read JS file %s: %v"), existingPath, err)
+			continue
+		}
+		jskv.SyncKeys(srcFile, f)
+		if err := f.WriteFile(existingPath); err != nil {
+			e.logError(T("Writing %s: %v"), existingPath, err)
+			continue
+		}
+		e.logSuccess(T("Updated: %s"), existingPath)
+	}
+	return nil
+}
+
+func (e *Engine) translateJSKVTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := jskv.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source JS file %s: %w"), srcPath, err)
+	}
+	srcTotal, _, _ := srcFile.Stats()
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			count := srcTotal
+			if !a.retranslate && !a.force {
+				filePath := rt.ExistingTranslationPath(lang)
+				if filePath == "" {
+					e.logInfo(T("%s (%s): %d strings to translate (file will be auto-created)"), lang, langName, count)
+					continue
+				}
+				if file, err := jskv.ParseFile(filePath); err == nil {
+					jskv.SyncKeys(srcFile, file)
+					count = len(file.UntranslatedKeys())
+				}
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		ChunkSize:           a.chunkSize,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		RetranslateExisting: a.retranslate,
+		SystemPrompt:        a.prompt,
+		PromptType:          "i18next",
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog:               func(format string, args ...any) { e.logInfo(format, args...) },
+		OnError:             func(format string, args ...any) { e.logError(format, args...) },
+	}
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	var tasks []translate.KVLangTask
+	for _, lang := range langs {
+		filePath := rt.ExistingTranslationPath(lang)
+		if filePath == "" {
+			filePath = rt.TranslationPath(lang)
+		}
+		file, err := jskv.ParseFile(filePath)
+		if err != nil {
+			file = jskv.NewTranslationFile(srcFile)
+		} else {
+			jskv.SyncKeys(srcFile, file)
+		}
+		tasks = append(tasks, translate.KVLangTask{Lang: lang, LangName: i18next.ResolveMeta(lang).Name, FilePath: filePath, File: file, SourceValues: srcFile.SourceValues()})
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	return translate.TranslateAllKV(ctx, tasks, opts, translate.I18NextChunkTranslator())
+}
+
+func (e *Engine) translateDesktopTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	path := rt.SourcePath()
+	src, err := desktop.ParseFile(path, rt.Target.SourceLang)
+	if err != nil {
+		return fmt.Errorf(T("cannot read desktop file %s: %w"), path, err)
+	}
+	opts := translate.Options{Provider: prov, SourceLanguage: rt.Target.SourceLang, ChunkSize: a.chunkSize, ParallelMode: translate.ParallelSequential, RequestDelay: a.requestDelay, Timeout: a.timeout, MaxRetries: a.maxRetries, RetranslateExisting: a.retranslate, SystemPrompt: a.prompt, PromptType: "default", Verbose: a.verbose, LockFile: a.lockFile, Memory: a.memory, Review: a.review, ReviewFuzzy: a.reviewFuzzy, Usage: a.usage, Providers: a.providers, Journal: a.journal, Resume: a.resume, LockTarget: rt.Target.Name, ForceTranslate: a.force, OnLog: func(format string, args ...any) { e.logInfo(format, args...) }, OnError: func(format string, args ...any) { e.logError(format, args...) }}
+	e.setExclusionOpts(&opts, &rt.Target)
+	var tasks []translate.KVLangTask
+	for _, lang := range langs {
+		f, err := desktop.ParseFile(path, lang)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, translate.KVLangTask{Lang: lang, LangName: i18next.ResolveMeta(lang).Name, FilePath: path, File: f, SourceValues: src.SourceValues()})
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	return translate.TranslateAllKV(ctx, tasks, opts, translate.DefaultKVChunkTranslator())
+}
+
+func (e *Engine) translatePolkitTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	path := rt.SourcePath()
+	src, err := polkit.ParseFile(path, rt.Target.SourceLang)
+	if err != nil {
+		return fmt.Errorf(T("cannot read policy file %s: %w"), path, err)
+	}
+	opts := translate.Options{Provider: prov, SourceLanguage: rt.Target.SourceLang, ChunkSize: a.chunkSize, ParallelMode: translate.ParallelSequential, RequestDelay: a.requestDelay, Timeout: a.timeout, MaxRetries: a.maxRetries, RetranslateExisting: a.retranslate, SystemPrompt: a.prompt, PromptType: "default", Verbose: a.verbose, LockFile: a.lockFile, Memory: a.memory, Review: a.review, ReviewFuzzy: a.reviewFuzzy, Usage: a.usage, Providers: a.providers, Journal: a.journal, Resume: a.resume, LockTarget: rt.Target.Name, ForceTranslate: a.force, OnLog: func(format string, args ...any) { e.logInfo(format, args...) }, OnError: func(format string, args ...any) { e.logError(format, args...) }}
+	e.setExclusionOpts(&opts, &rt.Target)
+	var tasks []translate.KVLangTask
+	for _, lang := range langs {
+		f, err := polkit.ParseFile(path, lang)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, translate.KVLangTask{Lang: lang, LangName: i18next.ResolveMeta(lang).Name, FilePath: path, File: f, SourceValues: src.SourceValues()})
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	return translate.TranslateAllKV(ctx, tasks, opts, translate.DefaultKVChunkTranslator())
+}
+
+func (e *Engine) translateXCStringsTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	path := rt.SourcePath()
+	src, err := xcstrings.ParseFile(path, rt.Target.SourceLang)
+	if err != nil {
+		return fmt.Errorf(T("cannot read string catalog %s: %w"), path, err)
+	}
+	if src.SourceLanguage() != rt.Target.SourceLang {
+		e.logWarning(T("String catalog source language %q differs from source_lang %q"), src.SourceLanguage(), rt.Target.SourceLang)
+	}
+	opts := translate.Options{Provider: prov, SourceLanguage: rt.Target.SourceLang, ChunkSize: a.chunkSize, ParallelMode: translate.ParallelSequential, RequestDelay: a.requestDelay, Timeout: a.timeout, MaxRetries: a.maxRetries, RetranslateExisting: a.retranslate, SystemPrompt: a.prompt, PromptType: "default", Verbose: a.verbose, LockFile: a.lockFile, Memory: a.memory, Review: a.review, ReviewFuzzy: a.reviewFuzzy, Usage: a.usage, Providers: a.providers, Journal: a.journal, Resume: a.resume, LockTarget: rt.Target.Name, ForceTranslate: a.force, OnLog: func(format string, args ...any) { e.logInfo(format, args...) }, OnError: func(format string, args ...any) { e.logError(format, args...) }}
+	e.setExclusionOpts(&opts, &rt.Target)
+	var tasks []translate.KVLangTask
+	for _, lang := range langs {
+		f, err := xcstrings.ParseFile(path, lang)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, translate.KVLangTask{Lang: lang, LangName: i18next.ResolveMeta(lang).Name, FilePath: path, File: f, SourceValues: src.SourceValues()})
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	return translate.TranslateAllKV(ctx, tasks, opts, translate.DefaultKVChunkTranslator())
+}
+
+func (e *Engine) runInitAppleStrings(rt config.ResolvedTarget, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+
+	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+		return fmt.Errorf(T("Cannot find source file: %s"), srcPath)
+	}
+
+	srcFile, err := applestrings.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source file %s: %v"), srcPath, err)
+	}
+
+	e.logInfo(T("Source language (%s): %d keys"), srcLang, len(srcFile.Keys()))
+
+	created, updated := 0, 0
+
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+		targetPath := rt.TranslationPath(lang)
+
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			newFile := applestrings.NewTranslationFile(srcFile, lang)
+			if err := os.MkdirAll(transDir, 0o755); err != nil {
+				e.logError(T("Creating directory %s: %v"), transDir, err)
+				continue
+			}
+			if err := newFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), targetPath, len(newFile.Keys()))
+			created++
+		} else {
+			targetFile, err := applestrings.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			applestrings.SyncKeys(srcFile, targetFile, lang)
+			if err := targetFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Writing %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Updated: %s"), targetPath)
+			updated++
+		}
+	}
+
+	e.logInfo(T("Apple strings init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translateAppleStringsTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := applestrings.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source file %s: %w"), srcPath, err)
+	}
+	srcTotal, _, _ := srcFile.Stats()
+	e.logInfo(T("Source strings: %d"), srcTotal)
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			targetPath := rt.TranslationPath(lang)
+			count := len(applestrings.NewTranslationFile(srcFile, lang).Keys())
+			if !a.retranslate && !a.force {
+				if _, err := os.Stat(targetPath); err == nil {
+					if tf, err := applestrings.ParseFile(targetPath); err == nil {
+						count = len(tf.UntranslatedKeys())
+					}
+				}
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	var tasks []translate.KVLangTask
+	for _, lang := range langs {
+		langName := i18next.ResolveMeta(lang).Name
+		targetPath := rt.TranslationPath(lang)
+
+		var targetFile *applestrings.File
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			targetFile = applestrings.NewTranslationFile(srcFile, lang)
+		} else {
+			targetFile, err = applestrings.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			applestrings.SyncKeys(srcFile, targetFile, lang)
+		}
+
+		tasks = append(tasks, translate.KVLangTask{Lang: lang, LangName: langName, FilePath: targetPath, File: targetFile, SourceValues: srcFile.SourceValues()})
+	}
+
+	if len(tasks) == 0 {
+		e.logInfo(T("No Apple strings files to translate"))
+		return nil
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		RetranslateExisting: a.retranslate,
+		ChunkSize:           a.chunkSize,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnLog:               func(format string, args ...any) { e.logInfo(format, args...) },
+		OnError:             func(format string, args ...any) { e.logError(format, args...) },
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d strings"), lang, done, total)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	return translate.TranslateAllKV(ctx, tasks, opts, translate.DefaultKVChunkTranslator())
+}
+
+func (e *Engine) runInitQt(rt config.ResolvedTarget, langs []string) error {
+	srcLang := rt.Target.SourceLang
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+
+	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+		return fmt.Errorf(T("Cannot find source file: %s"), srcPath)
+	}
+
+	srcFile, err := qt.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("Cannot read source file %s: %v"), srcPath, err)
+	}
+
+	e.logInfo(T("Source language (%s): %d messages"), srcLang, len(srcFile.Messages()))
+
+	created, updated := 0, 0
+
+	for _, lang := range langs {
+		if lang == srcLang {
+			continue
+		}
+		targetPath := rt.TranslationPath(lang)
+
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			newFile := qt.NewTranslationFile(srcFile, lang)
+			if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+				e.logError(T("Creating directory %s: %v"), filepath.Dir(targetPath), err)
+				continue
+			}
+			if err := newFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Creating %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d messages)"), targetPath, len(newFile.Messages()))
+			created++
+		} else {
+			targetFile, err := qt.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			qt.SyncMessages(srcFile, targetFile, lang)
+			if err := targetFile.WriteFile(targetPath); err != nil {
+				e.logError(T("Writing %s: %v"), targetPath, err)
+				continue
+			}
+			e.logSuccess(T("Updated: %s"), targetPath)
+			updated++
+		}
+	}
+
+	e.logInfo(T("Qt init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+// translateQtTarget translates Qt Linguist files through the gettext
+// pipeline: each file is converted to a PO catalog, so qt-format placeholder
+// checks, plural forms and review marks work as for PO files, and written
+// back as .ts when the catalog is saved.
+func (e *Engine) translateQtTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	transDir := rt.AbsTranslationsDir()
+
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	if a.parallel {
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+	}
+	if a.chunkSize > 0 {
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
+	}
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := qt.ParseFile(srcPath)
+	if err != nil {
+		return fmt.Errorf(T("cannot read source file %s: %w"), srcPath, err)
+	}
+	e.logInfo(T("Source strings: %d"), len(srcFile.Messages()))
+
+	type qtTask struct {
+		lang    string
+		path    string
+		file    *qt.File
+		catalog *po.File
+	}
+	var qtTasks []qtTask
+	for _, lang := range langs {
+		targetPath := rt.TranslationPath(lang)
+		var targetFile *qt.File
+		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+			targetFile = qt.NewTranslationFile(srcFile, lang)
+		} else {
+			targetFile, err = qt.ParseFile(targetPath)
+			if err != nil {
+				e.logError(T("Reading %s: %v"), targetPath, err)
+				continue
+			}
+			qt.SyncMessages(srcFile, targetFile, lang)
+		}
+		qtTasks = append(qtTasks, qtTask{lang: lang, path: targetPath, file: targetFile, catalog: targetFile.POFile(lang)})
+	}
+
+	if a.dryRun {
+		for _, t := range qtTasks {
+			count := len(t.catalog.UntranslatedEntries())
+			if a.retranslate || a.force {
+				count = len(t.catalog.Entries)
+			} else if a.fuzzy {
+				count += len(translate.FuzzyEntries(t.catalog))
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), t.lang, po.LangNameNative(t.lang), count)
+		}
+		return nil
+	}
+
+	var langTasks []translate.LangTask
+	for _, t := range qtTasks {
+		// Skip if already fully translated unless a full re-run was requested.
+		if !a.retranslate && !a.force {
+			if len(t.catalog.UntranslatedEntries()) == 0 && (!a.fuzzy || len(translate.FuzzyEntries(t.catalog)) == 0) {
+				continue
+			}
+		}
+		langTasks = append(langTasks, translate.LangTask{
+			Lang:   t.lang,
+			POFile: t.catalog,
+			POPath: t.path,
+			Save: func(catalog *po.File) error {
+				t.file.ApplyPO(catalog)
+				if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+					return err
+				}
+				return t.file.WriteFile(t.path)
+			},
+		})
+	}
+
+	if len(langTasks) == 0 {
+		e.logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
+		return nil
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	// lupdate writes locations relative to the .ts files, so source
+	// context is read relative to their directory.
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		ChunkSize:           a.chunkSize,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		RetranslateExisting: a.retranslate,
+		TranslateFuzzy:      a.fuzzy,
+		SystemPrompt:        a.prompt,
+		PromptType:          "default",
+		SourceContextLines:  rt.Target.SourceContext,
+		SourceRoot:          filepath.Dir(srcPath),
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d"), lang, done, total)
+		},
+		OnLog: func(format string, args ...any) {
+			e.logInfo(format, args...)
+		},
+		OnError: func(format string, args ...any) {
+			e.logError(format, args...)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	if rt.Target.Prompt != "" && opts.SystemPrompt == "" {
+		opts.SystemPrompt = rt.Target.Prompt
+	}
+
+	return translate.TranslateAll(ctx, langTasks, opts)
+}
@@ -1,11 +1,10 @@
-package cli
+package engine
 
 import (
 	"context"
-	"io"
+	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"testing"
 
@@ -14,91 +13,6 @@ import (
 	"github.com/minios-linux/lokit/translate"
 )
 
-func TestShowConfigJSKVStatsParsesJavaScriptFiles(t *testing.T) {
-	dir := t.TempDir()
-	translationsDir := filepath.Join(dir, "translations")
-	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
-		t.Fatalf("mkdir translations: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(translationsDir, "en.js"), []byte("window.translations = {\n    \"Hello\": \"Hello\"\n};\n"), 0o644); err != nil {
-		t.Fatalf("write source: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(translationsDir, "de.js"), []byte("window.translations = {\n    \"Hello\": \"Hallo\"\n};\n"), 0o644); err != nil {
-		t.Fatalf("write translation: %v", err)
-	}
-
-	rt := config.ResolvedTarget{
-		Target: config.Target{
-			Name:       "welcome",
-			Type:       config.TargetTypeJSKV,
-			Format:     config.TargetTypeJSKV,
-			Dir:        "translations",
-			Pattern:    "{lang}.js",
-			SourceLang: "en",
-		},
-		AbsRoot: dir,
-	}
-
-	output := captureStderr(t, func() {
-		showConfigJSKVStats(rt, []string{"de"})
-	})
-
-	if strings.Contains(output, "missing") {
-		t.Fatalf("JSKV stats reported existing translation as missing:\n%s", output)
-	}
-	if !regexp.MustCompile(`(?m)^\s*🇩🇪 de\s+.*\s+1\s+0\s*$`).MatchString(output) {
-		t.Fatalf("JSKV stats missing translated count:\n%s", output)
-	}
-}
-
-func TestShowConfigJSKVStatsReportsSourceParseError(t *testing.T) {
-	dir := t.TempDir()
-	translationsDir := filepath.Join(dir, "translations")
-	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
-		t.Fatalf("mkdir translations: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(translationsDir, "en.js"), []byte("not valid js-kv"), 0o644); err != nil {
-		t.Fatalf("write invalid source: %v", err)
-	}
-
-	output := captureStderr(t, func() {
-		showConfigJSKVStats(testJSKVResolvedTarget(dir), []string{"de"})
-	})
-
-	if !strings.Contains(output, "parse error") {
-		t.Fatalf("JSKV stats did not report source parse error:\n%s", output)
-	}
-	if strings.Contains(output, "not found") {
-		t.Fatalf("JSKV stats reported invalid existing source as not found:\n%s", output)
-	}
-}
-
-func TestShowConfigJSKVStatsReportsParseError(t *testing.T) {
-	dir := t.TempDir()
-	translationsDir := filepath.Join(dir, "translations")
-	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
-		t.Fatalf("mkdir translations: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(translationsDir, "en.js"), []byte("window.translations = {\n    \"Hello\": \"Hello\"\n};\n"), 0o644); err != nil {
-		t.Fatalf("write source: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(translationsDir, "de.js"), []byte("not valid js-kv"), 0o644); err != nil {
-		t.Fatalf("write invalid translation: %v", err)
-	}
-
-	rt := testJSKVResolvedTarget(dir)
-	output := captureStderr(t, func() {
-		showConfigJSKVStats(rt, []string{"de"})
-	})
-
-	if !strings.Contains(output, "parse error") {
-		t.Fatalf("JSKV stats did not report parse error:\n%s", output)
-	}
-	if strings.Contains(output, "missing") {
-		t.Fatalf("JSKV stats reported invalid existing file as missing:\n%s", output)
-	}
-}
-
 func TestTranslateJSKVTargetDryRunDoesNotCreateFilesOrLockEntries(t *testing.T) {
 	dir := t.TempDir()
 	translationsDir := filepath.Join(dir, "translations")
@@ -112,11 +26,10 @@ func TestTranslateJSKVTargetDryRunDoesNotCreateFilesOrLockEntries(t *testing.T)
 	rt := testJSKVResolvedTarget(dir)
 	lf := &lockfile.LockFile{Version: lockfile.Version, Checksums: map[string]map[string]string{}}
 
-	output := captureStderr(t, func() {
-		if err := translateJSKVTarget(context.Background(), rt, translate.Provider{}, translateArgs{dryRun: true, lockFile: lf}, []string{"de"}); err != nil {
-			t.Fatalf("translateJSKVTarget dry-run error: %v", err)
-		}
-	})
+	e, output := newLogEngine(dir)
+	if err := e.translateJSKVTarget(context.Background(), rt, translate.Provider{}, translateArgs{dryRun: true, lockFile: lf}, []string{"de"}); err != nil {
+		t.Fatalf("translateJSKVTarget dry-run error: %v", err)
+	}
 
 	if _, err := os.Stat(filepath.Join(translationsDir, "de.js")); !os.IsNotExist(err) {
 		t.Fatalf("dry-run created translation file, stat err=%v", err)
@@ -124,11 +37,11 @@ func TestTranslateJSKVTargetDryRunDoesNotCreateFilesOrLockEntries(t *testing.T)
 	if got := lf.TargetKeyCount(lockfile.LockTargetKey("welcome", "de")); got != 0 {
 		t.Fatalf("dry-run added %d lock keys, want 0", got)
 	}
-	if !strings.Contains(output, "1 strings to translate") {
-		t.Fatalf("dry-run output missing count:\n%s", output)
+	if !strings.Contains(output.String(), "1 strings to translate") {
+		t.Fatalf("dry-run output missing count:\n%s", output.String())
 	}
-	if !strings.Contains(output, "file will be auto-created") {
-		t.Fatalf("dry-run output missing auto-create hint:\n%s", output)
+	if !strings.Contains(output.String(), "file will be auto-created") {
+		t.Fatalf("dry-run output missing auto-create hint:\n%s", output.String())
 	}
 }
 
@@ -148,14 +61,13 @@ func TestTranslateJSKVTargetDryRunCountsMissingSourceKeys(t *testing.T) {
 	rt := testJSKVResolvedTarget(dir)
 	lf := &lockfile.LockFile{Version: lockfile.Version, Checksums: map[string]map[string]string{}}
 
-	output := captureStderr(t, func() {
-		if err := translateJSKVTarget(context.Background(), rt, translate.Provider{}, translateArgs{dryRun: true, lockFile: lf}, []string{"de"}); err != nil {
-			t.Fatalf("translateJSKVTarget dry-run error: %v", err)
-		}
-	})
+	e, output := newLogEngine(dir)
+	if err := e.translateJSKVTarget(context.Background(), rt, translate.Provider{}, translateArgs{dryRun: true, lockFile: lf}, []string{"de"}); err != nil {
+		t.Fatalf("translateJSKVTarget dry-run error: %v", err)
+	}
 
-	if !strings.Contains(output, "1 strings to translate") {
-		t.Fatalf("dry-run output missing stale-key count:\n%s", output)
+	if !strings.Contains(output.String(), "1 strings to translate") {
+		t.Fatalf("dry-run output missing stale-key count:\n%s", output.String())
 	}
 	if got := lf.TargetKeyCount(lockfile.LockTargetKey("welcome", "de")); got != 0 {
 		t.Fatalf("dry-run added %d lock keys, want 0", got)
@@ -169,13 +81,12 @@ func TestTranslateJSKVTargetDryRunCountsMissingSourceKeys(t *testing.T) {
 		{name: "force", args: translateArgs{dryRun: true, force: true, lockFile: lf}},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			output := captureStderr(t, func() {
-				if err := translateJSKVTarget(context.Background(), rt, translate.Provider{}, tc.args, []string{"de"}); err != nil {
-					t.Fatalf("translateJSKVTarget dry-run error: %v", err)
-				}
-			})
-			if !strings.Contains(output, "2 strings to translate") {
-				t.Fatalf("dry-run output missing full source count:\n%s", output)
+			e, output := newLogEngine(dir)
+			if err := e.translateJSKVTarget(context.Background(), rt, translate.Provider{}, tc.args, []string{"de"}); err != nil {
+				t.Fatalf("translateJSKVTarget dry-run error: %v", err)
+			}
+			if !strings.Contains(output.String(), "2 strings to translate") {
+				t.Fatalf("dry-run output missing full source count:\n%s", output.String())
 			}
 		})
 	}
@@ -194,7 +105,9 @@ func TestRunInitJSKVSyncsExistingFile(t *testing.T) {
 		t.Fatalf("write translation: %v", err)
 	}
 
-	runInitJSKV(testJSKVResolvedTarget(dir), []string{"de"})
+	if err := (&Engine{Root: dir}).runInitJSKV(testJSKVResolvedTarget(dir), []string{"de"}); err != nil {
+		t.Fatalf("runInitJSKV: %v", err)
+	}
 
 	updated, err := os.ReadFile(filepath.Join(translationsDir, "de.js"))
 	if err != nil {
@@ -225,7 +138,9 @@ func TestRunInitJSKVCreatesMissingFile(t *testing.T) {
 		t.Fatalf("write source: %v", err)
 	}
 
-	runInitJSKV(testJSKVResolvedTarget(dir), []string{"de"})
+	if err := (&Engine{Root: dir}).runInitJSKV(testJSKVResolvedTarget(dir), []string{"de"}); err != nil {
+		t.Fatalf("runInitJSKV: %v", err)
+	}
 
 	created, err := os.ReadFile(filepath.Join(translationsDir, "de.js"))
 	if err != nil {
@@ -250,29 +165,26 @@ func testJSKVResolvedTarget(dir string) config.ResolvedTarget {
 	}
 }
 
-func captureStderr(t *testing.T, fn func()) string {
-	t.Helper()
-
-	old := os.Stderr
-	r, w, err := os.Pipe()
-	if err != nil {
-		t.Fatalf("pipe stderr: %v", err)
-	}
-	os.Stderr = w
-
-	fn()
-
-	if err := w.Close(); err != nil {
-		t.Fatalf("close stderr writer: %v", err)
+// newLogEngine returns an engine for the project at root that records its
+// log messages in the returned builder.
+func newLogEngine(root string) (*Engine, *strings.Builder) {
+	var b strings.Builder
+	e := &Engine{Root: root}
+	e.OnLog = func(level Level, format string, args ...any) {
+		fmt.Fprintf(&b, format+"\n", args...)
 	}
-	os.Stderr = old
+	return e, &b
+}
 
-	out, err := io.ReadAll(r)
-	if err != nil {
-		t.Fatalf("read stderr: %v", err)
+func TestSetExclusionOptsClearsGlossary(t *testing.T) {
+	e := &Engine{}
+	opts := translate.Options{}
+	e.setExclusionOpts(&opts, &config.Target{Glossary: &config.Glossary{DoNotTranslate: []string{"MiniOS"}}})
+	if opts.Glossary == nil || len(opts.Glossary.DoNotTranslate) != 1 {
+		t.Fatalf("glossary = %+v, want the target glossary", opts.Glossary)
 	}
-	if err := r.Close(); err != nil {
-		t.Fatalf("close stderr reader: %v", err)
+	e.setExclusionOpts(&opts, &config.Target{})
+	if opts.Glossary != nil {
+		t.Fatalf("glossary = %+v, want none for a target without glossary", opts.Glossary)
 	}
-	return string(out)
 }
@@ -1,4 +1,4 @@
-package cli
+package engine
 
 import (
 	"context"
@@ -8,17 +8,18 @@ import (
 	"strings"
 
 	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/android"
+	"github.com/minios-linux/lokit/format/i18next"
+	po "github.com/minios-linux/lokit/format/po"
 	. "github.com/minios-linux/lokit/i18n"
-	"github.com/minios-linux/lokit/internal/format/android"
-	"github.com/minios-linux/lokit/internal/format/i18next"
-	po "github.com/minios-linux/lokit/internal/format/po"
+	"github.com/minios-linux/lokit/internal/layout"
 	"github.com/minios-linux/lokit/translate"
 )
 
 // translateGettextTarget translates a single gettext PO target.
 // Automatically runs extraction + PO update (equivalent to `lokit init`)
 // before translating, so that new strings are always picked up.
-func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+func (e *Engine) translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
 	poDir := rt.AbsPODir()
 	potPath := rt.AbsPOTFile()
 
@@ -52,12 +53,12 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 	// Skipped on --dry-run so that preview mode remains truly read-only.
 	var desktopFiles []string
 	if !a.dryRun {
-		logInfo(T("Extracting strings and updating PO files..."))
+		e.logInfo(T("Extracting strings and updating PO files..."))
 		var extractErr error
-		desktopFiles, extractErr = doExtract(proj)
+		desktopFiles, extractErr = e.doExtract(proj)
 		if extractErr != nil {
-			logWarning(T("Extraction failed: %v"), extractErr)
-			logInfo(T("Continuing with existing PO files"))
+			e.logWarning(T("Extraction failed: %v"), extractErr)
+			e.logInfo(T("Continuing with existing PO files"))
 		}
 
 		// Merge POT into existing PO files and seed desktop translations in
@@ -68,32 +69,32 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		if err == nil {
 			for _, lang := range langs {
 				poPath := rt.POPath(lang)
-				if !fileExists(poPath) {
+				if !layout.FileExists(poPath) {
 					continue // will be created below
 				}
 				existingPO, err := po.ParseFile(poPath)
 				if err != nil {
 					continue
 				}
-				merged := mergeAndSeedPO(existingPO, potPO, lang, root, desktopFiles)
+				merged := e.mergeAndSeedPO(existingPO, potPO, lang, root, desktopFiles)
 				if err := merged.WriteFile(poPath); err != nil {
-					logError(T("Updating %s: %v"), poPath, err)
+					e.logError(T("Updating %s: %v"), poPath, err)
 				}
 			}
-		} else if extractErr == nil || fileExists(proj.POTFile) {
-			logWarning(T("Cannot read POT template %s: %v"), proj.POTFile, err)
+		} else if extractErr == nil || layout.FileExists(proj.POTFile) {
+			e.logWarning(T("Cannot read POT template %s: %v"), proj.POTFile, err)
 		}
 	}
 
-	logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
 	if a.parallel {
-		logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
 	}
 	if a.chunkSize > 0 {
-		logInfo(T("Chunk size: %d"), a.chunkSize)
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
 	}
-	logInfo(T("PO dir: %s"), poDir)
-	logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+	e.logInfo(T("PO dir: %s"), poDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
 
 	if a.dryRun {
 		for _, lang := range langs {
@@ -101,9 +102,9 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 			if poFile, err := po.ParseFile(poPath); err == nil {
 				untranslated := poFile.UntranslatedEntries()
 				langName := po.LangNameNative(lang)
-				logInfo(T("%s (%s): %d strings to translate"), lang, langName, len(untranslated))
+				e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, len(untranslated))
 			} else {
-				logInfo(T("%s: PO file not found, will be created"), lang)
+				e.logInfo(T("%s: PO file not found, will be created"), lang)
 			}
 		}
 		return nil
@@ -128,22 +129,31 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		TranslateFuzzy:      a.fuzzy,
 		SystemPrompt:        a.prompt,
 		PromptType:          "default", // Use default gettext prompt template
+		SourceContextLines:  rt.Target.SourceContext,
+		SourceRoot:          rt.AbsRoot,
 		Verbose:             a.verbose,
 		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
 		LockTarget:          rt.Target.Name,
 		ForceTranslate:      a.force,
 		OnProgress: func(lang string, done, total int) {
-			logInfo(T("  %s: %d/%d"), lang, done, total)
+			e.logInfo(T("  %s: %d/%d"), lang, done, total)
 		},
 		OnLog: func(format string, args ...any) {
-			logInfo(format, args...)
+			e.logInfo(format, args...)
 		},
 		OnError: func(format string, args ...any) {
-			logError(format, args...)
+			e.logError(format, args...)
 		},
 	}
 
-	setExclusionOpts(&opts, &rt.Target)
+	e.setExclusionOpts(&opts, &rt.Target)
 
 	// Override prompt from target config
 	if rt.Target.Prompt != "" && opts.SystemPrompt == "" {
@@ -156,15 +166,15 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		poPath := rt.POPath(lang)
 		poFile, err := po.ParseFile(poPath)
 		if err != nil {
-			if !fileExists(poPath) {
+			if !layout.FileExists(poPath) {
 				// Pass root and desktopFiles so the new PO is seeded with inline
 				// desktop translations — same as init-created PO files.
-				poFile = createPOFromPOT(proj, lang, poPath, root, desktopFiles)
+				poFile = e.createPOFromPOT(proj, lang, poPath, root, desktopFiles)
 				if poFile == nil {
 					continue
 				}
 			} else {
-				logError(T("Reading %s: %v"), poPath, err)
+				e.logError(T("Reading %s: %v"), poPath, err)
 				continue
 			}
 		}
@@ -172,7 +182,7 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		// Skip if already fully translated unless a full re-run was requested.
 		if !a.retranslate && !a.force {
 			untranslated := poFile.UntranslatedEntries()
-			fuzzyEntries := poFile.FuzzyEntries()
+			fuzzyEntries := translate.FuzzyEntries(poFile)
 			if len(untranslated) == 0 && (!a.fuzzy || len(fuzzyEntries) == 0) {
 				continue
 			}
@@ -186,7 +196,7 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 	}
 
 	if len(langTasks) == 0 {
-		logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
+		e.logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
 		return nil
 	}
 
@@ -194,28 +204,28 @@ func translateGettextTarget(ctx context.Context, rt config.ResolvedTarget, prov
 }
 
 // translatePo4aTarget translates documentation PO files managed by po4a.
-func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+func (e *Engine) translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
 	cfgPath := rt.AbsPo4aConfig()
 	cfgDir := filepath.Dir(cfgPath)
 	poDir := filepath.Join(cfgDir, "po")
 
-	logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
-	logInfo(T("po4a config: %s"), cfgPath)
-	logInfo(T("PO dir: %s"), poDir)
-	logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	e.logInfo(T("po4a config: %s"), cfgPath)
+	e.logInfo(T("PO dir: %s"), poDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
 
 	if a.dryRun {
 		for _, lang := range langs {
 			files := rt.DocsPOFiles(lang)
 			if len(files) == 0 {
-				logInfo(T("%s: PO files not found"), lang)
+				e.logInfo(T("%s: PO files not found"), lang)
 				continue
 			}
 			count := 0
 			for _, file := range files {
 				poFile, err := po.ParseFile(file.Path)
 				if err != nil {
-					logInfo(T("%s: PO file not found at %s"), lang, file.Path)
+					e.logInfo(T("%s: PO file not found at %s"), lang, file.Path)
 					continue
 				}
 				if a.retranslate || a.force {
@@ -228,11 +238,11 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 				}
 				count += len(poFile.UntranslatedEntries())
 				if a.fuzzy {
-					count += len(poFile.FuzzyEntries())
+					count += len(translate.FuzzyEntries(poFile))
 				}
 			}
 			langName := po.LangNameNative(lang)
-			logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
 		}
 		return nil
 	}
@@ -255,29 +265,38 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 		TranslateFuzzy:      a.fuzzy,
 		SystemPrompt:        a.prompt,
 		PromptType:          "docs", // Use docs-specific prompt template
+		SourceContextLines:  rt.Target.SourceContext,
+		SourceRoot:          cfgDir,
 		Verbose:             a.verbose,
 		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
 		LockTarget:          rt.Target.Name,
 		ForceTranslate:      a.force,
 		OnProgress: func(lang string, done, total int) {
-			logInfo(T("  %s: %d/%d"), lang, done, total)
+			e.logInfo(T("  %s: %d/%d"), lang, done, total)
 		},
 		OnLog: func(format string, args ...any) {
-			logInfo(format, args...)
+			e.logInfo(format, args...)
 		},
 		OnError: func(format string, args ...any) {
-			logError(format, args...)
+			e.logError(format, args...)
 		},
 	}
 
-	setExclusionOpts(&opts, &rt.Target)
+	e.setExclusionOpts(&opts, &rt.Target)
 
 	// Override prompt from target config
 	if rt.Target.Prompt != "" && a.prompt == "" {
 		opts.SystemPrompt = rt.Target.Prompt
 	}
 	if opts.SystemPrompt == "" && opts.PromptType == "docs" {
-		logInfo(T("Using documentation-specific translation prompt (groff/man markup preservation)"))
+		e.logInfo(T("Using documentation-specific translation prompt (groff/man markup preservation)"))
 	}
 
 	// Auto-init: if any PO files are missing, run po4a to generate them
@@ -289,7 +308,7 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 		}
 	}
 	if hasMissing {
-		logInfo(T("PO files missing, running po4a initialization..."))
+		e.logInfo(T("PO files missing, running po4a initialization..."))
 		proj := &config.Project{
 			Name:        rt.Target.Name,
 			POStructure: config.POStructurePo4a,
@@ -307,7 +326,7 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 				break
 			}
 		}
-		if err := doPo4aInit(proj); err != nil {
+		if err := e.doPo4aInit(proj); err != nil {
 			return fmt.Errorf(T("auto-init failed: %v"), err)
 		}
 	}
@@ -317,24 +336,24 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 	for _, lang := range langs {
 		files := rt.DocsPOFiles(lang)
 		if len(files) == 0 {
-			logWarning(T("[%s] No PO files for %s, skipping"), rt.Target.Name, lang)
+			e.logWarning(T("[%s] No PO files for %s, skipping"), rt.Target.Name, lang)
 			continue
 		}
 		for _, file := range files {
 			poFile, err := po.ParseFile(file.Path)
 			if err != nil {
-				if !fileExists(file.Path) {
-					logWarning(T("[%s] No PO file for %s at %s, skipping"), rt.Target.Name, lang, file.Path)
+				if !layout.FileExists(file.Path) {
+					e.logWarning(T("[%s] No PO file for %s at %s, skipping"), rt.Target.Name, lang, file.Path)
 					continue
 				}
-				logError(T("Reading %s: %v"), file.Path, err)
+				e.logError(T("Reading %s: %v"), file.Path, err)
 				continue
 			}
 
 			// Skip if already fully translated unless a full re-run was requested.
 			if !a.retranslate && !a.force {
 				untranslated := poFile.UntranslatedEntries()
-				fuzzyEntries := poFile.FuzzyEntries()
+				fuzzyEntries := translate.FuzzyEntries(poFile)
 				if len(untranslated) == 0 && (!a.fuzzy || len(fuzzyEntries) == 0) {
 					continue
 				}
@@ -350,7 +369,7 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 	}
 
 	if len(langTasks) == 0 {
-		logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
+		e.logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
 		return nil
 	}
 
@@ -358,16 +377,16 @@ func translatePo4aTarget(ctx context.Context, rt config.ResolvedTarget, prov tra
 }
 
 // translateI18NextTarget translates flat JSON translation files.
-func translateI18NextTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
-	return translateJSONLikeTarget(ctx, rt, prov, a, langs)
+func (e *Engine) translateI18NextTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	return e.translateJSONLikeTarget(ctx, rt, prov, a, langs)
 }
 
-func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+func (e *Engine) translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
 	transDir := rt.AbsTranslationsDir()
 
-	logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
-	logInfo(T("Translations dir: %s"), transDir)
-	logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	e.logInfo(T("Translations dir: %s"), transDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
 
 	srcPath := rt.SourcePath()
 	srcFile, err := i18next.ParseFile(srcPath)
@@ -375,7 +394,7 @@ func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		return fmt.Errorf(T("cannot read source language file %s: %w"), srcPath, err)
 	}
 	srcKeys := srcFile.Keys()
-	logInfo(T("Source keys (%s): %d"), rt.Target.SourceLang, len(srcKeys))
+	e.logInfo(T("Source keys (%s): %d"), rt.Target.SourceLang, len(srcKeys))
 
 	if a.dryRun {
 		for _, lang := range langs {
@@ -383,7 +402,7 @@ func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov
 			file, err := i18next.ParseFile(filePath)
 			if err != nil {
 				langName := i18next.ResolveMeta(lang).Name
-				logInfo(T("%s (%s): %d strings to translate (file will be auto-created)"), lang, langName, len(srcKeys))
+				e.logInfo(T("%s (%s): %d strings to translate (file will be auto-created)"), lang, langName, len(srcKeys))
 				continue
 			}
 			count := len(file.UntranslatedKeys())
@@ -391,7 +410,7 @@ func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov
 				count = len(file.Keys())
 			}
 			langName := i18next.ResolveMeta(lang).Name
-			logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
 		}
 		return nil
 	}
@@ -420,35 +439,40 @@ func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		PromptType:          "i18next",
 		Verbose:             a.verbose,
 		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
 		LockTarget:          rt.Target.Name,
 		ForceTranslate:      a.force,
 		OnProgress: func(lang string, done, total int) {
-			logInfo(T("  %s: %d/%d"), lang, done, total)
+			e.logInfo(T("  %s: %d/%d"), lang, done, total)
 		},
 		OnLog: func(format string, args ...any) {
-			logInfo(format, args...)
+			e.logInfo(format, args...)
 		},
 		OnError: func(format string, args ...any) {
-			logError(format, args...)
+			e.logError(format, args...)
 		},
 	}
 
-	setExclusionOpts(&opts, &rt.Target)
+	e.setExclusionOpts(&opts, &rt.Target)
 
 	var langTasks []translate.KVLangTask
 	for _, lang := range langs {
 		filePath := rt.TranslationPath(lang)
 		file, err := i18next.ParseFile(filePath)
 		if err != nil {
-			meta := i18next.ResolveMeta(lang)
-			file = &i18next.File{Meta: meta, Translations: make(map[string]string)}
-			for _, key := range srcKeys {
-				file.Translations[key] = ""
-			}
-			logInfo(T("Auto-creating %s with %d keys"), filePath, len(srcKeys))
+			file = i18next.NewTranslationFile(srcFile, lang)
+			e.logInfo(T("Auto-creating %s with %d keys"), filePath, len(file.Keys()))
+		} else if added := file.SyncKeys(srcFile, lang); added > 0 {
+			e.logInfo(T("%s: added %d missing keys"), filePath, added)
 		}
 
-		if !a.retranslate && !a.force && len(file.UntranslatedKeys()) == 0 {
+		if !a.retranslate && !a.force && len(file.UntranslatedKeys()) == 0 && !hasRejectedReview(a, rt.Target.Name, lang) {
 			continue
 		}
 
@@ -462,7 +486,7 @@ func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov
 	}
 
 	if len(langTasks) == 0 {
-		logSuccess(T("All UI translations are complete!"))
+		e.logSuccess(T("All UI translations are complete!"))
 		return nil
 	}
 
@@ -470,18 +494,18 @@ func translateJSONLikeTarget(ctx context.Context, rt config.ResolvedTarget, prov
 }
 
 // translateAndroidTarget translates Android strings.xml files.
-func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+func (e *Engine) translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
 	resDir := rt.AbsResDir()
 
-	logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
+	e.logInfo(T("Provider: %s (%s), Model: %s"), prov.Name, prov.ID, prov.Model)
 	if a.parallel {
-		logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
+		e.logInfo(T("Parallel: enabled, max concurrent: %d"), a.maxConcurrent)
 	}
 	if a.chunkSize > 0 {
-		logInfo(T("Chunk size: %d"), a.chunkSize)
+		e.logInfo(T("Chunk size: %d"), a.chunkSize)
 	}
-	logInfo(T("Res dir: %s"), resDir)
-	logInfo(T("Translating: %s"), strings.Join(langs, ", "))
+	e.logInfo(T("Res dir: %s"), resDir)
+	e.logInfo(T("Translating: %s"), strings.Join(langs, ", "))
 
 	// Load source (English) strings.xml
 	srcPath := android.SourceStringsXMLPath(resDir)
@@ -490,7 +514,7 @@ func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		return fmt.Errorf(T("cannot read source strings.xml %s: %w"), srcPath, err)
 	}
 	srcTotal, _, _ := srcFile.Stats()
-	logInfo(T("Source strings: %d"), srcTotal)
+	e.logInfo(T("Source strings: %d"), srcTotal)
 
 	if a.dryRun {
 		for _, lang := range langs {
@@ -498,9 +522,10 @@ func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov
 			file, err := android.ParseFile(filePath)
 			if err != nil {
 				langName := i18next.ResolveMeta(lang).Name
-				logInfo(T("%s (%s): %d strings to translate (file will be auto-created)"), lang, langName, srcTotal)
+				e.logInfo(T("%s (%s): %d strings to translate (file will be auto-created)"), lang, langName, srcTotal)
 				continue
 			}
+			file.SyncKeys(srcFile, lang)
 			untranslated := file.UntranslatedKeys()
 			count := len(untranslated)
 			if a.retranslate || a.force {
@@ -508,7 +533,7 @@ func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov
 				count = srcTotal // retranslate all
 			}
 			langName := i18next.ResolveMeta(lang).Name
-			logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
 		}
 		return nil
 	}
@@ -539,20 +564,27 @@ func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		PromptType:          "android", // Use Android-specific prompt template
 		Verbose:             a.verbose,
 		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
 		LockTarget:          rt.Target.Name,
 		ForceTranslate:      a.force,
 		OnProgress: func(lang string, done, total int) {
-			logInfo(T("  %s: %d/%d"), lang, done, total)
+			e.logInfo(T("  %s: %d/%d"), lang, done, total)
 		},
 		OnLog: func(format string, args ...any) {
-			logInfo(format, args...)
+			e.logInfo(format, args...)
 		},
 		OnError: func(format string, args ...any) {
-			logError(format, args...)
+			e.logError(format, args...)
 		},
 	}
 
-	setExclusionOpts(&opts, &rt.Target)
+	e.setExclusionOpts(&opts, &rt.Target)
 
 	// Build language tasks
 	var langTasks []translate.AndroidLangTask
@@ -562,19 +594,19 @@ func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov
 		if err != nil {
 			// Auto-create translation file from source with empty values
 			file = android.NewTranslationFile(srcFile, lang)
-			logInfo(T("Auto-creating %s with %d strings"), filePath, srcTotal)
+			e.logInfo(T("Auto-creating %s with %d strings"), filePath, srcTotal)
 		} else {
 			// Sync keys: add any new keys from source
-			added := file.SyncKeys(srcFile)
+			added := file.SyncKeys(srcFile, lang)
 			if added > 0 {
-				logInfo(T("Added %d new strings to %s"), added, filePath)
+				e.logInfo(T("Added %d new strings to %s"), added, filePath)
 			}
 		}
 
 		// Skip if already fully translated unless a full re-run was requested.
 		if !a.retranslate && !a.force {
 			untranslated := file.UntranslatedKeys()
-			if len(untranslated) == 0 {
+			if len(untranslated) == 0 && !hasRejectedReview(a, rt.Target.Name, lang) {
 				continue
 			}
 		}
@@ -591,35 +623,9 @@ func translateAndroidTarget(ctx context.Context, rt config.ResolvedTarget, prov
 	}
 
 	if len(langTasks) == 0 {
-		logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
+		e.logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
 		return nil
 	}
 
 	return translate.TranslateAllAndroid(ctx, langTasks, opts)
 }
-
-// intersectLanguages returns the intersection of two language lists.
-func intersectLanguages(available, filter []string) []string {
-	filterMap := make(map[string]bool)
-	for _, l := range filter {
-		filterMap[strings.TrimSpace(l)] = true
-	}
-	var result []string
-	for _, l := range available {
-		if filterMap[l] {
-			result = append(result, l)
-		}
-	}
-	return result
-}
-
-// filterOutLang removes a specific language from a list.
-func filterOutLang(langs []string, exclude string) []string {
-	var result []string
-	for _, l := range langs {
-		if l != exclude {
-			result = append(result, l)
-		}
-	}
-	return result
-}
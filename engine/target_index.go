@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/i18next"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/translate"
+)
+
+func (e *Engine) runInitIndex(rt config.ResolvedTarget, langs []string) error {
+	item, err := layout.LoadIndexItem(rt)
+	if err != nil {
+		return fmt.Errorf(T("Index init failed: %v"), err)
+	}
+	if item == nil {
+		e.logInfo(T("No index source for %s"), rt.Target.Name)
+		return nil
+	}
+
+	sourceValues := layout.IndexSourceValues(item)
+	created := 0
+	updated := 0
+
+	for _, lang := range langs {
+		if lang == rt.Target.SourceLang {
+			continue
+		}
+
+		filePath := rt.TranslationPath(lang)
+		targetFile, err := layout.ParseIndexFile(filePath)
+		if err != nil {
+			targetFile = layout.NewIndexFile(sourceValues)
+			if err := targetFile.WriteFile(filePath); err != nil {
+				e.logError(T("Creating %s: %v"), filePath, err)
+				continue
+			}
+			e.logSuccess(T("Created: %s (%d keys)"), filePath, len(sourceValues))
+			created++
+			continue
+		}
+
+		if !targetFile.AddKeys(sourceValues) {
+			continue
+		}
+		if err := targetFile.WriteFile(filePath); err != nil {
+			e.logError(T("Writing %s: %v"), filePath, err)
+			continue
+		}
+		e.logSuccess(T("Updated: %s"), filePath)
+		updated++
+	}
+
+	e.logInfo(T("Index init: %d created, %d updated"), created, updated)
+	return nil
+}
+
+func (e *Engine) translateIndexTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string, quietNoop bool) error {
+	item, err := layout.LoadIndexItem(rt)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return fmt.Errorf(T("index source item not found for %s"), rt.Target.Name)
+	}
+	sourceValues := layout.IndexSourceValues(item)
+
+	if a.dryRun {
+		for _, lang := range langs {
+			langName := i18next.ResolveMeta(lang).Name
+			count := len(sourceValues)
+			if !a.retranslate && !a.force {
+				if f, err := layout.ParseIndexFile(rt.TranslationPath(lang)); err == nil {
+					f.AddKeys(sourceValues)
+					count = len(f.UntranslatedKeys())
+				}
+			}
+			e.logInfo(T("%s (%s): %d strings to translate"), lang, langName, count)
+		}
+		return nil
+	}
+
+	parallelMode := translate.ParallelSequential
+	if a.parallel {
+		parallelMode = translate.ParallelFullParallel
+	}
+
+	systemPrompt := a.prompt
+	if systemPrompt == "" {
+		systemPrompt = rt.Target.Prompt
+	}
+
+	opts := translate.Options{
+		Provider:            prov,
+		SourceLanguage:      rt.Target.SourceLang,
+		ChunkSize:           a.chunkSize,
+		ParallelMode:        parallelMode,
+		MaxConcurrent:       a.maxConcurrent,
+		RequestDelay:        a.requestDelay,
+		Timeout:             a.timeout,
+		MaxRetries:          a.maxRetries,
+		RetranslateExisting: a.retranslate,
+		SystemPrompt:        systemPrompt,
+		PromptType:          "default",
+		Verbose:             a.verbose,
+		LockFile:            a.lockFile,
+		Memory:              a.memory,
+		Review:              a.review,
+		ReviewFuzzy:         a.reviewFuzzy,
+		Usage:               a.usage,
+		Providers:           a.providers,
+		Journal:             a.journal,
+		Resume:              a.resume,
+		LockTarget:          rt.Target.Name,
+		ForceTranslate:      a.force,
+		OnProgress: func(lang string, done, total int) {
+			e.logInfo(T("  %s: %d/%d strings"), lang, done, total)
+		},
+		OnLog: func(format string, args ...any) {
+			if quietNoop && strings.HasPrefix(format, "  Lock file: skipping") {
+				return
+			}
+			e.logInfo(format, args...)
+		},
+		OnError: func(format string, args ...any) {
+			e.logError(format, args...)
+		},
+	}
+
+	e.setExclusionOpts(&opts, &rt.Target)
+
+	var tasks []translate.KVLangTask
+	for _, lang := range langs {
+		filePath := rt.TranslationPath(lang)
+		f, err := layout.ParseIndexFile(filePath)
+		if err != nil {
+			f = layout.NewIndexFile(sourceValues)
+		}
+		f.AddKeys(sourceValues)
+		if !a.retranslate && !a.force && len(f.UntranslatedKeys()) == 0 && !hasRejectedReview(a, rt.Target.Name, lang) {
+			continue
+		}
+
+		tasks = append(tasks, translate.KVLangTask{
+			Lang:         lang,
+			LangName:     i18next.ResolveMeta(lang).Name,
+			FilePath:     filePath,
+			File:         f,
+			SourceValues: sourceValues,
+		})
+	}
+
+	if len(tasks) == 0 {
+		if !quietNoop {
+			e.logSuccess(T("[%s] All translations complete!"), rt.Target.Name)
+		}
+		return nil
+	}
+
+	return translate.TranslateAllKV(ctx, tasks, opts, translate.DefaultKVChunkTranslator())
+}
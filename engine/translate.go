@@ -0,0 +1,424 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minios-linux/lokit/config"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/journal"
+	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/tm"
+	"github.com/minios-linux/lokit/translate"
+)
+
+// TranslateOptions selects what Translate translates and how.
+type TranslateOptions struct {
+	// Targets names the targets to translate (see Engine.Select); empty
+	// selects all targets.
+	Targets []string
+	// Languages limits translation to these languages; empty selects all
+	// languages of each target.
+	Languages []string
+
+	// Provider, Model and BaseURL replace the configured provider chain
+	// with a single provider when set. APIKey is used for the primary
+	// provider instead of its stored credential.
+	Provider, Model, BaseURL, APIKey string
+	// Proxy is the HTTP proxy URL for provider requests.
+	Proxy string
+	// Prompt replaces the system prompt; empty uses the configured one.
+	Prompt string
+
+	// ChunkSize is the number of strings per request (0 = all at once).
+	ChunkSize int
+	// Parallel is the number of concurrent requests (0 = sequential).
+	Parallel int
+	// RequestDelay is the delay between requests.
+	RequestDelay time.Duration
+	// Timeout is the timeout of each request (0 = provider default).
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed request.
+	MaxRetries int
+	// MaxTokens is the token budget of the run (0 = unlimited). It is
+	// ignored when Usage is set.
+	MaxTokens int
+	// Usage records token usage. Runs sharing a tracker share its budget;
+	// nil starts a new one.
+	Usage *translate.UsageTracker
+
+	// Retranslate translates all strings again, not only missing ones.
+	Retranslate bool
+	// Fuzzy also translates fuzzy PO entries.
+	Fuzzy bool
+	// Force translates strings whose source is unchanged since the last
+	// run.
+	Force bool
+	// DryRun reports what would be translated without calling a provider
+	// or writing files.
+	DryRun bool
+	// NoMemory disables the translation memory.
+	NoMemory bool
+	// Resume applies translations recorded in the journal of an
+	// interrupted run instead of translating them again.
+	Resume bool
+	// Verbose logs requests and lock file updates.
+	Verbose bool
+}
+
+// TranslateResult is the outcome of Translate.
+type TranslateResult struct {
+	// Targets holds one result per translated target. Targets without
+	// languages to translate are left out.
+	Targets []TargetResult
+	// Usage holds the token usage of the run.
+	Usage *translate.UsageTracker
+	// Translated is the number of strings translated, reused from the
+	// translation memory or resumed from the journal; 0 when there was
+	// nothing to translate or on a dry run.
+	Translated int
+}
+
+// translateArgs holds the options of a translation run and the state it
+// shares between targets.
+type translateArgs struct {
+	provider, apiKey, model, baseURL string
+	chunkSize                        int
+	retranslate, fuzzy               bool
+	prompt                           string
+	verbose, dryRun, force, parallel bool
+	noMemory, resume                 bool
+	maxTokens                        int
+	maxConcurrent                    int
+	requestDelay, timeout            time.Duration
+	proxy                            string
+	maxRetries                       int
+	lockFile                         *lockfile.LockFile
+	memory                           *tm.Memory
+	review                           *review.State
+	reviewFuzzy                      bool
+	usage                            *translate.UsageTracker
+	providers                        *translate.ProviderChain
+	journal                          *journal.Journal
+}
+
+func (o TranslateOptions) args() translateArgs {
+	return translateArgs{
+		provider: o.Provider, apiKey: o.APIKey, model: o.Model,
+		baseURL:   o.BaseURL,
+		chunkSize: o.ChunkSize, retranslate: o.Retranslate,
+		fuzzy: o.Fuzzy, prompt: o.Prompt, verbose: o.Verbose,
+		dryRun: o.DryRun, force: o.Force, noMemory: o.NoMemory, resume: o.Resume, parallel: o.Parallel > 0,
+		maxTokens:     o.MaxTokens,
+		maxConcurrent: o.Parallel, requestDelay: o.RequestDelay,
+		timeout: o.Timeout, proxy: o.Proxy, maxRetries: o.MaxRetries,
+		usage: o.Usage,
+	}
+}
+
+// Translate translates the selected targets with AI providers. Cancelling
+// ctx stops the run after saving the translations completed so far.
+//
+// Errors selecting the targets or setting up their providers are returned
+// with a nil result before anything is translated. Otherwise the result
+// lists every target and the error joins the errors of failed targets,
+// plus translate.ErrTokenBudgetExceeded if the budget ran out.
+func (e *Engine) Translate(ctx context.Context, opts TranslateOptions) (*TranslateResult, error) {
+	a := opts.args()
+	if p := e.Config.ProviderChain().Primary(); p != nil && a.prompt == "" {
+		a.prompt = p.Prompt
+	}
+
+	resolved, err := e.Select(opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved) == 0 {
+		return nil, withKind(ErrConfig, errors.New(T("No targets defined in lokit.yaml")))
+	}
+	providers := e.newProviderResolver(e.Config, a)
+	for _, rt := range resolved {
+		if _, err := providers.forTarget(rt); err != nil {
+			return nil, err
+		}
+	}
+
+	a = e.loadTranslateState(a)
+	result := &TranslateResult{Usage: a.usage}
+
+	if !a.dryRun {
+		jr, err := journal.Open(e.Root)
+		if err != nil {
+			e.logWarning(T("Could not load journal: %v"), err)
+			jr = nil
+		}
+		a.journal = jr
+	}
+	if a.journal != nil && a.journal.Len() > 0 {
+		if a.resume {
+			e.logInfo(T("Resuming %d translations from %s"), a.journal.Len(), journal.FileName)
+		} else {
+			e.logWarning(T("%s holds %d translations of an interrupted run (%s); use --resume to apply them"),
+				journal.FileName, a.journal.Len(), strings.Join(a.journal.Targets(), ", "))
+			e.logWarning(T("Without --resume they are discarded as their files are saved"))
+		}
+	} else if a.resume {
+		e.logInfo(T("Nothing to resume: %s is empty"), journal.FileName)
+	}
+	defer e.closeJournal(a)
+
+	translateOne := func(rt config.ResolvedTarget, langs []string, run func(prov translate.Provider, ta translateArgs) error) {
+		e.targetStart(rt)
+		logged := e.logged.Load()
+		prov, ta, err := providers.activeFor(rt, a)
+		if err == nil {
+			err = run(prov, ta)
+		}
+		// Files that could not be read or written are logged and skipped;
+		// the target still failed.
+		if n := e.logged.Load() - logged; err == nil && n > 0 {
+			err = fmt.Errorf(T("%d errors reported"), n)
+		}
+		if err != nil {
+			e.logError(T("[%s] %v"), rt.Target.Name, err)
+		}
+		res := TargetResult{Target: rt, Languages: langs, Err: err}
+		result.Targets = append(result.Targets, res)
+		e.targetDone(res)
+	}
+
+	indexGroups := make(map[string][]config.ResolvedTarget)
+	for _, rt := range resolved {
+		base, ok := layout.IndexGroup(rt)
+		if !ok {
+			continue
+		}
+		indexGroups[base] = append(indexGroups[base], rt)
+	}
+	processedGroups := make(map[string]struct{})
+
+	var budgetErr error
+	for _, rt := range resolved {
+		if ctx.Err() != nil {
+			break
+		}
+		if a.usage.Exceeded() {
+			e.logWarning(T("Token budget of %d exceeded, skipping remaining targets"), a.usage.Budget)
+			budgetErr = translate.ErrTokenBudgetExceeded
+			break
+		}
+
+		if base, ok := layout.IndexGroup(rt); ok {
+			group := indexGroups[base]
+			if len(group) > 1 {
+				if _, done := processedGroups[base]; done {
+					continue
+				}
+				processedGroups[base] = struct{}{}
+
+				for _, grt := range group {
+					if ctx.Err() != nil {
+						break
+					}
+					targetLangs := e.targetLanguages(grt, opts.Languages)
+					if len(targetLangs) == 0 {
+						continue
+					}
+					translateOne(grt, targetLangs, func(prov translate.Provider, ga translateArgs) error {
+						return e.translateIndexTarget(ctx, grt, prov, ga, targetLangs, true)
+					})
+				}
+
+				e.saveTargetState(a, base)
+				continue
+			}
+		}
+
+		targetLangs := e.targetLanguages(rt, opts.Languages)
+		if len(targetLangs) == 0 {
+			e.logInfo(T("[%s] No languages to translate, skipping"), rt.Target.Name)
+			continue
+		}
+
+		translateOne(rt, targetLangs, func(prov translate.Provider, ta translateArgs) error {
+			return e.translateTarget(ctx, rt, prov, ta, targetLangs)
+		})
+
+		e.saveTargetState(a, rt.Target.Name)
+	}
+
+	if err := a.lockFile.Save(); err != nil {
+		e.logWarning(T("Could not save lock file: %v"), err)
+	}
+	result.Translated = a.lockFile.Updates()
+
+	return result, errors.Join(targetErrors(result.Targets), budgetErr)
+}
+
+// targetLanguages returns the languages of rt to translate: all but the
+// source language, limited to filter if it is not empty.
+func (e *Engine) targetLanguages(rt config.ResolvedTarget, filter []string) []string {
+	langs := rt.Languages
+	if len(filter) > 0 {
+		langs = layout.IntersectLanguages(langs, filter)
+	}
+	return layout.FilterOutLang(langs, rt.Target.SourceLang)
+}
+
+// loadTranslateState loads the lock file, translation memory and review
+// state into a and sets up token usage tracking.
+func (e *Engine) loadTranslateState(a translateArgs) translateArgs {
+	lockF, err := lockfile.Load(e.Root)
+	if err != nil {
+		e.logWarning(T("Could not load lock file: %v"), err)
+		lockF = &lockfile.LockFile{Version: lockfile.Version, Checksums: make(map[string]map[string]string)}
+	}
+	a.lockFile = lockF
+
+	if !a.noMemory {
+		mem, err := tm.Load(e.Root)
+		if err != nil {
+			e.logWarning(T("Could not load translation memory: %v"), err)
+			mem = nil
+		}
+		a.memory = mem
+	}
+
+	// Without review state no translation is marked for review.
+	if e.Config.Review == nil || !e.Config.Review.Disabled {
+		reviewState, err := review.Load(e.Root)
+		if err != nil {
+			e.logWarning(T("Could not load review state: %v"), err)
+			reviewState = review.New()
+		}
+		a.review = reviewState
+		a.reviewFuzzy = e.Config.Review != nil && e.Config.Review.MarkFuzzy
+	}
+
+	if a.usage == nil {
+		a.usage = translate.NewUsageTracker(a.maxTokens)
+	}
+	return a
+}
+
+// translateTarget translates one resolved target into langs.
+func (e *Engine) translateTarget(ctx context.Context, rt config.ResolvedTarget, prov translate.Provider, a translateArgs, langs []string) error {
+	switch rt.Target.Type {
+	case config.TargetTypeGettext:
+		return e.translateGettextTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypePo4a:
+		return e.translatePo4aTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeI18Next:
+		return e.translateI18NextTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeVueI18n:
+		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+			return e.translateIndexTarget(ctx, rt, prov, a, langs, false)
+		}
+		return e.translateVueI18nTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeAndroid:
+		return e.translateAndroidTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeYAML:
+		return e.translateYAMLTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeMarkdown:
+		return e.translateMarkdownTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeProperties:
+		return e.translatePropertiesTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeFlutter:
+		return e.translateFlutterTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeJSKV:
+		return e.translateJSKVTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeDesktop:
+		return e.translateDesktopTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypePolkit:
+		return e.translatePolkitTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeAppleStrings:
+		return e.translateAppleStringsTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeXCStrings:
+		return e.translateXCStringsTarget(ctx, rt, prov, a, langs)
+	case config.TargetTypeQt:
+		return e.translateQtTarget(ctx, rt, prov, a, langs)
+	default:
+		e.logWarning(T("[%s] Unknown target type %q, skipping"), rt.Target.Name, rt.Target.Type)
+		return nil
+	}
+}
+
+// saveTargetState writes the lock file, translation memory and review state
+// after a target has been translated.
+func (e *Engine) saveTargetState(a translateArgs, name string) {
+	if err := a.lockFile.Save(); err != nil {
+		e.logWarning(T("Could not save lock file after target %s: %v"), name, err)
+	} else if a.verbose {
+		e.logInfo(T("Lock file saved after target %s/%s"), name, "*")
+	}
+	e.saveTranslationMemory(a)
+	e.saveReviewState(a)
+}
+
+// saveTranslationMemory writes lokit.tm if the memory is enabled.
+// The memory is not written during a dry run.
+func (e *Engine) saveTranslationMemory(a translateArgs) {
+	if a.memory == nil || a.dryRun {
+		return
+	}
+	if err := a.memory.Save(); err != nil {
+		e.logWarning(T("Could not save translation memory: %v"), err)
+	}
+}
+
+// closeJournal closes lokit.journal, removing it once every recorded
+// translation has been saved.
+func (e *Engine) closeJournal(a translateArgs) {
+	if a.journal == nil {
+		return
+	}
+	if err := a.journal.Close(); err != nil {
+		e.logWarning(T("Could not close journal: %v"), err)
+	}
+}
+
+// saveReviewState writes lokit.review. The state is not written during a
+// dry run.
+func (e *Engine) saveReviewState(a translateArgs) {
+	if a.review == nil || a.dryRun {
+		return
+	}
+	if err := a.review.Save(); err != nil {
+		e.logWarning(T("Could not save review state: %v"), err)
+	}
+}
+
+// hasRejectedReview reports whether a key-value target language has
+// translations rejected in review, which must be translated again even when
+// no key is untranslated.
+func hasRejectedReview(a translateArgs, target, lang string) bool {
+	return a.review != nil && a.review.HasRejected(lockfile.LockTargetKey(target, lang))
+}
+
+// setExclusionOpts populates the locked/ignored key fields, the glossary
+// and the length limits on translate.Options from the given target
+// configuration.
+func (e *Engine) setExclusionOpts(opts *translate.Options, t *config.Target) {
+	opts.LockedKeys = t.LockedKeys
+	opts.IgnoredKeys = t.IgnoredKeys
+	patterns, err := layout.CompileLockedPatterns(t.LockedPatterns)
+	if err != nil {
+		e.logWarning(T("%v"), err)
+	}
+	opts.LockedPatterns = patterns
+	// Options are reused across targets; a target without a glossary must
+	// not keep the previous target's one.
+	opts.Glossary = nil
+	if t.Glossary != nil {
+		opts.Glossary = &translate.Glossary{
+			DoNotTranslate: t.Glossary.DoNotTranslate,
+			Terms:          t.Glossary.Terms,
+		}
+	}
+	opts.LengthLimits = layout.LengthLimits(t)
+}
@@ -15,7 +15,7 @@ import (
 	"sort"
 	"strings"
 
-	po "github.com/minios-linux/lokit/internal/format/po"
+	po "github.com/minios-linux/lokit/format/po"
 )
 
 // SupportedExtensions maps file extensions to xgettext language names.
@@ -109,6 +109,12 @@ var skipDirs = map[string]bool{
 	".eggs":        true,
 }
 
+// IsSkippedDir reports whether source scanning skips directories named name
+// (version control metadata, dependencies, build output).
+func IsSkippedDir(name string) bool {
+	return skipDirs[name]
+}
+
 // shouldSkipPath returns true for Debian build artifacts and compiled PO files
 // that should not be scanned for translatable strings.
 func shouldSkipPath(path string) bool {
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"testing"
 
-	po "github.com/minios-linux/lokit/internal/format/po"
+	po "github.com/minios-linux/lokit/format/po"
 )
 
 func TestRunGoExtractUsesRelativeReferences(t *testing.T) {
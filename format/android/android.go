@@ -5,6 +5,9 @@
 //   - <string-array>  — ordered list of strings
 //   - <plurals>       — quantity-keyed plural forms (zero/one/two/few/many/other)
 //
+// Translation files get the plural quantities of their own language (see
+// Quantities), not the ones of the source file.
+//
 // Resources with translatable="false" are parsed but excluded from all
 // translation-related accessors (Keys, UntranslatedKeys, SyncKeys, etc.).
 // They are still written back verbatim on Marshal.
@@ -17,7 +20,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/minios-linux/lokit/langmeta"
 )
 
 // ---------------------------------------------------------------------------
@@ -50,6 +56,10 @@ type Entry struct {
 	Name string
 	// Translatable reflects the translatable="…" attribute. Defaults to true.
 	Translatable bool
+	// MaxLength is the maximum length of the translation declared with a
+	// maxLength attribute in any namespace (e.g. tools:maxLength="20"); 0
+	// if none. It is read from source files only and not written back.
+	MaxLength int
 
 	// --- KindString ---
 
@@ -108,11 +118,11 @@ func (e *Entry) IsTranslated() bool {
 		}
 		return true
 	case KindPlurals:
-		if len(e.Plurals) == 0 {
+		if len(e.PluralOrder) == 0 {
 			return false
 		}
-		for _, v := range e.Plurals {
-			if v == "" {
+		for _, q := range e.PluralOrder {
+			if e.Plurals[q] == "" {
 				return false
 			}
 		}
@@ -307,6 +317,18 @@ func parseAttrs(elem xml.StartElement) (name string, translatable bool) {
 	return
 }
 
+// parseMaxLength returns the value of a maxLength attribute, or 0.
+func parseMaxLength(elem xml.StartElement) int {
+	for _, attr := range elem.Attr {
+		if attr.Name.Local == "maxLength" {
+			if n, err := strconv.Atoi(strings.TrimSpace(attr.Value)); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
 // parseStringElement parses a <string> element already opened.
 func parseStringElement(dec *xml.Decoder, elem xml.StartElement, cdata cdataSet) (*Entry, error) {
 	name, translatable := parseAttrs(elem)
@@ -319,6 +341,7 @@ func parseStringElement(dec *xml.Decoder, elem xml.StartElement, cdata cdataSet)
 		Kind:         KindString,
 		Name:         name,
 		Translatable: translatable,
+		MaxLength:    parseMaxLength(elem),
 		Value:        inner.String(),
 		UseCDATA:     cdata[name],
 	}, nil
@@ -331,6 +354,7 @@ func parseStringArrayElement(dec *xml.Decoder, elem xml.StartElement, cdata cdat
 		Kind:         KindStringArray,
 		Name:         name,
 		Translatable: translatable,
+		MaxLength:    parseMaxLength(elem),
 	}
 
 	depth := 1
@@ -368,6 +392,7 @@ func parsePluralsElement(dec *xml.Decoder, elem xml.StartElement, cdata cdataSet
 		Kind:         KindPlurals,
 		Name:         name,
 		Translatable: translatable,
+		MaxLength:    parseMaxLength(elem),
 		Plurals:      make(map[string]string),
 		PluralCDATA:  make(map[string]bool),
 	}
@@ -549,6 +574,36 @@ func (f *File) GetEntry(name string) *Entry {
 	return f.Entries[idx]
 }
 
+// Notes returns the translator notes of resources: the XML comment directly
+// preceding a resource, keyed by resource name.
+func (f *File) Notes() map[string]string {
+	notes := make(map[string]string)
+	var pending string
+	for _, e := range f.Entries {
+		if e.IsComment() {
+			pending = e.Comment
+			continue
+		}
+		if pending != "" && e.Name != "" {
+			notes[e.Name] = pending
+		}
+		pending = ""
+	}
+	return notes
+}
+
+// MaxLengths returns the declared maximum lengths of resources, keyed by
+// resource name.
+func (f *File) MaxLengths() map[string]int {
+	limits := make(map[string]int)
+	for _, e := range f.Entries {
+		if e.MaxLength > 0 && e.Name != "" {
+			limits[e.Name] = e.MaxLength
+		}
+	}
+	return limits
+}
+
 // Set sets the string value for a KindString entry. Returns false if the key
 // doesn't exist or is not a KindString.
 func (f *File) Set(name, value string) bool {
@@ -708,10 +763,17 @@ func marshalStringValue(s string, useCDATA bool) string {
 // Creating / syncing translation files
 // ---------------------------------------------------------------------------
 
+// Quantities returns the <plurals> quantities a translation into lang needs:
+// the CLDR plural categories of the language, in canonical order.
+func Quantities(lang string) []string {
+	return langmeta.PluralCategories(lang)
+}
+
 // NewTranslationFile creates a new File with the same structure as source but
-// with all translatable values empty (untranslated). Non-translatable entries
-// are copied verbatim; comments are preserved.
-func NewTranslationFile(source *File, _ string) *File {
+// with all translatable values empty (untranslated). Plurals get the
+// quantities of lang. Non-translatable entries are copied verbatim; comments
+// are preserved.
+func NewTranslationFile(source *File, lang string) *File {
 	f := &File{byName: make(map[string]int)}
 	for _, e := range source.Entries {
 		var ne *Entry
@@ -737,8 +799,9 @@ func NewTranslationFile(source *File, _ string) *File {
 
 		case KindPlurals:
 			plurals := make(map[string]string)
-			order := append([]string(nil), e.PluralOrder...)
+			order := Quantities(lang)
 			if !e.Translatable {
+				order = append([]string(nil), e.PluralOrder...)
 				for q, v := range e.Plurals {
 					plurals[q] = v
 				}
@@ -757,15 +820,19 @@ func NewTranslationFile(source *File, _ string) *File {
 
 // SyncKeys ensures the translation file has all translatable keys from source.
 // Missing keys are added with empty values (preserving kind and structure).
+// Existing string arrays are resized to the length of their source array,
+// and existing plurals get the quantities of lang: missing ones are added
+// empty and those the language does not use are dropped.
 // Non-translatable entries and comments are not synced.
 // Returns the number of keys added.
-func (f *File) SyncKeys(source *File) int {
+func (f *File) SyncKeys(source *File, lang string) int {
 	added := 0
 	for _, e := range source.Entries {
 		if !e.IsTranslatable() {
 			continue
 		}
-		if _, exists := f.byName[e.Name]; exists {
+		if idx, exists := f.byName[e.Name]; exists {
+			f.Entries[idx].syncStructure(e, lang)
 			continue
 		}
 		var ne *Entry
@@ -775,8 +842,7 @@ func (f *File) SyncKeys(source *File) int {
 		case KindStringArray:
 			ne = &Entry{Kind: KindStringArray, Name: e.Name, Translatable: true, Items: make([]string, len(e.Items))}
 		case KindPlurals:
-			order := append([]string(nil), e.PluralOrder...)
-			ne = &Entry{Kind: KindPlurals, Name: e.Name, Translatable: true, Plurals: make(map[string]string), PluralOrder: order}
+			ne = &Entry{Kind: KindPlurals, Name: e.Name, Translatable: true, Plurals: make(map[string]string), PluralOrder: Quantities(lang)}
 		default:
 			continue
 		}
@@ -788,6 +854,44 @@ func (f *File) SyncKeys(source *File) int {
 	return added
 }
 
+// syncStructure fits a translated resource to its source resource src: a
+// string array gets as many items as the source array, plurals get the
+// quantities of lang.
+func (e *Entry) syncStructure(src *Entry, lang string) {
+	if e.Kind != src.Kind || !e.Translatable {
+		return
+	}
+	switch e.Kind {
+	case KindStringArray:
+		n := len(src.Items)
+		if len(e.Items) > n {
+			e.Items = e.Items[:n]
+		}
+		for len(e.Items) < n {
+			e.Items = append(e.Items, "")
+		}
+		if len(e.ItemCDATA) > n {
+			e.ItemCDATA = e.ItemCDATA[:n]
+		}
+	case KindPlurals:
+		order := Quantities(lang)
+		used := make(map[string]bool, len(order))
+		for _, q := range order {
+			used[q] = true
+		}
+		for q := range e.Plurals {
+			if !used[q] {
+				delete(e.Plurals, q)
+				delete(e.PluralCDATA, q)
+			}
+		}
+		if e.Plurals == nil {
+			e.Plurals = make(map[string]string)
+		}
+		e.PluralOrder = order
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Language detection from res/ directory
 // ---------------------------------------------------------------------------
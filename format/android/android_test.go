@@ -2,6 +2,7 @@
 package android
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -170,6 +171,52 @@ func TestParse_Comment(t *testing.T) {
 	}
 }
 
+func TestNotes_PrecedingComment(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Verb: button that opens a file -->
+    <string name="open">Open</string>
+    <string name="close">Close</string>
+    <!-- Shown in the toolbar -->
+    <plurals name="files">
+        <item quantity="one">%d file</item>
+        <item quantity="other">%d files</item>
+    </plurals>
+</resources>`
+
+	f, err := Parse([]byte(xml))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	notes := f.Notes()
+	if notes["open"] != "Verb: button that opens a file" {
+		t.Errorf("notes[open] = %q", notes["open"])
+	}
+	if _, ok := notes["close"]; ok {
+		t.Errorf("close should have no note, got %q", notes["close"])
+	}
+	if notes["files"] != "Shown in the toolbar" {
+		t.Errorf("notes[files] = %q", notes["files"])
+	}
+}
+
+func TestMaxLengths(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools">
+    <string name="ok" tools:maxLength="12">OK</string>
+    <string name="title">Title</string>
+</resources>`
+
+	f, err := Parse([]byte(xml))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	limits := f.MaxLengths()
+	if len(limits) != 1 || limits["ok"] != 12 {
+		t.Errorf("MaxLengths() = %v", limits)
+	}
+}
+
 func TestParse_StringArrayTranslatableFalse(t *testing.T) {
 	xml := `<?xml version="1.0" encoding="utf-8"?>
 <resources>
@@ -571,7 +618,7 @@ func TestSyncKeys_AddsNewKeys(t *testing.T) {
 	src, _ := Parse([]byte(source))
 	tgt, _ := Parse([]byte(target))
 
-	added := tgt.SyncKeys(src)
+	added := tgt.SyncKeys(src, "ru")
 	if added != 2 { // arr + p (skip excluded)
 		t.Errorf("added %d, want 2", added)
 	}
@@ -582,9 +629,9 @@ func TestSyncKeys_AddsNewKeys(t *testing.T) {
 		t.Errorf("arr not synced correctly: %+v", ea)
 	}
 
-	// p should exist with correct plural order
+	// p should exist with the Russian quantities
 	ep := tgt.GetEntry("p")
-	if ep == nil || ep.Kind != KindPlurals || len(ep.PluralOrder) != 1 {
+	if ep == nil || ep.Kind != KindPlurals || !reflect.DeepEqual(ep.PluralOrder, []string{"one", "few", "many", "other"}) {
 		t.Errorf("p not synced correctly: %+v", ep)
 	}
 
@@ -594,6 +641,61 @@ func TestSyncKeys_AddsNewKeys(t *testing.T) {
 	}
 }
 
+func TestSyncKeys_FitsExistingResources(t *testing.T) {
+	source := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string-array name="arr">
+        <item>X</item>
+        <item>Y</item>
+    </string-array>
+    <plurals name="p">
+        <item quantity="one">%d item</item>
+        <item quantity="other">%d items</item>
+    </plurals>
+</resources>`
+
+	target := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string-array name="arr">
+        <item>Икс</item>
+    </string-array>
+    <plurals name="p">
+        <item quantity="zero">нет элементов</item>
+        <item quantity="one">%d элемент</item>
+        <item quantity="other">%d элемента</item>
+    </plurals>
+</resources>`
+
+	src, _ := Parse([]byte(source))
+	tgt, _ := Parse([]byte(target))
+	if added := tgt.SyncKeys(src, "ru"); added != 0 {
+		t.Errorf("added %d, want 0", added)
+	}
+
+	ea := tgt.GetEntry("arr")
+	if !reflect.DeepEqual(ea.Items, []string{"Икс", ""}) || ea.IsTranslated() {
+		t.Errorf("arr = %q", ea.Items)
+	}
+
+	// zero is not a Russian quantity; few and many are missing.
+	ep := tgt.GetEntry("p")
+	if !reflect.DeepEqual(ep.PluralOrder, []string{"one", "few", "many", "other"}) {
+		t.Errorf("PluralOrder = %q", ep.PluralOrder)
+	}
+	if _, ok := ep.Plurals["zero"]; ok || ep.Plurals["one"] != "%d элемент" {
+		t.Errorf("Plurals = %q", ep.Plurals)
+	}
+	if ep.IsTranslated() {
+		t.Error("plurals without few and many reported as translated")
+	}
+	if _, translated, _ := tgt.Stats(); translated != 0 {
+		t.Errorf("translated = %d, want 0", translated)
+	}
+	if out := string(tgt.MarshalTarget()); strings.Contains(out, "zero") || !strings.Contains(out, `<item quantity="many"></item>`) {
+		t.Errorf("MarshalTarget() =\n%s", out)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // NewTranslationFile tests
 // ---------------------------------------------------------------------------
@@ -607,6 +709,10 @@ func TestNewTranslationFile(t *testing.T) {
         <item>Red</item>
         <item>Blue</item>
     </string-array>
+    <plurals name="songs">
+        <item quantity="one">%d song</item>
+        <item quantity="other">%d songs</item>
+    </plurals>
 </resources>`
 
 	src, _ := Parse([]byte(source))
@@ -634,4 +740,46 @@ func TestNewTranslationFile(t *testing.T) {
 			t.Errorf("colors[%d] should be empty, got %q", i, item)
 		}
 	}
+
+	// plurals should get the Russian quantities, not the English ones
+	ep := tgt.GetEntry("songs")
+	if ep == nil || !reflect.DeepEqual(ep.PluralOrder, []string{"one", "few", "many", "other"}) {
+		t.Errorf("songs plural order = %+v", ep)
+	}
+	if ja := NewTranslationFile(src, "ja").GetEntry("songs"); !reflect.DeepEqual(ja.PluralOrder, []string{"other"}) {
+		t.Errorf("ja plural order = %q", ja.PluralOrder)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Apostrophe lint tests
+// ---------------------------------------------------------------------------
+
+func TestInvalidApostrophes(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="escaped">Don\'t stop</string>
+    <string name="quoted">"Don't stop"</string>
+    <string name="bad">Don't stop</string>
+    <string name="bad_entity">Don&apos;t stop</string>
+    <string name="fixed" translatable="false">Don't care</string>
+    <string name="nested"><b>It's</b> here</string>
+    <string-array name="days">
+        <item>Today</item>
+        <item>Tomorrow's</item>
+    </string-array>
+    <plurals name="files">
+        <item quantity="one">%d file</item>
+        <item quantity="other">%d file's</item>
+    </plurals>
+</resources>`
+
+	got, err := InvalidApostrophes([]byte(xml))
+	if err != nil {
+		t.Fatalf("InvalidApostrophes: %v", err)
+	}
+	want := []string{"bad", "bad_entity", "nested", "days[1]", "files#other"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("InvalidApostrophes = %v, want %v", got, want)
+	}
 }
@@ -0,0 +1,115 @@
+package android
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// InvalidApostrophes returns the resources in raw strings.xml data whose
+// text contains an apostrophe that is neither escaped (\') nor inside a
+// double-quoted string. aapt rejects such resources at build time; Parse
+// hides the problem because it unescapes apostrophes.
+//
+// Keys use the same unit naming as the translation pipeline:
+//
+//	string:            "name"
+//	string-array item: "name[0]", "name[1]", …
+//	plurals item:      "name#one", "name#other", …
+func InvalidApostrophes(data []byte) ([]string, error) {
+	dec := xml.NewDecoder(strings.NewReader(string(data)))
+	var (
+		invalid   []string
+		parent    string // enclosing string-array/plurals name
+		itemIndex int
+		key       string // unit currently being read, "" outside units
+		depth     int    // element depth inside the current unit
+		text      strings.Builder
+	)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return invalid, nil
+			}
+			return invalid, fmt.Errorf("parsing strings.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if key != "" {
+				depth++
+				continue
+			}
+			name, translatable := parseAttrs(t)
+			switch t.Name.Local {
+			case "string":
+				if translatable {
+					key, depth = name, 0
+					text.Reset()
+				}
+			case "string-array", "plurals":
+				if translatable {
+					parent, itemIndex = name, 0
+				}
+			case "item":
+				if parent == "" {
+					continue
+				}
+				key, depth = fmt.Sprintf("%s[%d]", parent, itemIndex), 0
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "quantity" {
+						key = parent + "#" + attr.Value
+					}
+				}
+				itemIndex++
+				text.Reset()
+			}
+		case xml.CharData:
+			if key != "" {
+				text.Write(t)
+			}
+		case xml.Directive:
+			s := string(t)
+			if key != "" && strings.HasPrefix(s, "[CDATA[") && strings.HasSuffix(s, "]]") {
+				text.WriteString(s[7 : len(s)-2])
+			}
+		case xml.EndElement:
+			if key != "" {
+				if depth > 0 {
+					depth--
+					continue
+				}
+				if hasUnescapedApostrophe(text.String()) {
+					invalid = append(invalid, key)
+				}
+				key = ""
+				continue
+			}
+			if t.Name.Local == "string-array" || t.Name.Local == "plurals" {
+				parent = ""
+			}
+		}
+	}
+}
+
+// hasUnescapedApostrophe reports whether s contains an apostrophe outside
+// double quotes that is not preceded by a backslash.
+func hasUnescapedApostrophe(s string) bool {
+	inQuotes := false
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '\'' && !inQuotes:
+			return true
+		}
+	}
+	return false
+}
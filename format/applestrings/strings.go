@@ -0,0 +1,695 @@
+// Package applestrings implements reading and writing of Apple localization
+// files: .strings tables and .stringsdict plural dictionaries.
+//
+// A .strings file holds "key" = "value"; pairs. Block (/* */) and line (//)
+// comments and blank lines are preserved, and the comment directly above an
+// entry is passed to translators as a note, as Xcode intends. Files are read
+// as UTF-8 or, with a byte order mark, UTF-16, and written back in the same
+// encoding.
+//
+// A .stringsdict file is an XML property list of localized format strings
+// whose %#@variable@ references are resolved by plural rules. Every plural
+// form is exposed as its own key, entry#variable#category (for example
+// "files#count#one"), so that the forms of one variable are translated
+// together; translation files get the CLDR categories of their language.
+// The localized format string itself is a key only when it contains text
+// besides its variable references.
+//
+// File naming convention: each language is stored in its own .lproj
+// directory:
+//
+//	en.lproj/Localizable.strings      (source)
+//	ru.lproj/Localizable.strings      (translation)
+//	ru.lproj/Localizable.stringsdict  (translated plurals)
+package applestrings
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/minios-linux/lokit/langmeta"
+)
+
+// ---------------------------------------------------------------------------
+// File model
+// ---------------------------------------------------------------------------
+
+// itemKind classifies the items of a file.
+type itemKind int
+
+const (
+	itemBlank   itemKind = iota // blank line between entries
+	itemComment                 // comment
+	itemString                  // .strings "key" = "value"; pair
+	itemPlural                  // .stringsdict entry
+)
+
+// item is a single item of a file in document order.
+type item struct {
+	kind itemKind
+	// comment is the comment text: the raw comment including its
+	// delimiters in .strings files, the comment content in .stringsdict
+	// files.
+	comment string
+	key     string
+	value   string  // itemString; may be replaced by Set
+	plural  *plural // itemPlural
+}
+
+// File represents a parsed .strings or .stringsdict file.
+type File struct {
+	items []*item
+	// index maps entry keys to their items.
+	index map[string]*item
+	// dict is true for .stringsdict files.
+	dict bool
+	// utf16 is the byte order of a .strings file read as UTF-16, nil for
+	// UTF-8.
+	utf16 binary.ByteOrder
+}
+
+func newFile(dict bool) *File {
+	return &File{index: make(map[string]*item), dict: dict}
+}
+
+// add appends it to f. A duplicate key replaces the value of the earlier
+// entry, like Foundation does, but keeps its position.
+func (f *File) add(it *item) {
+	if it.kind == itemString || it.kind == itemPlural {
+		if prev, ok := f.index[it.key]; ok {
+			prev.value, prev.plural = it.value, it.plural
+			return
+		}
+		f.index[it.key] = it
+	}
+	f.items = append(f.items, it)
+}
+
+// ---------------------------------------------------------------------------
+// Parsing
+// ---------------------------------------------------------------------------
+
+// ParseFile reads and parses a .strings or, by its extension, .stringsdict
+// file from disk.
+func ParseFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var f *File
+	if IsDictPath(path) {
+		f, err = ParseDict(data)
+	} else {
+		f, err = Parse(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// IsDictPath reports whether path names a .stringsdict file.
+func IsDictPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".stringsdict")
+}
+
+// Parse parses .strings content from a byte slice.
+func Parse(data []byte) (*File, error) {
+	f := newFile(false)
+	text, order, err := decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+	f.utf16 = order
+
+	p := &stringsParser{s: text}
+	for {
+		if p.skipSpace() >= 2 && len(f.items) > 0 {
+			f.items = append(f.items, &item{kind: itemBlank})
+		}
+		if p.eof() {
+			return f, nil
+		}
+		if p.peek("/*") || p.peek("//") {
+			c, err := p.comment()
+			if err != nil {
+				return nil, err
+			}
+			f.add(&item{kind: itemComment, comment: c})
+			continue
+		}
+		key, value, err := p.entry()
+		if err != nil {
+			return nil, err
+		}
+		f.add(&item{kind: itemString, key: key, value: value})
+	}
+}
+
+// decodeText returns data as a string. Data starting with a UTF-16 byte
+// order mark is decoded as UTF-16 and its byte order is returned; other
+// data must be UTF-8.
+func decodeText(data []byte) (string, binary.ByteOrder, error) {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+	default:
+		if !utf8.Valid(data) {
+			return "", nil, fmt.Errorf("file is neither UTF-8 nor UTF-16 with a byte order mark")
+		}
+		return strings.TrimPrefix(string(data), "\uFEFF"), nil, nil
+	}
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), order, nil
+}
+
+// stringsParser scans the content of a .strings file.
+type stringsParser struct {
+	s   string
+	pos int
+}
+
+func (p *stringsParser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *stringsParser) peek(prefix string) bool {
+	return strings.HasPrefix(p.s[p.pos:], prefix)
+}
+
+// line returns the line number of the current position.
+func (p *stringsParser) line() int {
+	return strings.Count(p.s[:p.pos], "\n") + 1
+}
+
+func (p *stringsParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line(), fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips whitespace and returns the number of newlines skipped.
+func (p *stringsParser) skipSpace() int {
+	n := 0
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case '\n':
+			n++
+		case ' ', '\t', '\r', '\f', '\v':
+		default:
+			return n
+		}
+		p.pos++
+	}
+	return n
+}
+
+// skipSpaceAndComments skips whitespace and comments inside an entry.
+func (p *stringsParser) skipSpaceAndComments() error {
+	for {
+		p.skipSpace()
+		if !p.peek("/*") && !p.peek("//") {
+			return nil
+		}
+		if _, err := p.comment(); err != nil {
+			return err
+		}
+	}
+}
+
+// comment reads a block or line comment, delimiters included.
+func (p *stringsParser) comment() (string, error) {
+	start := p.pos
+	if p.peek("//") {
+		end := strings.IndexByte(p.s[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.s) - p.pos
+		}
+		p.pos += end
+		return strings.TrimRight(p.s[start:p.pos], " \t\r"), nil
+	}
+	end := strings.Index(p.s[p.pos+2:], "*/")
+	if end < 0 {
+		return "", p.errorf("unterminated comment")
+	}
+	p.pos += 2 + end + 2
+	return p.s[start:p.pos], nil
+}
+
+// entry reads a "key" = "value"; pair.
+func (p *stringsParser) entry() (key, value string, err error) {
+	if key, err = p.token(); err != nil {
+		return "", "", err
+	}
+	if err := p.skipSpaceAndComments(); err != nil {
+		return "", "", err
+	}
+	if !p.peek("=") {
+		return "", "", p.errorf("expected '=' after key %q", key)
+	}
+	p.pos++
+	if err := p.skipSpaceAndComments(); err != nil {
+		return "", "", err
+	}
+	if value, err = p.token(); err != nil {
+		return "", "", err
+	}
+	if err := p.skipSpaceAndComments(); err != nil {
+		return "", "", err
+	}
+	if !p.peek(";") {
+		return "", "", p.errorf("expected ';' after value of %q", key)
+	}
+	p.pos++
+	return key, value, nil
+}
+
+// token reads a quoted string or an unquoted word.
+func (p *stringsParser) token() (string, error) {
+	if p.eof() {
+		return "", p.errorf("unexpected end of file")
+	}
+	if p.s[p.pos] == '"' {
+		return p.quoted()
+	}
+	start := p.pos
+	for !p.eof() && isWordChar(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("unexpected character %q", p.s[p.pos])
+	}
+	return p.s[start:p.pos], nil
+}
+
+// isWordChar reports whether c may appear in an unquoted string of an
+// old-style property list.
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("_$+/:.-", c) >= 0
+}
+
+// quoted reads a quoted string and decodes its escapes: \n, \t, \r, \",
+// \\, \UXXXX (including UTF-16 surrogate pairs) and octal \NNN.
+func (p *stringsParser) quoted() (string, error) {
+	line := p.line()
+	p.pos++ // opening quote
+	var b strings.Builder
+	var high rune // pending high surrogate
+	flush := func() {
+		if high != 0 {
+			b.WriteRune(utf8.RuneError)
+			high = 0
+		}
+	}
+	for !p.eof() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '"':
+			flush()
+			return b.String(), nil
+		case c != '\\':
+			flush()
+			b.WriteByte(c)
+			continue
+		}
+		if p.eof() {
+			break
+		}
+		c = p.s[p.pos]
+		p.pos++
+		switch c {
+		case 'n':
+			flush()
+			b.WriteByte('\n')
+		case 't':
+			flush()
+			b.WriteByte('\t')
+		case 'r':
+			flush()
+			b.WriteByte('\r')
+		case 'U', 'u':
+			r, ok := hexRune(p.s[p.pos:])
+			if !ok {
+				return "", p.errorf(`malformed \U escape`)
+			}
+			p.pos += 4
+			switch {
+			case utf16.IsSurrogate(r) && r < 0xDC00:
+				flush()
+				high = r
+			case utf16.IsSurrogate(r) && high != 0:
+				b.WriteRune(utf16.DecodeRune(high, r))
+				high = 0
+			default:
+				flush()
+				b.WriteRune(r)
+			}
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			flush()
+			r := rune(c - '0')
+			for i := 0; i < 2 && !p.eof() && p.s[p.pos] >= '0' && p.s[p.pos] <= '7'; i++ {
+				r = r<<3 | rune(p.s[p.pos]-'0')
+				p.pos++
+			}
+			b.WriteRune(r)
+		default:
+			flush()
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("line %d: unterminated string", line)
+}
+
+// hexRune decodes the four hex digits at the start of s.
+func hexRune(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range s[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			r = r<<4 | (c - '0')
+		case c >= 'a' && c <= 'f':
+			r = r<<4 | (c - 'a' + 10)
+		case c >= 'A' && c <= 'F':
+			r = r<<4 | (c - 'A' + 10)
+		default:
+			return 0, false
+		}
+	}
+	return r, true
+}
+
+// ---------------------------------------------------------------------------
+// Accessors
+// ---------------------------------------------------------------------------
+
+// Keys returns all translation keys in document order.
+func (f *File) Keys() []string {
+	var keys []string
+	for _, it := range f.items {
+		switch it.kind {
+		case itemString:
+			keys = append(keys, it.key)
+		case itemPlural:
+			keys = append(keys, it.plural.keys(it.key)...)
+		}
+	}
+	return keys
+}
+
+// UntranslatedKeys returns keys whose value is empty.
+func (f *File) UntranslatedKeys() []string {
+	var keys []string
+	for _, key := range f.Keys() {
+		if v, _ := f.Get(key); v == "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Get returns the value for key and whether it was found.
+func (f *File) Get(key string) (string, bool) {
+	if it, ok := f.index[key]; ok {
+		if it.kind == itemString {
+			return it.value, true
+		}
+		if it.plural.translatableFormat() {
+			return it.plural.format, true
+		}
+	}
+	if v, cat, ok := f.form(key); ok {
+		return v.forms[cat], true
+	}
+	return "", false
+}
+
+// Set sets the value for an existing key. Returns true on success,
+// false if the key does not exist.
+func (f *File) Set(key, value string) bool {
+	if it, ok := f.index[key]; ok {
+		if it.kind == itemString {
+			it.value = value
+			return true
+		}
+		if it.plural.translatableFormat() {
+			it.plural.format = value
+			return true
+		}
+	}
+	if v, cat, ok := f.form(key); ok {
+		v.forms[cat] = value
+		return true
+	}
+	return false
+}
+
+// Stats returns (total, translated, percentTranslated) for this file.
+func (f *File) Stats() (int, int, float64) {
+	keys := f.Keys()
+	total, translated := len(keys), 0
+	for _, key := range keys {
+		if v, _ := f.Get(key); v != "" {
+			translated++
+		}
+	}
+	pct := 0.0
+	if total > 0 {
+		pct = float64(translated) / float64(total) * 100
+	}
+	return total, translated, pct
+}
+
+// SourceValues returns a map of key → value for use as translation source.
+func (f *File) SourceValues() map[string]string {
+	keys := f.Keys()
+	m := make(map[string]string, len(keys))
+	for _, key := range keys {
+		m[key], _ = f.Get(key)
+	}
+	return m
+}
+
+// Notes returns the comments written directly above entries, keyed by the
+// keys of each entry. Xcode's "No comment provided by engineer." is left
+// out.
+func (f *File) Notes() map[string]string {
+	notes := make(map[string]string)
+	var pending []string
+	for _, it := range f.items {
+		switch it.kind {
+		case itemBlank:
+			pending = nil
+		case itemComment:
+			if text := f.commentText(it.comment); text != "" && text != "No comment provided by engineer." {
+				pending = append(pending, text)
+			}
+		case itemString, itemPlural:
+			if len(pending) > 0 {
+				keys := []string{it.key}
+				if it.kind == itemPlural {
+					keys = it.plural.keys(it.key)
+				}
+				for _, key := range keys {
+					notes[key] = strings.Join(pending, "\n")
+				}
+			}
+			pending = nil
+		}
+	}
+	return notes
+}
+
+// commentText returns a comment without its delimiters.
+func (f *File) commentText(c string) string {
+	if !f.dict {
+		if strings.HasPrefix(c, "//") {
+			c = c[2:]
+		} else {
+			c = strings.TrimSuffix(strings.TrimPrefix(c, "/*"), "*/")
+		}
+	}
+	return strings.TrimSpace(c)
+}
+
+// ---------------------------------------------------------------------------
+// Plural keys
+// ---------------------------------------------------------------------------
+
+// PluralKey reports the plural unit (entry#variable) and CLDR category of
+// a plural form key.
+func (f *File) PluralKey(key string) (unit, category string, ok bool) {
+	if _, _, ok := f.form(key); !ok {
+		return "", "", false
+	}
+	i := strings.LastIndexByte(key, '#')
+	return key[:i], key[i+1:], true
+}
+
+// PluralFormKey returns the key holding the given category of a plural unit.
+func (f *File) PluralFormKey(unit, category string) string {
+	return unit + "#" + category
+}
+
+// form returns the plural variable and category a form key refers to.
+func (f *File) form(key string) (*pluralVar, string, bool) {
+	i := strings.LastIndexByte(key, '#')
+	if i < 0 || !langmeta.IsPluralCategory(key[i+1:]) {
+		return nil, "", false
+	}
+	unit, cat := key[:i], key[i+1:]
+	j := strings.LastIndexByte(unit, '#')
+	if j < 0 {
+		return nil, "", false
+	}
+	it, ok := f.index[unit[:j]]
+	if !ok || it.kind != itemPlural {
+		return nil, "", false
+	}
+	v := it.plural.variable(unit[j+1:])
+	if v == nil {
+		return nil, "", false
+	}
+	if _, ok := v.forms[cat]; !ok {
+		return nil, "", false
+	}
+	return v, cat, true
+}
+
+// formatSpecifier matches the format specifiers of localized strings,
+// including %#@variable@ references.
+var formatSpecifier = regexp.MustCompile(`%(?:[1-9][0-9]*\$)?(?:#@[^@]*@|[-+ #0']*[0-9]*(?:\.[0-9]+)?(?:hh|h|ll|l|q|L|z|t|j)?[@%a-zA-Z])`)
+
+// hasText reports whether s contains letters outside its format
+// specifiers.
+func hasText(s string) bool {
+	return strings.IndexFunc(formatSpecifier.ReplaceAllString(s, ""), unicode.IsLetter) >= 0
+}
+
+// ---------------------------------------------------------------------------
+// Serialization
+// ---------------------------------------------------------------------------
+
+// Marshal serialises the file back to .strings or .stringsdict format.
+func (f *File) Marshal() ([]byte, error) {
+	if f.dict {
+		return f.marshalDict(), nil
+	}
+	var b strings.Builder
+	for _, it := range f.items {
+		switch it.kind {
+		case itemBlank:
+			b.WriteByte('\n')
+		case itemComment:
+			b.WriteString(it.comment)
+			b.WriteByte('\n')
+		case itemString:
+			fmt.Fprintf(&b, "\"%s\" = \"%s\";\n", escape(it.key), escape(it.value))
+		}
+	}
+	if f.utf16 == nil {
+		return []byte(b.String()), nil
+	}
+	units := utf16.Encode([]rune("\uFEFF" + b.String()))
+	data := make([]byte, 2*len(units))
+	for i, u := range units {
+		f.utf16.PutUint16(data[2*i:], u)
+	}
+	return data, nil
+}
+
+// escape encodes s for a quoted .strings string.
+func escape(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\U%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// WriteFile serialises and writes to path, creating parent directories
+// with 0755 permissions.
+func (f *File) WriteFile(path string) error {
+	data, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Sync (create target from source)
+// ---------------------------------------------------------------------------
+
+// NewTranslationFile creates an empty target File for lang mirroring src's
+// structure and comments with all values cleared. Plural variables get
+// the CLDR categories of lang. It is written in the encoding of src.
+func NewTranslationFile(src *File, lang string) *File {
+	f := newFile(src.dict)
+	f.utf16 = src.utf16
+	for _, it := range src.items {
+		cp := *it
+		switch it.kind {
+		case itemString:
+			cp.value = ""
+		case itemPlural:
+			cp.plural = it.plural.translation(lang)
+		}
+		f.add(&cp)
+	}
+	return f
+}
+
+// SyncKeys ensures target has the same keys as src, preserving existing
+// translations and adding empty entries for new keys. Keys removed from
+// src are also removed from target, and comments follow src.
+func SyncKeys(src, target *File, lang string) {
+	rebuilt := NewTranslationFile(src, lang)
+	for _, key := range target.Keys() {
+		if v, _ := target.Get(key); v != "" {
+			rebuilt.Set(key, v)
+		}
+	}
+	target.items = rebuilt.items
+	target.index = rebuilt.index
+	target.dict = rebuilt.dict
+}
@@ -0,0 +1,275 @@
+package applestrings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+const stringsSource = `/* Title of the main window */
+"window.title" = "Files";
+
+// Shown after saving
+"saved" = "Saved \"%@\"\nto %2$@";
+unquoted_key = "Value";
+"emoji" = "\UD83D\UDE00 \U00e9";
+`
+
+func TestParse_Strings(t *testing.T) {
+	f, err := Parse([]byte(stringsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Keys(), []string{"window.title", "saved", "unquoted_key", "emoji"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() = %q, want %q", got, want)
+	}
+	for key, want := range map[string]string{
+		"saved":        "Saved \"%@\"\nto %2$@",
+		"unquoted_key": "Value",
+		"emoji":        "😀 é",
+	} {
+		if got, _ := f.Get(key); got != want {
+			t.Errorf("Get(%q) = %q, want %q", key, got, want)
+		}
+	}
+	notes := f.Notes()
+	if notes["window.title"] != "Title of the main window" || notes["saved"] != "Shown after saving" || notes["emoji"] != "" {
+		t.Errorf("Notes() = %q", notes)
+	}
+}
+
+func TestParse_StringsErrors(t *testing.T) {
+	for _, data := range []string{
+		`"a" "b";`,
+		`"a" = "b"`,
+		`"a" = "b`,
+		`/* open`,
+		`"a" = "\Uzz";`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", data)
+		}
+	}
+	_, err := Parse([]byte("\"a\" = \"b\";\n\"c\" = \"d\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("error = %v, want line 3", err)
+	}
+}
+
+func TestTranslationFile_Strings(t *testing.T) {
+	src, err := Parse([]byte(stringsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTranslationFile(src, "de")
+	if got := f.UntranslatedKeys(); len(got) != 4 {
+		t.Fatalf("UntranslatedKeys() = %q", got)
+	}
+	f.Set("window.title", "Dateien")
+	f.Set("saved", "„%@“ gespeichert\nin %2$@")
+
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `/* Title of the main window */
+"window.title" = "Dateien";
+
+// Shown after saving
+"saved" = "„%@“ gespeichert\nin %2$@";
+"unquoted_key" = "";
+"emoji" = "";
+`
+	if string(data) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, want)
+	}
+
+	back, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total, translated, _ := back.Stats(); total != 4 || translated != 2 {
+		t.Errorf("Stats() = %d, %d; want 4, 2", total, translated)
+	}
+}
+
+func TestParse_UTF16(t *testing.T) {
+	units := utf16.Encode([]rune("\uFEFF\"greeting\" = \"Grüß dich\";\n"))
+	data := make([]byte, 2*len(units))
+	for i, u := range units {
+		data[2*i], data[2*i+1] = byte(u), byte(u>>8)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := f.Get("greeting"); got != "Grüß dich" {
+		t.Fatalf("greeting = %q", got)
+	}
+	out, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(data) {
+		t.Errorf("Marshal() = % x, want UTF-16LE % x", out, data)
+	}
+}
+
+func TestSyncKeys_Strings(t *testing.T) {
+	src, err := Parse([]byte("\"a\" = \"A\";\n\"b\" = \"B\";\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := Parse([]byte("\"b\" = \"Б\";\n\"old\" = \"x\";\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SyncKeys(src, target, "ru")
+	if got, want := target.Keys(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() = %q, want %q", got, want)
+	}
+	if got, _ := target.Get("b"); got != "Б" {
+		t.Errorf("b = %q", got)
+	}
+}
+
+const dictSource = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<!-- Number of files in a folder -->
+	<key>files</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@count@</string>
+		<key>count</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>one</key>
+			<string>%d file</string>
+			<key>other</key>
+			<string>%d files</string>
+		</dict>
+	</dict>
+	<key>selected</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@n@ selected</string>
+		<key>n</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>lld</string>
+			<key>zero</key>
+			<string>Nothing</string>
+			<key>one</key>
+			<string>One &amp; only</string>
+			<key>other</key>
+			<string>%lld items</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+func TestParseDict(t *testing.T) {
+	f, err := ParseDict([]byte(dictSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"files#count#one", "files#count#other", "selected", "selected#n#zero", "selected#n#one", "selected#n#other"}
+	if got := f.Keys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() = %q, want %q", got, want)
+	}
+	if got, _ := f.Get("selected#n#one"); got != "One & only" {
+		t.Errorf("selected#n#one = %q", got)
+	}
+	if got, _ := f.Get("selected"); got != "%#@n@ selected" {
+		t.Errorf("selected = %q", got)
+	}
+	if unit, cat, ok := f.PluralKey("files#count#other"); !ok || unit != "files#count" || cat != "other" {
+		t.Errorf("PluralKey() = %q, %q, %v", unit, cat, ok)
+	}
+	if _, _, ok := f.PluralKey("selected"); ok {
+		t.Error("format key reported as plural")
+	}
+	if got := f.Notes()["files#count#one"]; got != "Number of files in a folder" {
+		t.Errorf("note = %q", got)
+	}
+
+	// Round trip.
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != dictSource {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, dictSource)
+	}
+}
+
+func TestTranslationFile_Dict(t *testing.T) {
+	src, err := ParseDict([]byte(dictSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTranslationFile(src, "ru")
+	want := []string{
+		"files#count#one", "files#count#few", "files#count#many", "files#count#other",
+		"selected", "selected#n#zero", "selected#n#one", "selected#n#few", "selected#n#many", "selected#n#other",
+	}
+	if got := f.UntranslatedKeys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("UntranslatedKeys() = %q, want %q", got, want)
+	}
+	if !f.Set("files#count#few", "%d файла") || f.Set("files#count#two", "x") {
+		t.Fatal("Set() accepted the wrong categories")
+	}
+	f.Set("selected", "Выбрано: %#@n@")
+
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"<string>%#@count@</string>",
+		"<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>lld</string>",
+		"<key>few</key>\n\t\t\t<string>%d файла</string>",
+		"<string>Выбрано: %#@n@</string>",
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("Marshal() lacks %q:\n%s", s, data)
+		}
+	}
+
+	// Translations survive a sync after the source changed.
+	back, err := ParseDict(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Set("files#count#other", "%d documents")
+	SyncKeys(src, back, "ru")
+	if got, _ := back.Get("files#count#few"); got != "%d файла" {
+		t.Errorf("few after sync = %q", got)
+	}
+	if total, translated, _ := back.Stats(); total != 10 || translated != 2 {
+		t.Errorf("Stats() = %d, %d; want 10, 2", total, translated)
+	}
+}
+
+func TestParseDict_Errors(t *testing.T) {
+	for _, data := range []string{
+		`<plist version="1.0"></plist>`,
+		`<plist><dict><key>a</key><integer>1</integer></dict></plist>`,
+		`<plist><dict><key>a</key><string>x</string></dict></plist>`,
+		`<plist><dict><key>a</key></dict></plist>`,
+		`<plist><dict><key>a</key><dict>`,
+	} {
+		if _, err := ParseDict([]byte(data)); err == nil {
+			t.Errorf("ParseDict(%q) succeeded, want error", data)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package applestrings
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minios-linux/lokit/langmeta"
+)
+
+// Property list keys of .stringsdict files.
+const (
+	formatKey      = "NSStringLocalizedFormatKey"
+	specTypeKey    = "NSStringFormatSpecTypeKey"
+	pluralRuleType = "NSStringPluralRuleType"
+)
+
+// plural is one entry of a .stringsdict file: a localized format string
+// and the variables it references.
+type plural struct {
+	// format is the NSStringLocalizedFormatKey value.
+	format string
+	// meta holds the other string values of the entry in document order.
+	meta [][2]string
+	vars []*pluralVar
+}
+
+// pluralVar is a variable of a plural entry.
+type pluralVar struct {
+	name string
+	// meta holds the string values that are not plural forms, such as
+	// NSStringFormatSpecTypeKey and NSStringFormatValueTypeKey.
+	meta [][2]string
+	// forms maps CLDR categories to text; nil for variables that do not
+	// use NSStringPluralRuleType.
+	forms map[string]string
+}
+
+// translatableFormat reports whether the format string is translated: it
+// has text besides its variable references, or is empty in a translation
+// file.
+func (p *plural) translatableFormat() bool {
+	return p.format == "" || hasText(p.format)
+}
+
+// keys returns the translation keys of the entry named key.
+func (p *plural) keys(key string) []string {
+	var keys []string
+	if p.translatableFormat() {
+		keys = append(keys, key)
+	}
+	for _, v := range p.vars {
+		for _, cat := range v.categories() {
+			keys = append(keys, key+"#"+v.name+"#"+cat)
+		}
+	}
+	return keys
+}
+
+func (p *plural) variable(name string) *pluralVar {
+	for _, v := range p.vars {
+		if v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// translation returns an empty copy of p for lang. A format string
+// without text is copied as is.
+func (p *plural) translation(lang string) *plural {
+	cp := &plural{meta: append([][2]string(nil), p.meta...)}
+	if !p.translatableFormat() {
+		cp.format = p.format
+	}
+	for _, v := range p.vars {
+		nv := &pluralVar{name: v.name, meta: append([][2]string(nil), v.meta...)}
+		if v.forms != nil {
+			nv.forms = make(map[string]string)
+			for _, cat := range langmeta.PluralCategories(lang) {
+				nv.forms[cat] = ""
+			}
+			// "zero" is an explicit case for a count of 0 in every
+			// language; keep it when the source uses it.
+			if _, ok := v.forms[langmeta.PluralZero]; ok {
+				nv.forms[langmeta.PluralZero] = ""
+			}
+		}
+		cp.vars = append(cp.vars, nv)
+	}
+	return cp
+}
+
+// categories returns the plural categories of v in CLDR order.
+func (v *pluralVar) categories() []string {
+	var cats []string
+	for _, cat := range langmeta.PluralCategoryOrder {
+		if _, ok := v.forms[cat]; ok {
+			cats = append(cats, cat)
+		}
+	}
+	return cats
+}
+
+// ---------------------------------------------------------------------------
+// Parsing
+// ---------------------------------------------------------------------------
+
+// plistValue is a key of a property list dictionary and its string or
+// dictionary value.
+type plistValue struct {
+	key string
+	// comment holds the comments written before the key.
+	comment string
+	str     string
+	dict    []plistValue
+	isDict  bool
+}
+
+// ParseDict parses .stringsdict content from a byte slice. Comments before
+// top-level entries are kept; comments inside entries are dropped.
+func ParseDict(data []byte) (*File, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, errors.New("no root <dict> element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "dict" {
+			root, err := readDict(dec)
+			if err != nil {
+				return nil, err
+			}
+			return newDictFile(root)
+		}
+	}
+}
+
+// readDict reads the content of a <dict> element up to its end.
+func readDict(dec *xml.Decoder) ([]plistValue, error) {
+	var values []plistValue
+	var key string
+	var comments []string
+	hasKey := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.Comment:
+			comments = append(comments, strings.TrimSpace(string(t)))
+		case xml.EndElement:
+			if hasKey {
+				return nil, fmt.Errorf("key %q has no value", key)
+			}
+			return values, nil
+		case xml.StartElement:
+			if t.Name.Local == "key" {
+				if hasKey {
+					return nil, fmt.Errorf("key %q has no value", key)
+				}
+				if err := dec.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				hasKey = true
+				continue
+			}
+			if !hasKey {
+				return nil, fmt.Errorf("<%s> without a key", t.Name.Local)
+			}
+			v := plistValue{key: key, comment: strings.Join(comments, "\n")}
+			switch t.Name.Local {
+			case "string":
+				if err := dec.DecodeElement(&v.str, &t); err != nil {
+					return nil, err
+				}
+			case "dict":
+				if v.dict, err = readDict(dec); err != nil {
+					return nil, err
+				}
+				v.isDict = true
+			default:
+				return nil, fmt.Errorf("key %q: unsupported property list value <%s>", key, t.Name.Local)
+			}
+			values = append(values, v)
+			key, comments, hasKey = "", nil, false
+		}
+	}
+}
+
+// newDictFile builds a .stringsdict File from its root dictionary.
+func newDictFile(root []plistValue) (*File, error) {
+	f := newFile(true)
+	for _, e := range root {
+		if e.comment != "" {
+			f.add(&item{kind: itemComment, comment: e.comment})
+		}
+		if !e.isDict {
+			return nil, fmt.Errorf("entry %q is not a dictionary", e.key)
+		}
+		p := &plural{}
+		for _, c := range e.dict {
+			switch {
+			case c.key == formatKey && !c.isDict:
+				p.format = c.str
+			case c.isDict:
+				v, err := newPluralVar(c)
+				if err != nil {
+					return nil, fmt.Errorf("entry %q: %w", e.key, err)
+				}
+				p.vars = append(p.vars, v)
+			default:
+				p.meta = append(p.meta, [2]string{c.key, c.str})
+			}
+		}
+		f.add(&item{kind: itemPlural, key: e.key, plural: p})
+	}
+	return f, nil
+}
+
+func newPluralVar(d plistValue) (*pluralVar, error) {
+	v := &pluralVar{name: d.key}
+	isPlural := false
+	for _, c := range d.dict {
+		if c.key == specTypeKey && c.str == pluralRuleType {
+			isPlural = true
+		}
+	}
+	if isPlural {
+		v.forms = make(map[string]string)
+	}
+	for _, c := range d.dict {
+		switch {
+		case c.isDict:
+			return nil, fmt.Errorf("variable %q: unexpected dictionary %q", d.key, c.key)
+		case isPlural && langmeta.IsPluralCategory(c.key):
+			v.forms[c.key] = c.str
+		default:
+			v.meta = append(v.meta, [2]string{c.key, c.str})
+		}
+	}
+	return v, nil
+}
+
+// ---------------------------------------------------------------------------
+// Serialization
+// ---------------------------------------------------------------------------
+
+var plistEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// marshalDict writes a .stringsdict property list laid out like Xcode does.
+func (f *File) marshalDict() []byte {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	str := func(indent int, key, value string) {
+		tabs := strings.Repeat("\t", indent)
+		fmt.Fprintf(&b, "%s<key>%s</key>\n%s<string>%s</string>\n", tabs, plistEscaper.Replace(key), tabs, plistEscaper.Replace(value))
+	}
+	for _, it := range f.items {
+		switch it.kind {
+		case itemComment:
+			fmt.Fprintf(&b, "\t<!-- %s -->\n", strings.ReplaceAll(it.comment, "--", "- -"))
+		case itemPlural:
+			p := it.plural
+			fmt.Fprintf(&b, "\t<key>%s</key>\n\t<dict>\n", plistEscaper.Replace(it.key))
+			str(2, formatKey, p.format)
+			for _, m := range p.meta {
+				str(2, m[0], m[1])
+			}
+			for _, v := range p.vars {
+				fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<dict>\n", plistEscaper.Replace(v.name))
+				for _, m := range v.meta {
+					str(3, m[0], m[1])
+				}
+				for _, cat := range v.categories() {
+					str(3, cat, v.forms[cat])
+				}
+				b.WriteString("\t\t</dict>\n")
+			}
+			b.WriteString("\t</dict>\n")
+		}
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return []byte(b.String())
+}
@@ -6,7 +6,9 @@
 //   - "@@locale" holds the BCP-47 language code (e.g. "en", "ru").
 //   - Keys starting with "@" (other than "@@locale") are metadata entries
 //     (e.g. "@greeting") and are preserved verbatim — never translated.
-//   - All other string values are translatable.
+//   - All other string values are translatable. Values may be ICU
+//     MessageFormat messages ({count, plural, one{...} other{...}}); see
+//     ParseICU.
 //
 // File naming convention: app_LANG.arb (e.g. app_en.arb, app_ru.arb) stored
 // in a single directory (e.g. lib/l10n/).
@@ -21,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -158,6 +161,65 @@ func (f *File) Get(key string) (string, bool) {
 	return "", false
 }
 
+// Placeholders returns the placeholder names declared in the "@key"
+// metadata entry, sorted.
+func (f *File) Placeholders(key string) []string {
+	idx, ok := f.index["@"+key]
+	if !ok {
+		return nil
+	}
+	var meta struct {
+		Placeholders map[string]json.RawMessage `json:"placeholders"`
+	}
+	if err := json.Unmarshal(f.entries[idx].rawValue, &meta); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(meta.Placeholders))
+	for name := range meta.Placeholders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Notes returns the "description" of each key's "@key" metadata entry,
+// keyed by translatable key.
+func (f *File) Notes() map[string]string {
+	notes := make(map[string]string)
+	for _, e := range f.entries {
+		if !e.isMeta || e.key == "@@locale" {
+			continue
+		}
+		var meta struct {
+			Description string `json:"description"`
+		}
+		if err := json.Unmarshal(e.rawValue, &meta); err != nil || meta.Description == "" {
+			continue
+		}
+		notes[strings.TrimPrefix(e.key, "@")] = meta.Description
+	}
+	return notes
+}
+
+// MaxLengths returns the "maxLength" of each key's "@key" metadata entry,
+// keyed by translatable key.
+func (f *File) MaxLengths() map[string]int {
+	limits := make(map[string]int)
+	for _, e := range f.entries {
+		if !e.isMeta || e.key == "@@locale" {
+			continue
+		}
+		var meta struct {
+			MaxLength int `json:"maxLength"`
+		}
+		if err := json.Unmarshal(e.rawValue, &meta); err != nil || meta.MaxLength <= 0 {
+			continue
+		}
+		limits[strings.TrimPrefix(e.key, "@")] = meta.MaxLength
+	}
+	return limits
+}
+
 // Set sets the value of an existing translatable key.
 // Returns true on success, false if the key is not found or is metadata.
 func (f *File) Set(key, value string) bool {
@@ -159,3 +159,102 @@ func TestSyncKeys_RemovesObsoleteKeys(t *testing.T) {
 		t.Error("obsolete key should have been removed")
 	}
 }
+
+func TestParseICU_PluralAndSelect(t *testing.T) {
+	msg, err := ParseICU("{count, plural, =0{No files} one{# file} other{{count} files by {user}}} {gender, select, male{he} female{she} other{they}} '{literal}'")
+	if err != nil {
+		t.Fatalf("ParseICU error: %v", err)
+	}
+	if got := strings.Join(msg.Arguments(), ","); got != "count,gender,user" {
+		t.Fatalf("Arguments() = %s", got)
+	}
+	sels := msg.Selectors()
+	if len(sels) != 2 || sels[0].Kind != ICUPlural || sels[1].Kind != ICUSelect {
+		t.Fatalf("unexpected selectors: %#v", sels)
+	}
+	var selectors []string
+	for _, b := range sels[0].Branches {
+		selectors = append(selectors, b.Selector)
+	}
+	if got := strings.Join(selectors, ","); got != "=0,one,other" {
+		t.Fatalf("plural selectors = %s", got)
+	}
+	last := msg.Parts[len(msg.Parts)-1]
+	if last.Kind != ICUText || last.Text != " {literal}" {
+		t.Fatalf("quoted literal not preserved: %#v", last)
+	}
+}
+
+func TestParseICU_Errors(t *testing.T) {
+	for _, s := range []string{
+		"{count, plural, one{# file}}",
+		"{count, plural, one{# file} other{# files}",
+		"{count, Mehrzahl, other{#}}}",
+		"Hello }",
+		"{, select, other{x}}",
+	} {
+		if _, err := ParseICU(s); err == nil {
+			t.Errorf("ParseICU(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestPlaceholders_FromMetadata(t *testing.T) {
+	f, err := Parse([]byte(`{
+  "files": "{count} files in {dir}",
+  "@files": {"placeholders": {"dir": {"type": "String"}, "count": {"type": "int"}}}
+}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if got := strings.Join(f.Placeholders("files"), ","); got != "count,dir" {
+		t.Fatalf("Placeholders() = %s", got)
+	}
+	if got := f.Placeholders("missing"); len(got) != 0 {
+		t.Fatalf("Placeholders(missing) = %v", got)
+	}
+}
+
+func TestNotes_FromMetadataDescription(t *testing.T) {
+	f, err := Parse([]byte(sampleARB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := f.Notes()
+	if notes["greeting"] != "A greeting message" {
+		t.Errorf("notes[greeting] = %q", notes["greeting"])
+	}
+	if _, ok := notes["farewell"]; ok {
+		t.Error("farewell has no description")
+	}
+}
+
+func TestMaxLengths_FromMetadata(t *testing.T) {
+	f, err := Parse([]byte(`{
+  "save": "Save",
+  "@save": {"description": "Button", "maxLength": 10},
+  "title": "Title"
+}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	limits := f.MaxLengths()
+	if len(limits) != 1 || limits["save"] != 10 {
+		t.Fatalf("MaxLengths() = %v", limits)
+	}
+}
+
+func TestICUMessage_StringRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"{count, plural, offset:1 =0{No files} one{# file} other{# files in {dir}}}",
+		"{gender, select, male{He''s '{'here'}'} other{They}} {n, number, currency}",
+	} {
+		msg, err := ParseICU(s)
+		if err != nil {
+			t.Fatalf("ParseICU(%q) error: %v", s, err)
+		}
+		if got := msg.String(); got != s {
+			t.Fatalf("String() = %q, want %q", got, s)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package arb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// ICU MessageFormat
+// ---------------------------------------------------------------------------
+
+// ICUKind identifies the kind of a message part.
+type ICUKind int
+
+const (
+	// ICUText is literal text.
+	ICUText ICUKind = iota
+	// ICUArgument is a simple argument such as {name} or {n, number}.
+	ICUArgument
+	// ICUPlural is a {n, plural, ...} argument.
+	ICUPlural
+	// ICUSelectOrdinal is a {n, selectordinal, ...} argument.
+	ICUSelectOrdinal
+	// ICUSelect is a {x, select, ...} argument.
+	ICUSelect
+	// ICUPound is "#" inside a plural branch (the formatted number).
+	ICUPound
+)
+
+// ICUBranch is one selector and its message in a plural or select argument.
+type ICUBranch struct {
+	Selector string
+	Message  *ICUMessage
+}
+
+// ICUPart is one element of a parsed message.
+type ICUPart struct {
+	Kind ICUKind
+	// Text holds literal text for ICUText parts.
+	Text string
+	// Arg is the argument name.
+	Arg string
+	// Format is the type of a simple argument ("number", "date", ...),
+	// optionally followed by its style.
+	Format string
+	// Offset is the plural offset ("offset:1"), if any.
+	Offset   string
+	Branches []ICUBranch
+}
+
+// ICUMessage is a parsed ICU MessageFormat message.
+type ICUMessage struct {
+	Parts []ICUPart
+}
+
+// ParseICU parses an ICU MessageFormat message as used in ARB values.
+// Apostrophe quoting follows ICU: a doubled apostrophe is a literal
+// apostrophe and an apostrophe before a syntax character starts a quoted
+// literal.
+func ParseICU(s string) (*ICUMessage, error) {
+	p := &icuParser{src: []rune(s)}
+	msg, err := p.message(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("unmatched '}' at offset %d", p.pos)
+	}
+	return msg, nil
+}
+
+// Arguments returns the sorted, unique argument names used in the message,
+// including nested branches.
+func (m *ICUMessage) Arguments() []string {
+	seen := make(map[string]bool)
+	m.walk(func(p ICUPart) {
+		if p.Kind != ICUText && p.Kind != ICUPound {
+			seen[p.Arg] = true
+		}
+	})
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Selectors returns the plural, selectordinal and select arguments of the
+// message, including nested ones, in document order.
+func (m *ICUMessage) Selectors() []ICUPart {
+	var out []ICUPart
+	m.walk(func(p ICUPart) {
+		if p.Kind == ICUPlural || p.Kind == ICUSelectOrdinal || p.Kind == ICUSelect {
+			out = append(out, p)
+		}
+	})
+	return out
+}
+
+func (m *ICUMessage) walk(fn func(ICUPart)) {
+	for _, p := range m.Parts {
+		fn(p)
+		for _, b := range p.Branches {
+			b.Message.walk(fn)
+		}
+	}
+}
+
+type icuParser struct {
+	src []rune
+	pos int
+}
+
+func (p *icuParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *icuParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// message parses text and arguments up to an unmatched '}' or the end of
+// input. inPlural enables '#' as the number placeholder.
+func (p *icuParser) message(inPlural bool) (*ICUMessage, error) {
+	msg := &ICUMessage{}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			msg.Parts = append(msg.Parts, ICUPart{Kind: ICUText, Text: text.String()})
+			text.Reset()
+		}
+	}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\'':
+			p.quoted(&text, inPlural)
+		case c == '{':
+			flush()
+			part, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			msg.Parts = append(msg.Parts, part)
+		case c == '}':
+			flush()
+			return msg, nil
+		case c == '#' && inPlural:
+			flush()
+			msg.Parts = append(msg.Parts, ICUPart{Kind: ICUPound})
+			p.pos++
+		default:
+			text.WriteRune(c)
+			p.pos++
+		}
+	}
+	flush()
+	return msg, nil
+}
+
+func (p *icuParser) quoted(text *strings.Builder, inPlural bool) {
+	p.pos++ // opening apostrophe
+	next := p.peek()
+	if next == '\'' {
+		text.WriteRune('\'')
+		p.pos++
+		return
+	}
+	if next != '{' && next != '}' && !(next == '#' && inPlural) {
+		text.WriteRune('\'')
+		return
+	}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		if c != '\'' {
+			text.WriteRune(c)
+			continue
+		}
+		if p.peek() == '\'' {
+			text.WriteRune('\'')
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func (p *icuParser) word() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if strings.ContainsRune(" \t\r\n{},", c) {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *icuParser) argument() (ICUPart, error) {
+	start := p.pos
+	p.pos++ // '{'
+	p.skipSpace()
+	name := p.word()
+	if name == "" {
+		return ICUPart{}, fmt.Errorf("missing argument name at offset %d", start)
+	}
+	p.skipSpace()
+	switch p.peek() {
+	case '}':
+		p.pos++
+		return ICUPart{Kind: ICUArgument, Arg: name}, nil
+	case ',':
+		p.pos++
+	default:
+		return ICUPart{}, fmt.Errorf("unterminated argument {%s at offset %d", name, start)
+	}
+
+	p.skipSpace()
+	typ := p.word()
+	p.skipSpace()
+	switch typ {
+	case "plural", "selectordinal", "select":
+	case "":
+		return ICUPart{}, fmt.Errorf("missing type for argument %q", name)
+	default:
+		return p.simpleArgument(name, typ, start)
+	}
+
+	kind := map[string]ICUKind{"plural": ICUPlural, "selectordinal": ICUSelectOrdinal, "select": ICUSelect}[typ]
+	part := ICUPart{Kind: kind, Arg: name}
+	if p.peek() != ',' {
+		return ICUPart{}, fmt.Errorf("%s argument %q has no branches", typ, name)
+	}
+	p.pos++
+	seen := make(map[string]bool)
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return ICUPart{}, fmt.Errorf("unterminated %s argument %q", typ, name)
+		}
+		if p.peek() == '}' {
+			p.pos++
+			break
+		}
+		sel := p.word()
+		if sel == "" {
+			return ICUPart{}, fmt.Errorf("missing selector in %s argument %q at offset %d", typ, name, p.pos)
+		}
+		if kind != ICUSelect && strings.HasPrefix(sel, "offset:") && len(part.Branches) == 0 {
+			part.Offset = strings.TrimPrefix(sel, "offset:")
+			continue
+		}
+		if seen[sel] {
+			return ICUPart{}, fmt.Errorf("duplicate selector %q in %s argument %q", sel, typ, name)
+		}
+		seen[sel] = true
+		p.skipSpace()
+		if p.peek() != '{' {
+			return ICUPart{}, fmt.Errorf("selector %q in %s argument %q has no message", sel, typ, name)
+		}
+		p.pos++
+		msg, err := p.message(kind != ICUSelect)
+		if err != nil {
+			return ICUPart{}, err
+		}
+		if p.peek() != '}' {
+			return ICUPart{}, fmt.Errorf("unterminated branch %q in %s argument %q", sel, typ, name)
+		}
+		p.pos++
+		part.Branches = append(part.Branches, ICUBranch{Selector: sel, Message: msg})
+	}
+	if !seen["other"] {
+		return ICUPart{}, fmt.Errorf("%s argument %q has no 'other' branch", typ, name)
+	}
+	return part, nil
+}
+
+// simpleArgument parses the rest of {name, type[, style]}.
+func (p *icuParser) simpleArgument(name, typ string, start int) (ICUPart, error) {
+	format := typ
+	if p.peek() == ',' {
+		p.pos++
+		styleStart := p.pos
+		depth := 0
+		for p.pos < len(p.src) && (p.src[p.pos] != '}' || depth > 0) {
+			switch p.src[p.pos] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			p.pos++
+		}
+		format += "," + strings.TrimSpace(string(p.src[styleStart:p.pos]))
+	}
+	if p.peek() != '}' {
+		return ICUPart{}, fmt.Errorf("unterminated argument {%s at offset %d", name, start)
+	}
+	p.pos++
+	return ICUPart{Kind: ICUArgument, Arg: name, Format: format}, nil
+}
+
+// String formats the message back to ICU MessageFormat syntax. Literal
+// apostrophes and syntax characters in text are quoted.
+func (m *ICUMessage) String() string {
+	var b strings.Builder
+	m.format(&b, false)
+	return b.String()
+}
+
+func (m *ICUMessage) format(b *strings.Builder, inPlural bool) {
+	for _, p := range m.Parts {
+		switch p.Kind {
+		case ICUText:
+			for _, c := range p.Text {
+				switch {
+				case c == '\'':
+					b.WriteString("''")
+				case c == '{' || c == '}' || (c == '#' && inPlural):
+					b.WriteString("'" + string(c) + "'")
+				default:
+					b.WriteRune(c)
+				}
+			}
+		case ICUPound:
+			b.WriteByte('#')
+		case ICUArgument:
+			b.WriteString("{" + p.Arg)
+			if p.Format != "" {
+				b.WriteString(", " + strings.Replace(p.Format, ",", ", ", 1))
+			}
+			b.WriteByte('}')
+		default:
+			typ := map[ICUKind]string{ICUPlural: "plural", ICUSelectOrdinal: "selectordinal", ICUSelect: "select"}[p.Kind]
+			b.WriteString("{" + p.Arg + ", " + typ + ",")
+			if p.Offset != "" {
+				b.WriteString(" offset:" + p.Offset)
+			}
+			for _, br := range p.Branches {
+				b.WriteString(" " + br.Selector + "{")
+				br.Message.format(b, p.Kind != ICUSelect)
+				b.WriteByte('}')
+			}
+			b.WriteByte('}')
+		}
+	}
+}
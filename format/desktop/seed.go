@@ -5,7 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
-	po "github.com/minios-linux/lokit/internal/format/po"
+	po "github.com/minios-linux/lokit/format/po"
 )
 
 // DesktopLocale converts a PO language code to the locale tag used in
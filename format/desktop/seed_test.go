@@ -5,7 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
-	po "github.com/minios-linux/lokit/internal/format/po"
+	po "github.com/minios-linux/lokit/format/po"
 )
 
 func TestDesktopLocale(t *testing.T) {
@@ -0,0 +1,26 @@
+// Package format defines the interfaces shared by the translation file
+// formats in its subpackages, which parse and write the files of each
+// supported format.
+package format
+
+// KVFile is a generic key-value translation file interface used by
+// format-agnostic translation pipelines.
+type KVFile interface {
+	Keys() []string
+	UntranslatedKeys() []string
+	Set(key, value string) bool
+	Stats() (total int, translated int, pct float64)
+	SourceValues() map[string]string
+	WriteFile(path string) error
+}
+
+// PluralKVFile is implemented by key-value files that store each plural
+// form under its own key, one per CLDR category. The forms of one plural
+// unit are translated together.
+type PluralKVFile interface {
+	KVFile
+	// PluralKey reports the plural unit and CLDR category of key.
+	PluralKey(key string) (unit, category string, ok bool)
+	// PluralFormKey returns the key holding category of unit.
+	PluralFormKey(unit, category string) string
+}
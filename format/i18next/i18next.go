@@ -12,6 +12,9 @@
 //
 // Keys are natural English text. Empty string values mean untranslated
 // (i18next falls back to the key itself as the English text).
+//
+// Plural forms use i18next suffix keys ("item_one", "item_other"), one per
+// CLDR plural category of the language; their source text is the value.
 package i18next
 
 import (
@@ -159,6 +162,12 @@ func (f *File) UntranslatedKeys() []string {
 	return result
 }
 
+// Get returns the translation value for key.
+func (f *File) Get(key string) (string, bool) {
+	v, ok := f.Translations[key]
+	return v, ok
+}
+
 // Set updates an existing translation value.
 func (f *File) Set(key, value string) bool {
 	if _, ok := f.Translations[key]; !ok {
@@ -183,11 +192,15 @@ func (f *File) Stats() (total, translated int, pct float64) {
 }
 
 // SourceValues returns key -> source string mapping.
-// In i18next files keys are natural English source strings.
+// In i18next files keys are natural English source strings; plural forms
+// ("item_one", "item_other") carry their source text in the value.
 func (f *File) SourceValues() map[string]string {
 	m := make(map[string]string, len(f.Translations))
-	for k := range f.Translations {
+	for k, v := range f.Translations {
 		m[k] = k
+		if _, _, ok := f.PluralKey(k); ok && v != "" {
+			m[k] = v
+		}
 	}
 	return m
 }
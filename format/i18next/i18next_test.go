@@ -0,0 +1,171 @@
+package i18next
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAndMarshal_PreservesOrderAndCounts(t *testing.T) {
+	data := []byte(`{
+  "_meta": {"name": "English", "flag": "US"},
+  "translations": {
+    "First key": "First value",
+    "Second key": "",
+    "Third key": "Third value"
+  }
+}`)
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	keys := f.Keys()
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d", len(keys))
+	}
+	if keys[0] != "First key" || keys[1] != "Second key" || keys[2] != "Third key" {
+		t.Fatalf("unexpected key order: %v", keys)
+	}
+
+	total, translated, pct := f.Stats()
+	if total != 3 || translated != 2 || pct <= 66 || pct >= 67 {
+		t.Fatalf("unexpected stats: total=%d translated=%d pct=%f", total, translated, pct)
+	}
+
+	out, err := f.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	outStr := string(out)
+	idxFirst := strings.Index(outStr, `"First key"`)
+	idxSecond := strings.Index(outStr, `"Second key"`)
+	idxThird := strings.Index(outStr, `"Third key"`)
+	if idxFirst < 0 || idxSecond < 0 || idxThird < 0 {
+		t.Fatalf("marshaled output missing keys: %s", outStr)
+	}
+	if !(idxFirst < idxSecond && idxSecond < idxThird) {
+		t.Fatalf("marshaled key order changed: %s", outStr)
+	}
+}
+
+func TestParse_InvalidJSON(t *testing.T) {
+	_, err := Parse([]byte(`{"broken":`))
+	if err == nil {
+		t.Fatal("expected parse error for invalid JSON")
+	}
+}
+
+func TestResolveMeta_NormalizationAndFallback(t *testing.T) {
+	m := ResolveMeta("pt_br")
+	if m.Name == "" || m.Flag == "" {
+		t.Fatalf("expected normalized metadata for pt_br, got %#v", m)
+	}
+
+	unknown := ResolveMeta("zz-ZZ")
+	if unknown.Name != "zz-ZZ" || unknown.Flag != "" {
+		t.Fatalf("unexpected unknown metadata fallback: %#v", unknown)
+	}
+}
+
+func TestMarshal_WithoutMeta_DoesNotInjectMeta(t *testing.T) {
+	data := []byte(`{
+  "translations": {
+    "Home": "Home"
+  }
+}`)
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	out, err := f.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if strings.Contains(string(out), `"_meta"`) {
+		t.Fatalf("unexpected _meta injection: %s", string(out))
+	}
+}
+
+func TestSplitPluralKey(t *testing.T) {
+	base, cat, ok := SplitPluralKey("item_few")
+	if !ok || base != "item" || cat != "few" {
+		t.Fatalf("SplitPluralKey(item_few) = %q, %q, %v", base, cat, ok)
+	}
+	if _, _, ok := SplitPluralKey("first_name"); ok {
+		t.Fatal("first_name should not be a plural key")
+	}
+	if _, _, ok := SplitPluralKey("_other"); ok {
+		t.Fatal("_other without base should not be a plural key")
+	}
+}
+
+func TestNewTranslationFile_ExpandsPluralCategories(t *testing.T) {
+	src, err := Parse([]byte(`{"translations": {
+    "Hello": "",
+    "item_one": "{{count}} item",
+    "item_other": "{{count}} items",
+    "Bye": ""
+}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	ru := NewTranslationFile(src, "ru")
+	got := strings.Join(ru.Keys(), ",")
+	want := "Hello,item_one,item_few,item_many,item_other,Bye"
+	if got != want {
+		t.Fatalf("ru keys = %s, want %s", got, want)
+	}
+
+	ja := NewTranslationFile(src, "ja")
+	if got := strings.Join(ja.Keys(), ","); got != "Hello,item_other,Bye" {
+		t.Fatalf("ja keys = %s", got)
+	}
+
+	vals := src.SourceValues()
+	if vals["item_one"] != "{{count}} item" || vals["Hello"] != "Hello" {
+		t.Fatalf("unexpected source values: %v", vals)
+	}
+	if unit, cat, ok := ru.PluralKey("item_many"); !ok || unit != "item" || cat != "many" {
+		t.Fatalf("PluralKey(item_many) = %q, %q, %v", unit, cat, ok)
+	}
+}
+
+func TestSyncKeys_InsertsMissingKeysInSourceOrder(t *testing.T) {
+	src, err := Parse([]byte(`{"translations": {
+    "Hello": "",
+    "item_one": "{{count}} item",
+    "item_other": "{{count}} items",
+    "Bye": ""
+}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	f, err := Parse([]byte(`{"translations": {
+    "Hello": "Привет",
+    "item_one": "{{count}} элемент",
+    "item_other": "{{count}} элемента",
+    "Extra": "Лишний"
+}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	if added := f.SyncKeys(src, "ru"); added != 3 {
+		t.Fatalf("SyncKeys added %d keys, want 3", added)
+	}
+	got := strings.Join(f.Keys(), ",")
+	want := "Hello,item_one,item_few,item_many,item_other,Bye,Extra"
+	if got != want {
+		t.Fatalf("keys = %s, want %s", got, want)
+	}
+	if f.Translations["item_one"] != "{{count}} элемент" || f.Translations["item_few"] != "" {
+		t.Fatalf("unexpected translations: %v", f.Translations)
+	}
+	if added := f.SyncKeys(src, "ru"); added != 0 {
+		t.Fatalf("second SyncKeys added %d keys", added)
+	}
+}
@@ -0,0 +1,104 @@
+package i18next
+
+import (
+	"strings"
+
+	"github.com/minios-linux/lokit/langmeta"
+)
+
+// SplitPluralKey splits an i18next plural key such as "item_one" or
+// "item_other" into its base key and CLDR plural category.
+func SplitPluralKey(key string) (base, category string, ok bool) {
+	i := strings.LastIndexByte(key, '_')
+	if i <= 0 || !langmeta.IsPluralCategory(key[i+1:]) {
+		return "", "", false
+	}
+	return key[:i], key[i+1:], true
+}
+
+// PluralKey reports whether key is one form of a plural unit. A suffixed
+// key only counts as plural when the unit also has an "_other" form, as
+// required by i18next.
+func (f *File) PluralKey(key string) (unit, category string, ok bool) {
+	base, cat, ok := SplitPluralKey(key)
+	if !ok {
+		return "", "", false
+	}
+	if _, exists := f.Translations[f.PluralFormKey(base, langmeta.PluralOther)]; !exists {
+		return "", "", false
+	}
+	return base, cat, true
+}
+
+// PluralFormKey returns the key holding the given category of a plural unit.
+func (f *File) PluralFormKey(unit, category string) string {
+	return unit + "_" + category
+}
+
+// NewTranslationFile creates an empty translation file for lang with all
+// keys of the source file. Plural units get one key per CLDR category of lang.
+func NewTranslationFile(src *File, lang string) *File {
+	f := &File{
+		Meta:         ResolveMeta(lang),
+		Translations: make(map[string]string),
+	}
+	f.SyncKeys(src, lang)
+	return f
+}
+
+// SyncKeys adds keys present in the source file but missing here, expanding
+// plural units to the CLDR categories of lang. New keys are inserted after
+// their preceding source key; existing keys are never removed. It returns
+// the number of added keys.
+func (f *File) SyncKeys(src *File, lang string) int {
+	keys := append([]string(nil), f.Keys()...)
+	index := make(map[string]int, len(keys))
+	for i, k := range keys {
+		index[k] = i
+	}
+
+	pos := -1
+	added := 0
+	for _, k := range src.wantedKeys(lang) {
+		if _, exists := f.Translations[k]; exists {
+			if i, ok := index[k]; ok {
+				pos = i
+			}
+			continue
+		}
+		pos++
+		keys = append(keys, "")
+		copy(keys[pos+1:], keys[pos:])
+		keys[pos] = k
+		for i := pos; i < len(keys); i++ {
+			index[keys[i]] = i
+		}
+		f.Translations[k] = ""
+		added++
+	}
+	f.keys = keys
+	return added
+}
+
+// wantedKeys returns the source keys in order, with each plural unit
+// replaced by one key per CLDR category of lang.
+func (f *File) wantedKeys(lang string) []string {
+	categories := langmeta.PluralCategories(lang)
+	seen := make(map[string]bool)
+	var out []string
+	for _, k := range f.Keys() {
+		unit, _, ok := f.PluralKey(k)
+		if !ok {
+			out = append(out, k)
+			continue
+		}
+		if seen[unit] {
+			continue
+		}
+		seen[unit] = true
+		for _, cat := range categories {
+			out = append(out, f.PluralFormKey(unit, cat))
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package po
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// moMagic is the magic number of little-endian GNU MO files.
+const moMagic = 0x950412de
+
+// moHeaderSize is the size of the fixed MO file header: magic, revision,
+// string count, the offsets of both string tables, and the size and offset
+// of the (unused) hash table.
+const moHeaderSize = 7 * 4
+
+// moMessage is a message as stored in an MO file.
+type moMessage struct {
+	id, str string
+}
+
+// moMessages returns the messages of f that an MO file holds, sorted by
+// id: the header and every translated entry. Fuzzy, untranslated and
+// obsolete entries are left out, like msgfmt does.
+func (f *File) moMessages() []moMessage {
+	var msgs []moMessage
+	if f.Header != nil && f.Header.MsgStr != "" {
+		msgs = append(msgs, moMessage{id: "", str: f.Header.MsgStr})
+	}
+	for _, e := range f.Entries {
+		if e.Obsolete || !e.IsTranslated() {
+			continue
+		}
+		id := e.MsgID
+		if e.MsgCtxt != "" {
+			id = e.MsgCtxt + "\x04" + id
+		}
+		str := e.MsgStr
+		if e.MsgIDPlural != "" {
+			id += "\x00" + e.MsgIDPlural
+			forms := make([]string, len(e.MsgStrPlural))
+			complete := true
+			for i := range forms {
+				form, ok := e.MsgStrPlural[i]
+				if !ok {
+					complete = false
+					break
+				}
+				forms[i] = form
+			}
+			if !complete {
+				continue
+			}
+			str = strings.Join(forms, "\x00")
+		}
+		msgs = append(msgs, moMessage{id: id, str: str})
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].id < msgs[j].id })
+	return msgs
+}
+
+// WriteMO writes f as a compiled GNU MO file, the binary catalog loaded by
+// gettext at run time. The file has no hash table, which gettext accepts.
+func (f *File) WriteMO(w io.Writer) error {
+	msgs := f.moMessages()
+	n := uint32(len(msgs))
+
+	idTable := uint32(moHeaderSize)
+	strTable := idTable + 8*n
+	offset := strTable + 8*n
+
+	bw := bufio.NewWriter(w)
+	put := func(v uint32) {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], v)
+		bw.Write(b[:])
+	}
+
+	put(moMagic)
+	put(0) // revision
+	put(n)
+	put(idTable)
+	put(strTable)
+	put(0) // hash table size
+	put(offset)
+
+	// Both tables hold (length, offset) pairs; every string is stored
+	// NUL-terminated after the tables, ids first.
+	for _, m := range msgs {
+		put(uint32(len(m.id)))
+		put(offset)
+		offset += uint32(len(m.id)) + 1
+	}
+	for _, m := range msgs {
+		put(uint32(len(m.str)))
+		put(offset)
+		offset += uint32(len(m.str)) + 1
+	}
+	for _, m := range msgs {
+		bw.WriteString(m.id)
+		bw.WriteByte(0)
+	}
+	for _, m := range msgs {
+		bw.WriteString(m.str)
+		bw.WriteByte(0)
+	}
+	return bw.Flush()
+}
+
+// WriteMOFile writes f as a compiled MO file to path.
+func (f *File) WriteMOFile(path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.WriteMO(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
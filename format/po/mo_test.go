@@ -0,0 +1,70 @@
+package po
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+func TestWriteMO(t *testing.T) {
+	input := `msgid ""
+msgstr ""
+"Language: ru\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "Open"
+msgstr "Открыть"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Открыть меню"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d файл"
+msgstr[1] "%d файла"
+msgstr[2] "%d файлов"
+
+#, fuzzy
+msgid "Close"
+msgstr "Закрыть"
+
+msgid "Save"
+msgstr ""
+
+#~ msgid "Old"
+#~ msgstr "Старый"
+`
+	f, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.WriteMO(&buf); err != nil {
+		t.Fatalf("WriteMO error: %v", err)
+	}
+
+	mo := gotext.NewMo()
+	mo.Parse(buf.Bytes())
+	if got := mo.Get("Open"); got != "Открыть" {
+		t.Errorf("Get(Open) = %q", got)
+	}
+	if got := mo.GetC("Open", "menu"); got != "Открыть меню" {
+		t.Errorf("GetC(Open, menu) = %q", got)
+	}
+	if got := mo.GetN("%d file", "%d files", 5, 5); got != "5 файлов" {
+		t.Errorf("GetN(5) = %q", got)
+	}
+	// Fuzzy, untranslated and obsolete entries fall back to the msgid.
+	for _, id := range []string{"Close", "Save", "Old"} {
+		if got := mo.Get(id); got != id {
+			t.Errorf("Get(%s) = %q, want untranslated", id, got)
+		}
+	}
+	if got := len(f.moMessages()); got != 4 {
+		t.Errorf("messages = %d, want header and 3 entries", got)
+	}
+}
@@ -71,11 +71,13 @@ func (e *Entry) IsFuzzy() bool {
 	return false
 }
 
-// SetFuzzy adds or removes the fuzzy flag.
+// SetFuzzy adds or removes the fuzzy flag. Removing it also drops the
+// previous msgid, which only makes sense for fuzzy entries.
 func (e *Entry) SetFuzzy(fuzzy bool) {
 	if fuzzy && !e.IsFuzzy() {
 		e.Flags = append(e.Flags, "fuzzy")
 	} else if !fuzzy {
+		e.PreviousMsgID = ""
 		filtered := make([]string, 0, len(e.Flags))
 		for _, f := range e.Flags {
 			if f != "fuzzy" {
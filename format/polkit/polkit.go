@@ -86,6 +86,13 @@ func (f *File) UntranslatedKeys() []string {
 	return out
 }
 
+func (f *File) Get(key string) (string, bool) {
+	if _, ok := f.sourceValues[key]; !ok {
+		return "", false
+	}
+	return f.values[key], true
+}
+
 func (f *File) Set(key, value string) bool {
 	if _, ok := f.sourceValues[key]; !ok {
 		return false
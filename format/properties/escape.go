@@ -0,0 +1,203 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// decodeText returns data as a string. Data that is not valid UTF-8 is
+// decoded as ISO-8859-1 and latin1 is true. A UTF-8 byte order mark is
+// dropped.
+func decodeText(data []byte) (text string, latin1 bool) {
+	if utf8.Valid(data) {
+		return strings.TrimPrefix(string(data), "\uFEFF"), false
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes), true
+}
+
+// continues reports whether a line ends in an odd number of backslashes,
+// i.e. continues on the next line.
+func continues(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+func hasNonASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+var errBadUnicodeEscape = errors.New(`malformed \uXXXX escape`)
+
+// unescape decodes the escapes of a key or value: \t, \n, \r, \f,
+// \uXXXX (including UTF-16 surrogate pairs) and a backslash before any
+// other character, which stands for that character.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	var high rune // pending high surrogate
+	flush := func() {
+		if high != 0 {
+			b.WriteRune(utf8.RuneError)
+			high = 0
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			flush()
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = s[i]; c {
+		case 't':
+			flush()
+			b.WriteByte('\t')
+		case 'n':
+			flush()
+			b.WriteByte('\n')
+		case 'r':
+			flush()
+			b.WriteByte('\r')
+		case 'f':
+			flush()
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errBadUnicodeEscape
+			}
+			var r rune
+			for _, h := range s[i+1 : i+5] {
+				d := hexDigit(h)
+				if d < 0 {
+					return "", fmt.Errorf(`%w: \u%s`, errBadUnicodeEscape, s[i+1:i+5])
+				}
+				r = r<<4 | d
+			}
+			i += 4
+			switch {
+			case utf16.IsSurrogate(r) && r < 0xDC00:
+				flush()
+				high = r
+			case utf16.IsSurrogate(r) && high != 0:
+				b.WriteRune(utf16.DecodeRune(high, r))
+				high = 0
+			default:
+				flush()
+				b.WriteRune(r)
+			}
+		default:
+			flush()
+			b.WriteByte(c)
+		}
+	}
+	flush()
+	return b.String(), nil
+}
+
+func hexDigit(c rune) rune {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10
+	}
+	return -1
+}
+
+// escape encodes a key or value for Marshal. Keys also escape the
+// separators and spaces; values only a leading space. Characters outside
+// ASCII become \uXXXX escapes when the file is written as ASCII.
+func (f *File) escape(s string, key bool) string {
+	ascii := f.encoding == ASCII
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case ' ':
+			if key || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(' ')
+		case '=', ':':
+			if key {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		case '#', '!':
+			if key && i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r == 0x7f || (ascii && r >= utf8.RuneSelf) {
+				writeUnicodeEscape(&b, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// escapeComment returns a comment line as written by Marshal: unchanged,
+// or with characters outside ASCII escaped when the file is written as
+// ASCII, like java.util.Properties.store does.
+func (f *File) escapeComment(s string) string {
+	if f.encoding != ASCII || !hasNonASCII(s) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			writeUnicodeEscape(&b, r)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// writeUnicodeEscape writes r as \uXXXX, or as a surrogate pair of
+// escapes outside the Basic Multilingual Plane.
+func writeUnicodeEscape(b *strings.Builder, r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		fmt.Fprintf(b, `\u%04X\u%04X`, r1, r2)
+		return
+	}
+	fmt.Fprintf(b, `\u%04X`, r)
+}
@@ -2,8 +2,14 @@
 //
 // Format: key=value pairs, one per line. Lines starting with '#' or '!' are
 // comments and are preserved verbatim in the output. Blank lines are also
-// preserved. Multi-line values (backslash continuation) are not supported —
-// each line is treated independently.
+// preserved. Keys and values are read like java.util.Properties does:
+// escapes such as \uXXXX, \n and \= are decoded, and a line ending in a
+// backslash continues on the next line. Files that are not valid UTF-8 are
+// read as ISO-8859-1, the encoding of Java 8 and earlier.
+//
+// Marshal writes one key=value line per entry, escaping what Java needs.
+// Characters outside ASCII are written as UTF-8 or as \uXXXX escapes
+// depending on the file's Encoding.
 //
 // File naming convention: each language is stored as a separate file:
 //
@@ -49,6 +55,34 @@ type File struct {
 	lines []line
 	// index maps key → index in lines for fast lookup.
 	index map[string]int
+	// encoding is the output encoding; "" means the file is pure ASCII and
+	// no encoding was chosen yet.
+	encoding Encoding
+}
+
+// Encoding selects how Marshal writes characters outside ASCII.
+type Encoding string
+
+const (
+	// UTF8 writes characters as UTF-8, read by Java 9 and later.
+	UTF8 Encoding = "utf-8"
+	// ASCII writes characters outside ASCII as \uXXXX escapes, read by
+	// every Java version.
+	ASCII Encoding = "ascii"
+)
+
+// Encoding returns the encoding Marshal writes. It is detected by Parse:
+// ASCII for files using \uXXXX escapes or ISO-8859-1, UTF8 otherwise.
+func (f *File) Encoding() Encoding {
+	if f.encoding == "" {
+		return UTF8
+	}
+	return f.encoding
+}
+
+// SetEncoding sets the encoding Marshal writes.
+func (f *File) SetEncoding(enc Encoding) {
+	f.encoding = enc
 }
 
 // ---------------------------------------------------------------------------
@@ -68,7 +102,10 @@ func ParseFile(path string) (*File, error) {
 func Parse(data []byte) (*File, error) {
 	f := &File{index: make(map[string]int)}
 
-	text := string(data)
+	text, latin1 := decodeText(data)
+	if latin1 {
+		f.encoding = ASCII
+	}
 	// Normalise Windows line endings.
 	text = strings.ReplaceAll(text, "\r\n", "\n")
 	rawLines := strings.Split(text, "\n")
@@ -78,23 +115,46 @@ func Parse(data []byte) (*File, error) {
 		rawLines = rawLines[:len(rawLines)-1]
 	}
 
-	for _, raw := range rawLines {
-		trimmed := strings.TrimSpace(raw)
+	for i := 0; i < len(rawLines); i++ {
+		raw := rawLines[i]
+		trimmed := strings.TrimLeft(raw, " \t\f")
 
 		switch {
-		case trimmed == "":
+		case strings.TrimSpace(trimmed) == "":
 			f.lines = append(f.lines, line{kind: lineBlank, raw: raw})
 
 		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!"):
 			f.lines = append(f.lines, line{kind: lineComment, raw: raw})
 
 		default:
-			k, v := splitKeyValue(trimmed)
+			// Join continuation lines; the leading whitespace of each
+			// continued line is dropped.
+			start := i
+			logical := trimmed
+			for continues(logical) && i+1 < len(rawLines) {
+				i++
+				logical = logical[:len(logical)-1] + strings.TrimLeft(rawLines[i], " \t\f")
+			}
+			if continues(logical) {
+				logical = logical[:len(logical)-1]
+			}
+
+			k, v, err := splitKeyValue(logical)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", start+1, err)
+			}
 			if k == "" {
 				// Malformed line — treat as comment to preserve it.
-				f.lines = append(f.lines, line{kind: lineComment, raw: raw})
+				f.lines = append(f.lines, line{kind: lineComment, raw: strings.Join(rawLines[start:i+1], "\n")})
 				continue
 			}
+			if f.encoding == "" && hasNonASCII(v) {
+				if hasNonASCII(logical) {
+					f.encoding = UTF8
+				} else {
+					f.encoding = ASCII
+				}
+			}
 			if _, exists := f.index[k]; exists {
 				// Duplicate key: overwrite value but keep position.
 				f.lines[f.index[k]].value = v
@@ -109,16 +169,32 @@ func Parse(data []byte) (*File, error) {
 	return f, nil
 }
 
-// splitKeyValue splits "key = value" or "key=value" into key and value.
-// The separator may be '=' or ':'. Surrounding whitespace is stripped.
-func splitKeyValue(s string) (key, value string) {
-	for i, ch := range s {
-		if ch == '=' || ch == ':' {
-			return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+// splitKeyValue splits a logical line into its unescaped key and value.
+// Like Java, the key ends at the first unescaped '=', ':' or whitespace,
+// and whitespace around the separator is dropped.
+func splitKeyValue(s string) (key, value string, err error) {
+	end := len(s)
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '=' || s[i] == ':' || isSpace(s[i]) {
+			end = i
+			break
 		}
 	}
-	// No separator — treat the whole line as a key with empty value.
-	return strings.TrimSpace(s), ""
+	rest := strings.TrimLeft(s[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	if key, err = unescape(s[:end]); err != nil {
+		return "", "", err
+	}
+	if value, err = unescape(rest); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
 }
 
 // ---------------------------------------------------------------------------
@@ -207,12 +283,12 @@ func (f *File) Marshal() ([]byte, error) {
 		case lineBlank:
 			buf.WriteByte('\n')
 		case lineComment:
-			buf.WriteString(ln.raw)
+			buf.WriteString(f.escapeComment(ln.raw))
 			buf.WriteByte('\n')
 		case lineEntry:
-			buf.WriteString(ln.key)
+			buf.WriteString(f.escape(ln.key, true))
 			buf.WriteByte('=')
-			buf.WriteString(ln.value)
+			buf.WriteString(f.escape(ln.value, false))
 			buf.WriteByte('\n')
 		}
 	}
@@ -240,9 +316,9 @@ func (f *File) WriteFile(path string) error {
 // ---------------------------------------------------------------------------
 
 // NewTranslationFile creates an empty target File mirroring src's structure
-// with all values cleared.
+// with all values cleared. It is written in the encoding of src.
 func NewTranslationFile(src *File, _ string) *File {
-	f := &File{index: make(map[string]int)}
+	f := &File{index: make(map[string]int), encoding: src.encoding}
 	for _, ln := range src.lines {
 		idx := len(f.lines)
 		cp := ln
@@ -257,8 +333,13 @@ func NewTranslationFile(src *File, _ string) *File {
 
 // SyncKeys ensures target has the same keys as src, preserving existing
 // translations and adding empty entries for new keys. Keys removed from
-// src are also removed from target.
+// src are also removed from target. A pure-ASCII target takes the encoding
+// of src.
 func SyncKeys(src, target *File) {
+	if target.encoding == "" {
+		target.encoding = src.encoding
+	}
+
 	// Collect existing translations from target.
 	existing := make(map[string]string, len(target.index))
 	for _, ln := range target.lines {
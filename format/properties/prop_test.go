@@ -169,3 +169,104 @@ func TestSyncKeys_RemovesObsoleteKeys(t *testing.T) {
 		t.Errorf("expected 1 key, got %d", len(target.Keys()))
 	}
 }
+
+func TestParse_Escapes(t *testing.T) {
+	data := []byte("title=\\u041F\\u0440\\u0438\\u0432\\u0435\\u0442\n" +
+		"emoji=\\uD83D\\uDE00\n" +
+		"path\\ name\\=x=C:\\\\temp\\tdir\n" +
+		"multi=first \\\n    second\\nline\n" +
+		"spaced = \\ leading\n")
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"title":       "Привет",
+		"emoji":       "😀",
+		"path name=x": "C:\\temp\tdir",
+		"multi":       "first second\nline",
+		"spaced":      " leading",
+	}
+	for k, v := range want {
+		if got, ok := f.Get(k); !ok || got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if f.Encoding() != ASCII {
+		t.Errorf("encoding = %s, want ascii for \\u-escaped file", f.Encoding())
+	}
+
+	out, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range want {
+		if got, _ := again.Get(k); got != v {
+			t.Errorf("round-trip %s = %q, want %q\n%s", k, got, v, out)
+		}
+	}
+	if !strings.Contains(string(out), "emoji=\\uD83D\\uDE00\n") {
+		t.Errorf("emoji not escaped as surrogate pair:\n%s", out)
+	}
+}
+
+func TestParse_MalformedUnicodeEscape(t *testing.T) {
+	if _, err := Parse([]byte("a=\\u12G4\n")); err == nil {
+		t.Fatal("expected error for malformed \\u escape")
+	}
+}
+
+func TestParse_Latin1(t *testing.T) {
+	f, err := Parse([]byte("greeting=Gr\xfc\xdfe\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := f.Get("greeting"); got != "Grüße" {
+		t.Errorf("greeting = %q, want Grüße", got)
+	}
+	if f.Encoding() != ASCII {
+		t.Errorf("encoding = %s, want ascii for ISO-8859-1 file", f.Encoding())
+	}
+	out, _ := f.Marshal()
+	if string(out) != "greeting=Gr\\u00FC\\u00DFe\n" {
+		t.Errorf("marshal = %q", out)
+	}
+}
+
+func TestMarshal_Encoding(t *testing.T) {
+	src, _ := Parse([]byte("# Заголовок\nhello=Hello\n"))
+	if src.Encoding() != UTF8 {
+		t.Errorf("encoding = %s, want utf-8", src.Encoding())
+	}
+	target := NewTranslationFile(src, "ja")
+	target.Set("hello", "こんにちは")
+
+	out, _ := target.Marshal()
+	if string(out) != "# Заголовок\nhello=こんにちは\n" {
+		t.Errorf("utf-8 marshal = %q", out)
+	}
+	target.SetEncoding(ASCII)
+	out, _ = target.Marshal()
+	if string(out) != "# \\u0417\\u0430\\u0433\\u043E\\u043B\\u043E\\u0432\\u043E\\u043A\nhello=\\u3053\\u3093\\u306B\\u3061\\u306F\n" {
+		t.Errorf("ascii marshal = %q", out)
+	}
+}
+
+func TestSyncKeys_InheritsSourceEncoding(t *testing.T) {
+	src, _ := Parse([]byte("a=\\u00C4rger\n"))
+	target, _ := Parse([]byte("a=\n"))
+	SyncKeys(src, target)
+	if target.Encoding() != ASCII {
+		t.Errorf("encoding = %s, want ascii from source", target.Encoding())
+	}
+
+	utf, _ := Parse([]byte("a=Ärger\n"))
+	SyncKeys(src, utf)
+	if utf.Encoding() != UTF8 {
+		t.Errorf("encoding = %s, want utf-8 kept from target", utf.Encoding())
+	}
+}
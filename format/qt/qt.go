@@ -0,0 +1,667 @@
+// Package qt implements reading and writing of Qt Linguist translation
+// files (.ts), as written by lupdate and compiled by lrelease.
+//
+// A .ts file holds the messages of one target language, grouped by context
+// (usually the C++ class or QML component that contains the string):
+//
+//	<TS version="2.1" language="de_DE" sourcelanguage="en">
+//	<context>
+//	    <name>MainWindow</name>
+//	    <message numerus="yes">
+//	        <location filename="../src/mainwindow.cpp" line="42"/>
+//	        <source>%n file(s) selected</source>
+//	        <translation type="unfinished">
+//	            <numerusform></numerusform>
+//	            <numerusform></numerusform>
+//	        </translation>
+//	    </message>
+//	</context>
+//	</TS>
+//
+// Messages are translated through the gettext pipeline: POFile converts them
+// to PO entries flagged qt-format or qt-plural-format, and ApplyPO copies
+// the translations back. Unfinished translations correspond to fuzzy
+// entries.
+package qt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// Translation types of a message. Finished translations have no type.
+const (
+	TypeUnfinished = "unfinished"
+	// TypeVanished marks messages lupdate no longer found in the sources.
+	TypeVanished = "vanished"
+	// TypeObsolete marks messages kept by lupdate -no-obsolete=false in
+	// older files.
+	TypeObsolete = "obsolete"
+)
+
+// ---------------------------------------------------------------------------
+// Document model
+// ---------------------------------------------------------------------------
+
+// Location is a source code reference of a message. With lupdate's default
+// relative locations, Filename is empty when unchanged from the previous
+// location and Line is an offset such as "+3".
+type Location struct {
+	Filename string
+	Line     string
+}
+
+// Message is one translatable string.
+type Message struct {
+	// ID is the message ID of lupdate -idbased projects.
+	ID        string
+	Numerus   bool
+	Locations []Location
+	Source    string
+	OldSource string
+	// Comment disambiguates identical sources within a context.
+	Comment           string
+	OldComment        string
+	ExtraComment      string
+	TranslatorComment string
+	// Type is empty for finished translations, or one of TypeUnfinished,
+	// TypeVanished and TypeObsolete.
+	Type         string
+	Translation  string
+	NumerusForms []string
+
+	// extra holds unknown child elements such as <userdata>, written back
+	// unchanged.
+	extra []rawElement
+}
+
+// Context groups the messages of one class or component.
+type Context struct {
+	Name     string
+	Messages []*Message
+
+	extra []rawElement
+}
+
+// File is a Qt Linguist translation file.
+type File struct {
+	Version        string
+	Language       string
+	SourceLanguage string
+	Contexts       []*Context
+
+	extra []rawElement
+	// entries maps the entries of the last POFile catalog to their
+	// messages.
+	entries map[*po.Entry]*Message
+}
+
+// active reports whether m is still used by the sources.
+func (m *Message) active() bool {
+	return m.Type != TypeVanished && m.Type != TypeObsolete
+}
+
+// hasText reports whether m has a complete translation text.
+func (m *Message) hasText() bool {
+	if !m.Numerus {
+		return m.Translation != ""
+	}
+	for _, form := range m.NumerusForms {
+		if form == "" {
+			return false
+		}
+	}
+	return len(m.NumerusForms) > 0
+}
+
+// hasAnyText reports whether m has any translation text.
+func (m *Message) hasAnyText() bool {
+	if !m.Numerus {
+		return m.Translation != ""
+	}
+	for _, form := range m.NumerusForms {
+		if form != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTranslated reports whether m has a finished, complete translation.
+func (m *Message) IsTranslated() bool {
+	return m.Type == "" && m.hasText()
+}
+
+// Messages returns the messages still used by the sources, in file order.
+func (f *File) Messages() []*Message {
+	var msgs []*Message
+	for _, c := range f.Contexts {
+		for _, m := range c.Messages {
+			if m.active() {
+				msgs = append(msgs, m)
+			}
+		}
+	}
+	return msgs
+}
+
+// Stats returns translation statistics over the active messages. Unfinished
+// messages with a translation text are counted as unfinished, others as
+// untranslated.
+func (f *File) Stats() (total, translated, unfinished, untranslated int) {
+	for _, m := range f.Messages() {
+		total++
+		switch {
+		case m.IsTranslated():
+			translated++
+		case m.hasAnyText():
+			unfinished++
+		default:
+			untranslated++
+		}
+	}
+	return
+}
+
+// ---------------------------------------------------------------------------
+// XML elements
+// ---------------------------------------------------------------------------
+
+type tsXML struct {
+	XMLName        xml.Name     `xml:"TS"`
+	Version        string       `xml:"version,attr"`
+	Language       string       `xml:"language,attr"`
+	SourceLanguage string       `xml:"sourcelanguage,attr"`
+	Contexts       []contextXML `xml:"context"`
+	Extra          []rawElement `xml:",any"`
+}
+
+type contextXML struct {
+	Name     string       `xml:"name"`
+	Messages []messageXML `xml:"message"`
+	Extra    []rawElement `xml:",any"`
+}
+
+type messageXML struct {
+	ID                string          `xml:"id,attr"`
+	Numerus           string          `xml:"numerus,attr"`
+	Locations         []locationXML   `xml:"location"`
+	Source            string          `xml:"source"`
+	OldSource         string          `xml:"oldsource"`
+	Comment           string          `xml:"comment"`
+	OldComment        string          `xml:"oldcomment"`
+	ExtraComment      string          `xml:"extracomment"`
+	TranslatorComment string          `xml:"translatorcomment"`
+	Translation       *translationXML `xml:"translation"`
+	Extra             []rawElement    `xml:",any"`
+}
+
+type locationXML struct {
+	Filename string `xml:"filename,attr"`
+	Line     string `xml:"line,attr"`
+}
+
+type translationXML struct {
+	Type         string   `xml:"type,attr"`
+	Text         string   `xml:",chardata"`
+	NumerusForms []string `xml:"numerusform"`
+}
+
+// rawElement is an element lokit does not interpret.
+type rawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// ---------------------------------------------------------------------------
+// Parsing
+// ---------------------------------------------------------------------------
+
+// ParseFile reads and parses a .ts file from disk.
+func ParseFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse parses a .ts file.
+func Parse(data []byte) (*File, error) {
+	var doc tsXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing Qt translation file: %w", err)
+	}
+	f := &File{
+		Version:        doc.Version,
+		Language:       doc.Language,
+		SourceLanguage: doc.SourceLanguage,
+		extra:          doc.Extra,
+	}
+	for _, xc := range doc.Contexts {
+		c := &Context{Name: xc.Name, extra: xc.Extra}
+		for _, xm := range xc.Messages {
+			m := &Message{
+				ID:                xm.ID,
+				Numerus:           xm.Numerus == "yes",
+				Source:            xm.Source,
+				OldSource:         xm.OldSource,
+				Comment:           xm.Comment,
+				OldComment:        xm.OldComment,
+				ExtraComment:      xm.ExtraComment,
+				TranslatorComment: xm.TranslatorComment,
+				Type:              TypeUnfinished,
+				extra:             xm.Extra,
+			}
+			for _, l := range xm.Locations {
+				m.Locations = append(m.Locations, Location(l))
+			}
+			if t := xm.Translation; t != nil {
+				m.Type = t.Type
+				if m.Numerus {
+					m.NumerusForms = t.NumerusForms
+				} else {
+					m.Translation = t.Text
+				}
+			}
+			c.Messages = append(c.Messages, m)
+		}
+		f.Contexts = append(f.Contexts, c)
+	}
+	return f, nil
+}
+
+// ---------------------------------------------------------------------------
+// Marshaling
+// ---------------------------------------------------------------------------
+
+var escaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
+)
+
+// Marshal serializes the file the way lupdate writes it.
+func (f *File) Marshal() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<!DOCTYPE TS>\n<TS")
+	writeAttr(&b, "version", f.Version)
+	writeAttr(&b, "language", f.Language)
+	writeAttr(&b, "sourcelanguage", f.SourceLanguage)
+	b.WriteString(">\n")
+	writeRaw(&b, f.extra, 0)
+	for _, c := range f.Contexts {
+		b.WriteString("<context>\n")
+		writeElement(&b, 1, "name", c.Name, true)
+		writeRaw(&b, c.extra, 1)
+		for _, m := range c.Messages {
+			writeMessage(&b, m)
+		}
+		b.WriteString("</context>\n")
+	}
+	b.WriteString("</TS>\n")
+	return b.Bytes(), nil
+}
+
+// WriteFile writes the file to path.
+func (f *File) WriteFile(path string) error {
+	data, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func writeMessage(b *bytes.Buffer, m *Message) {
+	b.WriteString("    <message")
+	writeAttr(b, "id", m.ID)
+	if m.Numerus {
+		writeAttr(b, "numerus", "yes")
+	}
+	b.WriteString(">\n")
+	for _, l := range m.Locations {
+		b.WriteString("        <location")
+		writeAttr(b, "filename", l.Filename)
+		writeAttr(b, "line", l.Line)
+		b.WriteString("/>\n")
+	}
+	writeElement(b, 2, "source", m.Source, true)
+	writeElement(b, 2, "oldsource", m.OldSource, false)
+	writeElement(b, 2, "comment", m.Comment, false)
+	writeElement(b, 2, "oldcomment", m.OldComment, false)
+	writeElement(b, 2, "extracomment", m.ExtraComment, false)
+	writeElement(b, 2, "translatorcomment", m.TranslatorComment, false)
+
+	b.WriteString("        <translation")
+	writeAttr(b, "type", m.Type)
+	b.WriteString(">")
+	if m.Numerus {
+		b.WriteString("\n")
+		for _, form := range m.NumerusForms {
+			writeElement(b, 3, "numerusform", form, true)
+		}
+		b.WriteString("        ")
+	} else {
+		b.WriteString(escaper.Replace(m.Translation))
+	}
+	b.WriteString("</translation>\n")
+	writeRaw(b, m.extra, 2)
+	b.WriteString("    </message>\n")
+}
+
+// writeElement writes a text element on its own line. Empty optional
+// elements are omitted.
+func writeElement(b *bytes.Buffer, depth int, name, text string, required bool) {
+	if text == "" && !required {
+		return
+	}
+	b.WriteString(strings.Repeat("    ", depth))
+	fmt.Fprintf(b, "<%s>%s</%s>\n", name, escaper.Replace(text), name)
+}
+
+func writeAttr(b *bytes.Buffer, name, value string) {
+	if value != "" {
+		fmt.Fprintf(b, " %s=\"%s\"", name, escaper.Replace(value))
+	}
+}
+
+func writeRaw(b *bytes.Buffer, elems []rawElement, depth int) {
+	for _, e := range elems {
+		b.WriteString(strings.Repeat("    ", depth))
+		b.WriteString("<" + e.XMLName.Local)
+		for _, a := range e.Attrs {
+			writeAttr(b, a.Name.Local, a.Value)
+		}
+		if e.Inner == "" {
+			b.WriteString("/>\n")
+			continue
+		}
+		fmt.Fprintf(b, ">%s</%s>\n", e.Inner, e.XMLName.Local)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Translation files
+// ---------------------------------------------------------------------------
+
+// Language returns the value of the language attribute for a lokit
+// language code, such as "pt_BR" for "pt-BR".
+func Language(lang string) string {
+	return strings.ReplaceAll(lang, "-", "_")
+}
+
+// numerusCount returns the number of plural forms of lang.
+func numerusCount(lang string) int {
+	for _, part := range strings.Split(po.PluralFormsForLang(lang), ";") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(part), "nplurals="); ok {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 2
+}
+
+// emptyCopy returns a copy of m without translation.
+func emptyCopy(m *Message, nforms int) *Message {
+	cp := *m
+	cp.Locations = append([]Location(nil), m.Locations...)
+	cp.OldSource, cp.OldComment, cp.TranslatorComment = "", "", ""
+	cp.Type = TypeUnfinished
+	cp.Translation = ""
+	cp.NumerusForms = nil
+	if m.Numerus {
+		cp.NumerusForms = make([]string, nforms)
+	}
+	cp.extra = nil
+	return &cp
+}
+
+// NewTranslationFile creates an untranslated file for lang with the active
+// messages of src.
+func NewTranslationFile(src *File, lang string) *File {
+	f := &File{Version: src.Version, Language: Language(lang), SourceLanguage: src.SourceLanguage}
+	if f.Version == "" {
+		f.Version = "2.1"
+	}
+	n := numerusCount(lang)
+	for _, sc := range src.Contexts {
+		c := &Context{Name: sc.Name}
+		for _, m := range sc.Messages {
+			if m.active() {
+				c.Messages = append(c.Messages, emptyCopy(m, n))
+			}
+		}
+		if len(c.Messages) > 0 {
+			f.Contexts = append(f.Contexts, c)
+		}
+	}
+	return f
+}
+
+// messageKey identifies a message across the files of a project.
+func messageKey(context string, m *Message) string {
+	return context + "\x04" + m.Comment + "\x04" + m.Source
+}
+
+// SyncMessages updates target to the messages of src, like lupdate does:
+// translations of messages still in src are kept, new messages are added
+// unfinished, and translated messages no longer in src are kept as
+// vanished.
+func SyncMessages(src, target *File, lang string) {
+	old := make(map[string]*Message)
+	oldContexts := make(map[string]*Context)
+	for _, c := range target.Contexts {
+		oldContexts[c.Name] = c
+		for _, m := range c.Messages {
+			old[messageKey(c.Name, m)] = m
+		}
+	}
+
+	n := numerusCount(lang)
+	used := make(map[*Message]bool)
+	var contexts []*Context
+	byName := make(map[string]*Context)
+	context := func(name string) *Context {
+		c := byName[name]
+		if c == nil {
+			c = &Context{Name: name}
+			if oc := oldContexts[name]; oc != nil {
+				c.extra = oc.extra
+			}
+			contexts = append(contexts, c)
+			byName[name] = c
+		}
+		return c
+	}
+
+	for _, sc := range src.Contexts {
+		c := context(sc.Name)
+		for _, sm := range sc.Messages {
+			if !sm.active() {
+				continue
+			}
+			m, ok := old[messageKey(sc.Name, sm)]
+			if !ok {
+				c.Messages = append(c.Messages, emptyCopy(sm, n))
+				continue
+			}
+			used[m] = true
+			m.ID, m.Numerus, m.ExtraComment = sm.ID, sm.Numerus, sm.ExtraComment
+			m.Locations = append([]Location(nil), sm.Locations...)
+			if !m.active() {
+				m.Type = TypeUnfinished
+			}
+			if m.Numerus && len(m.NumerusForms) == 0 {
+				m.NumerusForms = make([]string, n)
+			}
+			c.Messages = append(c.Messages, m)
+		}
+	}
+
+	for _, oc := range target.Contexts {
+		for _, m := range oc.Messages {
+			if used[m] || !m.hasAnyText() {
+				continue
+			}
+			if m.active() {
+				m.Type = TypeVanished
+			}
+			m.Locations = nil
+			c := context(oc.Name)
+			c.Messages = append(c.Messages, m)
+		}
+	}
+
+	target.Contexts = nil
+	for _, c := range contexts {
+		if len(c.Messages) > 0 {
+			target.Contexts = append(target.Contexts, c)
+		}
+	}
+	if target.SourceLanguage == "" {
+		target.SourceLanguage = src.SourceLanguage
+	}
+	if target.Language == "" {
+		target.Language = Language(lang)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Gettext bridge
+// ---------------------------------------------------------------------------
+
+// POFile returns the active messages of f as a PO catalog for lang, so that
+// they can be translated and checked like gettext entries. Entries carry the
+// context (and disambiguation comment) as msgctxt, extra comments as
+// extracted comments and locations as references. Numerus messages become
+// plural entries whose msgid and msgid_plural are both the source.
+//
+// The entries stay linked to their messages: ApplyPO copies their
+// translations back into f.
+func (f *File) POFile(lang string) *po.File {
+	catalog := po.NewFile()
+	catalog.SetHeaderField("Language", lang)
+	catalog.SetHeaderField("Plural-Forms", po.PluralFormsForLang(lang))
+	n := numerusCount(lang)
+
+	refs := f.references()
+	f.entries = make(map[*po.Entry]*Message)
+	for _, c := range f.Contexts {
+		for _, m := range c.Messages {
+			if !m.active() {
+				continue
+			}
+			e := &po.Entry{
+				MsgCtxt:       c.Name,
+				MsgID:         m.Source,
+				PreviousMsgID: m.OldSource,
+				References:    refs[m],
+				Flags:         []string{"qt-format"},
+			}
+			if m.Comment != "" {
+				e.MsgCtxt += "|" + m.Comment
+			}
+			if m.ExtraComment != "" {
+				e.ExtractedComments = strings.Split(m.ExtraComment, "\n")
+			}
+			if m.TranslatorComment != "" {
+				e.TranslatorComments = strings.Split(m.TranslatorComment, "\n")
+			}
+			if m.Numerus {
+				e.MsgIDPlural = m.Source
+				e.Flags = []string{"qt-plural-format"}
+				e.MsgStrPlural = make(map[int]string, n)
+				for i := 0; i < max(n, len(m.NumerusForms)); i++ {
+					if i < len(m.NumerusForms) {
+						e.MsgStrPlural[i] = m.NumerusForms[i]
+					} else {
+						e.MsgStrPlural[i] = ""
+					}
+				}
+			} else {
+				e.MsgStr = m.Translation
+			}
+			if m.Type == TypeUnfinished && m.hasAnyText() {
+				e.SetFuzzy(true)
+			}
+			catalog.Entries = append(catalog.Entries, e)
+			f.entries[e] = m
+		}
+	}
+	return catalog
+}
+
+// ApplyPO copies the translations of a catalog returned by POFile back into
+// the messages of f. Fuzzy and incomplete entries become unfinished
+// translations.
+func (f *File) ApplyPO(catalog *po.File) {
+	for _, e := range catalog.Entries {
+		m, ok := f.entries[e]
+		if !ok {
+			continue
+		}
+		if m.Numerus {
+			forms := make([]string, len(e.MsgStrPlural))
+			for i := range forms {
+				forms[i] = e.MsgStrPlural[i]
+			}
+			m.NumerusForms = forms
+		} else {
+			m.Translation = e.MsgStr
+		}
+		m.OldSource = e.PreviousMsgID
+		m.TranslatorComment = strings.Join(e.TranslatorComments, "\n")
+		if e.IsTranslated() {
+			m.Type = ""
+		} else {
+			m.Type = TypeUnfinished
+		}
+	}
+}
+
+// references returns the locations of each message as "path:line"
+// references, resolving lupdate's relative locations. A relative location
+// omits the file name when it is unchanged and gives the line as an offset
+// from the previous line seen in that file, in document order.
+func (f *File) references() map[*Message][]string {
+	refs := make(map[*Message][]string)
+	currentFile := ""
+	currentLine := make(map[string]int)
+	for _, c := range f.Contexts {
+		for _, m := range c.Messages {
+			for _, l := range m.Locations {
+				if l.Filename != "" {
+					currentFile = l.Filename
+				}
+				if currentFile == "" {
+					continue
+				}
+				line, err := strconv.Atoi(l.Line)
+				if err != nil {
+					refs[m] = append(refs[m], currentFile)
+					continue
+				}
+				if strings.HasPrefix(l.Line, "+") || strings.HasPrefix(l.Line, "-") {
+					line += currentLine[currentFile]
+				}
+				currentLine[currentFile] = line
+				refs[m] = append(refs[m], fmt.Sprintf("%s:%d", currentFile, line))
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,232 @@
+package qt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tsSource = `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE TS>
+<TS version="2.1" language="de_DE" sourcelanguage="en">
+<context>
+    <name>MainWindow</name>
+    <message>
+        <location filename="../src/mainwindow.ui" line="14"/>
+        <source>&amp;Open &quot;%1&quot;</source>
+        <translation>&amp;Öffnen „%1“</translation>
+    </message>
+    <message numerus="yes">
+        <location filename="../src/mainwindow.cpp" line="42"/>
+        <location line="+8"/>
+        <source>%n file(s) selected</source>
+        <extracomment>Status bar message</extracomment>
+        <translation type="unfinished">
+            <numerusform>%n Datei ausgewählt</numerusform>
+            <numerusform></numerusform>
+        </translation>
+    </message>
+    <message>
+        <location filename="../src/mainwindow.cpp" line="+5"/>
+        <source>Open</source>
+        <comment>adjective</comment>
+        <translation type="unfinished"></translation>
+    </message>
+    <message>
+        <source>Gone</source>
+        <translation type="vanished">Weg</translation>
+    </message>
+</context>
+<context>
+    <name>Settings</name>
+    <message id="settings.title">
+        <location filename="../qml/Settings.qml" line="7"/>
+        <source>Settings</source>
+        <translatorcomment>Keep it short</translatorcomment>
+        <translation type="unfinished">Einstellungen</translation>
+        <userdata>custom</userdata>
+    </message>
+</context>
+</TS>
+`
+
+func TestParse(t *testing.T) {
+	f, err := Parse([]byte(tsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Language != "de_DE" || f.SourceLanguage != "en" || len(f.Contexts) != 2 {
+		t.Fatalf("Parse() = %+v", f)
+	}
+	msgs := f.Messages()
+	if len(msgs) != 4 {
+		t.Fatalf("Messages() = %d, want 4", len(msgs))
+	}
+	if msgs[0].Source != `&Open "%1"` || msgs[0].Translation != "&Öffnen „%1“" || !msgs[0].IsTranslated() {
+		t.Errorf("message 0 = %+v", msgs[0])
+	}
+	if !msgs[1].Numerus || !reflect.DeepEqual(msgs[1].NumerusForms, []string{"%n Datei ausgewählt", ""}) {
+		t.Errorf("message 1 = %+v", msgs[1])
+	}
+	if msgs[2].Comment != "adjective" || msgs[3].ID != "settings.title" || msgs[3].TranslatorComment != "Keep it short" {
+		t.Errorf("messages 2, 3 = %+v, %+v", msgs[2], msgs[3])
+	}
+	if total, translated, unfinished, untranslated := f.Stats(); total != 4 || translated != 1 || unfinished != 2 || untranslated != 1 {
+		t.Errorf("Stats() = %d, %d, %d, %d; want 4, 1, 2, 1", total, translated, unfinished, untranslated)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	f, err := Parse([]byte(tsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != tsSource {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, tsSource)
+	}
+}
+
+func TestPOFile(t *testing.T) {
+	f, err := Parse([]byte(tsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog := f.POFile("de")
+	if len(catalog.Entries) != 4 {
+		t.Fatalf("POFile() has %d entries, want 4", len(catalog.Entries))
+	}
+	plural := catalog.Entries[1]
+	if plural.MsgIDPlural != plural.MsgID || !plural.HasFlag("qt-plural-format") || !plural.IsFuzzy() {
+		t.Errorf("plural entry = %+v", plural)
+	}
+	// Relative locations are resolved per file.
+	if want := []string{"../src/mainwindow.cpp:42", "../src/mainwindow.cpp:50"}; !reflect.DeepEqual(plural.References, want) {
+		t.Errorf("References = %q, want %q", plural.References, want)
+	}
+	if got := catalog.Entries[2].References; !reflect.DeepEqual(got, []string{"../src/mainwindow.cpp:55"}) {
+		t.Errorf("References = %q", got)
+	}
+	if e := catalog.Entries[2]; e.MsgCtxt != "MainWindow|adjective" || !e.HasFlag("qt-format") || e.IsFuzzy() {
+		t.Errorf("disambiguated entry = %+v", e)
+	}
+	if got := catalog.Entries[1].ExtractedComments; !reflect.DeepEqual(got, []string{"Status bar message"}) {
+		t.Errorf("ExtractedComments = %q", got)
+	}
+
+	plural.MsgStrPlural[1] = "%n Dateien ausgewählt"
+	plural.SetFuzzy(false)
+	catalog.Entries[2].MsgStr = "Offen"
+	f.ApplyPO(catalog)
+
+	msgs := f.Messages()
+	if !msgs[1].IsTranslated() || msgs[1].NumerusForms[1] != "%n Dateien ausgewählt" {
+		t.Errorf("plural message = %+v", msgs[1])
+	}
+	if !msgs[2].IsTranslated() || msgs[2].Translation != "Offen" {
+		t.Errorf("message = %+v", msgs[2])
+	}
+	// Untouched fuzzy entries stay unfinished.
+	if msgs[3].Type != TypeUnfinished {
+		t.Errorf("Type = %q, want unfinished", msgs[3].Type)
+	}
+	data, _ := f.Marshal()
+	if !strings.Contains(string(data), "        <translation>\n            <numerusform>%n Datei ausgewählt</numerusform>\n            <numerusform>%n Dateien ausgewählt</numerusform>\n        </translation>\n") {
+		t.Errorf("Marshal() =\n%s", data)
+	}
+}
+
+func TestNewTranslationFile(t *testing.T) {
+	src, err := Parse([]byte(tsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTranslationFile(src, "pt-BR")
+	if f.Language != "pt_BR" {
+		t.Errorf("Language = %q", f.Language)
+	}
+	msgs := f.Messages()
+	if len(msgs) != 4 || len(f.Contexts[0].Messages) != 3 {
+		t.Fatalf("NewTranslationFile() = %+v", f)
+	}
+	for _, m := range msgs {
+		if m.Type != TypeUnfinished || m.hasAnyText() || m.TranslatorComment != "" {
+			t.Errorf("message not cleared: %+v", m)
+		}
+	}
+	if got := len(msgs[1].NumerusForms); got != 2 {
+		t.Errorf("numerus forms = %d, want 2", got)
+	}
+	if got := len(NewTranslationFile(src, "ru").Messages()[1].NumerusForms); got != 3 {
+		t.Errorf("ru numerus forms = %d, want 3", got)
+	}
+}
+
+func TestSyncMessages(t *testing.T) {
+	src, err := Parse([]byte(`<TS version="2.1" sourcelanguage="en">
+<context>
+    <name>MainWindow</name>
+    <message>
+        <location filename="../src/mainwindow.ui" line="20"/>
+        <source>&amp;Open &quot;%1&quot;</source>
+        <translation type="unfinished"></translation>
+    </message>
+    <message>
+        <source>New</source>
+        <translation type="unfinished"></translation>
+    </message>
+    <message>
+        <source>Gone</source>
+        <translation type="unfinished"></translation>
+    </message>
+</context>
+</TS>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := Parse([]byte(tsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SyncMessages(src, target, "de")
+
+	var got []string
+	for _, c := range target.Contexts {
+		for _, m := range c.Messages {
+			got = append(got, c.Name+"/"+m.Source+"/"+m.Type)
+		}
+	}
+	want := []string{
+		`MainWindow/&Open "%1"/`,
+		"MainWindow/New/unfinished",
+		// A vanished message that is back needs a review.
+		"MainWindow/Gone/unfinished",
+		// Translated messages no longer in the source are kept as vanished.
+		"MainWindow/%n file(s) selected/vanished",
+		"Settings/Settings/vanished",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("messages = %q, want %q", got, want)
+	}
+	if l := target.Contexts[0].Messages[0].Locations; len(l) != 1 || l[0].Line != "20" {
+		t.Errorf("locations = %+v", l)
+	}
+	if total, _, _, _ := target.Stats(); total != 3 {
+		t.Errorf("total = %d, want 3", total)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, data := range []string{
+		`<xliff version="1.2"></xliff>`,
+		`<TS><context>`,
+		`not xml`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", data)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package xcstrings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// object is a JSON object that keeps the order of its keys, so that a
+// catalog is written back with the layout it was read with.
+type object struct {
+	keys []string
+	vals map[string]any
+}
+
+func newObject() *object {
+	return &object{vals: make(map[string]any)}
+}
+
+// The getters accept a nil object, so that paths can be followed without
+// checking each step.
+
+func (o *object) get(key string) (any, bool) {
+	if o == nil {
+		return nil, false
+	}
+	v, ok := o.vals[key]
+	return v, ok
+}
+
+// object returns the value of key if it is an object.
+func (o *object) object(key string) *object {
+	v, _ := o.get(key)
+	obj, _ := v.(*object)
+	return obj
+}
+
+func (o *object) str(key string) string {
+	v, _ := o.get(key)
+	s, _ := v.(string)
+	return s
+}
+
+// set sets key to v. A new key is inserted before the first key that
+// sorts after it, which keeps the sorted order Xcode writes.
+func (o *object) set(key string, v any) {
+	if _, ok := o.vals[key]; !ok {
+		i := len(o.keys)
+		for j, k := range o.keys {
+			if k > key {
+				i = j
+				break
+			}
+		}
+		o.keys = append(o.keys, "")
+		copy(o.keys[i+1:], o.keys[i:])
+		o.keys[i] = key
+	}
+	o.vals[key] = v
+}
+
+// child returns the object at key, creating it if it does not exist.
+func (o *object) child(key string) *object {
+	if c := o.object(key); c != nil {
+		return c
+	}
+	c := newObject()
+	o.set(key, c)
+	return c
+}
+
+// clone returns a deep copy of o.
+func (o *object) clone() *object {
+	cp := &object{keys: append([]string(nil), o.keys...), vals: make(map[string]any, len(o.vals))}
+	for k, v := range o.vals {
+		cp.vals[k] = cloneValue(v)
+	}
+	return cp
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case *object:
+		return v.clone()
+	case []any:
+		cp := make([]any, len(v))
+		for i, e := range v {
+			cp[i] = cloneValue(e)
+		}
+		return cp
+	}
+	return v
+}
+
+// ---------------------------------------------------------------------------
+// Decoding
+// ---------------------------------------------------------------------------
+
+// decodeJSON decodes a JSON document into objects, []any, string,
+// json.Number, bool and nil values.
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			o := newObject()
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				v, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, dup := o.vals[key]; !dup {
+					o.keys = append(o.keys, key)
+				}
+				o.vals[key] = v
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return o, nil
+		case '[':
+			arr := []any{}
+			for dec.More() {
+				v, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("unexpected %v", t)
+	default:
+		return t, nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Encoding
+// ---------------------------------------------------------------------------
+
+// encodeJSON writes v the way Xcode writes string catalogs: two-space
+// indentation, " : " between keys and values, and empty objects written
+// across two lines.
+func encodeJSON(b *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case *object:
+		b.WriteString("{\n")
+		for i, k := range v.keys {
+			writeIndent(b, indent+1)
+			writeString(b, k)
+			b.WriteString(" : ")
+			encodeJSON(b, v.vals[k], indent+1)
+			if i < len(v.keys)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		if len(v.keys) == 0 {
+			b.WriteByte('\n')
+		}
+		writeIndent(b, indent)
+		b.WriteByte('}')
+	case []any:
+		b.WriteString("[\n")
+		for i, e := range v {
+			writeIndent(b, indent+1)
+			encodeJSON(b, e, indent+1)
+			if i < len(v)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		if len(v) == 0 {
+			b.WriteByte('\n')
+		}
+		writeIndent(b, indent)
+		b.WriteByte(']')
+	case string:
+		writeString(b, v)
+	case json.Number:
+		b.WriteString(v.String())
+	case bool:
+		if v {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case nil:
+		b.WriteString("null")
+	default:
+		panic(fmt.Sprintf("xcstrings: cannot encode %T", v))
+	}
+}
+
+func writeIndent(b *strings.Builder, n int) {
+	for i := 0; i < n; i++ {
+		b.WriteString("  ")
+	}
+}
+
+// writeString writes s as a JSON string. Only quotes, backslashes and
+// control characters are escaped.
+func writeString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
@@ -0,0 +1,464 @@
+// Package xcstrings reads and writes Xcode String Catalogs (.xcstrings).
+//
+// A catalog is a JSON file holding the strings of every language of an
+// app. Each entry has a localization per language: a stringUnit with a
+// value and a state ("new", "translated" or "needs_review"), variations
+// by plural category or device, and substitutions for the %#@name@
+// variables of its string.
+//
+// A File is the view of a catalog for one language. Each string unit of
+// an entry is a key: the entry key for its main string, followed by
+// "#name" for each variation or substitution on the way to the unit, e.g.
+// "%lld files#one", "Photos#iphone" or "%#@count@ selected#count#few".
+package xcstrings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/minios-linux/lokit/langmeta"
+)
+
+// States of a stringUnit.
+const (
+	StateNew         = "new"
+	StateTranslated  = "translated"
+	StateNeedsReview = "needs_review"
+)
+
+// File is the view of a string catalog for one language.
+type File struct {
+	root       *object
+	strings    *object
+	sourceLang string
+	lang       string
+	// trailingNewline records whether the catalog ended with a newline.
+	trailingNewline bool
+
+	units        []*unit
+	byID         map[string]*unit
+	sourceValues map[string]string
+	// verbatim lists the strings of each entry that are copied from the
+	// source rather than translated: format strings without text.
+	verbatim map[string][]*unit
+}
+
+// unit is a string unit of an entry.
+type unit struct {
+	id    string
+	entry string
+	// path leads from a localization to the object holding the
+	// stringUnit, e.g. ["variations", "plural", "one"].
+	path []string
+	// category is the plural category of a plural variation.
+	category string
+	source   string
+}
+
+// ParseFile reads the catalog at path as seen by lang.
+func ParseFile(path, lang string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	f, err := Parse(data, lang)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse parses a catalog as seen by lang. The source language is taken
+// from the catalog's sourceLanguage.
+func Parse(data []byte, lang string) (*File, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := v.(*object)
+	if !ok {
+		return nil, errors.New("string catalog is not a JSON object")
+	}
+	f := &File{
+		root:            root,
+		sourceLang:      root.str("sourceLanguage"),
+		lang:            lang,
+		trailingNewline: strings.HasSuffix(string(data), "\n"),
+	}
+	if f.sourceLang == "" {
+		return nil, errors.New(`missing "sourceLanguage"`)
+	}
+	if _, ok := root.get("strings"); ok {
+		if f.strings = root.object("strings"); f.strings == nil {
+			return nil, errors.New(`"strings" is not an object`)
+		}
+	} else {
+		f.strings = root.child("strings")
+	}
+	f.collectUnits()
+	return f, nil
+}
+
+// SourceLanguage returns the catalog's source language.
+func (f *File) SourceLanguage() string {
+	return f.sourceLang
+}
+
+// Languages returns the languages with localizations in the catalog, in
+// order of first appearance.
+func (f *File) Languages() []string {
+	var langs []string
+	seen := make(map[string]bool)
+	for _, key := range f.strings.keys {
+		locs := f.strings.object(key).object("localizations")
+		if locs == nil {
+			continue
+		}
+		for _, lang := range locs.keys {
+			if !seen[lang] {
+				seen[lang] = true
+				langs = append(langs, lang)
+			}
+		}
+	}
+	return langs
+}
+
+// localization returns the localization of entry for lang, or nil.
+func (f *File) localization(entry, lang string) *object {
+	return f.strings.object(entry).object("localizations").object(lang)
+}
+
+// translatable reports whether an entry is sent for translation: it is
+// not marked shouldTranslate: false and is still used by the code.
+func translatable(e *object) bool {
+	if v, ok := e.get("shouldTranslate"); ok && v == false {
+		return false
+	}
+	return e.str("extractionState") != "stale"
+}
+
+// collectUnits builds the units of the view's language from the source
+// localizations.
+func (f *File) collectUnits() {
+	f.units = nil
+	f.byID = make(map[string]*unit)
+	f.sourceValues = make(map[string]string)
+	f.verbatim = make(map[string][]*unit)
+	for _, key := range f.strings.keys {
+		e := f.strings.object(key)
+		if e == nil || !translatable(e) {
+			continue
+		}
+		src := e.object("localizations").object(f.sourceLang)
+		if src == nil {
+			// Without a source localization the key is the source text.
+			src = newObject()
+			su := src.child("stringUnit")
+			su.set("state", StateTranslated)
+			su.set("value", key)
+		}
+		walk(src, key, nil, "", "", func(u *unit, node *object) {
+			f.sourceValues[u.id] = node.object("stringUnit").str("value")
+		})
+		walk(src, key, nil, "", f.lang, func(u *unit, node *object) {
+			u.entry = key
+			u.source = node.object("stringUnit").str("value")
+			if node.object("substitutions") != nil && !hasText(u.source) {
+				f.verbatim[key] = append(f.verbatim[key], u)
+				delete(f.sourceValues, u.id)
+				return
+			}
+			if u.source == "" {
+				return
+			}
+			f.units = append(f.units, u)
+			f.byID[u.id] = u
+		})
+	}
+}
+
+// walk calls visit for each string unit under node, a source
+// localization or one of its variations. Plural variations are expanded
+// to the categories of lang; a category missing in the source takes the
+// structure of its "other" variation. With an empty lang the source
+// categories are kept.
+func walk(node *object, id string, path []string, category, lang string, visit func(u *unit, node *object)) {
+	if node.object("stringUnit") != nil {
+		visit(&unit{id: id, path: path, category: category}, node)
+	}
+	if vars := node.object("variations"); vars != nil {
+		for _, kind := range vars.keys {
+			cases := vars.object(kind)
+			if cases == nil {
+				continue
+			}
+			names := cases.keys
+			if kind == "plural" && lang != "" {
+				names = pluralCases(cases, lang)
+			}
+			for _, name := range names {
+				c := cases.object(name)
+				if c == nil {
+					c = cases.object(langmeta.PluralOther)
+				}
+				if c == nil {
+					continue
+				}
+				cat := ""
+				if kind == "plural" {
+					cat = name
+				}
+				walk(c, id+"#"+name, appendPath(path, "variations", kind, name), cat, lang, visit)
+			}
+		}
+	}
+	if subs := node.object("substitutions"); subs != nil {
+		for _, name := range subs.keys {
+			if s := subs.object(name); s != nil {
+				walk(s, id+"#"+name, appendPath(path, "substitutions", name), "", lang, visit)
+			}
+		}
+	}
+}
+
+func appendPath(path []string, elems ...string) []string {
+	return append(path[:len(path):len(path)], elems...)
+}
+
+// pluralCases returns the plural categories of lang in CLDR order. A
+// "zero" case of the source, an explicit case for 0 in every language,
+// is kept.
+func pluralCases(cases *object, lang string) []string {
+	want := make(map[string]bool)
+	for _, cat := range langmeta.PluralCategories(lang) {
+		want[cat] = true
+	}
+	if cases.object(langmeta.PluralZero) != nil {
+		want[langmeta.PluralZero] = true
+	}
+	var names []string
+	for _, cat := range langmeta.PluralCategoryOrder {
+		if want[cat] {
+			names = append(names, cat)
+		}
+	}
+	return names
+}
+
+// formatSpecifier matches the format specifiers of localized strings,
+// including %#@variable@ references.
+var formatSpecifier = regexp.MustCompile(`%(?:[1-9][0-9]*\$)?(?:#@[^@]*@|[-+ #0']*[0-9]*(?:\.[0-9]+)?(?:hh|h|ll|l|q|L|z|t|j)?[@%a-zA-Z])`)
+
+// hasText reports whether s contains letters outside its format
+// specifiers.
+func hasText(s string) bool {
+	return strings.IndexFunc(formatSpecifier.ReplaceAllString(s, ""), unicode.IsLetter) >= 0
+}
+
+// ---------------------------------------------------------------------------
+// Key-value access
+// ---------------------------------------------------------------------------
+
+// Keys returns the keys of the view's language in catalog order.
+func (f *File) Keys() []string {
+	keys := make([]string, len(f.units))
+	for i, u := range f.units {
+		keys[i] = u.id
+	}
+	return keys
+}
+
+// UntranslatedKeys returns the keys without a translation. Units in state
+// "new" count as untranslated.
+func (f *File) UntranslatedKeys() []string {
+	var keys []string
+	for _, u := range f.units {
+		if v, _ := f.Get(u.id); v == "" {
+			keys = append(keys, u.id)
+		}
+	}
+	return keys
+}
+
+// Get returns the translation of key. A unit in state "new" has no
+// translation yet.
+func (f *File) Get(key string) (string, bool) {
+	u := f.byID[key]
+	if u == nil {
+		return "", false
+	}
+	su := f.stringUnit(u, f.lang)
+	if su == nil || su.str("state") == StateNew {
+		return "", true
+	}
+	return su.str("value"), true
+}
+
+// State returns the state of the unit of key, or "" if it has none.
+func (f *File) State(key string) string {
+	if u := f.byID[key]; u != nil {
+		return f.stringUnit(u, f.lang).str("state")
+	}
+	return ""
+}
+
+func (f *File) stringUnit(u *unit, lang string) *object {
+	node := f.localization(u.entry, lang)
+	for _, name := range u.path {
+		node = node.object(name)
+	}
+	return node.object("stringUnit")
+}
+
+// Set stores a translation in state "needs_review", so that it is
+// reviewed in Xcode. Missing localizations, variations and substitutions
+// are created from the source.
+func (f *File) Set(key, value string) bool {
+	return f.SetState(key, value, StateNeedsReview)
+}
+
+// SetState stores a translation with the given state.
+func (f *File) SetState(key, value, state string) bool {
+	u := f.byID[key]
+	if u == nil {
+		return false
+	}
+	f.setUnit(u, value, state)
+	for _, v := range f.verbatim[u.entry] {
+		if su := f.stringUnit(v, f.lang); su == nil || su.str("value") == "" {
+			f.setUnit(v, v.source, StateTranslated)
+		}
+	}
+	return true
+}
+
+func (f *File) setUnit(u *unit, value, state string) {
+	entry := f.strings.child(u.entry)
+	node := entry.child("localizations").child(f.lang)
+	src := f.localization(u.entry, f.sourceLang)
+	for i, name := range u.path {
+		node = node.child(name)
+		next := src.object(name)
+		if next == nil && i > 0 && u.path[i-1] == "plural" {
+			next = src.object(langmeta.PluralOther)
+		}
+		src = next
+		// Copy scalar fields such as argNum and formatSpecifier of
+		// substitutions.
+		if src != nil {
+			for _, k := range src.keys {
+				if _, isObj := src.vals[k].(*object); !isObj {
+					if _, ok := node.get(k); !ok {
+						node.set(k, cloneValue(src.vals[k]))
+					}
+				}
+			}
+		}
+	}
+	su := node.child("stringUnit")
+	su.set("state", state)
+	su.set("value", value)
+}
+
+// Stats returns the number of units, of translated units and the
+// percentage translated. Units in state "needs_review" count as
+// translated.
+func (f *File) Stats() (int, int, float64) {
+	total := len(f.units)
+	translated := total - len(f.UntranslatedKeys())
+	pct := 0.0
+	if total > 0 {
+		pct = float64(translated) / float64(total) * 100
+	}
+	return total, translated, pct
+}
+
+// SourceValues returns the source-language text of each unit, keyed as
+// in the source language.
+func (f *File) SourceValues() map[string]string {
+	m := make(map[string]string, len(f.sourceValues))
+	for k, v := range f.sourceValues {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// Notes returns the comment of each entry for its units.
+func (f *File) Notes() map[string]string {
+	notes := make(map[string]string)
+	for _, u := range f.units {
+		if c := f.strings.object(u.entry).str("comment"); c != "" {
+			notes[u.id] = c
+		}
+	}
+	return notes
+}
+
+// PluralKey reports the plural unit and CLDR category of key.
+func (f *File) PluralKey(key string) (unit, category string, ok bool) {
+	u := f.byID[key]
+	if u == nil || u.category == "" {
+		return "", "", false
+	}
+	return strings.TrimSuffix(key, "#"+u.category), u.category, true
+}
+
+// PluralFormKey returns the key holding category of unit.
+func (f *File) PluralFormKey(unit, category string) string {
+	return unit + "#" + category
+}
+
+// ---------------------------------------------------------------------------
+// Serialization
+// ---------------------------------------------------------------------------
+
+// Marshal returns the catalog as JSON laid out like Xcode writes it.
+func (f *File) Marshal() ([]byte, error) {
+	var b strings.Builder
+	encodeJSON(&b, f.root, 0)
+	if f.trailingNewline {
+		b.WriteByte('\n')
+	}
+	return []byte(b.String()), nil
+}
+
+// WriteFile writes the catalog to path. The localizations of the view's
+// language are merged into the catalog on disk, so that the views of
+// other languages saved in the meantime are kept.
+func (f *File) WriteFile(path string) error {
+	out := f
+	if data, err := os.ReadFile(path); err == nil {
+		cur, err := Parse(data, f.lang)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		for _, key := range f.strings.keys {
+			loc := f.localization(key, f.lang)
+			e := cur.strings.object(key)
+			if loc == nil || e == nil {
+				continue
+			}
+			e.child("localizations").set(f.lang, loc.clone())
+		}
+		out = cur
+	}
+	data, err := out.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package xcstrings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const catalog = `{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "%lld files selected" : {
+      "localizations" : {
+        "en" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "%#@count@"
+          },
+          "substitutions" : {
+            "count" : {
+              "argNum" : 1,
+              "formatSpecifier" : "lld",
+              "variations" : {
+                "plural" : {
+                  "one" : {
+                    "stringUnit" : {
+                      "state" : "translated",
+                      "value" : "%arg file"
+                    }
+                  },
+                  "other" : {
+                    "stringUnit" : {
+                      "state" : "translated",
+                      "value" : "%arg files"
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "%lld items" : {
+      "comment" : "Number of items in the cart",
+      "localizations" : {
+        "de" : {
+          "variations" : {
+            "plural" : {
+              "one" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld Artikel"
+                }
+              },
+              "other" : {
+                "stringUnit" : {
+                  "state" : "new",
+                  "value" : "%lld items"
+                }
+              }
+            }
+          }
+        },
+        "en" : {
+          "variations" : {
+            "plural" : {
+              "one" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld item"
+                }
+              },
+              "other" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld items"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "CFBundleName" : {
+      "shouldTranslate" : false
+    },
+    "Hello" : {
+      "localizations" : {
+        "de" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "Hallo"
+          }
+        }
+      }
+    },
+    "Old" : {
+      "extractionState" : "stale"
+    },
+    "Tap to open" : {
+      "localizations" : {
+        "en" : {
+          "variations" : {
+            "device" : {
+              "mac" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "Click to open"
+                }
+              },
+              "other" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "Tap to open"
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "version" : "1.0"
+}
+`
+
+func TestParse(t *testing.T) {
+	f, err := Parse([]byte(catalog), "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"%lld files selected#count#one", "%lld files selected#count#other",
+		"%lld items#one", "%lld items#other",
+		"Hello",
+		"Tap to open#mac", "Tap to open#other",
+	}
+	if got := f.Keys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() = %q, want %q", got, want)
+	}
+	// "new" units are untranslated.
+	if got, want := f.UntranslatedKeys(), []string{want[0], want[1], want[3], want[5], want[6]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UntranslatedKeys() = %q, want %q", got, want)
+	}
+	src := f.SourceValues()
+	if src["Hello"] != "Hello" || src["Tap to open#mac"] != "Click to open" || src["%lld items#one"] != "%lld item" {
+		t.Errorf("SourceValues() = %q", src)
+	}
+	if _, ok := src["%lld files selected"]; ok {
+		t.Error("format string without text is a source value")
+	}
+	if got := f.Notes()["%lld items#other"]; got != "Number of items in the cart" {
+		t.Errorf("note = %q", got)
+	}
+	if unit, cat, ok := f.PluralKey("%lld files selected#count#other"); !ok || unit != "%lld files selected#count" || cat != "other" {
+		t.Errorf("PluralKey() = %q, %q, %v", unit, cat, ok)
+	}
+	if _, _, ok := f.PluralKey("Tap to open#other"); ok {
+		t.Error("device variation reported as plural")
+	}
+	if langs := f.Languages(); !reflect.DeepEqual(langs, []string{"en", "de"}) {
+		t.Errorf("Languages() = %q", langs)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	f, err := Parse([]byte(catalog), "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != catalog {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, catalog)
+	}
+}
+
+func TestSet_CreatesLocalization(t *testing.T) {
+	f, err := Parse([]byte(catalog), "ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := f.Keys()
+	if len(keys) != 11 || keys[2] != "%lld files selected#count#many" {
+		t.Fatalf("Keys() = %q", keys)
+	}
+	if !f.Set("%lld files selected#count#few", "%arg файла") || f.Set("%lld files selected#count#two", "x") {
+		t.Fatal("Set() accepted the wrong categories")
+	}
+	f.Set("Tap to open#mac", "Нажмите, чтобы открыть")
+
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		// The format string is copied, substitution fields are kept.
+		`"ru" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "%#@count@"
+          },
+          "substitutions" : {
+            "count" : {
+              "argNum" : 1,
+              "formatSpecifier" : "lld",
+              "variations" : {
+                "plural" : {
+                  "few" : {
+                    "stringUnit" : {
+                      "state" : "needs_review",
+                      "value" : "%arg файла"
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }`,
+		`"ru" : {
+          "variations" : {
+            "device" : {
+              "mac" : {
+                "stringUnit" : {
+                  "state" : "needs_review",
+                  "value" : "Нажмите, чтобы открыть"
+                }
+              }
+            }
+          }
+        }`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("Marshal() lacks\n%s\nin\n%s", s, data)
+		}
+	}
+	if total, translated, _ := f.Stats(); total != 11 || translated != 2 {
+		t.Errorf("Stats() = %d, %d; want 11, 2", total, translated)
+	}
+	if got := f.State("Tap to open#mac"); got != StateNeedsReview {
+		t.Errorf("State() = %q", got)
+	}
+}
+
+func TestWriteFile_KeepsOtherLanguages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Localizable.xcstrings")
+	if err := os.WriteFile(path, []byte(catalog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Both views are read before either is written, as in a translation
+	// run over several languages.
+	fr, err := ParseFile(path, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ja, err := ParseFile(path, "ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr.Set("Hello", "Bonjour")
+	ja.Set("Hello", "こんにちは")
+	if err := fr.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ja.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for lang, want := range map[string]string{"de": "Hallo", "fr": "Bonjour", "ja": "こんにちは"} {
+		f, err := ParseFile(path, lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := f.Get("Hello"); got != want {
+			t.Errorf("%s: Hello = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, data := range []string{
+		`[]`,
+		`{"strings" : {}}`,
+		`{"sourceLanguage" : "en", "strings" : []}`,
+		`{"sourceLanguage" : "en"`,
+		`{"sourceLanguage" : "en"} {}`,
+	} {
+		if _, err := Parse([]byte(data), "de"); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", data)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+// Package xliff implements reading and writing of XLIFF 1.2 and 2.0
+// documents, the exchange format of most CAT tools and translation agencies.
+//
+// A Document holds the units of one target language. Every <file> element
+// corresponds to one translation file of a lokit target (identified by its
+// "original" attribute, a path relative to the project root), and every unit
+// to one translatable key of that file:
+//
+//	<file original="po/ru.po" source-language="en" target-language="ru">
+//	  <body>
+//	    <trans-unit id="u1" resname="Open file">
+//	      <source>Open file</source>
+//	      <target state="translated">Открыть файл</target>
+//	    </trans-unit>
+//	  </body>
+//	</file>
+//
+// Inline markup elements (<g>, <x/>, <ph>, <pc>, ...) are not supported:
+// texts are exchanged as plain strings, so HTML or placeholders stay escaped
+// character data. Parse rejects units that contain inline elements instead
+// of silently dropping them.
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Supported XLIFF versions.
+const (
+	Version12 = "1.2"
+	Version20 = "2.0"
+)
+
+const (
+	namespace12 = "urn:oasis:names:tc:xliff:document:1.2"
+	namespace20 = "urn:oasis:names:tc:xliff:document:2.0"
+)
+
+// ---------------------------------------------------------------------------
+// Document model
+// ---------------------------------------------------------------------------
+
+// State is the translation state of a unit, normalized across XLIFF
+// versions.
+type State string
+
+const (
+	// StateNew marks units without a translation.
+	StateNew State = "new"
+	// StateNeedsReview marks translations that still need a review, such as
+	// machine translations and fuzzy gettext entries.
+	StateNeedsReview State = "needs-review"
+	// StateTranslated marks accepted translations.
+	StateTranslated State = "translated"
+	// StateFinal marks translations signed off by the translator.
+	StateFinal State = "final"
+)
+
+// Unit is one translatable string.
+type Unit struct {
+	// ID identifies the unit within its file. Units without an ID are
+	// numbered when the document is marshaled.
+	ID string
+	// Name is the key of the string in the lokit target (resname in XLIFF
+	// 1.2, name in XLIFF 2.0).
+	Name   string
+	Source string
+	Target string
+	State  State
+	Notes  []string
+}
+
+// File is one translation file of a document.
+type File struct {
+	// Original is the translation file path, relative to the project root.
+	Original string
+	Units    []Unit
+}
+
+// Document is an XLIFF document with the units of one target language.
+type Document struct {
+	// Version is Version12 or Version20.
+	Version    string
+	SourceLang string
+	TargetLang string
+	Files      []File
+}
+
+// ---------------------------------------------------------------------------
+// XLIFF 1.2 elements
+// ---------------------------------------------------------------------------
+
+type xliff12 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string   `xml:"version,attr"`
+	Files   []file12 `xml:"file"`
+}
+
+type file12 struct {
+	Original   string  `xml:"original,attr"`
+	SourceLang string  `xml:"source-language,attr"`
+	TargetLang string  `xml:"target-language,attr,omitempty"`
+	Datatype   string  `xml:"datatype,attr"`
+	Body       group12 `xml:"body"`
+}
+
+type group12 struct {
+	Units  []unit12  `xml:"trans-unit"`
+	Groups []group12 `xml:"group"`
+}
+
+type unit12 struct {
+	ID      string `xml:"id,attr"`
+	Resname string `xml:"resname,attr,omitempty"`
+	Space   string `xml:"http://www.w3.org/XML/1998/namespace space,attr,omitempty"`
+	Source  text   `xml:"source"`
+	Target  *text  `xml:"target"`
+	Notes   []text `xml:"note"`
+}
+
+// text is a source, target or note element. Inline child elements are
+// collected only to be rejected.
+type text struct {
+	State  string    `xml:"state,attr,omitempty"`
+	Value  string    `xml:",chardata"`
+	Inline []xmlNode `xml:",any"`
+}
+
+type xmlNode struct {
+	XMLName xml.Name
+}
+
+// ---------------------------------------------------------------------------
+// XLIFF 2.0 elements
+// ---------------------------------------------------------------------------
+
+type xliff20 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string   `xml:"version,attr"`
+	SrcLang string   `xml:"srcLang,attr"`
+	TrgLang string   `xml:"trgLang,attr,omitempty"`
+	Files   []file20 `xml:"file"`
+}
+
+type file20 struct {
+	ID       string    `xml:"id,attr"`
+	Original string    `xml:"original,attr,omitempty"`
+	Units    []unit20  `xml:"unit"`
+	Groups   []group20 `xml:"group"`
+}
+
+type group20 struct {
+	Units  []unit20  `xml:"unit"`
+	Groups []group20 `xml:"group"`
+}
+
+type unit20 struct {
+	ID       string      `xml:"id,attr"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Notes    *notes20    `xml:"notes"`
+	Segments []segment20 `xml:"segment"`
+}
+
+type notes20 struct {
+	Notes []text `xml:"note"`
+}
+
+type segment20 struct {
+	State  string `xml:"state,attr,omitempty"`
+	Space  string `xml:"http://www.w3.org/XML/1998/namespace space,attr,omitempty"`
+	Source text   `xml:"source"`
+	Target *text  `xml:"target"`
+}
+
+// ---------------------------------------------------------------------------
+// State mapping
+// ---------------------------------------------------------------------------
+
+// state12 returns the XLIFF 1.2 target state of s.
+func state12(s State) string {
+	switch s {
+	case StateNeedsReview:
+		return "needs-review-translation"
+	case StateTranslated:
+		return "translated"
+	case StateFinal:
+		return "final"
+	default:
+		return "needs-translation"
+	}
+}
+
+// parseState12 normalizes an XLIFF 1.2 target state. The "needs-*" and
+// "new" states of a non-empty target mean it still needs a review.
+func parseState12(state, target string) State {
+	if target == "" {
+		return StateNew
+	}
+	switch state {
+	case "", "translated":
+		return StateTranslated
+	case "final", "signed-off":
+		return StateFinal
+	default:
+		return StateNeedsReview
+	}
+}
+
+// state20 returns the XLIFF 2.0 segment state of s. XLIFF 2.0 has no
+// "needs review" state: pending translations are "translated" and accepted
+// ones "reviewed".
+func state20(s State) string {
+	switch s {
+	case StateNeedsReview:
+		return "translated"
+	case StateTranslated:
+		return "reviewed"
+	case StateFinal:
+		return "final"
+	default:
+		return "initial"
+	}
+}
+
+// parseState20 normalizes an XLIFF 2.0 segment state.
+func parseState20(state, target string) State {
+	if target == "" {
+		return StateNew
+	}
+	switch state {
+	case "reviewed":
+		return StateTranslated
+	case "final":
+		return StateFinal
+	default:
+		return StateNeedsReview
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Marshaling
+// ---------------------------------------------------------------------------
+
+// Marshal serializes the document in its Version (XLIFF 1.2 by default).
+func (d *Document) Marshal() ([]byte, error) {
+	var v any
+	switch d.Version {
+	case "", Version12:
+		v = d.to12()
+	case Version20:
+		v = d.to20()
+	default:
+		return nil, fmt.Errorf("unsupported XLIFF version %q", d.Version)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the document to path.
+func (d *Document) WriteFile(path string) error {
+	data, err := d.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (d *Document) to12() *xliff12 {
+	doc := &xliff12{Version: Version12}
+	for _, f := range d.Files {
+		xf := file12{Original: f.Original, SourceLang: d.SourceLang, TargetLang: d.TargetLang, Datatype: "plaintext"}
+		for i, u := range f.Units {
+			xu := unit12{
+				ID:      unitID(u, i),
+				Resname: u.Name,
+				Space:   "preserve",
+				Source:  text{Value: u.Source},
+				Target:  &text{State: state12(u.State), Value: u.Target},
+			}
+			for _, n := range u.Notes {
+				xu.Notes = append(xu.Notes, text{Value: n})
+			}
+			xf.Body.Units = append(xf.Body.Units, xu)
+		}
+		doc.Files = append(doc.Files, xf)
+	}
+	return doc
+}
+
+func (d *Document) to20() *xliff20 {
+	doc := &xliff20{Version: Version20, SrcLang: d.SourceLang, TrgLang: d.TargetLang}
+	for i, f := range d.Files {
+		xf := file20{ID: "f" + strconv.Itoa(i+1), Original: f.Original}
+		for j, u := range f.Units {
+			xu := unit20{
+				ID:   unitID(u, j),
+				Name: u.Name,
+				Segments: []segment20{{
+					State:  state20(u.State),
+					Space:  "preserve",
+					Source: text{Value: u.Source},
+					Target: &text{Value: u.Target},
+				}},
+			}
+			if len(u.Notes) > 0 {
+				xu.Notes = &notes20{}
+				for _, n := range u.Notes {
+					xu.Notes.Notes = append(xu.Notes.Notes, text{Value: n})
+				}
+			}
+			xf.Units = append(xf.Units, xu)
+		}
+		doc.Files = append(doc.Files, xf)
+	}
+	return doc
+}
+
+func unitID(u Unit, index int) string {
+	if u.ID != "" {
+		return u.ID
+	}
+	return "u" + strconv.Itoa(index+1)
+}
+
+// ---------------------------------------------------------------------------
+// Parsing
+// ---------------------------------------------------------------------------
+
+// ParseFile reads and parses an XLIFF document from disk.
+func ParseFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse parses an XLIFF 1.2 or 2.0 document, detected by its namespace.
+func Parse(data []byte) (*Document, error) {
+	var root struct {
+		XMLName xml.Name
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing XLIFF: %w", err)
+	}
+	if root.XMLName.Local != "xliff" {
+		return nil, fmt.Errorf("not an XLIFF document: root element <%s>", root.XMLName.Local)
+	}
+
+	switch {
+	case root.XMLName.Space == namespace20 || strings.HasPrefix(root.Version, "2."):
+		var doc xliff20
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing XLIFF 2.0: %w", err)
+		}
+		return doc.document()
+	case root.XMLName.Space == namespace12 || strings.HasPrefix(root.Version, "1."):
+		var doc xliff12
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing XLIFF 1.2: %w", err)
+		}
+		return doc.document()
+	default:
+		return nil, fmt.Errorf("unsupported XLIFF version %q", root.Version)
+	}
+}
+
+func (x *xliff12) document() (*Document, error) {
+	d := &Document{Version: Version12}
+	for _, xf := range x.Files {
+		if d.SourceLang == "" {
+			d.SourceLang = xf.SourceLang
+		}
+		if d.TargetLang == "" {
+			d.TargetLang = xf.TargetLang
+		}
+		f := File{Original: xf.Original}
+		if err := xf.Body.collect(&f); err != nil {
+			return nil, err
+		}
+		d.Files = append(d.Files, f)
+	}
+	return d, nil
+}
+
+func (g *group12) collect(f *File) error {
+	for _, xu := range g.Units {
+		u := Unit{ID: xu.ID, Name: xu.Resname, Source: xu.Source.Value}
+		if u.Name == "" {
+			u.Name = xu.ID
+		}
+		if err := checkInline(u, xu.Source); err != nil {
+			return err
+		}
+		state := ""
+		if xu.Target != nil {
+			if err := checkInline(u, *xu.Target); err != nil {
+				return err
+			}
+			u.Target, state = xu.Target.Value, xu.Target.State
+		}
+		u.State = parseState12(state, u.Target)
+		for _, n := range xu.Notes {
+			u.Notes = append(u.Notes, n.Value)
+		}
+		f.Units = append(f.Units, u)
+	}
+	for i := range g.Groups {
+		if err := g.Groups[i].collect(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *xliff20) document() (*Document, error) {
+	d := &Document{Version: Version20, SourceLang: x.SrcLang, TargetLang: x.TrgLang}
+	for _, xf := range x.Files {
+		f := File{Original: xf.Original}
+		if err := collect20(&f, xf.Units, xf.Groups); err != nil {
+			return nil, err
+		}
+		d.Files = append(d.Files, f)
+	}
+	return d, nil
+}
+
+// collect20 flattens units and groups. Units split into several segments
+// are joined back into one string; the state of the first segment wins.
+func collect20(f *File, units []unit20, groups []group20) error {
+	for _, xu := range units {
+		u := Unit{ID: xu.ID, Name: xu.Name}
+		if u.Name == "" {
+			u.Name = xu.ID
+		}
+		state := ""
+		for i, seg := range xu.Segments {
+			if err := checkInline(u, seg.Source); err != nil {
+				return err
+			}
+			u.Source += seg.Source.Value
+			if seg.Target != nil {
+				if err := checkInline(u, *seg.Target); err != nil {
+					return err
+				}
+				u.Target += seg.Target.Value
+			}
+			if i == 0 {
+				state = seg.State
+			}
+		}
+		u.State = parseState20(state, u.Target)
+		if xu.Notes != nil {
+			for _, n := range xu.Notes.Notes {
+				u.Notes = append(u.Notes, n.Value)
+			}
+		}
+		f.Units = append(f.Units, u)
+	}
+	for _, g := range groups {
+		if err := collect20(f, g.Units, g.Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkInline(u Unit, t text) error {
+	if len(t.Inline) > 0 {
+		return fmt.Errorf("unit %q: inline element <%s> is not supported", u.Name, t.Inline[0].XMLName.Local)
+	}
+	return nil
+}
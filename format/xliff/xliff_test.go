@@ -0,0 +1,157 @@
+package xliff
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDocument(version string) *Document {
+	return &Document{
+		Version:    version,
+		SourceLang: "en",
+		TargetLang: "de",
+		Files: []File{{
+			Original: "po/de.po",
+			Units: []Unit{
+				{Name: "menu|Open", Source: "Open <b>file</b>", Target: " Öffnen\n", State: StateNeedsReview, Notes: []string{"Context: menu"}},
+				{Name: "Close", Source: "Close", State: StateNew},
+				{Name: "Save", Source: "Save", Target: "Speichern", State: StateTranslated},
+				{Name: "Quit", Source: "Quit", Target: "Beenden", State: StateFinal},
+			},
+		}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, version := range []string{Version12, Version20} {
+		t.Run(version, func(t *testing.T) {
+			want := testDocument(version)
+			data, err := want.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			got, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse: %v\n%s", err, data)
+			}
+			if got.Version != version || got.SourceLang != "en" || got.TargetLang != "de" {
+				t.Fatalf("document = %+v", got)
+			}
+			if len(got.Files) != 1 || got.Files[0].Original != "po/de.po" {
+				t.Fatalf("files = %+v", got.Files)
+			}
+			for i, u := range got.Files[0].Units {
+				w := want.Files[0].Units[i]
+				if u.Name != w.Name || u.Source != w.Source || u.Target != w.Target || u.State != w.State {
+					t.Errorf("unit %d = %+v, want %+v", i, u, w)
+				}
+			}
+			if notes := got.Files[0].Units[0].Notes; len(notes) != 1 || notes[0] != "Context: menu" {
+				t.Errorf("notes = %q", notes)
+			}
+		})
+	}
+}
+
+func TestMarshalStates(t *testing.T) {
+	data, err := testDocument(Version12).Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, want := range []string{`state="needs-review-translation"`, `state="needs-translation"`, `state="translated"`, `state="final"`, `xmlns="urn:oasis:names:tc:xliff:document:1.2"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("XLIFF 1.2 output lacks %s:\n%s", want, data)
+		}
+	}
+
+	data, err = testDocument(Version20).Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, want := range []string{`state="translated"`, `state="initial"`, `state="reviewed"`, `state="final"`, `trgLang="de"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("XLIFF 2.0 output lacks %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestParseThirdParty12(t *testing.T) {
+	doc, err := Parse([]byte(`<?xml version="1.0"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app.properties" source-language="en" target-language="fr" datatype="plaintext">
+    <body>
+      <group id="g1">
+        <trans-unit id="greeting">
+          <source>Hello</source>
+          <target state="signed-off">Bonjour</target>
+        </trans-unit>
+      </group>
+      <trans-unit id="bye" resname="app.bye">
+        <source>Bye</source>
+        <target state="new">Au revoir</target>
+      </trans-unit>
+      <trans-unit id="ok">
+        <source>OK</source>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	units := doc.Files[0].Units
+	if len(units) != 3 {
+		t.Fatalf("units = %+v", units)
+	}
+	byName := make(map[string]Unit)
+	for _, u := range units {
+		byName[u.Name] = u
+	}
+	if u := byName["greeting"]; u.Target != "Bonjour" || u.State != StateFinal {
+		t.Errorf("greeting = %+v", u)
+	}
+	if u := byName["app.bye"]; u.State != StateNeedsReview {
+		t.Errorf("app.bye = %+v", u)
+	}
+	if u := byName["ok"]; u.State != StateNew {
+		t.Errorf("ok = %+v", u)
+	}
+}
+
+func TestParseJoinsSegments20(t *testing.T) {
+	doc, err := Parse([]byte(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="f1" original="README.de.md">
+    <unit id="u1" name="p1">
+      <segment state="final"><source>One. </source><target>Eins. </target></segment>
+      <segment state="final"><source>Two.</source><target>Zwei.</target></segment>
+    </unit>
+  </file>
+</xliff>`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	u := doc.Files[0].Units[0]
+	if u.Source != "One. Two." || u.Target != "Eins. Zwei." || u.State != StateFinal {
+		t.Errorf("unit = %+v", u)
+	}
+}
+
+func TestParseRejectsInlineMarkup(t *testing.T) {
+	_, err := Parse([]byte(`<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="a" source-language="en" target-language="de" datatype="plaintext"><body>
+    <trans-unit id="u1"><source>Click <g id="1">here</g></source><target>Hier <g id="1">klicken</g></target></trans-unit>
+  </body></file>
+</xliff>`))
+	if err == nil || !strings.Contains(err.Error(), "inline element <g>") {
+		t.Fatalf("err = %v, want inline element error", err)
+	}
+}
+
+func TestParseRejectsOtherDocuments(t *testing.T) {
+	if _, err := Parse([]byte(`<resources><string name="a">b</string></resources>`)); err == nil {
+		t.Fatal("expected error for non-XLIFF document")
+	}
+	if _, err := Parse([]byte(`<xliff version="3.0"/>`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
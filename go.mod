@@ -2,10 +2,13 @@ module github.com/minios-linux/lokit
 
 go 1.23.6
 
+require (
+	github.com/leonelquinteros/gotext v1.7.2
+	github.com/spf13/cobra v1.10.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/leonelquinteros/gotext v1.7.2 // indirect
-	github.com/spf13/cobra v1.10.2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -32,6 +32,7 @@ Multi-method providers:
 
 API key providers (paste your key):
   google        Google AI Studio (Gemini API key)
+  anthropic     Anthropic Messages API
   groq          Groq Cloud
   opencode      OpenCode Zen API
   custom-openai Custom OpenAI-compatible endpoint
@@ -69,6 +70,7 @@ var allProviders = []struct {
 	{"copilot", "GitHub Copilot", "device code OAuth", "oauth"},
 	{"gemini", "Google Gemini", "Gemini CLI OAuth", "oauth"},
 	{"google", "Google AI Studio", "Gemini API key", "api-key"},
+	{"anthropic", "Anthropic", "Claude API key", "api-key"},
 	{"groq", "Groq Cloud", "API key", "api-key"},
 	{"opencode", "OpenCode", "Zen endpoint", "api-key"},
 	{"openai", "OpenAI", "browser OAuth, device code, or API key", "mixed"},
@@ -97,6 +99,7 @@ OAuth providers:
 
 API key providers:
   google        Paste your Google AI Studio API key
+  anthropic     Paste your Anthropic API key
   groq          Paste your Groq API key
   opencode      Paste your OpenCode API key
   custom-openai Paste your API key + endpoint URL`),
@@ -104,11 +107,11 @@ API key providers:
 			if headless {
 				if provider != "" && provider != "openai" {
 					logError(T("--headless is only supported for provider 'openai'"))
-					os.Exit(1)
+					os.Exit(exitConfig)
 				}
 				if authMethod != "" && authMethod != "device" {
 					logError(T("--headless conflicts with --auth-method=%s"), authMethod)
-					os.Exit(1)
+					os.Exit(exitConfig)
 				}
 				provider = "openai"
 				authMethod = "device"
@@ -138,7 +141,7 @@ API key providers:
 				scanner := bufio.NewScanner(os.Stdin)
 				if !scanner.Scan() {
 					logError(T("No input received"))
-					os.Exit(1)
+					os.Exit(exitFailure)
 				}
 				choice := strings.TrimSpace(scanner.Text())
 
@@ -152,7 +155,7 @@ API key providers:
 				}
 				if !found {
 					logError(T("Invalid choice. Use: lokit auth login --provider PROVIDER"))
-					os.Exit(1)
+					os.Exit(exitConfig)
 				}
 			}
 
@@ -163,7 +166,7 @@ API key providers:
 				authLoginWithInterrupt(authLoginGemini)
 			case "openai":
 				authLoginOpenAI(authMethod, apiKey)
-			case "google", "groq", "opencode":
+			case "google", "anthropic", "groq", "opencode":
 				authLoginAPIKey(provider, apiKey)
 			case "custom-openai":
 				authLoginCustomOpenAI(apiKey, baseURL)
@@ -173,7 +176,7 @@ API key providers:
 				fmt.Fprintln(os.Stderr)
 			default:
 				logError(T("Unknown provider '%s'. Run 'lokit auth login' for options."), provider)
-				os.Exit(1)
+				os.Exit(exitConfig)
 			}
 		},
 	}
@@ -234,10 +237,10 @@ func authLoginCopilot(ctx context.Context) {
 	if err != nil {
 		if ctx.Err() != nil {
 			logWarning(T("Authentication cancelled"))
-			os.Exit(0)
+			os.Exit(exitInterrupted)
 		}
 		logError(T("Authentication failed: %v"), err)
-		os.Exit(1)
+		os.Exit(exitAuth)
 	}
 
 	logSuccess(T("Copilot authentication successful!"))
@@ -258,10 +261,10 @@ func authLoginGemini(ctx context.Context) {
 	if err != nil {
 		if ctx.Err() != nil {
 			logWarning(T("Authentication cancelled"))
-			os.Exit(0)
+			os.Exit(exitInterrupted)
 		}
 		logError(T("Authentication failed: %v"), err)
-		os.Exit(1)
+		os.Exit(exitAuth)
 	}
 
 	_ = accessToken
@@ -331,7 +334,7 @@ func authLoginOpenAI(method, providedKey string) {
 		scanner := bufio.NewScanner(os.Stdin)
 		if !scanner.Scan() {
 			logError(T("No input received"))
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
 
 		switch strings.TrimSpace(scanner.Text()) {
@@ -343,7 +346,7 @@ func authLoginOpenAI(method, providedKey string) {
 			method = "api-key"
 		default:
 			logError(T("Invalid authentication method"))
-			os.Exit(1)
+			os.Exit(exitConfig)
 		}
 	}
 
@@ -361,10 +364,10 @@ func authLoginOpenAI(method, providedKey string) {
 			if err != nil {
 				if ctx.Err() != nil {
 					logWarning(T("Authentication cancelled"))
-					os.Exit(0)
+					os.Exit(exitInterrupted)
 				}
 				logError(T("Authentication failed: %v"), err)
-				os.Exit(1)
+				os.Exit(exitAuth)
 			}
 
 			logSuccess(T("OpenAI authentication successful!"))
@@ -384,10 +387,10 @@ func authLoginOpenAI(method, providedKey string) {
 			if err != nil {
 				if ctx.Err() != nil {
 					logWarning(T("Authentication cancelled"))
-					os.Exit(0)
+					os.Exit(exitInterrupted)
 				}
 				logError(T("Authentication failed: %v"), err)
-				os.Exit(1)
+				os.Exit(exitAuth)
 			}
 
 			logSuccess(T("OpenAI authentication successful!"))
@@ -398,7 +401,7 @@ func authLoginOpenAI(method, providedKey string) {
 		authLoginAPIKey("openai", providedKey)
 	default:
 		logError(T("Unknown authentication method '%s'"), method)
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 }
 
@@ -413,6 +416,11 @@ func authLoginAPIKey(providerID, providedKey string) {
 			helpURL: "https://aistudio.google.com/apikey",
 			example: "lokit translate --provider google --model MODEL_NAME",
 		},
+		"anthropic": {
+			name:    "Anthropic",
+			helpURL: "https://console.anthropic.com/settings/keys",
+			example: "lokit translate --provider anthropic --model MODEL_NAME",
+		},
 		"groq": {
 			name:    "Groq Cloud",
 			helpURL: "https://console.groq.com/keys",
@@ -442,7 +450,7 @@ func authLoginAPIKey(providerID, providedKey string) {
 	if providedKey != "" {
 		if err := settings.SetAPIKey(providerID, providedKey); err != nil {
 			logError(T("Failed to save API key: %v"), err)
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
 
 		logSuccess(T("%s API key saved!"), info.name)
@@ -462,7 +470,7 @@ func authLoginAPIKey(providerID, providedKey string) {
 	scanner := bufio.NewScanner(os.Stdin)
 	if !scanner.Scan() {
 		logError(T("No input received"))
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 	key := strings.TrimSpace(scanner.Text())
 
@@ -472,12 +480,12 @@ func authLoginAPIKey(providerID, providedKey string) {
 			return
 		}
 		logError(T("No API key provided"))
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	if err := settings.SetAPIKey(providerID, key); err != nil {
 		logError(T("Failed to save API key: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	logSuccess(T("%s API key saved!"), info.name)
@@ -498,7 +506,7 @@ func authLoginCustomOpenAI(providedKey, providedBaseURL string) {
 		}
 		if err := settings.SetAPIKeyWithBaseURL("custom-openai", apiKey, providedBaseURL); err != nil {
 			logError(T("Failed to save credentials: %v"), err)
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
 		logSuccess(T("Custom OpenAI endpoint saved!"))
 		logInfo(T("You can now use: lokit translate --provider custom-openai --model MODEL_NAME"))
@@ -515,7 +523,7 @@ func authLoginCustomOpenAI(providedKey, providedBaseURL string) {
 
 	if !scanner.Scan() {
 		logError(T("No input received"))
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 	baseURL := strings.TrimSpace(scanner.Text())
 
@@ -524,7 +532,7 @@ func authLoginCustomOpenAI(providedKey, providedBaseURL string) {
 	}
 	if baseURL == "" {
 		logError(T("Endpoint URL is required"))
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	if existing != nil && existing.Key != "" {
@@ -536,7 +544,7 @@ func authLoginCustomOpenAI(providedKey, providedBaseURL string) {
 
 	if !scanner.Scan() {
 		logError(T("No input received"))
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 	apiKey := strings.TrimSpace(scanner.Text())
 
@@ -546,7 +554,7 @@ func authLoginCustomOpenAI(providedKey, providedBaseURL string) {
 
 	if err := settings.SetAPIKeyWithBaseURL("custom-openai", apiKey, baseURL); err != nil {
 		logError(T("Failed to save credentials: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	logSuccess(T("Custom OpenAI endpoint saved!"))
@@ -575,24 +583,24 @@ Examples:
 				case "copilot":
 					if err := copilot.DeleteToken(); err != nil {
 						logError(T("Failed to remove Copilot credentials: %v"), err)
-						os.Exit(1)
+						os.Exit(exitFailure)
 					}
 					logSuccess(T("Copilot credentials removed"))
 				case "gemini":
 					if err := gemini.DeleteToken(); err != nil {
 						logError(T("Failed to remove Gemini credentials: %v"), err)
-						os.Exit(1)
+						os.Exit(exitFailure)
 					}
 					logSuccess(T("Gemini credentials removed"))
-				case "google", "groq", "opencode", "openai", "custom-openai":
+				case "google", "anthropic", "groq", "opencode", "openai", "custom-openai":
 					if err := settings.Remove(provider); err != nil {
 						logError(T("Failed to remove %s credentials: %v"), provider, err)
-						os.Exit(1)
+						os.Exit(exitFailure)
 					}
 					logSuccess(T("%s credentials removed"), provider)
 				default:
 					logError(T("Unknown provider '%s'. Run 'lokit auth list' to see providers."), provider)
-					os.Exit(1)
+					os.Exit(exitConfig)
 				}
 				return
 			}
@@ -606,7 +614,7 @@ Examples:
 				logError(T("Failed to remove Gemini credentials: %v"), err)
 				errCount++
 			}
-			for _, pid := range []string{"google", "groq", "opencode", "openai", "custom-openai"} {
+			for _, pid := range []string{"google", "anthropic", "groq", "opencode", "openai", "custom-openai"} {
 				if err := settings.Remove(pid); err != nil {
 					logError(T("Failed to remove %s credentials: %v"), pid, err)
 					errCount++
@@ -654,6 +662,7 @@ func newAuthListCmd() *cobra.Command {
 				name string
 			}{
 				{"google", "Google AI Studio"},
+				{"anthropic", "Anthropic"},
 				{"groq", "Groq Cloud"},
 				{"opencode", "OpenCode"},
 				{"custom-openai", "Custom OpenAI"},
@@ -680,6 +689,7 @@ func newAuthListCmd() *cobra.Command {
 				id string
 			}{
 				{"google"},
+				{"anthropic"},
 				{"groq"},
 				{"opencode"},
 				{"openai"},
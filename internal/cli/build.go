@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/spf13/cobra"
+)
+
+func newBuildCmd() *cobra.Command {
+	var langs string
+	var targets []string
+	var outDir string
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: T("Compile gettext PO files into MO files"),
+		Long: T(`Compile the PO files of gettext targets into binary MO files, the
+catalogs loaded by gettext at run time. No msgfmt is needed.
+
+Like msgfmt, only translated messages are compiled: fuzzy and untranslated
+messages fall back to the source text at run time.
+
+By default each MO file is written next to its PO file (po/de.po →
+po/de.mo). With --output, the files are laid out for installation as
+DIR/{locale}/LC_MESSAGES/{domain}.mo, where the domain is the name of the
+target's POT file without extension and the locale uses gettext naming
+(pt-BR → pt_BR). Point --output at a staging directory such as
+debian/tmp/usr/share/locale when packaging.
+
+Other target formats are ignored.
+
+Examples:
+  lokit build
+  lokit build --output locale
+  lokit build --target app --lang de,fr --output debian/tmp/usr/share/locale`),
+		Run: func(cmd *cobra.Command, args []string) {
+			runBuild(engine.BuildOptions{
+				Targets:   targets,
+				Languages: splitLangs(langs),
+				OutputDir: outDir,
+			})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all gettext targets)"))
+	cmd.Flags().StringVarP(&langs, "lang", "l", "", T("Comma-separated languages to compile (default: all)"))
+	cmd.Flags().StringVarP(&outDir, "output", "o", "", T("Write MO files to DIR/{locale}/LC_MESSAGES/{domain}.mo instead of next to the PO files"))
+	return cmd
+}
+
+func runBuild(opts engine.BuildOptions) {
+	e := loadEngine()
+	e.OnTargetStart = func(rt config.ResolvedTarget) {
+		targetHeader(rt.Target.Name, string(rt.Target.Type))
+	}
+
+	res, err := e.Build(opts)
+	if res == nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	if err != nil {
+		logError(T("Build completed with errors"))
+		os.Exit(exitPartial)
+	}
+	logSuccess(T("Compiled %d MO files"), len(res.Files))
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minios-linux/lokit/engine"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/translate"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCmd() *cobra.Command {
+	var targets []string
+	var langFlag string
+	var jsonOut, strict, quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: T("Check translated files for common problems"),
+		Long: T(`Check existing translations for common problems without calling any AI provider.
+
+Checks:
+  placeholders        printf, python-brace, Qt and {{var}} placeholders differ from source
+  whitespace          leading/trailing whitespace or newlines differ from source (warning)
+  identical           translation is identical to source (warning)
+  markdown-heading    Markdown heading level differs from source
+  android-apostrophe  unescaped apostrophe in Android strings.xml
+  tags                unbalanced HTML/XML tags
+  length              translation exceeds its length_limits rule or the
+                      file's maxLength hint (Android, Flutter ARB)
+
+Exit status is 5 when errors are found (or warnings, with --strict), so
+the command can gate CI pipelines.
+
+Examples:
+  lokit check
+  lokit check --target app --lang ru,de
+  lokit check --json
+  lokit check --quiet --strict`),
+		Run: func(cmd *cobra.Command, args []string) {
+			runCheck(targets, langFlag, jsonOut, strict, quiet)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+	cmd.Flags().StringVarP(&langFlag, "lang", "l", "", T("Comma-separated languages to check (default: all)"))
+	cmd.Flags().BoolVar(&jsonOut, "json", false, T("Output issues as JSON"))
+	cmd.Flags().BoolVar(&strict, "strict", false, T("Exit with status 5 on warnings too"))
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, T("Print nothing; only set the exit status"))
+
+	return cmd
+}
+
+func runCheck(targets []string, langFlag string, jsonOut, strict, quiet bool) {
+	e := loadEngine()
+	if quiet || jsonOut {
+		e.OnLog = nil
+	}
+
+	var langs []string
+	for _, l := range strings.Split(langFlag, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			langs = append(langs, l)
+		}
+	}
+
+	report, err := e.Check(engine.CheckOptions{Targets: targets, Languages: langs})
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+
+	switch {
+	case quiet:
+	case jsonOut:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			logError(T("JSON output error: %v"), err)
+			os.Exit(exitFailure)
+		}
+	default:
+		printCheckIssues(report)
+	}
+
+	if report.Errors > 0 || (strict && report.Warnings > 0) {
+		os.Exit(exitValidation)
+	}
+}
+
+func printCheckIssues(output *engine.CheckReport) {
+	sectionHeader(T("Check"))
+	lastGroup := ""
+	for _, issue := range output.Issues {
+		if group := issue.Target + "\x00" + issue.Lang; group != lastGroup {
+			targetHeader(issue.Target, issue.Lang)
+			lastGroup = group
+		}
+		color := colorYellow
+		if issue.Severity == translate.SeverityError {
+			color = colorRed
+		}
+		fmt.Fprintf(os.Stderr, "  %s%-7s%s %s%s:%s%s %s %s(%s)%s\n",
+			color, issue.Severity, colorReset,
+			colorDim, issue.File, issue.Key, colorReset,
+			issue.Message,
+			colorDim, issue.Rule, colorReset)
+	}
+	fmt.Fprintln(os.Stderr)
+	summary := fmt.Sprintf(T("%d strings checked: %d errors, %d warnings"), output.Checked, output.Errors, output.Warnings)
+	if output.Errors > 0 {
+		logError("%s", summary)
+	} else if output.Warnings > 0 {
+		logWarning("%s", summary)
+	} else {
+		logSuccess("%s", summary)
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/minios-linux/lokit/engine"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/translate"
+)
+
+// ---------------------------------------------------------------------------
+// Exit codes
+// ---------------------------------------------------------------------------
+
+// Exit codes of lokit commands, documented in docs/commands.md.
+const (
+	exitOK          = 0
+	exitFailure     = 1   // unexpected error
+	exitConfig      = 2   // missing or invalid lokit.yaml, bad arguments
+	exitAuth        = 3   // missing or rejected provider credentials
+	exitPartial     = 4   // some targets or languages failed
+	exitValidation  = 5   // check found problems, untranslated threshold exceeded
+	exitNothingToDo = 6   // translate --detailed-exit-code: nothing was translated
+	exitInterrupted = 130 // Ctrl-C
+)
+
+// exitCode returns the exit code for an error that stopped a command
+// before it did any work.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, engine.ErrNoConfig), errors.Is(err, engine.ErrConfig):
+		return exitConfig
+	case translate.IsAuthError(err):
+		return exitAuth
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	default:
+		return exitFailure
+	}
+}
+
+// ---------------------------------------------------------------------------
+// --fail-on-untranslated
+// ---------------------------------------------------------------------------
+
+// untranslatedLimit is the number or percentage of untranslated strings a
+// language may have before --fail-on-untranslated fails the command.
+type untranslatedLimit struct {
+	value   int
+	percent bool
+}
+
+// parseUntranslatedLimit parses a --fail-on-untranslated value: a count
+// such as "10" or a percentage of the source strings such as "5%".
+func parseUntranslatedLimit(s string) (untranslatedLimit, error) {
+	s = strings.TrimSpace(s)
+	num, percent := strings.CutSuffix(s, "%")
+	n, err := strconv.Atoi(strings.TrimSpace(num))
+	if err != nil || n < 0 || (percent && n > 100) {
+		return untranslatedLimit{}, fmt.Errorf(T("invalid --fail-on-untranslated value %q (expected a count such as 10 or a percentage such as 5%%)"), s)
+	}
+	return untranslatedLimit{value: n, percent: percent}, nil
+}
+
+// exceeded reports whether a language with the given counts is over the
+// limit. Fuzzy strings count as untranslated.
+func (l untranslatedLimit) exceeded(ls engine.LangStatus) bool {
+	missing := ls.Untranslated + ls.Fuzzy
+	if l.percent {
+		return ls.Total > 0 && missing*100 > l.value*ls.Total
+	}
+	return missing > l.value
+}
+
+func (l untranslatedLimit) String() string {
+	if l.percent {
+		return fmt.Sprintf("%d%%", l.value)
+	}
+	return strconv.Itoa(l.value)
+}
+
+// checkUntranslated logs every target language of report over limit and
+// reports whether there was any. Only the languages in langs are checked,
+// or all if langs is empty. Targets whose source cannot be read fail too.
+func checkUntranslated(report *engine.StatusReport, limit untranslatedLimit, langs []string) bool {
+	failed := false
+	for _, t := range report.Targets {
+		if t.Error != "" {
+			logError(T("[%s] Cannot count untranslated strings: %s"), t.Name, t.Error)
+			failed = true
+			continue
+		}
+		for _, ls := range t.Languages {
+			if len(langs) > 0 && !containsLang(langs, ls.Lang) {
+				continue
+			}
+			if !limit.exceeded(ls) {
+				continue
+			}
+			logError(T("[%s] %s: %d of %d strings untranslated (limit %s)"),
+				t.Name, ls.Lang, ls.Untranslated+ls.Fuzzy, ls.Total, limit)
+			failed = true
+		}
+	}
+	return failed
+}
+
+func containsLang(langs []string, lang string) bool {
+	for _, l := range langs {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
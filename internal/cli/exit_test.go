@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/minios-linux/lokit/engine"
+	"github.com/minios-linux/lokit/translate"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{fmt.Errorf("%w in /tmp", engine.ErrNoConfig), exitConfig},
+		{fmt.Errorf("bad target: %w", engine.ErrConfig), exitConfig},
+		{fmt.Errorf("provider: %w", translate.ErrAuthentication), exitAuth},
+		{context.Canceled, exitInterrupted},
+		{errors.New("disk full"), exitFailure},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestUntranslatedLimit(t *testing.T) {
+	lang := engine.LangStatus{Total: 40, Translated: 36, Fuzzy: 1, Untranslated: 3}
+	tests := []struct {
+		value    string
+		exceeded bool
+	}{
+		{"0", true},
+		{"3", true},
+		{"4", false},
+		{"10%", false},
+		{"9%", true},
+		{" 5 % ", true},
+	}
+	for _, tt := range tests {
+		limit, err := parseUntranslatedLimit(tt.value)
+		if err != nil {
+			t.Fatalf("parseUntranslatedLimit(%q) error = %v", tt.value, err)
+		}
+		if got := limit.exceeded(lang); got != tt.exceeded {
+			t.Errorf("limit %q exceeded = %v, want %v", tt.value, got, tt.exceeded)
+		}
+	}
+
+	for _, bad := range []string{"", "x", "-1", "101%", "5%%"} {
+		if _, err := parseUntranslatedLimit(bad); err == nil {
+			t.Errorf("parseUntranslatedLimit(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCheckUntranslated(t *testing.T) {
+	report := &engine.StatusReport{Targets: []engine.TargetStatus{{
+		Name: "ui",
+		Languages: []engine.LangStatus{
+			{Lang: "de", Status: engine.StatusOK, Total: 10, Translated: 10},
+			{Lang: "fr", Status: engine.StatusMissing, Total: 10, Untranslated: 10},
+		},
+	}}}
+	limit := untranslatedLimit{}
+	if !checkUntranslated(report, limit, nil) {
+		t.Fatal("missing fr translation passed")
+	}
+	if checkUntranslated(report, limit, []string{"de"}) {
+		t.Fatal("complete de translation failed")
+	}
+}
@@ -3,14 +3,10 @@ package cli
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
 
 	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
 	. "github.com/minios-linux/lokit/i18n"
-	"github.com/minios-linux/lokit/internal/format/i18next"
-	po "github.com/minios-linux/lokit/internal/format/po"
 	"github.com/spf13/cobra"
 )
 
@@ -30,13 +26,13 @@ then creates or updates PO files for each language.
 
 For po4a projects: runs 'po4a --no-translations' to update templates.
 
-For i18next/vue-i18n/yaml/properties/flutter/js-kv projects: creates missing language
-files with empty translations.
+For i18next/vue-i18n/yaml/properties/flutter/js-kv/apple-strings/qt projects:
+creates missing language files with empty translations.
 
 For index-source targets (source object with index, records_path, key_field,
 fields): creates per-record translation files from the index file.
 
-For android/desktop/polkit projects: no init step needed — use 'lokit translate'
+For android/desktop/polkit/xcstrings projects: no init step needed — use 'lokit translate'
 directly.
 
 This command is idempotent — safe to run multiple times. Existing
@@ -112,6 +108,18 @@ TARGET FORMATS
     dir: .                           # Directory with policy file
     pattern: "org.example.policy"    # Policy file name
 
+  apple-strings — Apple .strings / .stringsdict (plural rules)
+    dir: ios/MyApp                   # Directory with the .lproj folders (required)
+    pattern: "{lang}.lproj/Localizable.strings"  # Language file pattern (required)
+
+  xcstrings — Xcode String Catalog (single file)
+    dir: MyApp                       # Directory with the catalog
+    pattern: "Localizable.xcstrings" # Catalog file name
+
+  qt — Qt Linguist .ts files (lupdate output)
+    dir: translations                # .ts files directory (required)
+    pattern: "myapp_{lang}.ts"       # Language file pattern (required)
+
 COMMON OPTIONS (all target formats)
 
   languages: [ru, de]               # Override global language list
@@ -162,19 +170,7 @@ EXAMPLES
       format: properties
       dir: src/main/resources`),
 		Run: func(cmd *cobra.Command, args []string) {
-			// Require lokit.yaml
-			lf, err := config.LoadLokitFile(rootDir)
-			if err != nil {
-				logError(T("Config error: %v"), err)
-				os.Exit(1)
-			}
-			if lf == nil {
-				logError(T("No lokit.yaml found in %s"), rootDir)
-				logInfo(T("Create a lokit.yaml configuration file. See 'lokit init --help' for format reference."))
-				os.Exit(1)
-			}
-
-			runInitWithConfig(lf, langs, targets)
+			runInit(engine.InitOptions{Targets: targets, Languages: splitLangs(langs)})
 		},
 	}
 
@@ -184,426 +180,35 @@ EXAMPLES
 	return cmd
 }
 
-// runInitWithConfig initializes translation files using lokit.yaml targets.
-func runInitWithConfig(lf *config.LokitFile, langsFlag string, targets []string) {
-	absRoot, _ := filepath.Abs(rootDir)
-
-	resolved, err := lf.Resolve(rootDir)
-	if err != nil {
-		logError(T("Config resolve error: %v"), err)
-		os.Exit(1)
-	}
-	resolved, err = filterResolvedTargetsByNames(resolved, targets)
-	if err != nil {
-		logError(T("%v"), err)
-		os.Exit(1)
-	}
-
-	for _, rt := range resolved {
-		langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
-		if langsFlag != "" {
-			langs = strings.Split(langsFlag, ",")
-		}
-
-		targetHeader(rt.Target.Name, rt.Target.Type)
-
-		switch rt.Target.Type {
-		case config.TargetTypeGettext:
-			proj := &config.Project{
-				Root:        rt.AbsRoot,
-				Name:        rt.Target.Name,
-				PODir:       rt.AbsPODir(),
-				POTFile:     rt.AbsPOTFile(),
-				POStructure: config.POStructureFlat,
-				Languages:   langs,
-				Keywords:    rt.Target.Keywords,
-				SourceLang:  rt.Target.SourceLang,
-			}
-			if len(rt.Target.Sources) > 0 {
-				for _, src := range rt.Target.Sources {
-					proj.SourceDirs = append(proj.SourceDirs, filepath.Join(rt.AbsRoot, src))
-				}
-			} else {
-				proj.SourceDirs = []string{rt.AbsRoot}
-			}
-			runInitCode(proj)
-
-		case config.TargetTypePo4a:
-			proj := &config.Project{
-				Name:        rt.Target.Name,
-				POStructure: config.POStructurePo4a,
-				Po4aConfig:  rt.AbsPo4aConfig(),
-				Languages:   langs,
-				SourceLang:  rt.Target.SourceLang,
-			}
-			proj.PODir = filepath.Join(filepath.Dir(proj.Po4aConfig), "po")
-			// Check for docs directory for manpage generation
-			for _, candidate := range []string{"docs", "doc"} {
-				docsDir := filepath.Join(absRoot, candidate)
-				if info, err := os.Stat(docsDir); err == nil && info.IsDir() {
-					proj.DocsDir = docsDir
-					break
-				}
-			}
-			proj.ManpagesDir = filepath.Dir(proj.Po4aConfig)
-			runInitPo4a(proj)
-
-		case config.TargetTypeI18Next:
-			proj := &config.Project{
-				Name:               rt.Target.Name,
-				Type:               config.ProjectTypeI18Next,
-				I18NextDir:         rt.AbsTranslationsDir(),
-				I18NextPathPattern: rt.Target.Pattern,
-				Languages:          langs,
-				SourceLang:         rt.Target.SourceLang,
-			}
-			runInitI18Next(proj)
-
-		case config.TargetTypeVueI18n:
-			if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
-				runInitIndex(rt, langs)
-			} else {
-				runInitVueI18n(rt, langs)
-			}
-
-		case config.TargetTypeAndroid:
-			logInfo(T("Android targets do not require init — use 'lokit translate' directly."))
-
-		case config.TargetTypeYAML:
-			runInitYAML(rt, langs)
-
-		case config.TargetTypeMarkdown:
-			runInitMarkdown(rt, langs)
-
-		case config.TargetTypeProperties:
-			runInitProperties(rt, langs)
-
-		case config.TargetTypeFlutter:
-			runInitFlutter(rt, langs)
-
-		case config.TargetTypeJSKV:
-			runInitJSKV(rt, langs)
-
-		case config.TargetTypeDesktop:
-			logInfo(T("Desktop targets do not require init — use 'lokit translate' directly."))
-
-		case config.TargetTypePolkit:
-			logInfo(T("Polkit targets do not require init — use 'lokit translate' directly."))
-		default:
-			logWarning(T("[%s] Unknown target type %q, skipping"), rt.Target.Name, rt.Target.Type)
-		}
-	}
-}
-
-func runInitI18Next(proj *config.Project) {
-	srcPath := proj.I18NextPath(proj.SourceLang)
-	srcFile, err := i18next.ParseFile(srcPath)
-	if err != nil {
-		logError(T("Cannot read source language file %s: %v"), srcPath, err)
-		os.Exit(1)
+func runInit(opts engine.InitOptions) {
+	e := loadEngine()
+	e.OnTargetStart = func(rt config.ResolvedTarget) {
+		targetHeader(rt.Target.Name, string(rt.Target.Type))
 	}
-
-	srcKeys := srcFile.Keys()
-	logInfo(T("Source language (%s): %d keys"), proj.SourceLang, len(srcKeys))
-
-	created, updated := 0, 0
-
-	for _, lang := range proj.Languages {
-		if lang == proj.SourceLang {
-			continue
-		}
-
-		filePath := proj.I18NextPath(lang)
-		file, err := i18next.ParseFile(filePath)
-
-		if err != nil {
-			// Create new file with all keys empty
-			meta := i18next.ResolveMeta(lang)
-			file = &i18next.File{
-				Meta:         meta,
-				Translations: make(map[string]string),
-			}
-			for _, key := range srcKeys {
-				file.Translations[key] = ""
-			}
-			if err := file.WriteFile(filePath); err != nil {
-				logError(T("Creating %s: %v"), filePath, err)
-				continue
-			}
-			logSuccess(T("Created: %s (%d keys)"), filePath, len(srcKeys))
-			created++
-			continue
-		}
-
-		// Sync keys: add missing, don't remove extras (they may be intentional)
-		added := 0
-		for _, key := range srcKeys {
-			if _, exists := file.Translations[key]; !exists {
-				file.Translations[key] = ""
-				added++
-			}
-		}
-
-		if added > 0 {
-			if err := file.WriteFile(filePath); err != nil {
-				logError(T("Updating %s: %v"), filePath, err)
-				continue
-			}
-			logSuccess(T("Updated: %s (+%d new keys)"), filePath, added)
-			updated++
-		} else {
-			logInfo(T("%s: up to date"), lang)
-		}
-	}
-
-	logInfo(T("Summary: %d created, %d updated"), created, updated)
-	fmt.Fprintln(os.Stderr)
-	showI18NextStats(proj)
-	logSuccess(T("Init complete!"))
-}
-
-// generateManpagesFromMarkdown generates man pages from markdown files if they don't exist.
-// This is needed for po4a projects that reference .1, .7 files in po4a.cfg but only have .md sources.
-func generateManpagesFromMarkdown(proj *config.Project) error {
-	// Only process if we have both po4a config and docs directory
-	if proj.Po4aConfig == "" || proj.DocsDir == "" {
-		return nil
-	}
-
-	// Check if pandoc is available
-	if _, err := exec.LookPath("pandoc"); err != nil {
-		logInfo(T("pandoc not found, skipping manpage generation from markdown"))
-		return nil
-	}
-
-	// Check if docs directory exists
-	if _, err := os.Stat(proj.DocsDir); os.IsNotExist(err) {
-		return nil // No docs directory, nothing to generate
-	}
-
-	manpageDir := filepath.Dir(proj.Po4aConfig)
-
-	// Find all markdown files in docs that look like manpage sources (name.section.md)
-	// Example: myapp.1.md -> myapp.1
-	mdFiles, err := filepath.Glob(filepath.Join(proj.DocsDir, "*.*.md"))
-	if err != nil {
-		return fmt.Errorf(T("failed to list markdown files: %w"), err)
-	}
-
-	generated := 0
-	for _, mdPath := range mdFiles {
-		mdFile := filepath.Base(mdPath)
-
-		// Extract manpage name (remove .md extension)
-		// Example: myapp.1.md -> myapp.1
-		if !strings.HasSuffix(mdFile, ".md") {
-			continue
-		}
-		manFile := strings.TrimSuffix(mdFile, ".md")
-
-		// Check if this looks like a manpage (has section number)
-		// Format should be: name.section where section is a digit
-		parts := strings.Split(manFile, ".")
-		if len(parts) < 2 {
-			continue
-		}
-		lastPart := parts[len(parts)-1]
-		if len(lastPart) != 1 || lastPart[0] < '0' || lastPart[0] > '9' {
-			continue
-		}
-
-		manPath := filepath.Join(manpageDir, manFile)
-
-		// Skip if manpage already exists
-		if _, err := os.Stat(manPath); err == nil {
-			continue
-		}
-
-		// Generate manpage from markdown
-		logInfo(T("Generating %s from %s"), manFile, mdFile)
-		cmd := exec.Command("pandoc", "-s", "-t", "man", mdPath, "-o", manPath)
-		cmd.Stdout = os.Stderr
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf(T("pandoc failed for %s: %w"), mdFile, err)
+	e.OnTargetDone = func(res engine.TargetResult) {
+		if res.Err != nil {
+			return
 		}
-
-		// Post-process: remove \c sequences that cause po4a issues
-		if err := removeBackslashCSequences(manPath); err != nil {
-			logWarning(T("Failed to post-process %s: %v"), manFile, err)
-		}
-
-		generated++
-	}
-
-	if generated > 0 {
-		logSuccess(T("Generated %d manpage(s) from markdown"), generated)
-	}
-
-	return nil
-}
-
-// removeBackslashCSequences removes \c escape sequences from manpage files.
-// These are generated by pandoc but cause issues with po4a.
-func removeBackslashCSequences(filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	// Remove " \c" sequences (space + backslash + c)
-	// This is safe because \c is just a line continuation that po4a doesn't like
-	modified := strings.ReplaceAll(string(content), " \\c\n", "\n")
-
-	if modified != string(content) {
-		return os.WriteFile(filePath, []byte(modified), 0644)
-	}
-
-	return nil
-}
-
-// scanPo4aLanguages scans a PO directory for language subdirectories containing .po files.
-func scanPo4aLanguages(poDir string) []string {
-	return config.DetectLanguagesNested(poDir)
-}
-
-// doPo4aInit runs po4a --no-translations to generate/update POT and PO files.
-// Returns nil on success. Unlike runInitPo4a, this is safe to call from other
-// commands (no os.Exit, no stats display).
-func doPo4aInit(proj *config.Project) error {
-	if _, err := exec.LookPath("po4a"); err != nil {
-		return fmt.Errorf(T("po4a is not installed. Install with: sudo apt install po4a"))
-	}
-
-	// Check if manpages need to be generated from markdown
-	if err := generateManpagesFromMarkdown(proj); err != nil {
-		logWarning(T("Failed to generate manpages from markdown: %v"), err)
-		logWarning(T("Continuing anyway, po4a might fail if source files don't exist"))
-	}
-
-	logInfo(T("Running po4a --no-translations %s ..."), proj.Po4aConfig)
-	cmd := exec.Command("po4a", "--no-translations", proj.Po4aConfig)
-	cmd.Dir = filepath.Dir(proj.Po4aConfig)
-	cmd.Stdout = os.Stderr
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf(T("po4a failed: %v"), err)
-	}
-	logSuccess(T("po4a updated POT and PO files"))
-
-	// Re-scan languages from PO files after po4a ran (it may have created new ones)
-	poDir := proj.PODir
-	if poDir != "" {
-		if scannedLangs := scanPo4aLanguages(poDir); len(scannedLangs) > 0 {
-			proj.Languages = scannedLangs
+		switch res.Target.Target.Type {
+		case config.TargetTypeGettext, config.TargetTypePo4a, config.TargetTypeI18Next:
+			report, err := e.Status([]string{res.Target.Target.Name})
+			if err != nil || len(report.Targets) == 0 {
+				return
+			}
+			fmt.Fprintln(os.Stderr)
+			showStatusCounts(report.Targets[0])
 		}
 	}
 
-	// Re-resolve POT file (po4a may have generated it)
-	proj.POTFile = proj.POTPathResolved()
-	return nil
-}
-
-func runInitPo4a(proj *config.Project) {
-	if err := doPo4aInit(proj); err != nil {
+	res, err := e.Init(opts)
+	if res == nil {
 		logError(T("%v"), err)
-		os.Exit(1)
-	}
-
-	fmt.Fprintln(os.Stderr)
-	potPath := proj.POTPathResolved()
-	if potPath != "" && fileExists(potPath) {
-		showStatsTable(proj, potPath)
-	} else {
-		showPo4aStats(proj)
+		os.Exit(exitCode(err))
 	}
-
-	logSuccess(T("Init complete!"))
-}
-
-func runInitCode(proj *config.Project) {
-	// Step 1: Extract strings; desktop files are a byproduct of the scan —
-	// no second FindSources walk is needed for seeding.
-	desktopFiles, err := doExtract(proj)
 	if err != nil {
-		logError(T("%v"), err)
-		os.Exit(1)
-	}
-
-	// Step 2: Update PO files from POT
-	potPO, err := po.ParseFile(proj.POTFile)
-	if err != nil {
-		logError(T("Reading %s: %v"), proj.POTFile, err)
-		os.Exit(1)
+		logError(T("Init completed with errors"))
+		os.Exit(exitPartial)
 	}
-
-	logInfo(T("Updating PO files for: %s"), strings.Join(proj.Languages, ", "))
-
-	root := proj.Root
-	if root == "" {
-		root, _ = os.Getwd()
-	}
-
-	created, updated := 0, 0
-
-	for _, lang := range proj.Languages {
-		poPath := proj.POPath(lang)
-
-		if err := os.MkdirAll(filepath.Dir(poPath), 0755); err != nil {
-			logError(T("Creating directory for %s: %v"), poPath, err)
-			continue
-		}
-
-		if _, err := os.Stat(poPath); os.IsNotExist(err) {
-			newPO := po.NewFile()
-			newPO.Header = po.MakeHeader(proj.Name, proj.Version, proj.BugsEmail, proj.CopyrightHolder, lang)
-			newPO.SetHeaderField("Plural-Forms", po.PluralFormsForLang(lang))
-
-			for _, e := range potPO.Entries {
-				entry := &po.Entry{
-					ExtractedComments: e.ExtractedComments,
-					References:        e.References,
-					Flags:             copyFlags(e.Flags),
-					MsgCtxt:           e.MsgCtxt,
-					MsgID:             e.MsgID,
-					MsgIDPlural:       e.MsgIDPlural,
-					MsgStr:            "",
-					MsgStrPlural:      make(map[int]string),
-				}
-				newPO.Entries = append(newPO.Entries, entry)
-			}
-
-			seedDesktopTranslations(newPO, lang, root, desktopFiles)
-
-			if err := newPO.WriteFile(poPath); err != nil {
-				logError(T("Creating %s: %v"), poPath, err)
-				continue
-			}
-			logSuccess(T("Created: %s"), poPath)
-			created++
-		} else {
-			existingPO, err := po.ParseFile(poPath)
-			if err != nil {
-				logError(T("Reading %s: %v"), poPath, err)
-				continue
-			}
-
-			merged := mergeAndSeedPO(existingPO, potPO, lang, root, desktopFiles)
-			if err := merged.WriteFile(poPath); err != nil {
-				logError(T("Writing %s: %v"), poPath, err)
-				continue
-			}
-			logSuccess(T("Updated: %s"), poPath)
-			updated++
-		}
-	}
-
-	logInfo(T("Summary: %d created, %d updated"), created, updated)
-
-	// Show stats
-	fmt.Fprintln(os.Stderr)
-	showStatsTable(proj, proj.POTFile)
-
 	logSuccess(T("Init complete!"))
 }
 
@@ -11,19 +11,24 @@ import (
 	"strings"
 
 	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
+	formatfile "github.com/minios-linux/lokit/format"
+	"github.com/minios-linux/lokit/format/android"
+	"github.com/minios-linux/lokit/format/applestrings"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/desktop"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/format/jskv"
+	mdfile "github.com/minios-linux/lokit/format/markdown"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/polkit"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	"github.com/minios-linux/lokit/format/qt"
+	"github.com/minios-linux/lokit/format/vuei18n"
+	"github.com/minios-linux/lokit/format/xcstrings"
+	yamlfile "github.com/minios-linux/lokit/format/yaml"
 	. "github.com/minios-linux/lokit/i18n"
-	formatfile "github.com/minios-linux/lokit/internal/format"
-	"github.com/minios-linux/lokit/internal/format/android"
-	arbfile "github.com/minios-linux/lokit/internal/format/arb"
-	"github.com/minios-linux/lokit/internal/format/desktop"
-	"github.com/minios-linux/lokit/internal/format/i18next"
-	"github.com/minios-linux/lokit/internal/format/jskv"
-	mdfile "github.com/minios-linux/lokit/internal/format/markdown"
-	po "github.com/minios-linux/lokit/internal/format/po"
-	"github.com/minios-linux/lokit/internal/format/polkit"
-	propfile "github.com/minios-linux/lokit/internal/format/properties"
-	"github.com/minios-linux/lokit/internal/format/vuei18n"
-	yamlfile "github.com/minios-linux/lokit/internal/format/yaml"
+	"github.com/minios-linux/lokit/internal/layout"
 	"github.com/minios-linux/lokit/lockfile"
 	"github.com/spf13/cobra"
 )
@@ -157,13 +162,13 @@ func runLockInit(target string, force bool) {
 	resolved, err := loadResolvedTargets(target)
 	if err != nil {
 		logError(T("%v"), err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	lf, err := lockfile.Load(rootDir)
 	if err != nil {
 		logError(T("Could not load lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	updatedTargets := 0
@@ -179,7 +184,7 @@ func runLockInit(target string, force bool) {
 			continue
 		}
 
-		langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
+		langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
 		if len(langs) == 0 {
 			continue
 		}
@@ -238,7 +243,7 @@ func runLockInit(target string, force bool) {
 
 	if err := lf.Save(); err != nil {
 		logError(T("Could not save lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	logSuccess(T("Lock initialized: %d target-language entries updated"), updatedTargets)
@@ -246,7 +251,7 @@ func runLockInit(target string, force bool) {
 	logInfo(T("Lock file: %s"), lf.Path())
 
 	if hadErrors {
-		os.Exit(1)
+		os.Exit(exitPartial)
 	}
 }
 
@@ -270,13 +275,13 @@ func runLockStatus(target string, verbose bool, jsonOut bool) {
 	resolved, err := loadResolvedTargets(target)
 	if err != nil {
 		logError(T("%v"), err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	lf, err := lockfile.Load(rootDir)
 	if err != nil {
 		logError(T("Could not load lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	exists := true
@@ -291,7 +296,7 @@ func runLockStatus(target string, verbose bool, jsonOut bool) {
 	}
 
 	for _, rt := range resolved {
-		langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
+		langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
 		if len(langs) == 0 {
 			continue
 		}
@@ -306,7 +311,7 @@ func runLockStatus(target string, verbose bool, jsonOut bool) {
 
 		for _, lang := range langs {
 			count := 0
-			for _, lockTarget := range lockTargetKeysFor(rt, lang) {
+			for _, lockTarget := range layout.LockTargetKeys(rt, lang) {
 				count += lf.TargetKeyCount(lockTarget)
 			}
 			ts.Keys += count
@@ -326,7 +331,7 @@ func runLockStatus(target string, verbose bool, jsonOut bool) {
 		enc.SetIndent("", "  ")
 		if err := enc.Encode(output); err != nil {
 			logError(T("JSON output error: %v"), err)
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
 		return
 	}
@@ -361,26 +366,19 @@ func runLockStatus(target string, verbose bool, jsonOut bool) {
 }
 
 func runLockClean(targets []string, dryRun bool) {
-	allResolved, err := loadResolvedTargets("")
+	e := loadEngine()
+	allResolved := e.Targets
+	resolved, err := e.Select(targets)
 	if err != nil {
 		logError(T("%v"), err)
-		os.Exit(1)
-	}
-
-	resolved := allResolved
-	if len(targets) > 0 {
-		resolved, err = filterResolvedTargetsByNames(allResolved, targets)
-		if err != nil {
-			logError(T("%v"), err)
-			os.Exit(1)
-		}
+		os.Exit(exitCode(err))
 	}
 	orphanScopes := orphanCleanupScopes(allResolved, resolved, targets)
 
 	lf, err := lockfile.Load(rootDir)
 	if err != nil {
 		logError(T("Could not load lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	totalRemoved := 0
@@ -397,7 +395,7 @@ func runLockClean(targets []string, dryRun bool) {
 		}
 
 		keys := mapKeys(sourceEntries)
-		langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
+		langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
 		for _, lang := range langs {
 			if rt.Target.Type == config.TargetTypePo4a {
 				units := collectPo4aLockUnits(rt, lang)
@@ -465,19 +463,19 @@ func runLockClean(targets []string, dryRun bool) {
 	if dryRun {
 		logInfo(T("Dry run: total stale/orphan entries: %d"), totalRemoved)
 		if hadErrors {
-			os.Exit(1)
+			os.Exit(exitPartial)
 		}
 		return
 	}
 
 	if err := lf.Save(); err != nil {
 		logError(T("Could not save lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 	logSuccess(T("Removed stale/orphan entries: %d"), totalRemoved)
 
 	if hadErrors {
-		os.Exit(1)
+		os.Exit(exitPartial)
 	}
 }
 
@@ -485,18 +483,18 @@ func runLockReset(target, lang string, yes bool) {
 	lf, err := lockfile.Load(rootDir)
 	if err != nil {
 		logError(T("Could not load lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	if lang != "" && target == "" {
 		logError(T("--lang requires --target"))
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	if target != "" {
 		if _, err := loadResolvedTargets(target); err != nil {
 			logError(T("%v"), err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 	}
 
@@ -507,7 +505,7 @@ func runLockReset(target, lang string, yes bool) {
 		}
 		if err := os.Remove(lf.Path()); err != nil && !errors.Is(err, os.ErrNotExist) {
 			logError(T("Removing %s: %v"), lf.Path(), err)
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
 		logSuccess(T("Reset complete: %s removed"), lf.Path())
 		return
@@ -527,7 +525,7 @@ func runLockReset(target, lang string, yes bool) {
 
 	if err := lf.Save(); err != nil {
 		logError(T("Could not save lock file: %v"), err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	if lang != "" {
@@ -537,21 +535,14 @@ func runLockReset(target, lang string, yes bool) {
 	logSuccess(T("Reset complete: target %s"), target)
 }
 
+// loadResolvedTargets returns the target named target, or all targets if
+// it is empty. Errors match engine.ErrNoConfig or engine.ErrConfig.
 func loadResolvedTargets(target string) ([]config.ResolvedTarget, error) {
-	lf, err := config.LoadLokitFile(rootDir)
+	e, err := engine.Load(rootDir)
 	if err != nil {
-		return nil, fmt.Errorf(T("Config error: %v"), err)
-	}
-	if lf == nil {
-		return nil, fmt.Errorf(T("No lokit.yaml found in %s"), rootDir)
-	}
-
-	resolved, err := lf.Resolve(rootDir)
-	if err != nil {
-		return nil, fmt.Errorf(T("Config resolve error: %v"), err)
+		return nil, err
 	}
-
-	return filterResolvedTargetsByNames(resolved, []string{target})
+	return e.Select([]string{target})
 }
 
 func collectSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
@@ -564,7 +555,7 @@ func collectSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
 		return collectI18NextSourceEntries(rt)
 	case config.TargetTypeVueI18n:
 		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
-			return collectIndexSourceEntries(rt)
+			return layout.IndexSourceEntries(rt)
 		}
 		return collectVueI18nSourceEntries(rt)
 	case config.TargetTypeAndroid:
@@ -583,6 +574,12 @@ func collectSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
 		return collectDesktopSourceEntries(rt)
 	case config.TargetTypePolkit:
 		return collectPolkitSourceEntries(rt)
+	case config.TargetTypeAppleStrings:
+		return collectAppleStringsSourceEntries(rt)
+	case config.TargetTypeXCStrings:
+		return collectXCStringsSourceEntries(rt)
+	case config.TargetTypeQt:
+		return collectQtSourceEntries(rt)
 	default:
 		return nil, fmt.Errorf(T("unsupported target type %q"), rt.Target.Type)
 	}
@@ -709,6 +706,31 @@ func collectTranslatedKeys(rt config.ResolvedTarget, lang string) (map[string]st
 			return polkit.ParseFile(p, lang)
 		})
 
+	case config.TargetTypeAppleStrings:
+		return collectTranslatedSimpleKV(rt.TranslationPath(lang), func(path string) (formatfile.KVFile, error) {
+			return applestrings.ParseFile(path)
+		})
+
+	case config.TargetTypeXCStrings:
+		path := rt.SourcePath()
+		return collectTranslatedSimpleKV(path, func(p string) (formatfile.KVFile, error) {
+			return xcstrings.ParseFile(p, lang)
+		})
+
+	case config.TargetTypeQt:
+		path := rt.TranslationPath(lang)
+		f, err := qt.ParseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys := make(map[string]struct{})
+		for _, e := range f.POFile(lang).Entries {
+			if e.IsTranslated() {
+				keys[lockfile.POEntryKey(e.MsgID, e.MsgCtxt)] = struct{}{}
+			}
+		}
+		return keys, nil
+
 	default:
 		return nil, fmt.Errorf(T("unsupported target type %q"), rt.Target.Type)
 	}
@@ -749,14 +771,6 @@ func collectPo4aLockUnits(rt config.ResolvedTarget, lang string) []po4aLockUnit
 	return units
 }
 
-func lockTargetKeysFor(rt config.ResolvedTarget, lang string) []string {
-	keys, ok := po4aAwareLockTargetKeysFor(rt, lang)
-	if !ok {
-		return []string{lockfile.LockTargetKey(rt.Target.Name, lang)}
-	}
-	return keys
-}
-
 func collectTranslatedSimpleKV(path string, parse func(path string) (formatfile.KVFile, error)) (map[string]struct{}, error) {
 	file, err := parse(path)
 	if err != nil {
@@ -766,8 +780,8 @@ func collectTranslatedSimpleKV(path string, parse func(path string) (formatfile.
 }
 
 func collectTranslatedMarkdownKeys(rt config.ResolvedTarget, lang string) (map[string]struct{}, error) {
-	srcDir := markdownSourceDir(rt)
-	srcFiles, err := discoverMarkdownSourceFiles(rt)
+	srcDir := layout.MarkdownSourceDir(rt)
+	srcFiles, err := layout.MarkdownSourceFiles(rt)
 	if err != nil {
 		return nil, fmt.Errorf(T("cannot read markdown files in %s: %v"), srcDir, err)
 	}
@@ -779,7 +793,7 @@ func collectTranslatedMarkdownKeys(rt config.ResolvedTarget, lang string) (map[s
 			continue
 		}
 		relSlash := filepath.ToSlash(relPath)
-		targetPath := filepath.Join(markdownLangDir(rt, lang), filepath.FromSlash(relSlash))
+		targetPath := filepath.Join(layout.MarkdownLangDir(rt, lang), filepath.FromSlash(relSlash))
 		mf, err := mdfile.ParseFile(targetPath)
 		if err != nil {
 			continue
@@ -914,7 +928,7 @@ func collectAndroidSourceEntries(rt config.ResolvedTarget) (map[string]string, e
 		return nil, fmt.Errorf(T("cannot read source strings.xml %s: %v"), srcPath, err)
 	}
 
-	srcVals := androidSourceValuesByUnit(srcFile)
+	srcVals := layout.AndroidSourceValues(srcFile)
 
 	entries := make(map[string]string)
 	for key, v := range srcVals {
@@ -923,28 +937,6 @@ func collectAndroidSourceEntries(rt config.ResolvedTarget) (map[string]string, e
 	return entries, nil
 }
 
-func androidSourceValuesByUnit(srcFile *android.File) map[string]string {
-	vals := make(map[string]string)
-	for _, e := range srcFile.Entries {
-		if !e.IsTranslatable() || e.IsComment() {
-			continue
-		}
-		switch e.Kind {
-		case android.KindString:
-			vals[e.Name] = e.Value
-		case android.KindStringArray:
-			for idx, v := range e.Items {
-				vals[fmt.Sprintf("%s[%d]", e.Name, idx)] = v
-			}
-		case android.KindPlurals:
-			for _, q := range e.PluralOrder {
-				vals[fmt.Sprintf("%s#%s", e.Name, q)] = e.Plurals[q]
-			}
-		}
-	}
-	return vals
-}
-
 func collectYAMLSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
 	return collectSimpleKVSourceEntries(rt, func(path string) (kvSourceFile, error) {
 		return yamlfile.ParseFile(path)
@@ -952,8 +944,8 @@ func collectYAMLSourceEntries(rt config.ResolvedTarget) (map[string]string, erro
 }
 
 func collectMarkdownSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
-	srcDir := markdownSourceDir(rt)
-	srcFiles, err := discoverMarkdownSourceFiles(rt)
+	srcDir := layout.MarkdownSourceDir(rt)
+	srcFiles, err := layout.MarkdownSourceFiles(rt)
 	if err != nil {
 		return nil, fmt.Errorf(T("cannot read markdown files in %s: %v"), srcDir, err)
 	}
@@ -992,6 +984,12 @@ func collectFlutterSourceEntries(rt config.ResolvedTarget) (map[string]string, e
 	})
 }
 
+func collectAppleStringsSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
+	return collectSimpleKVSourceEntries(rt, func(path string) (kvSourceFile, error) {
+		return applestrings.ParseFile(path)
+	})
+}
+
 func collectJSKVSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
 	srcPath := rt.ExistingSourcePath()
 	if srcPath == "" {
@@ -1035,6 +1033,37 @@ func collectPolkitSourceEntries(rt config.ResolvedTarget) (map[string]string, er
 	return entries, nil
 }
 
+func collectXCStringsSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
+	srcPath := rt.SourcePath()
+	srcFile, err := xcstrings.ParseFile(srcPath, rt.Target.SourceLang)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read source file %s: %v"), srcPath, err)
+	}
+	entries := make(map[string]string)
+	for key, v := range srcFile.SourceValues() {
+		entries[key] = lockfile.KVEntryContent(key, v)
+	}
+	return entries, nil
+}
+
+// collectQtSourceEntries keys Qt messages like gettext entries, as they are
+// translated through the PO pipeline.
+func collectQtSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
+	srcPath := rt.ExistingSourcePath()
+	if srcPath == "" {
+		srcPath = rt.SourcePath()
+	}
+	srcFile, err := qt.ParseFile(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read source file %s: %v"), srcPath, err)
+	}
+	entries := make(map[string]string)
+	for _, e := range srcFile.POFile(rt.Target.SourceLang).Entries {
+		entries[lockfile.POEntryKey(e.MsgID, e.MsgCtxt)] = lockfile.POEntryContent(e.MsgID, e.MsgIDPlural)
+	}
+	return entries, nil
+}
+
 func mapKeys(m map[string]string) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -1065,9 +1094,9 @@ func expectedLockTargets(resolved []config.ResolvedTarget) (map[string]struct{},
 	expected := make(map[string]struct{})
 	blockedScopes := make(map[string]struct{})
 	for _, rt := range resolved {
-		langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
+		langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
 		for _, lang := range langs {
-			keys, ok := po4aAwareLockTargetKeysFor(rt, lang)
+			keys, ok := layout.Po4aLockTargetKeys(rt, lang)
 			if !ok {
 				blockedScopes[lockfile.LockTargetKey(rt.Target.Name, lang)] = struct{}{}
 				continue
@@ -1080,32 +1109,6 @@ func expectedLockTargets(resolved []config.ResolvedTarget) (map[string]struct{},
 	return expected, blockedScopes
 }
 
-func po4aAwareLockTargetKeysFor(rt config.ResolvedTarget, lang string) ([]string, bool) {
-	if rt.Target.Type != config.TargetTypePo4a {
-		return []string{lockfile.LockTargetKey(rt.Target.Name, lang)}, true
-	}
-
-	masters, err := rt.DocsPOMasters()
-	if err == nil && len(masters) > 0 {
-		keys := make([]string, 0, len(masters))
-		for _, master := range masters {
-			keys = append(keys, lockfile.LockTargetKey(rt.Target.Name+"/"+master, lang))
-		}
-		return keys, true
-	}
-
-	files := rt.DocsPOFiles(lang)
-	if len(files) == 0 {
-		return nil, false
-	}
-
-	keys := make([]string, 0, len(files))
-	for _, file := range files {
-		keys = append(keys, lockfile.LockTargetKey(rt.Target.Name+"/"+file.Master, lang))
-	}
-	return keys, true
-}
-
 type orphanScope struct {
 	name       string
 	targetType string
@@ -1160,7 +1163,7 @@ func orphanCleanupScopes(allResolved, selected []config.ResolvedTarget, targets
 }
 
 func orphanScopeForTarget(rt config.ResolvedTarget) orphanScope {
-	langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
+	langs := layout.FilterOutLang(rt.Languages, rt.Target.SourceLang)
 	langSet := make(map[string]struct{}, len(langs))
 	for _, lang := range langs {
 		langSet[lang] = struct{}{}
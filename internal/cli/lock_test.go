@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/internal/layout"
 	"github.com/minios-linux/lokit/lockfile"
 )
 
@@ -185,9 +186,9 @@ func TestExpectedLockTargetsPo4aFromConfiguredMasters(t *testing.T) {
 		}
 	}
 
-	statusKeys := lockTargetKeysFor(resolved[0], "de")
+	statusKeys := layout.LockTargetKeys(resolved[0], "de")
 	if len(statusKeys) != 2 || statusKeys[0] != "docs/chapter1.1/de" || statusKeys[1] != "docs/chapter2.1/de" {
-		t.Fatalf("lockTargetKeysFor = %v, want configured po4a masters", statusKeys)
+		t.Fatalf("layout.LockTargetKeys = %v, want configured po4a masters", statusKeys)
 	}
 }
 
@@ -340,3 +341,25 @@ func TestTranslatedKeysFromKVFile(t *testing.T) {
 		t.Fatalf("k2 should be untranslated")
 	}
 }
+
+func TestLoadResolvedTargetsExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	oldRoot := rootDir
+	rootDir = dir
+	t.Cleanup(func() { rootDir = oldRoot })
+
+	if _, err := loadResolvedTargets(""); exitCode(err) != exitConfig {
+		t.Fatalf("without lokit.yaml: exitCode(%v) = %d, want %d", err, exitCode(err), exitConfig)
+	}
+
+	cfg := "languages: [de]\ntargets:\n  - name: app\n    format: i18next\n    dir: locales\n    pattern: '{lang}.json'\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write lokit.yaml: %v", err)
+	}
+	if _, err := loadResolvedTargets("app"); err != nil {
+		t.Fatalf("loadResolvedTargets(app): %v", err)
+	}
+	if _, err := loadResolvedTargets("nope"); exitCode(err) != exitConfig {
+		t.Fatalf("unknown target: exitCode(%v) = %d, want %d", err, exitCode(err), exitConfig)
+	}
+}
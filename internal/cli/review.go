@@ -0,0 +1,459 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/qt"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/tm"
+	"github.com/spf13/cobra"
+)
+
+func newReviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: T("Review machine translations"),
+		Long: T(`List, accept or reject translations written by an AI provider.
+
+lokit translate marks every machine translation for review. Gettext entries
+and Qt messages get a "lokit: machine, model=..." translator comment (plus
+the fuzzy flag, or the unfinished type in Qt files, when review.mark_fuzzy is
+set in lokit.yaml); translations in other formats are tracked in
+lokit.review next to lokit.yaml.
+
+Accepting a translation removes the mark. Rejecting it clears the gettext or
+Qt translation, or flags the key in lokit.review, so the next translate run
+translates it again.
+
+Subcommands:
+  list    List machine translations pending review
+  accept  Accept machine translations
+  reject  Reject machine translations`),
+	}
+
+	cmd.AddCommand(
+		newReviewListCmd(),
+		newReviewDecisionCmd(true),
+		newReviewDecisionCmd(false),
+	)
+
+	return cmd
+}
+
+func newReviewListCmd() *cobra.Command {
+	var targets []string
+	var langFlag string
+	var jsonOut, strict bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: T("List machine translations pending review"),
+		Long: T(`List machine translations pending review, grouped by target and language.
+
+Exit status is 5 with --strict when any translation is pending, so the
+command can gate releases on human review.
+
+Examples:
+  lokit review list
+  lokit review list --target app --lang ru
+  lokit review list --json
+  lokit review list --strict`),
+		Run: func(cmd *cobra.Command, args []string) {
+			runReviewList(targets, langFlag, jsonOut, strict)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+	cmd.Flags().StringVarP(&langFlag, "lang", "l", "", T("Comma-separated languages (default: all)"))
+	cmd.Flags().BoolVar(&jsonOut, "json", false, T("Output pending translations as JSON"))
+	cmd.Flags().BoolVar(&strict, "strict", false, T("Exit with status 5 when translations are pending review"))
+	return cmd
+}
+
+func newReviewDecisionCmd(accept bool) *cobra.Command {
+	var targets []string
+	var langFlag string
+	var all bool
+
+	use, short, long := "accept", T("Accept machine translations"), T(`Accept machine translations, removing their review mark.
+
+Keys are shown by 'lokit review list'. Gettext keys are the msgid, or
+"context|msgid" for entries with a context. Use --all to accept every
+pending translation of the selected targets and languages.
+
+Examples:
+  lokit review accept --target app --lang ru "Open file"
+  lokit review accept --target app --lang ru --all`)
+	if !accept {
+		use, short, long = "reject", T("Reject machine translations"), T(`Reject machine translations so the next translate run translates them again.
+
+Rejected gettext entries are cleared. Rejected keys in other formats keep
+their value until they are translated again. Rejected translations are also
+removed from the translation memory.
+
+Examples:
+  lokit review reject --target app --lang ru "Open file"
+  lokit review reject --target app --lang ru --all`)
+	}
+
+	cmd := &cobra.Command{
+		Use:   use + " [KEY...]",
+		Short: short,
+		Long:  long,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 && !all {
+				logError(T("Specify keys to %s or use --all"), use)
+				os.Exit(exitConfig)
+			}
+			runReviewDecision(targets, langFlag, args, accept)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+	cmd.Flags().StringVarP(&langFlag, "lang", "l", "", T("Comma-separated languages (default: all)"))
+	cmd.Flags().BoolVar(&all, "all", false, T("Apply to every pending translation of the selected targets and languages"))
+	return cmd
+}
+
+// reviewItem is one machine translation found in a target.
+type reviewItem struct {
+	Target      string `json:"target"`
+	Lang        string `json:"lang"`
+	File        string `json:"file,omitempty"`
+	Key         string `json:"key"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+	Model       string `json:"model"`
+	Rejected    bool   `json:"rejected,omitempty"`
+
+	entry     *po.Entry // set for gettext/po4a/qt items only
+	catalog   string    // file path for gettext/po4a/qt items
+	lockScope string    // lock target key for other formats
+}
+
+// reviewCatalog is a PO file, or the PO view of a Qt file, loaded for
+// review. write saves its entries.
+type reviewCatalog struct {
+	write func() error
+	dirty bool
+}
+
+type reviewSession struct {
+	state    *review.State
+	catalogs map[string]*reviewCatalog
+	items    []reviewItem
+}
+
+// loadReviewSession collects the machine translations of the selected
+// targets and languages.
+func loadReviewSession(targets []string, langFlag string) *reviewSession {
+	resolved, err := loadEngine().Select(targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	state, err := review.Load(rootDir)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitFailure)
+	}
+
+	var onlyLangs map[string]bool
+	if langFlag != "" {
+		onlyLangs = make(map[string]bool)
+		for _, l := range strings.Split(langFlag, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				onlyLangs[l] = true
+			}
+		}
+	}
+
+	s := &reviewSession{state: state, catalogs: make(map[string]*reviewCatalog)}
+	for _, rt := range resolved {
+		for _, lang := range layout.FilterOutLang(rt.Languages, rt.Target.SourceLang) {
+			if onlyLangs != nil && !onlyLangs[lang] {
+				continue
+			}
+			switch rt.Target.Type {
+			case config.TargetTypeGettext:
+				s.addPOItems(rt.Target.Name, lang, rt.POPath(lang))
+			case config.TargetTypePo4a:
+				for _, file := range rt.DocsPOFiles(lang) {
+					s.addPOItems(rt.Target.Name, lang, file.Path)
+				}
+			case config.TargetTypeQt:
+				s.addQtItems(rt.Target.Name, lang, rt.TranslationPath(lang))
+			default:
+				s.addStateItems(rt.Target.Name, lang)
+			}
+		}
+	}
+	return s
+}
+
+func (s *reviewSession) addPOItems(target, lang, path string) {
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	catalog, err := po.ParseFile(path)
+	if err != nil {
+		logWarning(T("[%s] %s: %v"), target, lang, err)
+		return
+	}
+	s.addCatalogItems(target, lang, path, catalog, func() error { return catalog.WriteFile(path) })
+}
+
+func (s *reviewSession) addQtItems(target, lang, path string) {
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	file, err := qt.ParseFile(path)
+	if err != nil {
+		logWarning(T("[%s] %s: %v"), target, lang, err)
+		return
+	}
+	catalog := file.POFile(lang)
+	s.addCatalogItems(target, lang, path, catalog, func() error {
+		file.ApplyPO(catalog)
+		return file.WriteFile(path)
+	})
+}
+
+// addCatalogItems adds the machine-translated entries of catalog, read from
+// path and saved by write.
+func (s *reviewSession) addCatalogItems(target, lang, path string, catalog *po.File, write func() error) {
+	s.catalogs[path] = &reviewCatalog{write: write}
+	for _, e := range catalog.Entries {
+		if e.MsgID == "" || e.Obsolete {
+			continue
+		}
+		model, ok := review.POModel(e)
+		if !ok {
+			continue
+		}
+		key := e.MsgID
+		if e.MsgCtxt != "" {
+			key = e.MsgCtxt + "|" + e.MsgID
+		}
+		translation := e.MsgStr
+		if e.MsgIDPlural != "" {
+			forms := make([]string, len(e.MsgStrPlural))
+			for i := range forms {
+				forms[i] = e.MsgStrPlural[i]
+			}
+			translation = strings.Join(forms, " | ")
+		}
+		s.items = append(s.items, reviewItem{
+			Target:      target,
+			Lang:        lang,
+			File:        layout.RelPath(rootDir, path),
+			Key:         key,
+			Source:      e.MsgID,
+			Translation: translation,
+			Model:       model,
+			entry:       e,
+			catalog:     path,
+		})
+	}
+}
+
+func (s *reviewSession) addStateItems(target, lang string) {
+	scope := lockfile.LockTargetKey(target, lang)
+	for _, key := range s.state.Keys(scope) {
+		e, _ := s.state.Get(scope, key)
+		s.items = append(s.items, reviewItem{
+			Target:      target,
+			Lang:        lang,
+			Key:         key,
+			Source:      e.Source,
+			Translation: e.Translation,
+			Model:       e.Model,
+			Rejected:    e.Rejected,
+			lockScope:   scope,
+		})
+	}
+}
+
+func runReviewList(targets []string, langFlag string, jsonOut, strict bool) {
+	s := loadReviewSession(targets, langFlag)
+	pending, rejected := 0, 0
+	for _, item := range s.items {
+		if item.Rejected {
+			rejected++
+		} else {
+			pending++
+		}
+	}
+
+	if jsonOut {
+		items := s.items
+		if items == nil {
+			items = []reviewItem{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(items); err != nil {
+			logError(T("JSON output error: %v"), err)
+			os.Exit(exitFailure)
+		}
+	} else {
+		printReviewItems(s.items, pending, rejected)
+	}
+
+	// Rejected keys still hold the rejected text until the next translate
+	// run, so they fail the gate as well.
+	if strict && pending+rejected > 0 {
+		os.Exit(exitValidation)
+	}
+}
+
+func printReviewItems(items []reviewItem, pending, rejected int) {
+	sectionHeader(T("Review"))
+	lastGroup := ""
+	for _, item := range items {
+		if group := item.Target + "\x00" + item.Lang; group != lastGroup {
+			targetHeader(item.Target, item.Lang)
+			lastGroup = group
+		}
+		status := ""
+		if item.Rejected {
+			status = " " + colorRed + T("rejected") + colorReset
+		}
+		location := item.Key
+		if item.File != "" {
+			location = item.File + ":" + item.Key
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s(%s)%s%s\n", location, colorDim, item.Model, colorReset, status)
+		fmt.Fprintf(os.Stderr, "    %s%s%s\n    → %s\n", colorDim, reviewSnippet(item.Source), colorReset, reviewSnippet(item.Translation))
+	}
+	fmt.Fprintln(os.Stderr)
+	if pending > 0 {
+		logWarning(T("%d machine translations pending review"), pending)
+	}
+	if rejected > 0 {
+		logWarning(T("%d rejected translations waiting to be translated again"), rejected)
+	}
+	if pending+rejected == 0 {
+		logSuccess("%s", T("No machine translations pending review"))
+	}
+}
+
+// reviewSnippet returns s on one line, shortened for display.
+func reviewSnippet(s string) string {
+	s = strings.NewReplacer("\n", `\n`, "\t", `\t`).Replace(s)
+	if runes := []rune(s); len(runes) > 100 {
+		s = string(runes[:99]) + "…"
+	}
+	return s
+}
+
+func runReviewDecision(targets []string, langFlag string, keys []string, accept bool) {
+	s := loadReviewSession(targets, langFlag)
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	mem, err := tm.Load(rootDir)
+	if err != nil {
+		logWarning(T("Could not load translation memory: %v"), err)
+		mem = nil
+	}
+
+	matched := make(map[string]bool, len(keys))
+	count := 0
+	for _, item := range s.items {
+		if len(wanted) > 0 && !wanted[item.Key] {
+			continue
+		}
+		matched[item.Key] = true
+		if s.decide(item, accept, mem) {
+			count++
+		}
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if !matched[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		logWarning(T("No machine translation of %q found"), key)
+	}
+
+	s.save(mem)
+	if accept {
+		logSuccess(T("Accepted %d translations"), count)
+	} else {
+		logSuccess(T("Rejected %d translations; they will be translated again on the next run"), count)
+	}
+}
+
+// decide accepts or rejects one item and updates the translation memory.
+func (s *reviewSession) decide(item reviewItem, accept bool, mem *tm.Memory) bool {
+	if item.entry == nil {
+		if accept {
+			return s.state.Accept(item.lockScope, item.Key)
+		}
+		if mem != nil {
+			mem.Forget(item.Lang, item.Source, item.Translation)
+		}
+		return s.state.Reject(item.lockScope, item.Key)
+	}
+
+	e := item.entry
+	memKey := tm.SourceKey(e.MsgID, e.MsgCtxt)
+	translation := e.MsgStr
+	var ok bool
+	if accept {
+		ok = review.AcceptPO(e)
+		if ok && mem != nil && e.MsgIDPlural == "" {
+			mem.Add(item.Lang, memKey, translation)
+		}
+	} else {
+		ok = review.RejectPO(e)
+		if ok && mem != nil && e.MsgIDPlural == "" {
+			mem.Forget(item.Lang, memKey, translation)
+		}
+	}
+	if ok {
+		s.catalogs[item.catalog].dirty = true
+	}
+	return ok
+}
+
+func (s *reviewSession) save(mem *tm.Memory) {
+	paths := make([]string, 0, len(s.catalogs))
+	for path, c := range s.catalogs {
+		if c.dirty {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := s.catalogs[path].write(); err != nil {
+			logError(T("Error saving %s: %v"), path, err)
+			os.Exit(exitFailure)
+		}
+	}
+	if err := s.state.Save(); err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitFailure)
+	}
+	if mem != nil {
+		if err := mem.Save(); err != nil {
+			logWarning(T("Could not save translation memory: %v"), err)
+		}
+	}
+}
@@ -2,18 +2,15 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 	"unicode/utf8"
 
-	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
+	"github.com/minios-linux/lokit/format/i18next"
 	. "github.com/minios-linux/lokit/i18n"
-	"github.com/minios-linux/lokit/internal/format/android"
-	"github.com/minios-linux/lokit/internal/format/i18next"
-	po "github.com/minios-linux/lokit/internal/format/po"
-	"github.com/minios-linux/lokit/translate"
 	"github.com/spf13/cobra"
 )
 
@@ -52,30 +49,36 @@ func logError(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, colorRed+"  ✗ "+colorReset+format+"\n", args...)
 }
 
-// compileLockedPatterns compiles locked_patterns strings into regexps.
-// Invalid patterns are logged as warnings and skipped.
-func compileLockedPatterns(patterns []string) []*regexp.Regexp {
-	if len(patterns) == 0 {
-		return nil
+// logEngine prints a message logged by the engine.
+func logEngine(level engine.Level, format string, args ...any) {
+	switch level {
+	case engine.LevelSuccess:
+		logSuccess(format, args...)
+	case engine.LevelWarning:
+		logWarning(format, args...)
+	case engine.LevelError:
+		logError(format, args...)
+	default:
+		logInfo(format, args...)
 	}
-	var compiled []*regexp.Regexp
-	for _, p := range patterns {
-		re, err := regexp.Compile(p)
-		if err != nil {
-			logWarning(T("Invalid locked_pattern %q: %v"), p, err)
-			continue
-		}
-		compiled = append(compiled, re)
-	}
-	return compiled
 }
 
-// setExclusionOpts populates the locked/ignored key fields on translate.Options
-// from the given target configuration.
-func setExclusionOpts(opts *translate.Options, t *config.Target) {
-	opts.LockedKeys = t.LockedKeys
-	opts.IgnoredKeys = t.IgnoredKeys
-	opts.LockedPatterns = compileLockedPatterns(t.LockedPatterns)
+// loadEngine loads lokit.yaml from the project root, exiting if it is
+// missing or invalid. The engine logs like the commands do.
+func loadEngine() *engine.Engine {
+	e, err := engine.Load(rootDir)
+	if errors.Is(err, engine.ErrNoConfig) {
+		logError(T("No lokit.yaml found in %s"), rootDir)
+		logInfo(T("Create a lokit.yaml configuration file. See 'lokit init --help' for format reference."))
+		os.Exit(exitConfig)
+	}
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	e.OnLog = logEngine
+	e.Output = os.Stderr
+	return e
 }
 
 // progressBar renders a text progress bar: [████████░░░░] 75%
@@ -195,6 +198,9 @@ Supported target formats configured via lokit.yaml:
   js-kv       JavaScript assignment key-value translation files
   desktop     freedesktop desktop entry translations
   polkit      PolicyKit XML policy translations
+  apple-strings  Apple .strings and .stringsdict files ({lang}.lproj)
+  xcstrings   Xcode String Catalogs (.xcstrings)
+  qt          Qt Linguist translation files (.ts)
 
 Configuration:
   Project settings are defined in lokit.yaml at the project root.
@@ -204,12 +210,14 @@ Configuration:
 AI Providers:
   google         Google AI (Gemini) — API key
   gemini         Gemini CLI — browser OAuth
+  anthropic      Anthropic Messages API — API key required
   groq           Groq — API key required
   opencode       OpenCode Zen API — API key (optional)
   copilot        GitHub Copilot — native OAuth
   openai         OpenAI — browser OAuth, device code, or API key
   ollama         Ollama local server
-  custom-openai  Custom OpenAI-compatible endpoint`),
+  custom-openai  Custom OpenAI-compatible endpoint
+  mock           Offline pseudo-translation for testing`),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
@@ -297,7 +305,13 @@ AI Providers:
 	root.AddCommand(
 		newStatusCmd(),
 		newInitCmd(),
+		newBuildCmd(),
 		newTranslateCmd(),
+		newWatchCmd(),
+		newCheckCmd(),
+		newReviewCmd(),
+		newExportCmd(),
+		newImportCmd(),
 		newLockCmd(),
 		newAuthCmd(),
 		newVersionCmd(),
@@ -325,8 +339,9 @@ func Execute() {
 	translateHelpFlags(root)
 	root.SetArgs(normalizeCLIArgs(os.Args[1:]))
 	if err := root.Execute(); err != nil {
+		// Commands exit themselves; errors here come from argument parsing.
 		logError(T("%v"), err)
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 }
 
@@ -349,360 +364,6 @@ func normalizeCLIArgs(args []string) []string {
 	return out
 }
 
-// ---------------------------------------------------------------------------
-// ---------------------------------------------------------------------------
-// status (read-only: project info + translation stats)
-// ---------------------------------------------------------------------------
-
-// showConfigGettextStats shows translation stats for a gettext target.
-func showConfigGettextStats(rt config.ResolvedTarget, langs []string) {
-	potPath := rt.AbsPOTFile()
-
-	potTotal := 0
-	if potPO, err := po.ParseFile(potPath); err == nil {
-		for _, e := range potPO.Entries {
-			if e.MsgID != "" && !e.Obsolete {
-				potTotal++
-			}
-		}
-	}
-
-	if potTotal == 0 {
-		keyVal(T("POT"), colorYellow+T("not found")+colorReset+" ("+T("run 'lokit init'")+")")
-		return
-	}
-
-	keyVal(T("Source strings"), fmt.Sprintf("%d", potTotal))
-	langWidth := langColumnWidth(langs)
-
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Fuzzy"), T("Left"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 52)+colorReset)
-
-	for _, lang := range langs {
-		poPath := rt.POPath(lang)
-		poFile, err := po.ParseFile(poPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n", langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		_, translated, fuzzy, untranslated := poFile.Stats()
-		percent := 0
-		if potTotal > 0 {
-			percent = translated * 100 / potTotal
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, fuzzy, untranslated)
-	}
-}
-
-// showConfigPo4aStats shows translation stats for a po4a target.
-func showConfigPo4aStats(rt config.ResolvedTarget, langs []string) {
-	cfgPath := rt.AbsPo4aConfig()
-	keyVal(T("po4a config"), cfgPath)
-	langWidth := langColumnWidth(langs)
-
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Fuzzy"), T("Total"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 52)+colorReset)
-
-	for _, lang := range langs {
-		poPaths := rt.DocsPOPaths(lang)
-		if len(poPaths) == 0 {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
-				langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		total, translated, fuzzy := 0, 0, 0
-		missing := false
-		for _, poPath := range poPaths {
-			catalog, err := po.ParseFile(poPath)
-			if err != nil {
-				missing = true
-				continue
-			}
-			fileTotal, fileTranslated, fileFuzzy, _ := catalog.Stats()
-			total += fileTotal
-			translated += fileTranslated
-			fuzzy += fileFuzzy
-		}
-		percent := 0
-		if total > 0 {
-			percent = (translated * 100) / total
-		}
-		if missing {
-			fmt.Fprintf(os.Stderr, "  %s %s %5d %5d %5d %s%s%s\n",
-				langCell(lang, langWidth), progressBar(percent, 16), translated, fuzzy, total,
-				colorYellow, T("missing files"), colorReset)
-			continue
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, fuzzy, total)
-	}
-}
-
-// showConfigI18NextStats shows translation stats for a flat JSON target.
-func showConfigI18NextStats(rt config.ResolvedTarget, langs []string) {
-	transDir := rt.AbsTranslationsDir()
-	keyVal(T("Translations"), transDir)
-
-	srcPath := rt.ExistingSourcePath()
-	if srcPath == "" {
-		srcPath = rt.SourcePath()
-	}
-	srcFile, err := i18next.ParseFile(srcPath)
-	if err != nil {
-		keyVal(T("Source"), colorYellow+T("not found")+colorReset+" ("+srcPath+")")
-		return
-	}
-	srcKeys := len(srcFile.Translations)
-	keyVal(T("Source keys"), fmt.Sprintf("%d (%s)", srcKeys, rt.Target.SourceLang))
-	langWidth := langColumnWidth(langs)
-
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Left"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 46)+colorReset)
-
-	for _, lang := range langs {
-		filePath := rt.ExistingTranslationPath(lang)
-		if filePath == "" {
-			filePath = rt.TranslationPath(lang)
-		}
-		file, err := i18next.ParseFile(filePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
-				langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		_, translated, _ := file.Stats()
-		untranslated := srcKeys - translated
-		if untranslated < 0 {
-			untranslated = 0
-		}
-		percent := 0
-		if srcKeys > 0 {
-			percent = translated * 100 / srcKeys
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, untranslated)
-	}
-
-}
-
-// showConfigAndroidStats shows translation stats for an Android target.
-func showConfigAndroidStats(rt config.ResolvedTarget, langs []string) {
-	resDir := rt.AbsResDir()
-	srcPath := android.SourceStringsXMLPath(resDir)
-	keyVal(T("Res dir"), resDir)
-
-	srcFile, err := android.ParseFile(srcPath)
-	if err != nil {
-		keyVal(T("Source"), colorYellow+T("not found")+colorReset+" ("+srcPath+")")
-		return
-	}
-	srcTotal, _, _ := srcFile.Stats()
-	keyVal(T("Source strings"), fmt.Sprintf("%d (%s)", srcTotal, rt.Target.SourceLang))
-	langWidth := langColumnWidth(langs)
-
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Left"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 46)+colorReset)
-
-	for _, lang := range langs {
-		filePath := android.StringsXMLPath(resDir, lang)
-		file, err := android.ParseFile(filePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
-				langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		_, translated, _ := file.Stats()
-		untranslated := srcTotal - translated
-		if untranslated < 0 {
-			untranslated = 0
-		}
-		percent := 0
-		if srcTotal > 0 {
-			percent = translated * 100 / srcTotal
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, untranslated)
-	}
-}
-
-func showStatsTable(proj *config.Project, potPath string) {
-	potTotal := 0
-	if potPO, err := po.ParseFile(potPath); err == nil {
-		for _, e := range potPO.Entries {
-			if e.MsgID != "" && !e.Obsolete {
-				potTotal++
-			}
-		}
-	}
-
-	if potTotal == 0 {
-		logInfo(T("No translatable strings found in %s"), potPath)
-		return
-	}
-
-	sectionHeader(T("Translation Statistics"))
-	keyVal(T("Source strings"), fmt.Sprintf("%d", potTotal))
-	langWidth := langColumnWidth(proj.Languages)
-
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Fuzzy"), T("Left"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 52)+colorReset)
-
-	type langIssue struct {
-		lang         string
-		untranslated int
-		fuzzy        int
-	}
-	var issues []langIssue
-
-	for _, lang := range proj.Languages {
-		poPath := proj.POPath(lang)
-
-		poFile, err := po.ParseFile(poPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
-				langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		_, translated, fuzzy, untranslated := poFile.Stats()
-		percent := 0
-		if potTotal > 0 {
-			percent = translated * 100 / potTotal
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, fuzzy, untranslated)
-
-		if untranslated > 0 || fuzzy > 0 {
-			issues = append(issues, langIssue{lang, untranslated, fuzzy})
-		}
-	}
-
-	if len(issues) > 0 {
-		fmt.Fprintln(os.Stderr)
-		logInfo(T("Translation gaps:"))
-		for _, issue := range issues {
-			parts := []string{}
-			if issue.untranslated > 0 {
-				parts = append(parts, fmt.Sprintf(T("%d untranslated"), issue.untranslated))
-			}
-			if issue.fuzzy > 0 {
-				parts = append(parts, fmt.Sprintf(T("%d fuzzy"), issue.fuzzy))
-			}
-			fmt.Fprintf(os.Stderr, "  %s: %s\n", issue.lang, strings.Join(parts, ", "))
-		}
-	}
-
-	fmt.Fprintln(os.Stderr)
-}
-
-func showPo4aStats(proj *config.Project) {
-	if len(proj.Languages) == 0 {
-		return
-	}
-
-	sectionHeader(T("Documentation Statistics"))
-	langWidth := langColumnWidth(proj.Languages)
-
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Fuzzy"), T("Total"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 52)+colorReset)
-
-	for _, lang := range proj.Languages {
-		poPath := proj.POPath(lang)
-		catalog, err := po.ParseFile(poPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
-				langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		total, translated, fuzzy, _ := catalog.Stats()
-		percent := 0
-		if total > 0 {
-			percent = (translated * 100) / total
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, fuzzy, total)
-	}
-
-	fmt.Fprintln(os.Stderr)
-}
-
-func showI18NextStats(proj *config.Project) {
-	if len(proj.Languages) == 0 {
-		logInfo(T("No language files detected in %s"), proj.I18NextDir)
-		return
-	}
-
-	// Show source language key count first
-	srcFile, err := i18next.ParseFile(proj.I18NextPath(proj.SourceLang))
-	srcKeys := 0
-	if err == nil {
-		srcKeys = len(srcFile.Translations)
-	}
-
-	sectionHeader(T("UI Translation Statistics"))
-	if srcKeys > 0 {
-		keyVal(T("Source keys"), fmt.Sprintf("%d (%s)", srcKeys, proj.SourceLang))
-	}
-	langWidth := langColumnWidth(proj.Languages)
-
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Left"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 46)+colorReset)
-
-	// Skip source language
-	for _, lang := range proj.Languages {
-		if lang == proj.SourceLang {
-			continue
-		}
-		filePath := proj.I18NextPath(lang)
-		file, err := i18next.ParseFile(filePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
-				langCell(lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
-			continue
-		}
-
-		_, translated, _ := file.Stats()
-		untranslated := srcKeys - translated
-		if untranslated < 0 {
-			untranslated = 0
-		}
-		percent := 0
-		if srcKeys > 0 {
-			percent = translated * 100 / srcKeys
-		}
-
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, untranslated)
-	}
-
-	fmt.Fprintln(os.Stderr)
-}
-
 // ---------------------------------------------------------------------------
 // init (extract + create/update PO files)
 // ---------------------------------------------------------------------------
@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"os"
-	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -73,43 +71,6 @@ func TestLangHelpers(t *testing.T) {
 	}
 }
 
-func TestIntersectLanguages(t *testing.T) {
-	available := []string{"en", "fr", "de", "es"}
-	filter := []string{" fr ", "es", "it"}
-	want := []string{"fr", "es"}
-
-	if got := intersectLanguages(available, filter); !reflect.DeepEqual(got, want) {
-		t.Fatalf("intersectLanguages() = %#v, want %#v", got, want)
-	}
-}
-
-func TestFilterOutLang(t *testing.T) {
-	langs := []string{"en", "fr", "en", "de"}
-	want := []string{"fr", "de"}
-
-	if got := filterOutLang(langs, "en"); !reflect.DeepEqual(got, want) {
-		t.Fatalf("filterOutLang() = %#v, want %#v", got, want)
-	}
-}
-
-func TestFileExists(t *testing.T) {
-	dir := t.TempDir()
-	filePath := filepath.Join(dir, "file.txt")
-	if err := os.WriteFile(filePath, []byte("ok"), 0644); err != nil {
-		t.Fatalf("os.WriteFile() error: %v", err)
-	}
-
-	if !fileExists(filePath) {
-		t.Fatalf("fileExists(file) = false, want true")
-	}
-	if fileExists(dir) {
-		t.Fatalf("fileExists(directory) = true, want false")
-	}
-	if fileExists(filepath.Join(dir, "missing.txt")) {
-		t.Fatalf("fileExists(missing) = true, want false")
-	}
-}
-
 func TestNormalizeCLIArgsParallel(t *testing.T) {
 	tests := []struct {
 		name string
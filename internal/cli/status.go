@@ -3,18 +3,20 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
 	. "github.com/minios-linux/lokit/i18n"
-	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/internal/layout"
 	"github.com/spf13/cobra"
 )
 
 func newStatusCmd() *cobra.Command {
 	var targets []string
+	var format string
+	var jsonOut bool
+	var failOnUntranslated string
 
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -23,274 +25,299 @@ func newStatusCmd() *cobra.Command {
 
 Displays target format, file structure, configured or detected languages, and per-language
 translation progress for gettext, po4a, i18next, vue-i18n, android,
-yaml, markdown, properties, flutter, js-kv, desktop, and polkit projects. For projects
+yaml, markdown, properties, flutter, js-kv, desktop, polkit, apple-strings, xcstrings, and qt projects. For projects
 configured via lokit.yaml, shows each target separately.
 
-Does not modify any files.`),
+Does not modify any files.
+
+Use --json or --format csv to print per-target, per-language counts and lock
+coverage to stdout for scripts and dashboards.
+
+Use --fail-on-untranslated to exit with code 5 when a language has more
+untranslated or fuzzy strings than a count (e.g. 10) or a percentage of the
+source strings (e.g. 5%). Missing translation files count as untranslated.
+
+Examples:
+  lokit status
+  lokit status --target app
+  lokit status --json
+  lokit status --format csv > status.csv
+  lokit status --fail-on-untranslated 0`),
 		Run: func(cmd *cobra.Command, args []string) {
-			runStatus(targets)
+			if jsonOut {
+				format = statusFormatJSON
+			}
+			switch format {
+			case statusFormatText, statusFormatJSON, statusFormatCSV:
+			default:
+				logError(T("Unknown output format %q (expected text, json or csv)"), format)
+				os.Exit(exitConfig)
+			}
+			var limit *untranslatedLimit
+			if cmd.Flags().Changed("fail-on-untranslated") {
+				l, err := parseUntranslatedLimit(failOnUntranslated)
+				if err != nil {
+					logError(T("%v"), err)
+					os.Exit(exitConfig)
+				}
+				limit = &l
+			}
+			runStatus(targets, format, limit)
 		},
 	}
 
 	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+	cmd.Flags().StringVar(&format, "format", statusFormatText, T("Output format: text, json or csv"))
+	cmd.Flags().BoolVar(&jsonOut, "json", false, T("Output statistics as JSON (same as --format json)"))
+	cmd.Flags().StringVar(&failOnUntranslated, "fail-on-untranslated", "", T("Exit with code 5 if a language has more untranslated or fuzzy strings than this count or percentage (e.g. 0, 10, 5%)"))
 
 	return cmd
 }
 
-func runStatus(targets []string) {
-	lf, err := config.LoadLokitFile(rootDir)
-	if err != nil {
-		logError(T("Config error: %v"), err)
-		os.Exit(1)
+func runStatus(targets []string, format string, limit *untranslatedLimit) {
+	e := loadEngine()
+	if format != statusFormatText {
+		runStatusReport(e, targets, format)
+	} else {
+		runStatusText(e, targets)
 	}
-	if lf != nil {
-		runStatusWithConfig(lf, targets)
+	if limit == nil {
 		return
 	}
-
-	logError(T("No lokit.yaml found in %s"), rootDir)
-	logInfo(T("Create a lokit.yaml configuration file. See 'lokit init --help' for format reference."))
-	os.Exit(1)
+	report, err := e.Status(targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	if checkUntranslated(report, *limit, nil) {
+		os.Exit(exitValidation)
+	}
 }
 
-func runStatusWithConfig(lf *config.LokitFile, targets []string) {
-	absRoot, _ := filepath.Abs(rootDir)
+func runStatusText(e *engine.Engine, targets []string) {
+	report, err := e.Status(targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	// Status reports the targets in the order Select returns them.
+	resolved, _ := e.Select(targets)
 
 	sectionHeader(T("Project"))
 	keyVal(T("Config"), "lokit.yaml")
-	keyVal(T("Root"), absRoot)
-	keyVal(T("Source lang"), lf.SourceLang)
+	keyVal(T("Root"), report.Root)
+	keyVal(T("Source lang"), report.SourceLang)
 
-	if len(lf.Languages) > 0 {
-		keyVal(T("Languages"), strings.Join(lf.Languages, ", "))
+	if len(e.Config.Languages) > 0 {
+		keyVal(T("Languages"), strings.Join(e.Config.Languages, ", "))
 	}
-	keyVal(T("Targets"), fmt.Sprintf("%d", len(lf.Targets)))
+	keyVal(T("Targets"), fmt.Sprintf("%d", len(e.Config.Targets)))
 
-	lockF, err := lockfile.Load(rootDir)
-	lockPath := filepath.Join(rootDir, lockfile.LockFileName)
-	lockExists := true
-	if _, statErr := os.Stat(lockPath); os.IsNotExist(statErr) {
-		lockExists = false
-	}
-	if err != nil {
-		keyVal(T("Lock file"), colorYellow+fmt.Sprintf(T("error: %v"), err)+colorReset)
-		lockF = &lockfile.LockFile{Version: lockfile.Version, Checksums: make(map[string]map[string]string)}
-	} else if !lockExists {
+	switch {
+	case !report.Lock.Exists:
 		keyVal(T("Lock file"), T("not found"))
-	} else {
-		lockTargets, keys := lockF.Stats()
-		if lockTargets == 0 {
-			keyVal(T("Lock file"), T("empty"))
-		} else {
-			keyVal(T("Lock file"), fmt.Sprintf(T("%d targets, %d keys"), lockTargets, keys))
-		}
-	}
-
-	resolved, err := lf.Resolve(rootDir)
-	if err != nil {
-		logError(T("Config resolve error: %v"), err)
-		os.Exit(1)
-	}
-	resolved, err = filterResolvedTargetsByNames(resolved, targets)
-	if err != nil {
-		logError(T("%v"), err)
-		os.Exit(1)
+	case report.Lock.Targets == 0:
+		keyVal(T("Lock file"), T("empty"))
+	default:
+		keyVal(T("Lock file"), fmt.Sprintf(T("%d targets, %d keys"), report.Lock.Targets, report.Lock.Keys))
 	}
 
-	indexGroups := make(map[string][]config.ResolvedTarget)
-	for _, rt := range resolved {
-		base, ok := statusIndexGroupKey(rt)
-		if !ok {
-			continue
+	indexGroups := make(map[string][]int)
+	for i, rt := range resolved {
+		if base, ok := layout.IndexGroup(rt); ok {
+			indexGroups[base] = append(indexGroups[base], i)
 		}
-		indexGroups[base] = append(indexGroups[base], rt)
 	}
 	renderedGroups := make(map[string]struct{})
 
-	for _, rt := range resolved {
-		if base, ok := statusIndexGroupKey(rt); ok {
-			group := indexGroups[base]
-			if len(group) > 1 {
+	for i, rt := range resolved {
+		ts := report.Targets[i]
+		if base, ok := layout.IndexGroup(rt); ok {
+			if group := indexGroups[base]; len(group) > 1 {
 				if _, seen := renderedGroups[base]; seen {
 					continue
 				}
 				renderedGroups[base] = struct{}{}
-				showConfigIndexGroupStats(base, group, lockF)
+				showIndexGroupStatus(base, rt, report, group)
 				fmt.Fprintln(os.Stderr)
 				continue
 			}
 		}
 
-		langs := filterOutLang(rt.Languages, rt.Target.SourceLang)
-		showConfigTargetStats(rt, langs, lockF)
-
+		showTargetStatus(rt, ts)
 		fmt.Fprintln(os.Stderr)
 	}
 }
 
-func showConfigTargetStats(rt config.ResolvedTarget, langs []string, lockF *lockfile.LockFile) {
-	targetHeader(rt.Target.Name, rt.Target.Type)
-	keyVal(T("Root"), rt.Target.Root)
-
-	lockKeys := 0
-	for _, lang := range langs {
-		for _, lockTarget := range lockTargetKeysFor(rt, lang) {
-			lockKeys += lockF.TargetKeyCount(lockTarget)
-		}
+// showTargetStatus prints the status of one target.
+func showTargetStatus(rt config.ResolvedTarget, ts engine.TargetStatus) {
+	targetHeader(ts.Name, ts.Type)
+	keyVal(T("Root"), ts.Root)
+	showLanguagesStatus(ts)
+	if key, value := targetLocation(rt); key != "" {
+		keyVal(key, value)
 	}
-	keyVal(T("Locked"), fmt.Sprintf(T("%d keys x %d languages"), lockKeys, len(langs)))
+	showStatusCounts(ts)
+	showLengthLimitStatus(rt, ts)
+}
 
+// showLanguagesStatus prints the lock coverage and the languages of a
+// target.
+func showLanguagesStatus(ts engine.TargetStatus) {
+	locked := 0
+	langs := make([]string, 0, len(ts.Languages))
+	for _, ls := range ts.Languages {
+		locked += ls.Locked
+		langs = append(langs, ls.Lang)
+	}
+	keyVal(T("Locked"), fmt.Sprintf(T("%d keys x %d languages"), locked, len(langs)))
 	if len(langs) > 0 {
 		keyVal(T("Languages"), strings.Join(langs, ", "))
 	} else {
 		keyVal(T("Languages"), colorYellow+T("none detected")+colorReset)
 	}
+}
 
+// targetLocation returns the label and path of the files a target reads
+// its strings from, or an empty label if the root says it all.
+func targetLocation(rt config.ResolvedTarget) (string, string) {
 	switch rt.Target.Type {
 	case config.TargetTypeGettext:
-		showConfigGettextStats(rt, langs)
+		return T("POT"), rt.AbsPOTFile()
 	case config.TargetTypePo4a:
-		showConfigPo4aStats(rt, langs)
-	case config.TargetTypeI18Next:
-		showConfigI18NextStats(rt, langs)
-	case config.TargetTypeVueI18n:
-		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
-			showConfigIndexStats(rt, langs)
-		} else {
-			showConfigVueI18nStats(rt, langs)
-		}
+		return T("po4a config"), rt.AbsPo4aConfig()
 	case config.TargetTypeAndroid:
-		showConfigAndroidStats(rt, langs)
-	case config.TargetTypeYAML:
-		showConfigYAMLStats(rt, langs)
-	case config.TargetTypeMarkdown:
-		showConfigMarkdownStats(rt, langs)
-	case config.TargetTypeProperties:
-		showConfigPropertiesStats(rt, langs)
-	case config.TargetTypeFlutter:
-		showConfigFlutterStats(rt, langs)
-	case config.TargetTypeJSKV:
-		showConfigJSKVStats(rt, langs)
-	case config.TargetTypeDesktop:
-		showConfigDesktopStats(rt, langs)
-	case config.TargetTypePolkit:
-		showConfigPolkitStats(rt, langs)
-	default:
-		logWarning(T("[%s] Unknown target type %q, skipping"), rt.Target.Name, rt.Target.Type)
-	}
-}
-
-func statusIndexGroupKey(rt config.ResolvedTarget) (string, bool) {
-	if rt.Target.Source == nil || !rt.Target.Source.IsIndex() {
-		return "", false
+		return T("Res dir"), rt.AbsResDir()
+	case config.TargetTypeDesktop, config.TargetTypePolkit, config.TargetTypeXCStrings:
+		return T("Source"), rt.SourcePath()
 	}
-	base, _, ok := config.SplitExpandedTargetName(rt.Target.Name)
-	if !ok {
-		return "", false
+	if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+		return T("Source index"), rt.Target.Source.Index
 	}
-	return base, true
+	return T("Translations"), rt.AbsTranslationsDir()
 }
 
-func showConfigIndexGroupStats(baseName string, group []config.ResolvedTarget, lockF *lockfile.LockFile) {
-	if len(group) == 0 {
+// showStatusCounts prints the number of source strings of a target and a
+// table with the progress of each language.
+func showStatusCounts(ts engine.TargetStatus) {
+	if ts.SourceMissing {
+		keyVal(T("Source"), colorYellow+T("not found")+colorReset+" ("+ts.SourcePath+")")
 		return
 	}
+	if ts.Error != "" {
+		keyVal(T("Source"), colorYellow+fmt.Sprintf(T("error: %v"), ts.Error)+colorReset)
+		return
+	}
+	// po4a counts the strings of each language's PO files.
+	if ts.Type != config.TargetTypePo4a {
+		keyVal(T("Source strings"), fmt.Sprintf("%d (%s)", ts.Source, ts.SourceLang))
+	}
 
-	baseRT := group[0]
-	langs := filterOutLang(baseRT.Languages, baseRT.Target.SourceLang)
-
-	targetHeader(baseName, baseRT.Target.Type)
-	keyVal(T("Root"), baseRT.Target.Root)
-
-	lockKeys := 0
-	for _, rt := range group {
-		for _, lang := range langs {
-			lockKeys += lockF.TargetKeyCount(lockfile.LockTargetKey(rt.Target.Name, lang))
-		}
+	langs := make([]string, 0, len(ts.Languages))
+	for _, ls := range ts.Languages {
+		langs = append(langs, ls.Lang)
 	}
-	keyVal(T("Locked"), fmt.Sprintf(T("%d keys x %d languages"), lockKeys, len(langs)))
+	langWidth := langColumnWidth(langs)
+	fuzzy := hasFuzzyStatus(ts.Type)
 
-	if len(langs) > 0 {
-		keyVal(T("Languages"), strings.Join(langs, ", "))
+	fmt.Fprintln(os.Stderr)
+	if fuzzy {
+		fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s %5s%s\n",
+			colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Fuzzy"), T("Left"), colorReset)
+		fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 52)+colorReset)
 	} else {
-		keyVal(T("Languages"), colorYellow+T("none detected")+colorReset)
+		fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s%s\n",
+			colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Left"), colorReset)
+		fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 46)+colorReset)
 	}
 
-	items, err := config.LoadIndexItemsForTarget(baseRT.Target, baseRT.AbsRoot)
-	if err != nil {
-		keyVal(T("Source"), colorYellow+fmt.Sprintf(T("error: %v"), err)+colorReset)
-		return
+	for _, ls := range ts.Languages {
+		switch {
+		case ls.Status == engine.StatusMissing:
+			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
+				langCell(ls.Lang, langWidth), progressBar(0, 16), colorYellow, T("missing"), colorReset)
+		case ls.Status == engine.StatusError:
+			fmt.Fprintf(os.Stderr, "  %s %s  %s%s%s\n",
+				langCell(ls.Lang, langWidth), progressBar(0, 16), colorYellow, T("parse error"), colorReset)
+		case fuzzy:
+			fmt.Fprintf(os.Stderr, "  %s %s %5d %5d %5d\n",
+				langCell(ls.Lang, langWidth), progressBar(ls.Percent, 16), ls.Translated, ls.Fuzzy, ls.Untranslated)
+		default:
+			fmt.Fprintf(os.Stderr, "  %s %s %5d %5d\n",
+				langCell(ls.Lang, langWidth), progressBar(ls.Percent, 16), ls.Translated, ls.Untranslated)
+		}
 	}
+}
 
-	itemsByID := make(map[string]config.IndexItem, len(items))
-	for _, item := range items {
-		itemsByID[item.ID] = item
+// hasFuzzyStatus reports whether translations of a target type can be
+// fuzzy (unfinished in Qt Linguist files).
+func hasFuzzyStatus(targetType string) bool {
+	switch targetType {
+	case config.TargetTypeGettext, config.TargetTypePo4a, config.TargetTypeQt:
+		return true
 	}
+	return false
+}
 
-	groupByID := make(map[string]config.ResolvedTarget, len(group))
-	ids := make([]string, 0, len(group))
-	for _, rt := range group {
-		_, id, ok := config.SplitExpandedTargetName(rt.Target.Name)
-		if !ok {
-			continue
+// showLengthLimitStatus lists the languages with translations over their
+// length limit.
+func showLengthLimitStatus(rt config.ResolvedTarget, ts engine.TargetStatus) {
+	var over []string
+	for _, ls := range ts.Languages {
+		if ls.TooLong > 0 {
+			over = append(over, fmt.Sprintf("%s: %d", ls.Lang, ls.TooLong))
 		}
-		if _, exists := groupByID[id]; exists {
-			continue
-		}
-		groupByID[id] = rt
-		ids = append(ids, id)
 	}
-	sort.Strings(ids)
+	if len(over) > 0 {
+		keyVal(T("Too long"), colorYellow+strings.Join(over, ", ")+colorReset)
+	} else if len(rt.Target.LengthLimits) > 0 {
+		keyVal(T("Too long"), T("none"))
+	}
+}
 
-	sourceKeysTotal := 0
-	for _, id := range ids {
-		item, ok := itemsByID[id]
-		if !ok {
+// showIndexGroupStatus prints the targets expanded from one source index
+// as a single target. group holds their positions in report.Targets.
+func showIndexGroupStatus(baseName string, baseRT config.ResolvedTarget, report *engine.StatusReport, group []int) {
+	merged := engine.TargetStatus{
+		Name:       baseName,
+		Type:       baseRT.Target.Type,
+		Root:       baseRT.Target.Root,
+		SourceLang: baseRT.Target.SourceLang,
+	}
+	byLang := make(map[string]int)
+	for _, i := range group {
+		ts := report.Targets[i]
+		if ts.Error != "" {
 			continue
 		}
-		sourceKeysTotal += len(item.Fields)
-	}
-
-	keyVal(T("Source index"), baseRT.Target.Source.Index)
-	keyVal(T("Records"), fmt.Sprintf("%d", len(ids)))
-	keyVal(T("Source keys"), fmt.Sprintf("%d (%s)", sourceKeysTotal, baseRT.Target.SourceLang))
-
-	langWidth := langColumnWidth(langs)
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "  %s%-*s %-22s %5s %5s%s\n",
-		colorDim, langWidth+3, T("Lang"), T("Progress"), T("Done"), T("Left"), colorReset)
-	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", 46)+colorReset)
-
-	for _, lang := range langs {
-		total := 0
-		translated := 0
-
-		for _, id := range ids {
-			item, ok := itemsByID[id]
+		merged.Source += ts.Source
+		for _, ls := range ts.Languages {
+			j, ok := byLang[ls.Lang]
 			if !ok {
-				continue
+				j = len(merged.Languages)
+				byLang[ls.Lang] = j
+				merged.Languages = append(merged.Languages, engine.LangStatus{Lang: ls.Lang, Status: engine.StatusMissing})
 			}
-			rt := groupByID[id]
-			total += len(item.Fields)
-
-			filePath := rt.TranslationPath(lang)
-			f, err := parseIndexJSONFile(filePath)
-			if err != nil {
-				continue
-			}
-			for key := range item.Fields {
-				if strings.TrimSpace(f.Get(key)) != "" {
-					translated++
-				}
+			m := &merged.Languages[j]
+			if ls.Status == engine.StatusOK {
+				m.Status = engine.StatusOK
 			}
+			m.Total += ls.Total
+			m.Translated += ls.Translated
+			m.Untranslated += ls.Untranslated
+			m.Locked += ls.Locked
 		}
-
-		untranslated := total - translated
-		percent := 0
-		if total > 0 {
-			percent = translated * 100 / total
+	}
+	for i := range merged.Languages {
+		if ls := &merged.Languages[i]; ls.Total > 0 {
+			ls.Percent = ls.Translated * 100 / ls.Total
 		}
-		fmt.Fprintf(os.Stderr, "  %s %s %5d %5d\n",
-			langCell(lang, langWidth), progressBar(percent, 16), translated, untranslated)
 	}
+
+	targetHeader(merged.Name, merged.Type)
+	keyVal(T("Root"), merged.Root)
+	showLanguagesStatus(merged)
+	keyVal(T("Source index"), baseRT.Target.Source.Index)
+	keyVal(T("Records"), fmt.Sprintf("%d", len(group)))
+	showStatusCounts(merged)
 }
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/minios-linux/lokit/engine"
+	. "github.com/minios-linux/lokit/i18n"
+)
+
+// ---------------------------------------------------------------------------
+// status --format json|csv (machine-readable translation statistics)
+// ---------------------------------------------------------------------------
+
+// Status output formats.
+const (
+	statusFormatText = "text"
+	statusFormatJSON = "json"
+	statusFormatCSV  = "csv"
+)
+
+func runStatusReport(e *engine.Engine, targets []string, format string) {
+	report, err := e.Status(targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+
+	switch format {
+	case statusFormatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case statusFormatCSV:
+		err = writeStatusCSV(os.Stdout, report)
+	}
+	if err != nil {
+		logError(T("Output error: %v"), err)
+		os.Exit(exitFailure)
+	}
+}
+
+func writeStatusCSV(out io.Writer, report *engine.StatusReport) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"target", "type", "lang", "status", "total", "translated", "fuzzy", "untranslated", "percent", "locked", "too_long"}); err != nil {
+		return err
+	}
+	for _, t := range report.Targets {
+		for _, l := range t.Languages {
+			record := []string{
+				t.Name, t.Type, l.Lang, l.Status,
+				strconv.Itoa(l.Total), strconv.Itoa(l.Translated), strconv.Itoa(l.Fuzzy),
+				strconv.Itoa(l.Untranslated), strconv.Itoa(l.Percent), strconv.Itoa(l.Locked),
+				strconv.Itoa(l.TooLong),
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/minios-linux/lokit/engine"
+)
+
+func TestWriteStatusCSV(t *testing.T) {
+	report := &engine.StatusReport{Targets: []engine.TargetStatus{{
+		Name: "welcome",
+		Type: "js-kv",
+		Languages: []engine.LangStatus{
+			{Lang: "de", Status: engine.StatusOK, Total: 2, Translated: 1, Untranslated: 1, Percent: 50, Locked: 1, TooLong: 1},
+			{Lang: "fr", Status: engine.StatusMissing, Total: 2, Untranslated: 2},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	if err := writeStatusCSV(&buf, report); err != nil {
+		t.Fatalf("writeStatusCSV: %v", err)
+	}
+	want := "target,type,lang,status,total,translated,fuzzy,untranslated,percent,locked,too_long\n" +
+		"welcome,js-kv,de,ok,2,1,0,1,50,1,1\n" +
+		"welcome,js-kv,fr,missing,2,0,0,2,0,0,0\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("csv =\n%s\nwant\n%s", got, want)
+	}
+}
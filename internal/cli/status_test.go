@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
+)
+
+func TestShowStatusCounts(t *testing.T) {
+	ts := engine.TargetStatus{
+		Name:       "welcome",
+		Type:       config.TargetTypeJSKV,
+		SourceLang: "en",
+		Source:     2,
+		Languages: []engine.LangStatus{
+			{Lang: "de", Status: engine.StatusOK, Total: 2, Translated: 1, Untranslated: 1, Percent: 50},
+			{Lang: "fr", Status: engine.StatusMissing, Total: 2, Untranslated: 2},
+			{Lang: "it", Status: engine.StatusError, Total: 2, Untranslated: 2, Error: "bad syntax"},
+		},
+	}
+	output := captureStderr(t, func() { showStatusCounts(ts) })
+
+	if !strings.Contains(output, "2 (en)") {
+		t.Fatalf("source count missing:\n%s", output)
+	}
+	if !regexp.MustCompile(`(?m)^\s*🇩🇪 de\s+.*\s+1\s+1\s*$`).MatchString(output) {
+		t.Fatalf("de counts missing:\n%s", output)
+	}
+	if !regexp.MustCompile(`(?m)fr\s+.*missing`).MatchString(output) {
+		t.Fatalf("fr not reported as missing:\n%s", output)
+	}
+	if !regexp.MustCompile(`(?m)it\s+.*parse error`).MatchString(output) {
+		t.Fatalf("it not reported as a parse error:\n%s", output)
+	}
+	if strings.Contains(output, "Fuzzy") {
+		t.Fatalf("js-kv table must not have a fuzzy column:\n%s", output)
+	}
+
+	ts.Error = "unexpected token"
+	output = captureStderr(t, func() { showStatusCounts(ts) })
+	if !strings.Contains(output, "unexpected token") || strings.Contains(output, "Progress") {
+		t.Fatalf("source error not reported alone:\n%s", output)
+	}
+}
+
+func TestRunStatusTextSourceMissing(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "source_lang: en\nlanguages: [ru, de]\ntargets:\n  - name: web\n    format: i18next\n    dir: locales\n    pattern: '{lang}.json'\n"
+	if err := os.WriteFile(filepath.Join(dir, "lokit.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	e, err := engine.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	output := captureStderr(t, func() { runStatusText(e, nil) })
+
+	if !regexp.MustCompile(`(?m)Locked\s+.*0 keys x 2 languages`).MatchString(output) {
+		t.Fatalf("configured languages not counted:\n%s", output)
+	}
+	if !regexp.MustCompile(`(?m)Languages\s+.*ru, de$`).MatchString(output) || strings.Contains(output, "none detected") {
+		t.Fatalf("configured languages not listed:\n%s", output)
+	}
+	want := "not found" + colorReset + " (" + filepath.Join(dir, "locales", "en.json") + ")"
+	if !strings.Contains(output, want) || strings.Contains(output, "error:") {
+		t.Fatalf("missing source not reported as not found:\n%s", output)
+	}
+}
+
+func TestShowIndexGroupStatusMergesRecords(t *testing.T) {
+	report := &engine.StatusReport{Targets: []engine.TargetStatus{
+		{Name: "apps/a", Source: 2, Languages: []engine.LangStatus{{Lang: "de", Status: engine.StatusOK, Total: 2, Translated: 2}}},
+		{Name: "apps/b", Source: 3, Languages: []engine.LangStatus{{Lang: "de", Status: engine.StatusMissing, Total: 3, Untranslated: 3}}},
+	}}
+	rt := config.ResolvedTarget{Target: config.Target{
+		Type:       config.TargetTypeVueI18n,
+		SourceLang: "en",
+		Source:     &config.SourceField{Index: "apps.json"},
+	}}
+	output := captureStderr(t, func() { showIndexGroupStatus("apps", rt, report, []int{0, 1}) })
+
+	if !strings.Contains(output, "5 (en)") {
+		t.Fatalf("merged source count missing:\n%s", output)
+	}
+	if !regexp.MustCompile(`(?m)de\s+.*\s40%\s+2\s+3\s*$`).MatchString(output) {
+		t.Fatalf("merged de counts missing:\n%s", output)
+	}
+}
+
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	old := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe stderr: %v", err)
+	}
+	os.Stderr = w
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("close stderr writer: %v", err)
+	}
+	os.Stderr = old
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read stderr: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close stderr reader: %v", err)
+	}
+	return string(out)
+}
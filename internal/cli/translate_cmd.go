@@ -2,43 +2,25 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
 	. "github.com/minios-linux/lokit/i18n"
-	"github.com/minios-linux/lokit/lockfile"
-	"github.com/minios-linux/lokit/settings"
+	"github.com/minios-linux/lokit/journal"
+	"github.com/minios-linux/lokit/translate"
 	"github.com/spf13/cobra"
 )
 
 func newTranslateCmd() *cobra.Command {
-	var (
-		langs   string
-		targets []string
-
-		provider string
-		apiKey   string
-		model    string
-		baseURL  string
-
-		chunkSize   int
-		retranslate bool
-		fuzzy       bool
-		prompt      string
-		verbose     bool
-		dryRun      bool
-		force       bool
-
-		parallel     int
-		requestDelay time.Duration
-
-		timeout time.Duration
-		proxy   string
-		retries int
-	)
+	var f translateFlags
+	var failOnUntranslated string
+	var detailedExitCode bool
 
 	cmd := &cobra.Command{
 		Use:   "translate",
@@ -46,7 +28,9 @@ func newTranslateCmd() *cobra.Command {
 		Long: T(`Translate files using AI providers.
 
 Supports gettext PO, po4a, i18next, vue-i18n, Android strings.xml,
-YAML, Markdown, Java .properties, Flutter ARB, JS-KV, desktop, and polkit formats.
+YAML, Markdown, Java .properties, Flutter ARB, JS-KV, desktop, polkit,
+Apple .strings/.stringsdict, Xcode String Catalog, and Qt Linguist .ts
+formats.
 Target formats are configured in lokit.yaml.
 
 For gettext/po4a projects, automatically initializes if needed (extracts
@@ -56,6 +40,29 @@ Incremental translation: lokit tracks source string checksums in lokit.lock.
 Only new or changed strings are sent to the AI provider, saving tokens and
 time. Use --force to ignore the lock file and re-translate everything.
 
+Translation memory: accepted translations are stored in lokit.tm and shared
+across targets and runs. A string already translated in one target (e.g.
+"Cancel" in a gettext catalog) is reused verbatim in every other target
+instead of being sent to the AI provider again. The memory is seeded from
+existing translated files. Use --no-memory to bypass it.
+
+Review: machine translations are marked for review. Gettext entries get a
+"# lokit: machine, model=..." translator comment (and the fuzzy flag with
+review.mark_fuzzy in lokit.yaml); other formats are tracked in lokit.review.
+Use 'lokit review' to list, accept or reject them, or set review.disabled in
+lokit.yaml to turn marking off.
+
+Token usage: prompt and completion tokens reported by the provider are
+summed per target and language and printed at the end of the run. Use
+--max-tokens-budget to stop sending new chunks once the budget is used up;
+chunks already in flight complete and their results are saved.
+
+Resuming: every chunk returned by the provider is recorded in lokit.journal
+until its file is saved. On Ctrl-C, completed chunks are saved before lokit
+exits; press Ctrl-C again to quit immediately. If a run is cut short before
+saving (a second Ctrl-C, a crash), use --resume to apply the recorded
+translations and continue with the remaining strings.
+
 Key filtering: configure per-target in lokit.yaml:
   ignored_keys  — keys excluded from translation entirely (never sent to AI)
   locked_keys   — keys whose translations are preserved (skipped even with
@@ -68,6 +75,11 @@ Use the --prompt flag to override it for the current run, or set prompt:
 in lokit.yaml target config for a permanent override.
 Use {{targetLang}} and {{sourceLang}} as placeholders for language names.
 
+Exit codes: 0 on success, 2 for configuration errors, 3 for missing or
+rejected credentials, 4 when some targets or languages failed, 5 when
+--fail-on-untranslated is exceeded after the run. With --detailed-exit-code,
+6 is returned when there was nothing to translate. See docs/commands.md.
+
 Examples:
   # Basic translation run
   lokit translate --provider copilot --model MODEL_NAME
@@ -85,59 +97,113 @@ Examples:
   # Use local Ollama
   lokit translate --provider ollama --model MODEL_NAME
 
+  # Pseudo-translate offline (no credentials, no network)
+  lokit translate --provider mock --lang de
+
   # Force full re-translation (ignore lock file)
   lokit translate --provider copilot --model MODEL_NAME --force
 
   # Dry run (show what would be translated)
-  lokit translate --provider copilot --model MODEL_NAME --dry-run`),
+  lokit translate --provider copilot --model MODEL_NAME --dry-run
+
+  # Stop after about 200k tokens
+  lokit translate --provider copilot --model MODEL_NAME --max-tokens-budget 200000
+
+  # Continue an interrupted run
+  lokit translate --provider copilot --model MODEL_NAME --resume
+
+  # Fail in CI if any language is still more than 5% untranslated
+  lokit translate --provider copilot --model MODEL_NAME --fail-on-untranslated 5%`),
 		Run: func(cmd *cobra.Command, args []string) {
-			runTranslate(translateArgs{
-				langs:    langs,
-				targets:  targets,
-				provider: provider, apiKey: apiKey, model: model,
-				baseURL:   baseURL,
-				chunkSize: chunkSize, retranslate: retranslate,
-				fuzzy: fuzzy, prompt: prompt, verbose: verbose,
-				dryRun: dryRun, force: force, parallel: parallel > 0,
-				maxConcurrent: parallel, requestDelay: requestDelay,
-				timeout: timeout, proxy: proxy, maxRetries: retries,
-			})
+			var limit *untranslatedLimit
+			if cmd.Flags().Changed("fail-on-untranslated") {
+				l, err := parseUntranslatedLimit(failOnUntranslated)
+				if err != nil {
+					logError(T("%v"), err)
+					os.Exit(exitConfig)
+				}
+				limit = &l
+			}
+			runTranslate(f.args(), limit, detailedExitCode)
 		},
 	}
 
-	cmd.Flags().StringVar(&provider, "provider", "", T("AI provider: google, gemini, groq, opencode, copilot, openai, ollama, custom-openai (or use lokit.yaml provider.id)"))
-	cmd.Flags().StringVar(&model, "model", "", T("Model name (or use lokit.yaml provider.model)"))
-	cmd.Flags().StringVar(&apiKey, "api-key", "", T("API key (or provider env var: GOOGLE_API_KEY, GROQ_API_KEY, OPENAI_API_KEY, CUSTOM_OPENAI_API_KEY, OPENCODE_API_KEY)"))
-	cmd.Flags().StringVar(&baseURL, "base-url", "", T("Custom API base URL"))
-
-	cmd.Flags().StringVarP(&langs, "lang", "l", "", T("Languages to translate (comma-separated, default: all with untranslated)"))
-	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
-
-	cmd.Flags().IntVar(&chunkSize, "chunk", 0, T("Entries per API request (0 = all at once)"))
-	cmd.Flags().BoolVarP(&retranslate, "all", "a", false, T("Translate all entries, including already translated ones"))
-	cmd.Flags().BoolVar(&fuzzy, "fuzzy", true, T("Translate fuzzy entries and clear fuzzy flag"))
-	cmd.Flags().StringVar(&prompt, "prompt", "", T("Custom system prompt (use {{targetLang}}/{{sourceLang}} placeholders)"))
-	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, T("Enable detailed logging"))
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, T("Show what would be translated without calling AI"))
-	cmd.Flags().BoolVarP(&force, "force", "f", false, T("Ignore lock file and locked keys; re-translate all non-ignored entries"))
+	f.register(cmd)
+	cmd.Flags().StringVar(&failOnUntranslated, "fail-on-untranslated", "", T("Exit with code 5 if a language still has more untranslated or fuzzy strings than this count or percentage (e.g. 0, 10, 5%)"))
+	cmd.Flags().BoolVar(&detailedExitCode, "detailed-exit-code", false, T("Exit with code 6 if there was nothing to translate"))
+	return cmd
+}
 
-	cmd.Flags().IntVar(&parallel, "parallel", 0, T("Enable parallel translation with optional worker count (e.g. --parallel or --parallel=8)"))
-	cmd.Flags().DurationVar(&requestDelay, "delay", 0, T("Delay between translation requests"))
+// translateFlags holds the command-line flags shared by translate and
+// watch.
+type translateFlags struct {
+	langs   string
+	targets []string
+
+	provider string
+	apiKey   string
+	model    string
+	baseURL  string
+
+	chunkSize   int
+	retranslate bool
+	fuzzy       bool
+	prompt      string
+	verbose     bool
+	dryRun      bool
+	force       bool
+	noMemory    bool
+	resume      bool
+	maxTokens   int
+
+	parallel     int
+	requestDelay time.Duration
+
+	timeout time.Duration
+	proxy   string
+	retries int
+}
 
-	cmd.Flags().DurationVar(&timeout, "timeout", 0, T("Request timeout (0 = provider default)"))
-	cmd.Flags().StringVar(&proxy, "proxy", "", T("HTTP/HTTPS proxy URL"))
-	cmd.Flags().IntVar(&retries, "retries", 3, T("Maximum retries on rate limit (429)"))
+// register adds the translation flags to cmd.
+func (f *translateFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.provider, "provider", "", T("AI provider: google, gemini, anthropic, groq, opencode, copilot, openai, ollama, custom-openai, mock (or use lokit.yaml provider.id)"))
+	cmd.Flags().StringVar(&f.model, "model", "", T("Model name (or use lokit.yaml provider.model)"))
+	cmd.Flags().StringVar(&f.apiKey, "api-key", "", T("API key (or provider env var: GOOGLE_API_KEY, GROQ_API_KEY, OPENAI_API_KEY, CUSTOM_OPENAI_API_KEY, OPENCODE_API_KEY)"))
+	cmd.Flags().StringVar(&f.baseURL, "base-url", "", T("Custom API base URL"))
+
+	cmd.Flags().StringVarP(&f.langs, "lang", "l", "", T("Languages to translate (comma-separated, default: all with untranslated)"))
+	cmd.Flags().StringSliceVar(&f.targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+
+	cmd.Flags().IntVar(&f.chunkSize, "chunk", 0, T("Entries per API request (0 = all at once)"))
+	cmd.Flags().BoolVarP(&f.retranslate, "all", "a", false, T("Translate all entries, including already translated ones"))
+	cmd.Flags().BoolVar(&f.fuzzy, "fuzzy", true, T("Translate fuzzy entries and clear fuzzy flag"))
+	cmd.Flags().StringVar(&f.prompt, "prompt", "", T("Custom system prompt (use {{targetLang}}/{{sourceLang}} placeholders)"))
+	cmd.Flags().BoolVarP(&f.verbose, "verbose", "v", false, T("Enable detailed logging"))
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, T("Show what would be translated without calling AI"))
+	cmd.Flags().BoolVarP(&f.force, "force", "f", false, T("Ignore lock file and locked keys; re-translate all non-ignored entries"))
+	cmd.Flags().BoolVar(&f.noMemory, "no-memory", false, T("Do not read or update the translation memory (lokit.tm)"))
+	cmd.Flags().BoolVar(&f.resume, "resume", false, T("Apply translations recorded in lokit.journal by an interrupted run"))
+	cmd.Flags().IntVar(&f.maxTokens, "max-tokens-budget", 0, T("Stop sending new chunks after this many provider tokens (0 = unlimited)"))
+
+	cmd.Flags().IntVar(&f.parallel, "parallel", 0, T("Enable parallel translation with optional worker count (e.g. --parallel or --parallel=8)"))
+	cmd.Flags().DurationVar(&f.requestDelay, "delay", 0, T("Delay between translation requests"))
+
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, T("Request timeout (0 = provider default)"))
+	cmd.Flags().StringVar(&f.proxy, "proxy", "", T("HTTP/HTTPS proxy URL"))
+	cmd.Flags().IntVar(&f.retries, "retries", 3, T("Maximum retries on rate limit (429)"))
 
 	_ = cmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{
 			"google\t" + T("Google AI (Gemini) — API key required"),
 			"gemini\t" + T("Gemini CLI — browser OAuth"),
+			"anthropic\t" + T("Anthropic Messages API — API key required"),
 			"groq\t" + T("Groq — API key required"),
 			"opencode\t" + T("OpenCode — optional API key"),
 			"copilot\t" + T("GitHub Copilot — native OAuth"),
 			"openai\t" + T("OpenAI — OAuth, device code, or API key"),
 			"ollama\t" + T("Ollama local server"),
 			"custom-openai\t" + T("Custom OpenAI-compatible endpoint"),
+			"mock\t" + T("Offline pseudo-translation for testing"),
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
 
@@ -146,7 +212,7 @@ Examples:
 		switch p {
 		case "google", "gemini":
 			return nil, cobra.ShellCompDirectiveNoFileComp
-		case "groq":
+		case "anthropic", "groq":
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		case "opencode":
 			return nil, cobra.ShellCompDirectiveNoFileComp
@@ -156,291 +222,130 @@ Examples:
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		case "ollama":
 			return nil, cobra.ShellCompDirectiveNoFileComp
+		case "mock":
+			return []string{translate.MockModelPseudo, translate.MockModelEcho}, cobra.ShellCompDirectiveNoFileComp
 		default:
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 	})
-
-	return cmd
 }
 
-type translateArgs struct {
-	langs                            string
-	targets                          []string
-	provider, apiKey, model, baseURL string
-	chunkSize                        int
-	retranslate, fuzzy               bool
-	prompt                           string
-	verbose, dryRun, force, parallel bool
-	maxConcurrent                    int
-	requestDelay, timeout            time.Duration
-	proxy                            string
-	maxRetries                       int
-	lockFile                         *lockfile.LockFile
-}
-
-func runTranslate(a translateArgs) {
-	lf, err := config.LoadLokitFile(rootDir)
-	if err != nil {
-		logError(T("Config error: %v"), err)
-		os.Exit(1)
-	}
-	if lf != nil {
-		runTranslateWithConfig(lf, a)
-		return
+// args returns the translation options selected by the flags.
+func (f *translateFlags) args() engine.TranslateOptions {
+	return engine.TranslateOptions{
+		Targets:   f.targets,
+		Languages: splitLangs(f.langs),
+		Provider:  f.provider, APIKey: f.apiKey, Model: f.model,
+		BaseURL:   f.baseURL,
+		ChunkSize: f.chunkSize, Retranslate: f.retranslate,
+		Fuzzy: f.fuzzy, Prompt: f.prompt, Verbose: f.verbose,
+		DryRun: f.dryRun, Force: f.force, NoMemory: f.noMemory, Resume: f.resume,
+		MaxTokens: f.maxTokens,
+		Parallel:  f.parallel, RequestDelay: f.requestDelay,
+		Timeout: f.timeout, Proxy: f.proxy, MaxRetries: f.retries,
 	}
-
-	logError(T("No lokit.yaml found in %s"), rootDir)
-	logInfo(T("Create a lokit.yaml configuration file. See 'lokit init --help' for format reference."))
-	os.Exit(1)
 }
 
-func runTranslateWithConfig(lf *config.LokitFile, a translateArgs) {
-	providerName := a.provider
-	modelName := a.model
-	baseURL := a.baseURL
-	if lf.Provider != nil {
-		if providerName == "" {
-			providerName = lf.Provider.ID
-		}
-		if modelName == "" {
-			modelName = lf.Provider.Model
-		}
-		if baseURL == "" {
-			baseURL = lf.Provider.BaseURL
-		}
-		if a.prompt == "" {
-			a.prompt = lf.Provider.Prompt
-		}
-	}
-
-	key := settings.ResolveAPIKey(providerName, a.apiKey)
-
-	if providerName == "" {
-		logError(T("No provider specified. Use --provider to choose an AI translation service.\n\n") +
-			"Example: lokit translate --provider copilot --model MODEL_NAME")
-		os.Exit(1)
-	}
-
-	prov := resolveProvider(providerName, baseURL, key, modelName, a.proxy, a.timeout)
-	if lf.Provider != nil && lf.Provider.Settings.Temperature != nil {
-		prov.Temperature = *lf.Provider.Settings.Temperature
-	}
-	if err := validateProvider(prov, key); err != nil {
-		logError(T("%v"), err)
-		os.Exit(1)
-	}
-
-	resolved, err := lf.Resolve(rootDir)
-	if err != nil {
-		logError(T("Config resolve error: %v"), err)
-		os.Exit(1)
-	}
-	resolved, err = filterResolvedTargetsByNames(resolved, a.targets)
-	if err != nil {
-		logError(T("%v"), err)
-		os.Exit(1)
-	}
-
-	if len(resolved) == 0 {
-		logError(T("No targets defined in lokit.yaml"))
-		os.Exit(1)
-	}
-
-	lockF, err := lockfile.Load(rootDir)
-	if err != nil {
-		logWarning(T("Could not load lock file: %v"), err)
-		lockF = &lockfile.LockFile{Version: lockfile.Version, Checksums: make(map[string]map[string]string)}
+// runTranslate translates the selected targets. If limit is set, the run
+// fails when a language is left over it; detailed selects the exit code
+// for runs with nothing to translate.
+func runTranslate(opts engine.TranslateOptions, limit *untranslatedLimit, detailed bool) {
+	e := loadEngine()
+	e.OnTargetStart = func(rt config.ResolvedTarget) {
+		targetHeader(rt.Target.Name, string(rt.Target.Type))
 	}
-	a.lockFile = lockF
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigCh := make(chan os.Signal, 1)
+	sigCh := make(chan os.Signal, 2)
 	signal.Notify(sigCh, os.Interrupt)
 	go func() {
 		<-sigCh
-		logWarning(T("Interrupted, saving progress..."))
+		logWarning(T("Interrupted, saving progress... (press Ctrl-C again to quit now)"))
 		cancel()
+		<-sigCh
+		if info, err := os.Stat(filepath.Join(rootDir, journal.FileName)); err == nil && info.Size() > 0 {
+			logWarning(T("Completed chunks are kept in %s; run with --resume to apply them"), journal.FileName)
+		}
+		os.Exit(exitInterrupted)
 	}()
 
-	var langFilter []string
-	if a.langs != "" {
-		langFilter = strings.Split(a.langs, ",")
+	res, err := e.Translate(ctx, opts)
+	if res == nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
 	}
 
-	hadErrors := false
+	printUsageSummary(res.Usage)
 
-	indexGroups := make(map[string][]config.ResolvedTarget)
-	for _, rt := range resolved {
-		base, ok := translateIndexGroupKey(rt)
-		if !ok {
-			continue
-		}
-		indexGroups[base] = append(indexGroups[base], rt)
+	switch {
+	case ctx.Err() != nil:
+		logWarning(T("Translation interrupted"))
+		os.Exit(exitInterrupted)
+	case translate.IsAuthError(err):
+		logError(T("Translation completed with errors"))
+		os.Exit(exitAuth)
+	case err != nil:
+		logError(T("Translation completed with errors"))
+		os.Exit(exitPartial)
 	}
-	processedGroups := make(map[string]struct{})
-
-	for _, rt := range resolved {
-		if ctx.Err() != nil {
-			break
-		}
-
-		if base, ok := translateIndexGroupKey(rt); ok {
-			group := indexGroups[base]
-			if len(group) > 1 {
-				if _, done := processedGroups[base]; done {
-					continue
-				}
-				processedGroups[base] = struct{}{}
-
-				targetHeader(base, string(rt.Target.Type))
-				for _, grt := range group {
-					if ctx.Err() != nil {
-						break
-					}
-
-					targetLangs := grt.Languages
-					if len(langFilter) > 0 {
-						targetLangs = intersectLanguages(targetLangs, langFilter)
-					}
-					targetLangs = filterOutLang(targetLangs, grt.Target.SourceLang)
-					if len(targetLangs) == 0 {
-						continue
-					}
-
-					if err := translateIndexTarget(ctx, grt, prov, a, targetLangs, true); err != nil {
-						logError(T("[%s] %v"), grt.Target.Name, err)
-						hadErrors = true
-					}
-				}
-
-				if err := a.lockFile.Save(); err != nil {
-					logWarning(T("Could not save lock file after target %s: %v"), base, err)
-				} else if a.verbose {
-					logInfo(T("Lock file saved after target %s/%s"), base, "*")
-				}
-				continue
-			}
-		}
-
-		targetLangs := rt.Languages
-		if len(langFilter) > 0 {
-			targetLangs = intersectLanguages(targetLangs, langFilter)
-		}
-
-		targetLangs = filterOutLang(targetLangs, rt.Target.SourceLang)
-
-		if len(targetLangs) == 0 {
-			logInfo(T("[%s] No languages to translate, skipping"), rt.Target.Name)
-			continue
-		}
-
-		targetHeader(rt.Target.Name, string(rt.Target.Type))
-
-		switch rt.Target.Type {
-		case config.TargetTypeGettext:
-			if err := translateGettextTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypePo4a:
-			if err := translatePo4aTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeI18Next:
-			if err := translateI18NextTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeVueI18n:
-			translateFn := translateVueI18nTarget
-			if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
-				if err := translateIndexTarget(ctx, rt, prov, a, targetLangs, false); err != nil {
-					logError(T("[%s] %v"), rt.Target.Name, err)
-					hadErrors = true
-				}
-				break
-			}
-			if err := translateFn(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeAndroid:
-			if err := translateAndroidTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeYAML:
-			if err := translateYAMLTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeMarkdown:
-			if err := translateMarkdownTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeProperties:
-			if err := translatePropertiesTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeFlutter:
-			if err := translateFlutterTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeJSKV:
-			if err := translateJSKVTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypeDesktop:
-			if err := translateDesktopTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		case config.TargetTypePolkit:
-			if err := translatePolkitTarget(ctx, rt, prov, a, targetLangs); err != nil {
-				logError(T("[%s] %v"), rt.Target.Name, err)
-				hadErrors = true
-			}
-		default:
-			logWarning(T("[%s] Unknown target type %q, skipping"), rt.Target.Name, rt.Target.Type)
+	if opts.DryRun {
+		logSuccess(T("Dry run complete"))
+		return
+	}
+	if limit != nil {
+		report, err := e.Status(opts.Targets)
+		if err != nil {
+			logError(T("%v"), err)
+			os.Exit(exitCode(err))
 		}
-
-		if err := a.lockFile.Save(); err != nil {
-			logWarning(T("Could not save lock file after target %s: %v"), rt.Target.Name, err)
-		} else if a.verbose {
-			logInfo(T("Lock file saved after target %s/%s"), rt.Target.Name, "*")
+		if checkUntranslated(report, *limit, opts.Languages) {
+			os.Exit(exitValidation)
 		}
 	}
-
-	if err := a.lockFile.Save(); err != nil {
-		logWarning(T("Could not save lock file: %v"), err)
+	if detailed && res.Translated == 0 {
+		logInfo(T("Nothing to translate"))
+		os.Exit(exitNothingToDo)
 	}
+	logSuccess(T("All targets translated!"))
+}
 
-	if hadErrors {
-		logError(T("Translation completed with errors"))
-		os.Exit(1)
+// splitLangs splits a comma-separated --lang value; "" selects no
+// languages.
+func splitLangs(s string) []string {
+	if s == "" {
+		return nil
 	}
-	if a.dryRun {
-		logSuccess(T("Dry run complete"))
-		return
-	}
-	logSuccess(T("All targets translated!"))
+	return strings.Split(s, ",")
 }
 
-func translateIndexGroupKey(rt config.ResolvedTarget) (string, bool) {
-	if rt.Target.Source == nil || !rt.Target.Source.IsIndex() {
-		return "", false
+// printUsageSummary prints provider token usage per target and language.
+func printUsageSummary(usage *translate.UsageTracker) {
+	entries := usage.Entries()
+	if len(entries) == 0 {
+		return
+	}
+	sectionHeader(T("Token Usage"))
+	targetWidth := len(T("Target"))
+	for _, e := range entries {
+		targetWidth = max(targetWidth, len(e.Target))
+	}
+	fmt.Fprintf(os.Stderr, "  %s%-*s %-8s %8s %10s %10s %10s%s\n",
+		colorDim, targetWidth, T("Target"), T("Lang"), T("Requests"), T("Prompt"), T("Completion"), T("Total"), colorReset)
+	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", targetWidth+51)+colorReset)
+	requests := 0
+	for _, e := range entries {
+		requests += e.Requests
+		fmt.Fprintf(os.Stderr, "  %-*s %-8s %8d %10d %10d %10d\n",
+			targetWidth, e.Target, e.Lang, e.Requests, e.PromptTokens, e.CompletionTokens, e.Total())
 	}
-	base, _, ok := config.SplitExpandedTargetName(rt.Target.Name)
-	if !ok {
-		return "", false
+	total := usage.Total()
+	fmt.Fprintln(os.Stderr, "  "+colorDim+strings.Repeat("─", targetWidth+51)+colorReset)
+	fmt.Fprintf(os.Stderr, "  %-*s %-8s %8d %10d %10d %10d\n",
+		targetWidth, T("Total"), "", requests, total.PromptTokens, total.CompletionTokens, total.Total())
+	if usage.Budget > 0 {
+		keyVal(T("Budget"), fmt.Sprintf(T("%d of %d tokens used"), total.Total(), usage.Budget))
 	}
-	return base, true
+	fmt.Fprintln(os.Stderr)
 }
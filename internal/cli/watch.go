@@ -0,0 +1,508 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/engine"
+	"github.com/minios-linux/lokit/extract"
+	"github.com/minios-linux/lokit/format/android"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/translate"
+	"github.com/spf13/cobra"
+)
+
+func newWatchCmd() *cobra.Command {
+	var f translateFlags
+	var debounce time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: T("Translate targets again whenever their sources change"),
+		Long: T(`Watch the sources of every target and translate a target again as soon
+as its sources change.
+
+lokit watch first brings all targets up to date, like lokit translate, then
+waits for changes. What is watched depends on the target type:
+  gettext   the "sources" files and directories (default: the target
+            root), for files of a supported source language
+  markdown  the source Markdown files, including new ones
+  po4a      po4a.cfg and the master documents it lists
+  others    the source-language file (or the source index)
+
+When a source changes, only the affected target is processed: gettext
+targets are extracted and merged again, then new and changed strings are
+translated. Changes are debounced, so a burst of saves triggers a single
+run. Translated files written by lokit itself do not trigger a run.
+
+Changes are detected with inotify on Linux and by polling elsewhere.
+Press Ctrl-C to stop.
+
+Examples:
+  lokit watch --provider copilot --model MODEL_NAME
+  lokit watch --target webapp --lang de,fr
+  lokit watch --provider mock --debounce 2s`),
+		Run: func(cmd *cobra.Command, args []string) {
+			runWatch(f.args(), debounce)
+		},
+	}
+
+	f.register(cmd)
+	cmd.Flags().DurationVar(&debounce, "debounce", 500*time.Millisecond, T("Wait this long after the last change before translating"))
+	return cmd
+}
+
+func runWatch(opts engine.TranslateOptions, debounce time.Duration) {
+	e := loadEngine()
+	e.OnTargetStart = func(rt config.ResolvedTarget) {
+		targetHeader(rt.Target.Name, string(rt.Target.Type))
+	}
+	resolved, err := e.Select(opts.Targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	// Runs share one tracker, so the budget covers the whole session.
+	opts.Usage = translate.NewUsageTracker(opts.MaxTokens)
+
+	var targets []*watchTarget
+	var dirs []watchDir
+	for _, rt := range resolved {
+		wt := newWatchTarget(rt)
+		if len(wt.files) == 0 && len(wt.roots) == 0 {
+			logWarning(T("[%s] No sources to watch, skipping"), rt.Target.Name)
+			continue
+		}
+		targets = append(targets, wt)
+		dirs = append(dirs, wt.watchDirs()...)
+	}
+	if len(targets) == 0 {
+		logError(T("No targets to watch"))
+		os.Exit(exitConfig)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		<-sigCh
+		cancel()
+		<-sigCh
+		os.Exit(exitInterrupted)
+	}()
+
+	// translateOne translates a target. It returns an error if the
+	// providers could not be set up or rejected the credentials; other
+	// failures are reported by the engine and the target is retried on the
+	// next change.
+	translateOne := func(wt *watchTarget) error {
+		if opts.Usage.Exceeded() {
+			logWarning(T("Token budget of %d exceeded, skipping %s"), opts.MaxTokens, wt.rt.Target.Name)
+			return nil
+		}
+		o := opts
+		o.Targets = []string{wt.rt.Target.Name}
+		res, err := e.Translate(ctx, o)
+		if res == nil {
+			logError(T("%v"), err)
+			return err
+		}
+		// Targets writing into their own source (desktop, polkit) must not
+		// trigger themselves.
+		wt.snapshot()
+		if stopWatching(err) {
+			return err
+		}
+		return nil
+	}
+
+	// Start watching before the first pass so that no change is missed.
+	w, err := newFileWatcher(dirs)
+	if err != nil {
+		logError(T("Cannot watch sources: %v"), err)
+		os.Exit(exitFailure)
+	}
+	defer w.Close()
+
+	for _, wt := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := translateOne(wt); err != nil {
+			os.Exit(exitCode(err))
+		}
+	}
+
+	if ctx.Err() == nil {
+		logInfo(T("Watching %d targets for changes (Ctrl-C to stop)"), len(targets))
+	}
+
+	pending := make(map[string]bool)
+	rescan := false
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case err := <-w.Errors():
+			logWarning(T("Watch error: %v"), err)
+		case path := <-w.Events():
+			pending[path] = true
+			timer.Reset(debounce)
+		case <-w.Rescan():
+			rescan = true
+			timer.Reset(debounce)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			clear(pending)
+			all := rescan
+			rescan = false
+
+			ran := false
+			for _, wt := range targets {
+				if ctx.Err() != nil {
+					break
+				}
+				candidates := paths
+				if all {
+					// Changes were dropped: compare every source.
+					candidates = append(wt.sources(), paths...)
+				}
+				changed := wt.changed(candidates)
+				if len(changed) == 0 {
+					continue
+				}
+				logInfo(T("[%s] Changed: %s"), wt.rt.Target.Name, describeChanged(changed))
+				if err := translateOne(wt); err != nil {
+					os.Exit(exitCode(err))
+				}
+				ran = true
+			}
+			if ran && ctx.Err() == nil {
+				logInfo(T("Watching for changes..."))
+			}
+		}
+	}
+
+	printUsageSummary(opts.Usage)
+}
+
+// stopWatching reports whether err of a translation run means further runs
+// cannot succeed: the configuration or the credentials are wrong.
+func stopWatching(err error) bool {
+	switch exitCode(err) {
+	case exitConfig, exitAuth:
+		return true
+	}
+	return false
+}
+
+// describeChanged lists changed paths relative to the project root.
+func describeChanged(paths []string) string {
+	const maxListed = 3
+	names := make([]string, 0, maxListed)
+	for i, p := range paths {
+		if i == maxListed {
+			names = append(names, fmt.Sprintf(T("and %d more"), len(paths)-maxListed))
+			break
+		}
+		names = append(names, displayPath(p))
+	}
+	return strings.Join(names, ", ")
+}
+
+// displayPath returns path relative to the project root when it is below it.
+func displayPath(path string) string {
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(absRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// ---------------------------------------------------------------------------
+// Watched sources
+// ---------------------------------------------------------------------------
+
+// fileWatcher reports paths changed below watched directories. It is
+// implemented with inotify on Linux and by polling elsewhere.
+type fileWatcher interface {
+	Events() <-chan string
+	Errors() <-chan error
+	// Rescan signals that changes were dropped, so every source must be
+	// compared again.
+	Rescan() <-chan struct{}
+	Close() error
+}
+
+// watchQueue buffers the notifications of a fileWatcher. Sends never
+// block, so a burst of changes during a long translation run cannot stall
+// the watcher: events that do not fit are dropped and a rescan is requested
+// instead, and errors that do not fit are dropped.
+type watchQueue struct {
+	events chan string
+	errors chan error
+	rescan chan struct{}
+}
+
+func newWatchQueue() watchQueue {
+	return watchQueue{
+		events: make(chan string, 256),
+		errors: make(chan error, 16),
+		rescan: make(chan struct{}, 1),
+	}
+}
+
+func (q watchQueue) Events() <-chan string   { return q.events }
+func (q watchQueue) Errors() <-chan error    { return q.errors }
+func (q watchQueue) Rescan() <-chan struct{} { return q.rescan }
+
+func (q watchQueue) sendEvent(path string) {
+	select {
+	case q.events <- path:
+	default:
+		q.requestRescan()
+	}
+}
+
+func (q watchQueue) sendError(err error) {
+	select {
+	case q.errors <- err:
+	default:
+	}
+}
+
+func (q watchQueue) requestRescan() {
+	select {
+	case q.rescan <- struct{}{}:
+	default:
+	}
+}
+
+// watchDir is a directory to watch, with or without its subdirectories.
+type watchDir struct {
+	path      string
+	recursive bool
+}
+
+// watchTarget holds the sources of a target and the content they had when
+// the target was last translated.
+type watchTarget struct {
+	rt config.ResolvedTarget
+	// files are individual source files.
+	files []string
+	// roots are source directories, watched recursively; match selects the
+	// changed paths below them that are sources.
+	roots []string
+	match func(paths []string) []string
+	// digests holds the content digest of each source path seen so far.
+	digests map[string][sha256.Size]byte
+}
+
+// newWatchTarget returns the sources of rt.
+func newWatchTarget(rt config.ResolvedTarget) *watchTarget {
+	wt := &watchTarget{rt: rt, digests: make(map[string][sha256.Size]byte)}
+
+	switch rt.Target.Type {
+	case config.TargetTypeGettext:
+		srcs := []string{rt.AbsRoot}
+		if len(rt.Target.Sources) > 0 {
+			srcs = srcs[:0]
+			for _, src := range rt.Target.Sources {
+				srcs = append(srcs, filepath.Join(rt.AbsRoot, src))
+			}
+		}
+		for _, src := range srcs {
+			if info, err := os.Stat(src); err == nil && info.Mode().IsRegular() {
+				wt.files = append(wt.files, src)
+			} else {
+				wt.roots = append(wt.roots, src)
+			}
+		}
+		poDir := rt.AbsPODir()
+		wt.match = func(paths []string) []string {
+			var out []string
+			for _, p := range paths {
+				if !isBelow(p, poDir) && extract.FileLanguage(p) != "" {
+					out = append(out, p)
+				}
+			}
+			return out
+		}
+	case config.TargetTypePo4a:
+		cfgPath := rt.AbsPo4aConfig()
+		wt.files = append(wt.files, cfgPath)
+		masters, _ := rt.DocsPOMasters()
+		for _, m := range masters {
+			if !filepath.IsAbs(m) {
+				m = filepath.Join(filepath.Dir(cfgPath), m)
+			}
+			wt.files = append(wt.files, m)
+		}
+	case config.TargetTypeAndroid:
+		wt.files = append(wt.files, android.SourceStringsXMLPath(rt.AbsResDir()))
+	case config.TargetTypeMarkdown:
+		wt.roots = append(wt.roots, layout.MarkdownSourceDir(rt))
+		wt.match = func(paths []string) []string {
+			// Re-discover so that new documents are picked up and
+			// translations in the source tree stay excluded.
+			files, err := layout.MarkdownSourceFiles(rt)
+			if err != nil {
+				return nil
+			}
+			sources := make(map[string]bool, len(files))
+			for _, f := range files {
+				sources[f] = true
+			}
+			var out []string
+			for _, p := range paths {
+				if sources[p] {
+					out = append(out, p)
+				}
+			}
+			return out
+		}
+	default:
+		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+			wt.files = append(wt.files, filepath.Join(rt.AbsRoot, filepath.FromSlash(rt.Target.Source.Index)))
+		} else {
+			wt.files = append(wt.files, rt.SourcePathCandidates()...)
+		}
+	}
+
+	wt.snapshot()
+	return wt
+}
+
+// watchDirs returns the directories to watch for the target's sources.
+// Individual files are watched through their directory, so that editors
+// replacing a file on save are noticed.
+func (wt *watchTarget) watchDirs() []watchDir {
+	var dirs []watchDir
+	for _, f := range wt.files {
+		dirs = append(dirs, watchDir{path: filepath.Dir(f)})
+	}
+	for _, r := range wt.roots {
+		dirs = append(dirs, watchDir{path: r, recursive: true})
+	}
+	return dirs
+}
+
+// changed returns the sources among paths whose content changed since it
+// was last seen.
+func (wt *watchTarget) changed(paths []string) []string {
+	var candidates []string
+	var below []string
+	for _, p := range paths {
+		if wt.isFile(p) {
+			candidates = append(candidates, p)
+			continue
+		}
+		for _, r := range wt.roots {
+			if isBelow(p, r) {
+				below = append(below, p)
+				break
+			}
+		}
+	}
+	if len(below) > 0 && wt.match != nil {
+		candidates = append(candidates, wt.match(below)...)
+	}
+
+	var out []string
+	for _, p := range candidates {
+		sum, ok := fileDigest(p)
+		if prev, seen := wt.digests[p]; ok == seen && sum == prev {
+			continue
+		}
+		if ok {
+			wt.digests[p] = sum
+		} else {
+			delete(wt.digests, p)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// sources lists the target's individual source files and every file below
+// its source directories.
+func (wt *watchTarget) sources() []string {
+	out := append([]string(nil), wt.files...)
+	for _, r := range wt.roots {
+		_ = filepath.WalkDir(r, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != r && skipWatchDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			out = append(out, path)
+			return nil
+		})
+	}
+	return out
+}
+
+// snapshot records the content of the target's individual source files.
+func (wt *watchTarget) snapshot() {
+	for _, f := range wt.files {
+		if sum, ok := fileDigest(f); ok {
+			wt.digests[f] = sum
+		} else {
+			delete(wt.digests, f)
+		}
+	}
+}
+
+func (wt *watchTarget) isFile(path string) bool {
+	for _, f := range wt.files {
+		if f == path {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDigest returns the SHA-256 of a file's content; ok is false if the
+// file cannot be read (e.g. it was removed).
+func fileDigest(path string) (sum [sha256.Size]byte, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sum, false
+	}
+	return sha256.Sum256(data), true
+}
+
+// isBelow reports whether path is dir or inside it.
+func isBelow(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// skipWatchDir reports whether a subdirectory of a recursively watched
+// directory is left out, like source scanning does.
+func skipWatchDir(name string) bool {
+	return extract.IsSkippedDir(name)
+}
@@ -0,0 +1,156 @@
+//go:build linux
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF
+
+// inotifyWatcher reports changes below watched directories using inotify.
+type inotifyWatcher struct {
+	watchQueue
+	file *os.File
+	fd   int
+
+	mu        sync.Mutex
+	dirs      map[int]string
+	recursive map[string]bool
+}
+
+// newFileWatcher starts watching dirs.
+func newFileWatcher(dirs []watchDir) (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	w := &inotifyWatcher{
+		// A non-blocking descriptor wrapped in an os.File goes through the
+		// runtime poller, so Close unblocks a pending Read.
+		watchQueue: newWatchQueue(),
+		file:       os.NewFile(uintptr(fd), "inotify"),
+		fd:         fd,
+		dirs:       make(map[int]string),
+		recursive:  make(map[string]bool),
+	}
+	for _, d := range dirs {
+		if err := w.add(d.path, d.recursive); err != nil {
+			w.file.Close()
+			return nil, err
+		}
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Close() error { return w.file.Close() }
+
+// add watches dir and, if recursive, its subdirectories. Missing
+// directories are ignored: they are picked up when created below a
+// recursively watched directory.
+func (w *inotifyWatcher) add(dir string, recursive bool) error {
+	if !recursive {
+		return w.addOne(dir, false)
+	}
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && skipWatchDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		return w.addOne(path, true)
+	})
+}
+
+func (w *inotifyWatcher) addOne(dir string, recursive bool) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ENOTDIR) {
+			return nil
+		}
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirs[wd] = dir
+	w.recursive[dir] = w.recursive[dir] || recursive
+	return nil
+}
+
+// run reads inotify events until the watcher is closed.
+func (w *inotifyWatcher) run() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.sendError(err)
+			}
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+			off += syscall.SizeofInotifyEvent + int(ev.Len)
+			w.handle(ev, string(trimNUL(nameBytes)))
+		}
+	}
+}
+
+func (w *inotifyWatcher) handle(ev *syscall.InotifyEvent, name string) {
+	if ev.Mask&syscall.IN_Q_OVERFLOW != 0 {
+		w.sendError(errors.New("inotify event queue overflowed, rescanning sources"))
+		w.requestRescan()
+		return
+	}
+
+	w.mu.Lock()
+	dir, ok := w.dirs[int(ev.Wd)]
+	if ev.Mask&syscall.IN_IGNORED != 0 {
+		delete(w.dirs, int(ev.Wd))
+	}
+	recursive := w.recursive[dir]
+	w.mu.Unlock()
+	if !ok || name == "" {
+		return
+	}
+
+	path := filepath.Join(dir, name)
+	if ev.Mask&syscall.IN_ISDIR != 0 {
+		// New subdirectories of recursively watched directories are
+		// watched too; files created in them before the watch was added
+		// are reported as they are found.
+		if recursive && ev.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && !skipWatchDir(name) {
+			if err := w.add(path, true); err != nil {
+				w.sendError(err)
+			}
+			_ = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					w.sendEvent(p)
+				}
+				return nil
+			})
+		}
+		return
+	}
+	w.sendEvent(path)
+}
+
+// trimNUL strips the NUL padding of an inotify event name.
+func trimNUL(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+//go:build !linux
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often watched directories are scanned for changes.
+var pollInterval = time.Second
+
+// pollWatcher reports changes below watched directories by comparing the
+// modification time and size of their files between scans.
+type pollWatcher struct {
+	watchQueue
+	dirs []watchDir
+	done chan struct{}
+	once sync.Once
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// newFileWatcher starts watching dirs.
+func newFileWatcher(dirs []watchDir) (fileWatcher, error) {
+	w := &pollWatcher{
+		watchQueue: newWatchQueue(),
+		dirs:       dirs,
+		done:       make(chan struct{}),
+	}
+	go w.run(w.scan())
+	return w, nil
+}
+
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *pollWatcher) run(prev map[string]fileStamp) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		cur := w.scan()
+		for path, st := range cur {
+			if old, ok := prev[path]; !ok || old != st {
+				w.sendEvent(path)
+			}
+		}
+		for path := range prev {
+			if _, ok := cur[path]; !ok {
+				w.sendEvent(path)
+			}
+		}
+		prev = cur
+	}
+}
+
+// scan returns the stamps of all files in the watched directories.
+func (w *pollWatcher) scan() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	add := func(path string, info os.FileInfo) {
+		if info.Mode().IsRegular() {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	for _, d := range w.dirs {
+		if !d.recursive {
+			entries, err := os.ReadDir(d.path)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				if info, err := e.Info(); err == nil {
+					add(filepath.Join(d.path, e.Name()), info)
+				}
+			}
+			continue
+		}
+		_ = filepath.WalkDir(d.path, func(path string, e os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if e.IsDir() {
+				if path != d.path && skipWatchDir(e.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := e.Info(); err == nil {
+				add(path, info)
+			}
+			return nil
+		})
+	}
+	return stamps
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minios-linux/lokit/config"
+)
+
+func TestWatchTargetChanged(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	app := write("src/app.py", "print(_('Hello'))\n")
+	notes := write("src/notes.txt", "not a source\n")
+	poFile := write("po/de.po", "")
+
+	t.Run("gettext sources", func(t *testing.T) {
+		wt := newWatchTarget(config.ResolvedTarget{
+			Target:  config.Target{Name: "app", Type: config.TargetTypeGettext, Dir: "po", Sources: []string{"."}},
+			AbsRoot: dir,
+		})
+		paths := []string{app, notes, poFile}
+		if got, want := wt.changed(paths), []string{app}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("changed = %v, want %v", got, want)
+		}
+		if got := wt.changed(paths); got != nil {
+			t.Fatalf("unchanged content reported: %v", got)
+		}
+		write("src/app.py", "print(_('Hello, world'))\n")
+		if got, want := wt.changed(paths), []string{app}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("after edit changed = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("source file ignores own writes", func(t *testing.T) {
+		src := write("locales/en.json", `{"hello": "Hello"}`)
+		de := write("locales/de.json", `{}`)
+		wt := newWatchTarget(config.ResolvedTarget{
+			Target:  config.Target{Name: "web", Type: config.TargetTypeI18Next, Dir: "locales", Pattern: "{lang}.json", SourceLang: "en"},
+			AbsRoot: dir,
+		})
+		if got := wt.changed([]string{src, de}); got != nil {
+			t.Fatalf("snapshotted source reported: %v", got)
+		}
+		write("locales/en.json", `{"hello": "Hello", "bye": "Bye"}`)
+		if got, want := wt.changed([]string{src, de}), []string{src}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("changed = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestFileWatcherReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newFileWatcher([]watchDir{{path: dir, recursive: true}})
+	if err != nil {
+		t.Fatalf("newFileWatcher: %v", err)
+	}
+	defer w.Close()
+
+	// Give a polling watcher time for its first scan.
+	time.Sleep(100 * time.Millisecond)
+	path := filepath.Join(dir, "sub", "app.js")
+	if err := os.WriteFile(path, []byte("t('hello')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-w.Events():
+			if got == path {
+				return
+			}
+		case err := <-w.Errors():
+			t.Fatalf("watch error: %v", err)
+		case <-timeout:
+			t.Fatalf("no event for %s", path)
+		}
+	}
+}
+
+func TestWatchQueueRescansWhenFull(t *testing.T) {
+	q := newWatchQueue()
+	// Nobody reads: sends must not block.
+	for i := 0; i < cap(q.events)+10; i++ {
+		q.sendEvent(filepath.Join("src", "app.js"))
+	}
+	for i := 0; i < cap(q.errors)+10; i++ {
+		q.sendError(os.ErrClosed)
+	}
+	select {
+	case <-q.Rescan():
+	default:
+		t.Fatal("no rescan requested after dropped events")
+	}
+	if len(q.events) != cap(q.events) {
+		t.Fatalf("queued %d events, want %d", len(q.events), cap(q.events))
+	}
+
+	dir := t.TempDir()
+	app := filepath.Join(dir, "src", "app.py")
+	if err := os.MkdirAll(filepath.Dir(app), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(app, []byte("print(_('Hello'))\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt := newWatchTarget(config.ResolvedTarget{
+		Target:  config.Target{Name: "app", Type: config.TargetTypeGettext, Dir: "po", Sources: []string{"src"}},
+		AbsRoot: dir,
+	})
+	if got, want := wt.changed(wt.sources()), []string{app}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("rescan changed = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,850 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	formatfile "github.com/minios-linux/lokit/format"
+	"github.com/minios-linux/lokit/format/android"
+	"github.com/minios-linux/lokit/format/applestrings"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/desktop"
+	"github.com/minios-linux/lokit/format/i18next"
+	"github.com/minios-linux/lokit/format/jskv"
+	mdfile "github.com/minios-linux/lokit/format/markdown"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/polkit"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	"github.com/minios-linux/lokit/format/qt"
+	"github.com/minios-linux/lokit/format/vuei18n"
+	"github.com/minios-linux/lokit/format/xcstrings"
+	"github.com/minios-linux/lokit/format/xliff"
+	yamlfile "github.com/minios-linux/lokit/format/yaml"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/internal/layout"
+	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/translate"
+	"github.com/spf13/cobra"
+)
+
+// ---------------------------------------------------------------------------
+// Commands
+// ---------------------------------------------------------------------------
+
+func newExportCmd() *cobra.Command {
+	var targets []string
+	var langFlag, formatFlag, version, outDir string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: T("Export translations for external translators"),
+		Long: T(`Export the translations of every configured target as XLIFF files, one
+file per target and language.
+
+Each translation file of the target becomes an XLIFF <file> element and
+each key a unit with the source text, the current translation, its state
+and notes (gettext context, comments and references, machine translation
+model). Untranslated keys are exported with an empty target.
+
+States: untranslated keys are "needs-translation" (XLIFF 2.0: "initial"),
+machine translations pending review and fuzzy entries are
+"needs-review-translation" (2.0: "translated") and other translations are
+"translated" (2.0: "reviewed").
+
+Examples:
+  lokit export --format xliff
+  lokit export --format xliff --xliff-version 2.0 --output to-agency
+  lokit export --format xliff --target app --lang de,fr`),
+		Run: func(cmd *cobra.Command, args []string) {
+			if formatFlag != "xliff" {
+				logError(T("Unsupported export format %q (supported: xliff)"), formatFlag)
+				os.Exit(exitConfig)
+			}
+			if version != xliff.Version12 && version != xliff.Version20 {
+				logError(T("Unsupported XLIFF version %q (supported: 1.2, 2.0)"), version)
+				os.Exit(exitConfig)
+			}
+			runExport(targets, langFlag, version, outDir)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+	cmd.Flags().StringVarP(&langFlag, "lang", "l", "", T("Comma-separated languages (default: all)"))
+	cmd.Flags().StringVar(&formatFlag, "format", "xliff", T("Export format: xliff"))
+	cmd.Flags().StringVar(&version, "xliff-version", xliff.Version12, T("XLIFF version: 1.2 or 2.0"))
+	cmd.Flags().StringVarP(&outDir, "output", "o", "xliff", T("Output directory, relative to the project root"))
+	return cmd
+}
+
+func newImportCmd() *cobra.Command {
+	var targets []string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE...",
+		Short: T("Import reviewed XLIFF files"),
+		Long: T(`Import XLIFF 1.2 or 2.0 files produced by 'lokit export' and write the
+translations back into the target files.
+
+Units are matched by file ("original" attribute) and key. Units whose
+source text changed since the export, and units with an empty target, are
+skipped.
+
+Translations in the "translated", "final" or "signed-off" state (XLIFF
+2.0: "reviewed" or "final") are accepted: the machine translation mark and
+the fuzzy flag of gettext entries are removed, and the key is removed from
+lokit.review. Other translations are written without changing their
+review state.
+
+Examples:
+  lokit import xliff/app.de.xlf
+  lokit import --dry-run from-agency/*.xlf`),
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runImport(args, targets, dryRun)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&targets, "target", nil, T("Target name from lokit.yaml (repeat flag or use comma-separated list; default: all targets)"))
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, T("Show what would be imported without writing files"))
+	return cmd
+}
+
+// ---------------------------------------------------------------------------
+// Exchange files
+// ---------------------------------------------------------------------------
+
+// exchangeUnit is one translatable string of a translation file.
+type exchangeUnit struct {
+	key         string
+	source      string
+	translation string
+	state       xliff.State
+	notes       []string
+}
+
+// exchangeFile is one translation file of a target language, viewed as
+// keyed units for XLIFF export and import.
+type exchangeFile struct {
+	path  string
+	units []exchangeUnit
+	// set writes the translation of units[i]; accept also clears its
+	// review marks. It reports whether the file changed.
+	set   func(i int, value string, accept bool) bool
+	write func() error
+}
+
+// collectExchangeFiles loads the translation files of one target language.
+// Files that do not exist yet are created in memory from the source, so
+// untranslated languages can be exported too (gettext and po4a catalogs are
+// created by 'lokit translate' and are skipped until then).
+func collectExchangeFiles(rt config.ResolvedTarget, lang string, state *review.State) ([]*exchangeFile, error) {
+	scope := lockfile.LockTargetKey(rt.Target.Name, lang)
+	excluded := exchangeExclusions(&rt.Target)
+	kv := func(path string, file formatfile.KVFile, get func(string) (string, bool), srcVals map[string]string, prefix string) *exchangeFile {
+		return newKVExchangeFile(path, file, get, srcVals, scope, prefix, state, excluded)
+	}
+
+	switch rt.Target.Type {
+	case config.TargetTypeGettext:
+		path := rt.POPath(lang)
+		if !layout.FileExists(path) {
+			return nil, nil
+		}
+		f, err := newPOExchangeFile(path, lang)
+		if err != nil {
+			return nil, err
+		}
+		return []*exchangeFile{f}, nil
+	case config.TargetTypePo4a:
+		var files []*exchangeFile
+		for _, doc := range rt.DocsPOFiles(lang) {
+			if !layout.FileExists(doc.Path) {
+				continue
+			}
+			f, err := newPOExchangeFile(doc.Path, lang)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+		return files, nil
+	case config.TargetTypeI18Next:
+		src, err := i18next.ParseFile(rt.SourcePath())
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), rt.SourcePath(), err)
+		}
+		path := rt.TranslationPath(lang)
+		file, err := i18next.ParseFile(path)
+		if err != nil {
+			file = i18next.NewTranslationFile(src, lang)
+		} else {
+			file.SyncKeys(src, lang)
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeVueI18n:
+		if rt.Target.Source != nil && rt.Target.Source.IsIndex() {
+			return collectIndexExchangeFiles(rt, lang, kv)
+		}
+		src, err := vuei18n.ParseFile(exchangeSourcePath(rt))
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), exchangeSourcePath(rt), err)
+		}
+		path := rt.TranslationPath(lang)
+		file, err := vuei18n.ParseFile(path)
+		if err != nil {
+			file = vuei18n.NewTranslationFile(src, lang)
+		} else {
+			vuei18n.SyncKeys(src, file)
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeAndroid:
+		srcPath := android.SourceStringsXMLPath(rt.AbsResDir())
+		src, err := android.ParseFile(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source strings.xml %s: %v"), srcPath, err)
+		}
+		path := android.StringsXMLPath(rt.AbsResDir(), lang)
+		file, err := android.ParseFile(path)
+		if err != nil {
+			file = android.NewTranslationFile(src, lang)
+		} else {
+			file.SyncKeys(src, lang)
+		}
+		kvFile := translate.NewAndroidKVFile(file, src, lang)
+		getter, _ := kvFile.(interface{ Get(string) (string, bool) })
+		return []*exchangeFile{kv(path, kvFile, getter.Get, kvFile.SourceValues(), "")}, nil
+	case config.TargetTypeYAML:
+		srcPath := rt.ExistingSourcePath()
+		if srcPath == "" {
+			return nil, fmt.Errorf(T("cannot find source YAML file for language %q in %s"), rt.Target.SourceLang, rt.AbsTranslationsDir())
+		}
+		src, err := yamlfile.ParseFile(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), srcPath, err)
+		}
+		path := rt.ExistingTranslationPath(lang)
+		var file *yamlfile.File
+		if path == "" {
+			path = rt.TranslationPath(lang)
+			file = yamlfile.NewTranslationFile(src, lang)
+		} else {
+			if file, err = yamlfile.ParseFile(path); err != nil {
+				return nil, err
+			}
+			yamlfile.SyncKeys(src, file)
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeMarkdown:
+		return collectMarkdownExchangeFiles(rt, lang, kv)
+	case config.TargetTypeProperties:
+		src, err := propfile.ParseFile(exchangeSourcePath(rt))
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), exchangeSourcePath(rt), err)
+		}
+		path := rt.TranslationPath(lang)
+		file, err := propfile.ParseFile(path)
+		if err != nil {
+			file = propfile.NewTranslationFile(src, lang)
+		} else {
+			propfile.SyncKeys(src, file)
+		}
+		layout.SetPropertiesEncoding(rt, file)
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeFlutter:
+		src, err := arbfile.ParseFile(exchangeSourcePath(rt))
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), exchangeSourcePath(rt), err)
+		}
+		path := rt.TranslationPath(lang)
+		file, err := arbfile.ParseFile(path)
+		if err != nil {
+			file = arbfile.NewTranslationFile(src, lang)
+		} else {
+			arbfile.SyncKeys(src, file)
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeJSKV:
+		src, err := jskv.ParseFile(exchangeSourcePath(rt))
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), exchangeSourcePath(rt), err)
+		}
+		path := rt.ExistingTranslationPath(lang)
+		if path == "" {
+			path = rt.TranslationPath(lang)
+		}
+		file, err := jskv.ParseFile(path)
+		if err != nil {
+			file = jskv.NewTranslationFile(src)
+		} else {
+			jskv.SyncKeys(src, file)
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeDesktop:
+		path := rt.SourcePath()
+		src, err := desktop.ParseFile(path, rt.Target.SourceLang)
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read desktop file %s: %v"), path, err)
+		}
+		file, err := desktop.ParseFile(path, lang)
+		if err != nil {
+			return nil, err
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypePolkit:
+		path := rt.SourcePath()
+		src, err := polkit.ParseFile(path, rt.Target.SourceLang)
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read policy file %s: %v"), path, err)
+		}
+		file, err := polkit.ParseFile(path, lang)
+		if err != nil {
+			return nil, err
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeXCStrings:
+		path := rt.SourcePath()
+		src, err := xcstrings.ParseFile(path, rt.Target.SourceLang)
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read string catalog %s: %v"), path, err)
+		}
+		file, err := xcstrings.ParseFile(path, lang)
+		if err != nil {
+			return nil, err
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeAppleStrings:
+		src, err := applestrings.ParseFile(exchangeSourcePath(rt))
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), exchangeSourcePath(rt), err)
+		}
+		path := rt.TranslationPath(lang)
+		file, err := applestrings.ParseFile(path)
+		if err != nil {
+			file = applestrings.NewTranslationFile(src, lang)
+		} else {
+			applestrings.SyncKeys(src, file, lang)
+		}
+		return []*exchangeFile{kv(path, file, file.Get, src.SourceValues(), "")}, nil
+	case config.TargetTypeQt:
+		src, err := qt.ParseFile(exchangeSourcePath(rt))
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), exchangeSourcePath(rt), err)
+		}
+		path := rt.TranslationPath(lang)
+		file, err := qt.ParseFile(path)
+		if err != nil {
+			file = qt.NewTranslationFile(src, lang)
+		} else {
+			qt.SyncMessages(src, file, lang)
+		}
+		return []*exchangeFile{newQtExchangeFile(path, lang, file)}, nil
+	default:
+		return nil, fmt.Errorf(T("unsupported target type %q"), rt.Target.Type)
+	}
+}
+
+func exchangeSourcePath(rt config.ResolvedTarget) string {
+	if p := rt.ExistingSourcePath(); p != "" {
+		return p
+	}
+	return rt.SourcePath()
+}
+
+// exchangeExclusions reports keys that are not exchanged: ignored keys are
+// never translated and locked keys are hand-curated.
+func exchangeExclusions(t *config.Target) func(key string) bool {
+	patterns, err := layout.CompileLockedPatterns(t.LockedPatterns)
+	if err != nil {
+		logWarning(T("%v"), err)
+	}
+	skip := make(map[string]bool, len(t.IgnoredKeys)+len(t.LockedKeys))
+	for _, k := range t.IgnoredKeys {
+		skip[k] = true
+	}
+	for _, k := range t.LockedKeys {
+		skip[k] = true
+	}
+	return func(key string) bool {
+		if skip[key] {
+			return true
+		}
+		for _, re := range patterns {
+			if re.MatchString(key) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type kvExchangeBuilder func(path string, file formatfile.KVFile, get func(string) (string, bool), srcVals map[string]string, prefix string) *exchangeFile
+
+func collectMarkdownExchangeFiles(rt config.ResolvedTarget, lang string, kv kvExchangeBuilder) ([]*exchangeFile, error) {
+	srcDir := layout.MarkdownSourceDir(rt)
+	srcFiles, err := layout.MarkdownSourceFiles(rt)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read markdown files in %s: %v"), srcDir, err)
+	}
+	var files []*exchangeFile
+	for _, srcPath := range srcFiles {
+		relPath, err := filepath.Rel(srcDir, srcPath)
+		if err != nil {
+			continue
+		}
+		src, err := mdfile.ParseFile(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf(T("cannot read source file %s: %v"), srcPath, err)
+		}
+		path := layout.MarkdownTargetPath(rt, lang, relPath)
+		file, err := mdfile.ParseFile(path)
+		if err != nil {
+			file = mdfile.NewTranslationFile(src, lang)
+		} else {
+			mdfile.SyncKeys(src, file)
+		}
+		files = append(files, kv(path, file, file.Get, src.SourceValues(), filepath.ToSlash(relPath)))
+	}
+	return files, nil
+}
+
+func collectIndexExchangeFiles(rt config.ResolvedTarget, lang string, kv kvExchangeBuilder) ([]*exchangeFile, error) {
+	item, err := layout.LoadIndexItem(rt)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf(T("index source item not found for %s"), rt.Target.Name)
+	}
+	sourceValues := layout.IndexSourceValues(item)
+	path := rt.TranslationPath(lang)
+	file, err := layout.ParseIndexFile(path)
+	if err != nil {
+		file = layout.NewIndexFile(sourceValues)
+	}
+	file.AddKeys(sourceValues)
+	return []*exchangeFile{kv(path, file, file.Lookup, sourceValues, "")}, nil
+}
+
+// newKVExchangeFile exposes a key-value translation file. Machine
+// translations recorded in lokit.review need a review.
+func newKVExchangeFile(path string, file formatfile.KVFile, get func(string) (string, bool), srcVals map[string]string, scope, prefix string, state *review.State, excluded func(string) bool) *exchangeFile {
+	stateKey := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + ":" + key
+	}
+
+	// Plural categories the target language adds (e.g. "item_few" for
+	// Russian) are exported with the source "other" form.
+	srcVals = translate.WithPluralSourceValues(file, srcVals)
+	f := &exchangeFile{path: path}
+	for _, key := range file.Keys() {
+		source := srcVals[key]
+		if source == "" || excluded(key) {
+			continue
+		}
+		value, _ := get(key)
+		u := exchangeUnit{key: key, source: source, translation: value, state: xliff.StateTranslated}
+		if value == "" {
+			u.state = xliff.StateNew
+		} else if e, ok := state.Get(scope, stateKey(key)); ok {
+			u.state = xliff.StateNeedsReview
+			u.notes = append(u.notes, fmt.Sprintf("Machine translation (%s)", e.Model))
+			if e.Rejected {
+				u.notes = append(u.notes, "Rejected in review")
+			}
+		}
+		f.units = append(f.units, u)
+	}
+
+	f.set = func(i int, value string, accept bool) bool {
+		key := f.units[i].key
+		changed := false
+		if old, _ := get(key); old != value {
+			file.Set(key, value)
+			changed = true
+		}
+		if accept && state.Accept(scope, stateKey(key)) {
+			changed = true
+		}
+		return changed
+	}
+	f.write = func() error {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return file.WriteFile(path)
+	}
+	return f
+}
+
+// newPOExchangeFile exposes a PO catalog. Plural entries become one unit
+// per plural form ("msgid[0]", "msgid[1]", ...); fuzzy and machine-translated
+// entries need a review.
+func newPOExchangeFile(path, lang string) (*exchangeFile, error) {
+	catalog, err := po.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(T("cannot read PO %s: %v"), path, err)
+	}
+	return newCatalogExchangeFile(path, lang, catalog, func() error { return catalog.WriteFile(path) }), nil
+}
+
+// newQtExchangeFile exposes a Qt Linguist file through its PO view.
+// Unfinished translations need a review.
+func newQtExchangeFile(path, lang string, file *qt.File) *exchangeFile {
+	catalog := file.POFile(lang)
+	return newCatalogExchangeFile(path, lang, catalog, func() error {
+		file.ApplyPO(catalog)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return file.WriteFile(path)
+	})
+}
+
+// newCatalogExchangeFile exposes the entries of catalog, saved by write.
+func newCatalogExchangeFile(path, lang string, catalog *po.File, write func() error) *exchangeFile {
+	type poUnit struct {
+		entry *po.Entry
+		form  int // -1 for singular entries
+	}
+	var refs []poUnit
+	f := &exchangeFile{path: path}
+	for _, e := range catalog.Entries {
+		if e.MsgID == "" || e.Obsolete {
+			continue
+		}
+		key := e.MsgID
+		if e.MsgCtxt != "" {
+			key = e.MsgCtxt + "|" + e.MsgID
+		}
+		notes := poExchangeNotes(e)
+		pending := e.IsFuzzy()
+		if _, ok := review.POModel(e); ok {
+			pending = true
+		}
+		add := func(key, source, value string, form int) {
+			u := exchangeUnit{key: key, source: source, translation: value, state: xliff.StateTranslated, notes: notes}
+			if value == "" {
+				u.state = xliff.StateNew
+			} else if pending {
+				u.state = xliff.StateNeedsReview
+			}
+			f.units = append(f.units, u)
+			refs = append(refs, poUnit{entry: e, form: form})
+		}
+
+		if e.MsgIDPlural == "" {
+			add(key, e.MsgID, e.MsgStr, -1)
+			continue
+		}
+		n := len(e.MsgStrPlural)
+		if n == 0 {
+			n = translate.NPlurals(catalog, lang)
+		}
+		for form := 0; form < n; form++ {
+			source := e.MsgIDPlural
+			if form == 0 {
+				source = e.MsgID
+			}
+			add(fmt.Sprintf("%s[%d]", key, form), source, e.MsgStrPlural[form], form)
+		}
+	}
+
+	f.set = func(i int, value string, accept bool) bool {
+		ref := refs[i]
+		e := ref.entry
+		changed := false
+		if ref.form < 0 {
+			if e.MsgStr != value {
+				e.MsgStr, changed = value, true
+			}
+		} else if e.MsgStrPlural[ref.form] != value {
+			if e.MsgStrPlural == nil {
+				e.MsgStrPlural = make(map[int]string)
+			}
+			e.MsgStrPlural[ref.form], changed = value, true
+		}
+		if accept {
+			if review.AcceptPO(e) {
+				changed = true
+			}
+			if e.IsFuzzy() {
+				e.SetFuzzy(false)
+				changed = true
+			}
+		}
+		return changed
+	}
+	f.write = write
+	return f
+}
+
+func poExchangeNotes(e *po.Entry) []string {
+	var notes []string
+	if e.MsgCtxt != "" {
+		notes = append(notes, "Context: "+e.MsgCtxt)
+	}
+	notes = append(notes, e.ExtractedComments...)
+	for _, c := range e.TranslatorComments {
+		if !review.IsPOComment(c) {
+			notes = append(notes, c)
+		}
+	}
+	if model, ok := review.POModel(e); ok {
+		notes = append(notes, fmt.Sprintf("Machine translation (%s)", model))
+	}
+	if len(e.References) > 0 {
+		notes = append(notes, "References: "+strings.Join(e.References, " "))
+	}
+	return notes
+}
+
+// ---------------------------------------------------------------------------
+// Export
+// ---------------------------------------------------------------------------
+
+func runExport(targets []string, langFlag, version, outDir string) {
+	resolved, err := loadEngine().Select(targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	state, err := review.Load(rootDir)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitFailure)
+	}
+
+	if !filepath.IsAbs(outDir) {
+		outDir = filepath.Join(rootDir, outDir)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		logError(T("Creating directory %s: %v"), outDir, err)
+		os.Exit(exitFailure)
+	}
+
+	onlyLangs := parseLangFilter(langFlag)
+	sectionHeader(T("Export"))
+	written, failed := 0, false
+	for _, rt := range resolved {
+		for _, lang := range layout.FilterOutLang(rt.Languages, rt.Target.SourceLang) {
+			if onlyLangs != nil && !onlyLangs[lang] {
+				continue
+			}
+			files, err := collectExchangeFiles(rt, lang, state)
+			if err != nil {
+				logError(T("[%s] %s: %v"), rt.Target.Name, lang, err)
+				failed = true
+				continue
+			}
+			doc := &xliff.Document{Version: version, SourceLang: rt.Target.SourceLang, TargetLang: lang}
+			units, untranslated := 0, 0
+			for _, f := range files {
+				xf := xliff.File{Original: layout.RelPath(rootDir, f.path)}
+				for _, u := range f.units {
+					xf.Units = append(xf.Units, xliff.Unit{Name: u.key, Source: u.source, Target: u.translation, State: u.state, Notes: u.notes})
+					if u.state == xliff.StateNew {
+						untranslated++
+					}
+				}
+				units += len(xf.Units)
+				if len(xf.Units) > 0 {
+					doc.Files = append(doc.Files, xf)
+				}
+			}
+			if units == 0 {
+				continue
+			}
+			path := filepath.Join(outDir, exportFileName(rt.Target.Name, lang))
+			if err := doc.WriteFile(path); err != nil {
+				logError(T("Writing %s: %v"), path, err)
+				failed = true
+				continue
+			}
+			written++
+			logInfo(T("%s: %d units (%d untranslated)"), layout.RelPath(rootDir, path), units, untranslated)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr)
+	if written == 0 {
+		logWarning(T("Nothing to export"))
+	} else {
+		logSuccess(T("Exported %d XLIFF files to %s"), written, layout.RelPath(rootDir, outDir))
+	}
+	if failed {
+		os.Exit(exitPartial)
+	}
+}
+
+var exportNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportFileName returns "<target>.<lang>.xlf" with characters that are not
+// safe in file names replaced.
+func exportFileName(target, lang string) string {
+	return exportNameUnsafe.ReplaceAllString(target, "_") + "." + lang + ".xlf"
+}
+
+// parseLangFilter parses a comma-separated --lang value. It returns nil when
+// no filter is set.
+func parseLangFilter(langFlag string) map[string]bool {
+	if langFlag == "" {
+		return nil
+	}
+	langs := make(map[string]bool)
+	for _, l := range strings.Split(langFlag, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			langs[l] = true
+		}
+	}
+	return langs
+}
+
+// ---------------------------------------------------------------------------
+// Import
+// ---------------------------------------------------------------------------
+
+// importStats counts the units of one imported XLIFF document.
+type importStats struct {
+	updated, accepted, unchanged, skipped int
+	files                                 int
+}
+
+func runImport(paths, targets []string, dryRun bool) {
+	resolved, err := loadEngine().Select(targets)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitCode(err))
+	}
+	state, err := review.Load(rootDir)
+	if err != nil {
+		logError(T("%v"), err)
+		os.Exit(exitFailure)
+	}
+
+	sectionHeader(T("Import"))
+	failed := false
+	for _, path := range paths {
+		doc, err := xliff.ParseFile(path)
+		if err != nil {
+			logError(T("%v"), err)
+			failed = true
+			continue
+		}
+		stats, err := importDocument(doc, resolved, state, dryRun)
+		if err != nil {
+			logError(T("%s: %v"), path, err)
+			failed = true
+			continue
+		}
+		logInfo(T("%s: %d updated, %d accepted, %d unchanged, %d skipped (%d files)"),
+			path, stats.updated, stats.accepted, stats.unchanged, stats.skipped, stats.files)
+	}
+
+	if !dryRun {
+		if err := state.Save(); err != nil {
+			logWarning(T("Could not save review state: %v"), err)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr)
+	if failed {
+		os.Exit(exitPartial)
+	}
+	if dryRun {
+		logSuccess("%s", T("Dry run: no files were written"))
+	} else {
+		logSuccess("%s", T("Import complete"))
+	}
+}
+
+// importDocument applies the units of doc to the translation files of the
+// document's target language.
+func importDocument(doc *xliff.Document, resolved []config.ResolvedTarget, state *review.State, dryRun bool) (importStats, error) {
+	var stats importStats
+	if doc.TargetLang == "" {
+		return stats, fmt.Errorf("%s", T("XLIFF document has no target language"))
+	}
+
+	files := make(map[string]*exchangeFile)
+	for _, rt := range resolved {
+		lang, ok := matchTargetLang(rt, doc.TargetLang)
+		if !ok {
+			continue
+		}
+		collected, err := collectExchangeFiles(rt, lang, state)
+		if err != nil {
+			return stats, fmt.Errorf("[%s] %v", rt.Target.Name, err)
+		}
+		for _, f := range collected {
+			rel := layout.RelPath(rootDir, f.path)
+			if _, ok := files[rel]; !ok {
+				files[rel] = f
+			}
+		}
+	}
+
+	for _, xf := range doc.Files {
+		f, ok := files[xf.Original]
+		if !ok {
+			logWarning(T("%s: no configured %s translation file; %d units skipped"), xf.Original, doc.TargetLang, len(xf.Units))
+			stats.skipped += len(xf.Units)
+			continue
+		}
+		index := make(map[string]int, len(f.units))
+		for i, u := range f.units {
+			index[u.key] = i
+		}
+
+		dirty := false
+		for _, xu := range xf.Units {
+			i, ok := index[xu.Name]
+			if !ok || xu.Target == "" || f.units[i].source != xu.Source {
+				stats.skipped++
+				continue
+			}
+			accept := xu.State == xliff.StateTranslated || xu.State == xliff.StateFinal
+			if !f.set(i, xu.Target, accept) {
+				stats.unchanged++
+				continue
+			}
+			dirty = true
+			if f.units[i].translation != xu.Target {
+				stats.updated++
+			} else {
+				stats.accepted++
+			}
+		}
+		if !dirty {
+			continue
+		}
+		stats.files++
+		if dryRun {
+			continue
+		}
+		if err := f.write(); err != nil {
+			return stats, fmt.Errorf(T("writing %s: %v"), f.path, err)
+		}
+	}
+	return stats, nil
+}
+
+// matchTargetLang returns the language of rt that lang refers to, treating
+// "pt_BR" and "pt-BR" as the same language.
+func matchTargetLang(rt config.ResolvedTarget, lang string) (string, bool) {
+	want := strings.ReplaceAll(lang, "_", "-")
+	for _, l := range layout.FilterOutLang(rt.Languages, rt.Target.SourceLang) {
+		if strings.EqualFold(strings.ReplaceAll(l, "_", "-"), want) {
+			return l, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minios-linux/lokit/config"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/format/xliff"
+	"github.com/minios-linux/lokit/review"
+)
+
+func TestExchangeFilesJSKVRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	oldRoot := rootDir
+	rootDir = dir
+	t.Cleanup(func() { rootDir = oldRoot })
+
+	translationsDir := filepath.Join(dir, "translations")
+	if err := os.MkdirAll(translationsDir, 0o755); err != nil {
+		t.Fatalf("mkdir translations: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "en.js"), []byte("window.translations = {\n    \"Hello\": \"Hello\",\n    \"Bye\": \"Bye\",\n    \"OK\": \"OK\",\n    \"debug\": \"debug\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(translationsDir, "de.js"), []byte("window.translations = {\n    \"Hello\": \"Hallo\",\n    \"Bye\": \"[Tschüss]\",\n    \"OK\": \"\"\n};\n"), 0o644); err != nil {
+		t.Fatalf("write translation: %v", err)
+	}
+
+	rt := config.ResolvedTarget{
+		Target: config.Target{
+			Name:        "welcome",
+			Type:        config.TargetTypeJSKV,
+			Format:      config.TargetTypeJSKV,
+			Dir:         "translations",
+			Pattern:     "{lang}.js",
+			SourceLang:  "en",
+			Languages:   []string{"de"},
+			IgnoredKeys: []string{"debug"},
+		},
+		Languages: []string{"en", "de"},
+		AbsRoot:   dir,
+	}
+	state := review.New()
+	state.Mark("welcome/de", "Bye", "Bye", "[Tschüss]", "mock/pseudo")
+
+	files, err := collectExchangeFiles(rt, "de", state)
+	if err != nil {
+		t.Fatalf("collectExchangeFiles: %v", err)
+	}
+	if len(files) != 1 || len(files[0].units) != 3 {
+		t.Fatalf("files = %+v, want 1 file with 3 units (ignored key excluded)", files)
+	}
+	states := make(map[string]xliff.State)
+	for _, u := range files[0].units {
+		states[u.key] = u.state
+	}
+	if states["Hello"] != xliff.StateTranslated || states["Bye"] != xliff.StateNeedsReview || states["OK"] != xliff.StateNew {
+		t.Fatalf("states = %v", states)
+	}
+
+	doc := &xliff.Document{
+		SourceLang: "en",
+		TargetLang: "de",
+		Files: []xliff.File{{
+			Original: "translations/de.js",
+			Units: []xliff.Unit{
+				{Name: "Bye", Source: "Bye", Target: "Tschüss", State: xliff.StateFinal},
+				{Name: "OK", Source: "OK", Target: "OK", State: xliff.StateNeedsReview},
+				{Name: "Hello", Source: "Hello (old)", Target: "Servus", State: xliff.StateTranslated},
+			},
+		}},
+	}
+	stats, err := importDocument(doc, []config.ResolvedTarget{rt}, state, false)
+	if err != nil {
+		t.Fatalf("importDocument: %v", err)
+	}
+	if stats.updated != 2 || stats.skipped != 1 || stats.files != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	data, err := os.ReadFile(filepath.Join(translationsDir, "de.js"))
+	if err != nil {
+		t.Fatalf("read translation: %v", err)
+	}
+	for _, want := range []string{`"Bye": "Tschüss"`, `"OK": "OK"`, `"Hello": "Hallo"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("de.js lacks %s:\n%s", want, data)
+		}
+	}
+	if _, ok := state.Get("welcome/de", "Bye"); ok {
+		t.Error("accepted key is still pending review")
+	}
+}
+
+func TestPOExchangeFileAcceptsReviewedTranslations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "de.po")
+	if err := os.WriteFile(path, []byte(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#. menu item
+msgctxt "menu"
+msgid "Open"
+msgstr ""
+
+# lokit: machine, model=mock/pseudo
+#, fuzzy
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "[%d file]"
+msgstr[1] "[%d files]"
+`), 0o644); err != nil {
+		t.Fatalf("write PO: %v", err)
+	}
+
+	f, err := newPOExchangeFile(path, "de")
+	if err != nil {
+		t.Fatalf("newPOExchangeFile: %v", err)
+	}
+	if len(f.units) != 3 {
+		t.Fatalf("units = %+v", f.units)
+	}
+	open, plural := f.units[0], f.units[2]
+	if open.key != "menu|Open" || open.state != xliff.StateNew || open.notes[0] != "Context: menu" {
+		t.Errorf("open unit = %+v", open)
+	}
+	if plural.key != "%d file[1]" || plural.source != "%d files" || plural.state != xliff.StateNeedsReview {
+		t.Errorf("plural unit = %+v", plural)
+	}
+
+	f.set(1, "%d Datei", true)
+	f.set(2, "%d Dateien", true)
+	if err := f.write(); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	catalog, err := po.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	e := catalog.EntryByMsgID("%d file")
+	if e.IsFuzzy() || e.MsgStrPlural[1] != "%d Dateien" {
+		t.Errorf("entry = %+v", e)
+	}
+	if _, ok := review.POModel(e); ok {
+		t.Error("accepted entry still has the machine translation comment")
+	}
+}
+
+func TestExchangeFilesI18NextPluralCategories(t *testing.T) {
+	dir := t.TempDir()
+	oldRoot := rootDir
+	rootDir = dir
+	t.Cleanup(func() { rootDir = oldRoot })
+
+	localesDir := filepath.Join(dir, "locales")
+	if err := os.MkdirAll(localesDir, 0o755); err != nil {
+		t.Fatalf("mkdir locales: %v", err)
+	}
+	src := `{"translations": {"Hello": "Hello", "item_one": "{{count}} item", "item_other": "{{count}} items"}}`
+	if err := os.WriteFile(filepath.Join(localesDir, "en.json"), []byte(src), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	ru := `{"translations": {"Hello": "Привет", "item_one": "{{count}} элемент", "item_few": "", "item_many": "", "item_other": "{{count}} элемента"}}`
+	ruPath := filepath.Join(localesDir, "ru.json")
+	if err := os.WriteFile(ruPath, []byte(ru), 0o644); err != nil {
+		t.Fatalf("write translation: %v", err)
+	}
+
+	rt := config.ResolvedTarget{
+		Target: config.Target{
+			Name:       "web",
+			Type:       config.TargetTypeI18Next,
+			Format:     config.TargetTypeI18Next,
+			Dir:        "locales",
+			Pattern:    "{lang}.json",
+			SourceLang: "en",
+			Languages:  []string{"ru"},
+		},
+		Languages: []string{"en", "ru"},
+		AbsRoot:   dir,
+	}
+	state := review.New()
+
+	files, err := collectExchangeFiles(rt, "ru", state)
+	if err != nil {
+		t.Fatalf("collectExchangeFiles: %v", err)
+	}
+	if len(files) != 1 || len(files[0].units) != 5 {
+		t.Fatalf("files = %+v, want 1 file with 5 units", files)
+	}
+	sources := make(map[string]string)
+	for _, u := range files[0].units {
+		sources[u.key] = u.source
+	}
+	if sources["item_few"] != "{{count}} items" || sources["item_many"] != "{{count}} items" || sources["item_one"] != "{{count}} item" {
+		t.Fatalf("sources = %v, want target-only categories to use the source other form", sources)
+	}
+
+	doc := &xliff.Document{
+		SourceLang: "en",
+		TargetLang: "ru",
+		Files: []xliff.File{{
+			Original: "locales/ru.json",
+			Units: []xliff.Unit{
+				{Name: "item_few", Source: "{{count}} items", Target: "{{count}} элемента", State: xliff.StateTranslated},
+				{Name: "item_many", Source: "{{count}} items", Target: "{{count}} элементов", State: xliff.StateTranslated},
+			},
+		}},
+	}
+	stats, err := importDocument(doc, []config.ResolvedTarget{rt}, state, false)
+	if err != nil {
+		t.Fatalf("importDocument: %v", err)
+	}
+	if stats.updated != 2 || stats.skipped != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	data, err := os.ReadFile(ruPath)
+	if err != nil {
+		t.Fatalf("read translation: %v", err)
+	}
+	for _, want := range []string{`"item_few": "{{count}} элемента"`, `"item_many": "{{count}} элементов"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("ru.json lacks %s:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/lockfile"
+)
+
+// IndexFile is the JSON translation file of one index record: a flat
+// object mapping field names to translations.
+type IndexFile struct {
+	translations map[string]string
+}
+
+// NewIndexFile returns an index translation file holding the keys of
+// source, all untranslated.
+func NewIndexFile(source map[string]string) *IndexFile {
+	t := make(map[string]string, len(source))
+	for k := range source {
+		t[k] = ""
+	}
+	return &IndexFile{translations: t}
+}
+
+// ParseIndexFile reads an index translation file. Non-string values are
+// ignored.
+func ParseIndexFile(path string) (*IndexFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	t := make(map[string]string, len(raw))
+	for k, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		t[k] = s
+	}
+	return &IndexFile{translations: t}, nil
+}
+
+// Keys returns the keys in sorted order.
+func (f *IndexFile) Keys() []string {
+	keys := make([]string, 0, len(f.translations))
+	for k := range f.translations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UntranslatedKeys returns the keys with an empty value.
+func (f *IndexFile) UntranslatedKeys() []string {
+	keys := make([]string, 0)
+	for _, k := range f.Keys() {
+		if strings.TrimSpace(f.translations[k]) == "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Lookup returns the value of key and whether the file holds it.
+func (f *IndexFile) Lookup(key string) (string, bool) {
+	v, ok := f.translations[key]
+	return v, ok
+}
+
+// AddKeys adds the keys of source missing from the file, untranslated, and
+// reports whether any was added.
+func (f *IndexFile) AddKeys(source map[string]string) bool {
+	if f.translations == nil {
+		f.translations = make(map[string]string)
+	}
+	added := false
+	for k := range source {
+		if _, ok := f.translations[k]; !ok {
+			f.translations[k] = ""
+			added = true
+		}
+	}
+	return added
+}
+
+// Get returns the value of key.
+func (f *IndexFile) Get(key string) string {
+	return f.translations[key]
+}
+
+// Set sets the value of key and reports whether it changed.
+func (f *IndexFile) Set(key, value string) bool {
+	if f.translations == nil {
+		f.translations = make(map[string]string)
+	}
+	if old, ok := f.translations[key]; ok && old == value {
+		return false
+	}
+	f.translations[key] = value
+	return true
+}
+
+// Stats returns the number of keys, translated keys and their percentage.
+func (f *IndexFile) Stats() (total int, translated int, pct float64) {
+	total = len(f.translations)
+	for _, v := range f.translations {
+		if strings.TrimSpace(v) != "" {
+			translated++
+		}
+	}
+	if total > 0 {
+		pct = float64(translated) * 100 / float64(total)
+	}
+	return total, translated, pct
+}
+
+// SourceValues returns a copy of all values.
+func (f *IndexFile) SourceValues() map[string]string {
+	out := make(map[string]string, len(f.translations))
+	for k, v := range f.translations {
+		out[k] = v
+	}
+	return out
+}
+
+// WriteFile writes the file as indented JSON.
+func (f *IndexFile) WriteFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f.translations, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(path, data, 0o644)
+}
+
+func indexTargetID(rt config.ResolvedTarget) (string, bool) {
+	_, id, ok := config.SplitExpandedTargetName(rt.Target.Name)
+	if !ok {
+		return "", false
+	}
+	return id, true
+}
+
+// LoadIndexItem returns the source record of an index-sourced target, or nil
+// if the target has no index source.
+func LoadIndexItem(rt config.ResolvedTarget) (*config.IndexItem, error) {
+	if rt.Target.Source == nil || !rt.Target.Source.IsIndex() {
+		return nil, nil
+	}
+	id, ok := indexTargetID(rt)
+	if !ok {
+		return nil, fmt.Errorf(T("index source target must be expanded with {id}: %s"), rt.Target.Name)
+	}
+	items, err := config.LoadIndexItemsForTarget(rt.Target, rt.AbsRoot)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].ID == id {
+			return &items[i], nil
+		}
+	}
+	return nil, fmt.Errorf(T("source record %q not found in %s"), id, rt.Target.Source.Index)
+}
+
+// IndexSourceValues returns the translatable fields of a source record.
+func IndexSourceValues(item *config.IndexItem) map[string]string {
+	vals := make(map[string]string, len(item.Fields))
+	for k, v := range item.Fields {
+		vals[k] = v
+	}
+	return vals
+}
+
+// IndexSourceEntries returns the lock file entries of an index-sourced
+// target, keyed by field.
+func IndexSourceEntries(rt config.ResolvedTarget) (map[string]string, error) {
+	item, err := LoadIndexItem(rt)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]string)
+	if item == nil {
+		return entries, nil
+	}
+	for key, value := range item.Fields {
+		entries[key] = lockfile.KVEntryContent(key, value)
+	}
+	return entries, nil
+}
+
+// IndexGroup returns the name of the index-sourced target rt was expanded
+// from; ok is false for other targets.
+func IndexGroup(rt config.ResolvedTarget) (string, bool) {
+	if rt.Target.Source == nil || !rt.Target.Source.IsIndex() {
+		return "", false
+	}
+	base, _, ok := config.SplitExpandedTargetName(rt.Target.Name)
+	if !ok {
+		return "", false
+	}
+	return base, true
+}
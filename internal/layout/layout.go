@@ -0,0 +1,242 @@
+// Package layout locates the files of resolved targets and holds the
+// helpers shared by the lokit commands and the engine package.
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+	"github.com/minios-linux/lokit/format/android"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	. "github.com/minios-linux/lokit/i18n"
+	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/translate"
+)
+
+// FileExists returns true if the file exists and is not a directory.
+func FileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// SelectTargets returns the targets named in names, which may hold
+// comma-separated lists. A name that is not a target selects the targets
+// expanded from it (name/...). All targets are returned if names is empty.
+func SelectTargets(resolved []config.ResolvedTarget, targets []string) ([]config.ResolvedTarget, error) {
+	if len(targets) == 0 {
+		return resolved, nil
+	}
+
+	selected := make([]string, 0, len(targets))
+	seenInput := make(map[string]struct{}, len(targets))
+	for _, raw := range targets {
+		for _, part := range strings.Split(raw, ",") {
+			name := strings.TrimSpace(part)
+			if name == "" {
+				continue
+			}
+			if _, ok := seenInput[name]; ok {
+				continue
+			}
+			seenInput[name] = struct{}{}
+			selected = append(selected, name)
+		}
+	}
+
+	if len(selected) == 0 {
+		return resolved, nil
+	}
+
+	result := make([]config.ResolvedTarget, 0, len(resolved))
+	added := make(map[string]struct{}, len(resolved))
+
+	appendTarget := func(rt config.ResolvedTarget) {
+		if _, ok := added[rt.Target.Name]; ok {
+			return
+		}
+		added[rt.Target.Name] = struct{}{}
+		result = append(result, rt)
+	}
+
+	for _, target := range selected {
+		exactFound := false
+		for _, rt := range resolved {
+			if rt.Target.Name == target {
+				appendTarget(rt)
+				exactFound = true
+				break
+			}
+		}
+		if exactFound {
+			continue
+		}
+
+		prefixFound := false
+		for _, rt := range resolved {
+			if strings.HasPrefix(rt.Target.Name, target+"/") {
+				appendTarget(rt)
+				prefixFound = true
+			}
+		}
+		if !prefixFound {
+			return nil, fmt.Errorf(T("Target %q not found in lokit.yaml"), target)
+		}
+	}
+
+	return result, nil
+}
+
+// IntersectLanguages returns the intersection of two language lists.
+func IntersectLanguages(available, filter []string) []string {
+	filterMap := make(map[string]bool)
+	for _, l := range filter {
+		filterMap[strings.TrimSpace(l)] = true
+	}
+	var result []string
+	for _, l := range available {
+		if filterMap[l] {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// FilterOutLang removes a specific language from a list.
+func FilterOutLang(langs []string, exclude string) []string {
+	var result []string
+	for _, l := range langs {
+		if l != exclude {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// LockTargetKeys returns the lock file targets of rt for lang: one per
+// master document for po4a targets.
+func LockTargetKeys(rt config.ResolvedTarget, lang string) []string {
+	keys, ok := Po4aLockTargetKeys(rt, lang)
+	if !ok {
+		return []string{lockfile.LockTargetKey(rt.Target.Name, lang)}
+	}
+	return keys
+}
+
+// AndroidSourceValues returns the translatable values of an Android
+// resource file, keyed by name, name[index] for array items and
+// name#quantity for plurals.
+func AndroidSourceValues(srcFile *android.File) map[string]string {
+	vals := make(map[string]string)
+	for _, e := range srcFile.Entries {
+		if !e.IsTranslatable() || e.IsComment() {
+			continue
+		}
+		switch e.Kind {
+		case android.KindString:
+			vals[e.Name] = e.Value
+		case android.KindStringArray:
+			for idx, v := range e.Items {
+				vals[fmt.Sprintf("%s[%d]", e.Name, idx)] = v
+			}
+		case android.KindPlurals:
+			for _, q := range e.PluralOrder {
+				vals[fmt.Sprintf("%s#%s", e.Name, q)] = e.Plurals[q]
+			}
+		}
+	}
+	return vals
+}
+
+// Po4aLockTargetKeys is like LockTargetKeys but reports false when the
+// documents of a po4a target cannot be determined.
+func Po4aLockTargetKeys(rt config.ResolvedTarget, lang string) ([]string, bool) {
+	if rt.Target.Type != config.TargetTypePo4a {
+		return []string{lockfile.LockTargetKey(rt.Target.Name, lang)}, true
+	}
+
+	masters, err := rt.DocsPOMasters()
+	if err == nil && len(masters) > 0 {
+		keys := make([]string, 0, len(masters))
+		for _, master := range masters {
+			keys = append(keys, lockfile.LockTargetKey(rt.Target.Name+"/"+master, lang))
+		}
+		return keys, true
+	}
+
+	files := rt.DocsPOFiles(lang)
+	if len(files) == 0 {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(files))
+	for _, file := range files {
+		keys = append(keys, lockfile.LockTargetKey(rt.Target.Name+"/"+file.Master, lang))
+	}
+	return keys, true
+}
+
+// CompileLockedPatterns compiles locked_patterns strings into regexps.
+// Invalid patterns are skipped and reported in the returned error.
+func CompileLockedPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	var compiled []*regexp.Regexp
+	var errs []error
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(T("Invalid locked_pattern %q: %v"), p, err))
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, errors.Join(errs...)
+}
+
+// LengthLimits converts the length_limits of a target. Returns nil if the
+// target has none. Patterns are validated when lokit.yaml is loaded, so an
+// invalid one is skipped.
+func LengthLimits(t *config.Target) *translate.LengthLimits {
+	if len(t.LengthLimits) == 0 {
+		return nil
+	}
+	limits := &translate.LengthLimits{}
+	for _, l := range t.LengthLimits {
+		rule := translate.LengthRule{Max: l.Max, Ratio: l.Ratio}
+		if l.Keys != "" {
+			re, err := regexp.Compile(l.Keys)
+			if err != nil {
+				continue
+			}
+			rule.Pattern = re
+		}
+		limits.Rules = append(limits.Rules, rule)
+	}
+	return limits
+}
+
+// RelPath returns path relative to the project root for display.
+func RelPath(root, path string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(absRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// SetPropertiesEncoding applies the encoding configured for rt, if any, to
+// a .properties translation file.
+func SetPropertiesEncoding(rt config.ResolvedTarget, f *propfile.File) {
+	if rt.Target.Encoding != "" {
+		f.SetEncoding(propfile.Encoding(rt.Target.Encoding))
+	}
+}
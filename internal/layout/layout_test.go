@@ -0,0 +1,85 @@
+package layout
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/minios-linux/lokit/config"
+)
+
+func TestSelectTargets(t *testing.T) {
+	resolved := []config.ResolvedTarget{
+		{Target: config.Target{Name: "app"}},
+		{Target: config.Target{Name: "matrix/a"}},
+		{Target: config.Target{Name: "matrix/b"}},
+		{Target: config.Target{Name: "docs"}},
+	}
+
+	t.Run("empty selection returns all", func(t *testing.T) {
+		filtered, err := SelectTargets(resolved, nil)
+		if err != nil {
+			t.Fatalf("SelectTargets error: %v", err)
+		}
+		if len(filtered) != len(resolved) {
+			t.Fatalf("len(filtered) = %d, want %d", len(filtered), len(resolved))
+		}
+	})
+
+	t.Run("supports exact and prefix targets with dedupe", func(t *testing.T) {
+		filtered, err := SelectTargets(resolved, []string{"app", "matrix", "matrix/a"})
+		if err != nil {
+			t.Fatalf("SelectTargets error: %v", err)
+		}
+		if len(filtered) != 3 {
+			t.Fatalf("len(filtered) = %d, want 3", len(filtered))
+		}
+		if filtered[0].Target.Name != "app" || filtered[1].Target.Name != "matrix/a" || filtered[2].Target.Name != "matrix/b" {
+			t.Fatalf("unexpected target order: %q, %q, %q", filtered[0].Target.Name, filtered[1].Target.Name, filtered[2].Target.Name)
+		}
+	})
+
+	t.Run("returns error for unknown target", func(t *testing.T) {
+		if _, err := SelectTargets(resolved, []string{"missing"}); err == nil {
+			t.Fatal("expected error for unknown target")
+		}
+	})
+}
+
+func TestIntersectLanguages(t *testing.T) {
+	available := []string{"en", "fr", "de", "es"}
+	filter := []string{" fr ", "es", "it"}
+	want := []string{"fr", "es"}
+
+	if got := IntersectLanguages(available, filter); !reflect.DeepEqual(got, want) {
+		t.Fatalf("IntersectLanguages() = %#v, want %#v", got, want)
+	}
+}
+
+func TestFilterOutLang(t *testing.T) {
+	langs := []string{"en", "fr", "en", "de"}
+	want := []string{"fr", "de"}
+
+	if got := FilterOutLang(langs, "en"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("FilterOutLang() = %#v, want %#v", got, want)
+	}
+}
+
+func TestFileExists(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(filePath, []byte("ok"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	if !FileExists(filePath) {
+		t.Fatalf("FileExists(file) = false, want true")
+	}
+	if FileExists(dir) {
+		t.Fatalf("FileExists(directory) = true, want false")
+	}
+	if FileExists(filepath.Join(dir, "missing.txt")) {
+		t.Fatalf("FileExists(missing) = true, want false")
+	}
+}
@@ -0,0 +1,230 @@
+package layout
+
+import (
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minios-linux/lokit/config"
+)
+
+func findMarkdownFiles(dir, matchRoot string, excludes []string) ([]string, error) {
+	if matchRoot == "" {
+		matchRoot = dir
+	}
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if shouldExcludeMarkdownPath(matchRoot, path, excludes) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func shouldExcludeMarkdownPath(root, filePath string, excludes []string) bool {
+	if len(excludes) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range excludes {
+		pattern = strings.Trim(strings.TrimSpace(filepath.ToSlash(pattern)), "/")
+		if pattern == "" {
+			continue
+		}
+		if strings.HasSuffix(pattern, "/**") {
+			base := strings.TrimSuffix(pattern, "/**")
+			if rel == base || strings.HasPrefix(rel, base+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := pathpkg.Match(pattern, rel); ok || rel == pattern || strings.HasPrefix(rel, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func markdownSourceExcludes(rt config.ResolvedTarget) []string {
+	excludes := append([]string{}, rt.Target.Exclude...)
+	for _, lang := range rt.Languages {
+		if lang == rt.Target.SourceLang {
+			continue
+		}
+		for _, targetPath := range rt.TranslationPathCandidates(lang) {
+			rel, err := filepath.Rel(rt.AbsRoot, targetPath)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			excludes = append(excludes, rel, rel+"/**")
+		}
+	}
+	return excludes
+}
+
+// MarkdownLangDir returns the directory holding the translations of a
+// markdown target into lang.
+func MarkdownLangDir(rt config.ResolvedTarget, lang string) string {
+	if rt.Target.TargetPath != "" && strings.Contains(rt.Target.TargetPath, "{path}") {
+		prefix := strings.Split(rt.Target.TargetPath, "{path}")[0]
+		prefix = strings.ReplaceAll(prefix, "{lang}", lang)
+		prefix = strings.TrimSuffix(prefix, "/")
+		return filepath.Join(rt.AbsRoot, filepath.FromSlash(prefix))
+	}
+	if MarkdownTargetIsFile(rt) {
+		return filepath.Dir(rt.TranslationPath(lang))
+	}
+	if rt.Target.Pattern == "" {
+		return filepath.Join(rt.AbsTranslationsDir(), lang)
+	}
+	path := rt.TranslationPath(lang)
+	if path == "" {
+		return filepath.Join(rt.AbsTranslationsDir(), lang)
+	}
+	return path
+}
+
+// MarkdownTargetPath returns the translation into lang of the source
+// document at relPath, relative to the source directory.
+func MarkdownTargetPath(rt config.ResolvedTarget, lang, relPath string) string {
+	if MarkdownTargetIsFile(rt) {
+		return rt.TranslationPath(lang)
+	}
+	if rt.Target.TargetPath != "" && strings.Contains(rt.Target.TargetPath, "{path}") {
+		out := strings.ReplaceAll(rt.Target.TargetPath, "{lang}", lang)
+		out = strings.ReplaceAll(out, "{path}", filepath.ToSlash(relPath))
+		return filepath.Join(rt.AbsRoot, filepath.FromSlash(out))
+	}
+	return filepath.Join(MarkdownLangDir(rt, lang), relPath)
+}
+
+// MarkdownTargetIsFile reports whether a markdown target translates a
+// single document rather than a directory tree.
+func MarkdownTargetIsFile(rt config.ResolvedTarget) bool {
+	return rt.Target.TargetPath != "" && !strings.Contains(rt.Target.TargetPath, "{path}") && strings.EqualFold(filepath.Ext(rt.Target.TargetPath), ".md")
+}
+
+// MarkdownSourceDir returns the directory holding the source documents of a
+// markdown target.
+func MarkdownSourceDir(rt config.ResolvedTarget) string {
+	if len(rt.Target.Sources) > 0 {
+		return rt.AbsRoot
+	}
+	if p := rt.ExistingSourcePath(); p != "" {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return filepath.Dir(p)
+		}
+		return p
+	}
+	if p := rt.SourcePath(); p != "" {
+		if strings.EqualFold(filepath.Ext(p), ".md") {
+			return filepath.Dir(p)
+		}
+		return p
+	}
+	return MarkdownLangDir(rt, rt.Target.SourceLang)
+}
+
+// MarkdownSourceFiles returns the source documents of a markdown target,
+// leaving out its translations when they live in the source tree.
+func MarkdownSourceFiles(rt config.ResolvedTarget) ([]string, error) {
+	if len(rt.Target.Sources) == 0 {
+		if p := rt.ExistingSourcePath(); p != "" {
+			if info, err := os.Stat(p); err == nil && !info.IsDir() && strings.EqualFold(filepath.Ext(p), ".md") {
+				return []string{p}, nil
+			}
+		}
+		srcDir := MarkdownSourceDir(rt)
+		return findMarkdownFiles(srcDir, rt.AbsRoot, markdownSourceExcludes(rt))
+	}
+
+	all, err := findMarkdownFiles(rt.AbsRoot, rt.AbsRoot, markdownSourceExcludes(rt))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, p := range all {
+		rel, err := filepath.Rel(rt.AbsRoot, p)
+		if err != nil {
+			continue
+		}
+		if matchAnyPathPattern(filepath.ToSlash(rel), rt.Target.Sources) {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func matchAnyPathPattern(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(strings.TrimSpace(filepath.ToSlash(pattern)), "/")
+		if pattern == "" {
+			continue
+		}
+		if strings.HasSuffix(pattern, "/**") {
+			base := strings.TrimSuffix(pattern, "/**")
+			if rel == base || strings.HasPrefix(rel, base+"/") {
+				return true
+			}
+		}
+		if strings.Contains(pattern, "**/") {
+			prefix := strings.Split(pattern, "**/")[0]
+			suffix := strings.Split(pattern, "**/")[1]
+			if strings.HasPrefix(rel, prefix) {
+				if ok, _ := pathpkg.Match(suffix, strings.TrimPrefix(rel, prefix)); ok {
+					return true
+				}
+				parts := strings.Split(strings.TrimPrefix(rel, prefix), "/")
+				for i := range parts {
+					if ok, _ := pathpkg.Match(suffix, strings.Join(parts[i:], "/")); ok {
+						return true
+					}
+				}
+			}
+		}
+		if ok, _ := pathpkg.Match(pattern, rel); ok || rel == pattern || strings.HasPrefix(rel, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkdownTargetFiles returns the existing translated documents of a
+// markdown target for lang.
+func MarkdownTargetFiles(rt config.ResolvedTarget, lang string) ([]string, error) {
+	if MarkdownTargetIsFile(rt) {
+		path := rt.TranslationPath(lang)
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}, nil
+		}
+		return nil, nil
+	}
+	langDir := MarkdownLangDir(rt, lang)
+	return findMarkdownFiles(langDir, langDir, nil)
+}
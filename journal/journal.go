@@ -0,0 +1,305 @@
+// Package journal implements lokit.journal — a checkpoint log of translated
+// chunks. Every chunk returned by an AI provider is appended to the journal
+// and synced to disk before the next one is requested, so an interrupted run
+// (Ctrl-C, rate-limit exhaustion, crash) loses at most the chunks in flight.
+// Entries of a target language are dropped once its file has been saved;
+// `lokit translate --resume` applies whatever is left from an interrupted
+// run before planning new requests.
+//
+// The journal is stored alongside lokit.yaml as lokit.journal, one JSON
+// object per line. The file is removed when no entries are left.
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileName is the default journal file name.
+const FileName = "lokit.journal"
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+// Entry is one translated string recorded after its chunk completed.
+type Entry struct {
+	// Target is the lock target key ("target/language").
+	Target string `json:"target"`
+	// Scope is the lock key prefix of multi-file key-value targets.
+	Scope string `json:"scope,omitempty"`
+	// Key is the PO lock key (context and msgid) or the key-value key.
+	Key string `json:"key"`
+	// Source is the source text the translation was made from.
+	Source       string `json:"source"`
+	SourcePlural string `json:"source_plural,omitempty"`
+	Translation  string `json:"translation,omitempty"`
+	// Plural holds the plural forms of gettext plural entries.
+	Plural []string `json:"plural,omitempty"`
+	// Model is "provider/model" of the translation.
+	Model string `json:"model,omitempty"`
+}
+
+type scopeKey struct {
+	target, scope string
+}
+
+// Journal is the set of translated strings not yet saved to their files.
+// It is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	entries map[scopeKey]map[string]Entry
+}
+
+// ---------------------------------------------------------------------------
+// Loading and saving
+// ---------------------------------------------------------------------------
+
+// Open reads the journal from the given directory. Returns an empty journal
+// if the file doesn't exist. A truncated last line, left by a crash in the
+// middle of a write, is ignored.
+func Open(dir string) (*Journal, error) {
+	path := filepath.Join(dir, FileName)
+	j := &Journal{
+		path:    path,
+		entries: make(map[scopeKey]map[string]Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return j, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			if i == len(lines)-1 {
+				// Drop the partial line so new entries start on a line of
+				// their own.
+				if err := j.rewrite(); err != nil {
+					return nil, err
+				}
+				break
+			}
+			return nil, fmt.Errorf("parsing %s: line %d: %w", path, i+1, err)
+		}
+		j.add(e)
+	}
+
+	return j, nil
+}
+
+// Record appends entries to the journal and syncs the file.
+func (j *Journal) Record(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshaling journal entry: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	if j.file == nil {
+		f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", j.path, err)
+		}
+		j.file = f
+	}
+	if _, err := j.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing %s: %w", j.path, err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("syncing %s: %w", j.path, err)
+	}
+
+	for _, e := range entries {
+		j.add(e)
+	}
+	return nil
+}
+
+// Forget drops the entries of a target language and scope, typically after
+// its file was saved. The journal file is rewritten, or removed when no
+// entries are left.
+func (j *Journal) Forget(target, scope string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	k := scopeKey{target, scope}
+	if _, ok := j.entries[k]; !ok {
+		return nil
+	}
+	delete(j.entries, k)
+	return j.rewrite()
+}
+
+// Close closes the journal file. It removes the file if no entries are
+// left.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var err error
+	if j.file != nil {
+		err = j.file.Close()
+		j.file = nil
+	}
+	if len(j.entries) == 0 {
+		if rmErr := os.Remove(j.path); rmErr != nil && !os.IsNotExist(rmErr) {
+			err = errors.Join(err, rmErr)
+		}
+	}
+	return err
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// rewrite replaces the journal file with the current entries. Caller must
+// hold j.mu.
+func (j *Journal) rewrite() error {
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+	if len(j.entries) == 0 {
+		if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", j.path, err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, k := range j.sortedScopes() {
+		for _, e := range sortedEntries(j.entries[k]) {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("marshaling journal entry: %w", err)
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("writing %s: %w", j.path, err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Queries
+// ---------------------------------------------------------------------------
+
+// Entries returns the recorded entries of a target language and scope,
+// keyed by Entry.Key.
+func (j *Journal) Entries(target, scope string) map[string]Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	src := j.entries[scopeKey{target, scope}]
+	if len(src) == 0 {
+		return nil
+	}
+	out := make(map[string]Entry, len(src))
+	for k, e := range src {
+		out[k] = e
+	}
+	return out
+}
+
+// Len returns the total number of entries.
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	n := 0
+	for _, m := range j.entries {
+		n += len(m)
+	}
+	return n
+}
+
+// Targets returns the sorted target keys that have entries.
+func (j *Journal) Targets() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var out []string
+	for k := range j.entries {
+		if !seen[k.target] {
+			seen[k.target] = true
+			out = append(out, k.target)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+// add stores e, replacing an earlier entry for the same key. Caller must
+// hold j.mu (or own j exclusively).
+func (j *Journal) add(e Entry) {
+	k := scopeKey{e.Target, e.Scope}
+	if j.entries[k] == nil {
+		j.entries[k] = make(map[string]Entry)
+	}
+	j.entries[k][e.Key] = e
+}
+
+func (j *Journal) sortedScopes() []scopeKey {
+	keys := make([]scopeKey, 0, len(j.entries))
+	for k := range j.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].target != keys[b].target {
+			return keys[a].target < keys[b].target
+		}
+		return keys[a].scope < keys[b].scope
+	})
+	return keys
+}
+
+func sortedEntries(m map[string]Entry) []Entry {
+	out := make([]Entry, 0, len(m))
+	for _, e := range m {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Key < out[b].Key })
+	return out
+}
@@ -0,0 +1,114 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := j.Record(
+		Entry{Target: "app/de", Key: "a", Source: "Hello", Translation: "Hallo"},
+		Entry{Target: "app/de", Scope: "docs/intro", Key: "p1", Source: "Intro", Translation: "Einleitung"},
+	); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := j.Record(Entry{Target: "app/de", Key: "a", Source: "Hello", Translation: "Servus"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", reopened.Len())
+	}
+	if got := reopened.Entries("app/de", "")["a"].Translation; got != "Servus" {
+		t.Errorf("translation = %q, want the latest entry", got)
+	}
+	if got := reopened.Entries("app/de", "docs/intro")["p1"].Translation; got != "Einleitung" {
+		t.Errorf("scoped translation = %q", got)
+	}
+	if targets := reopened.Targets(); len(targets) != 1 || targets[0] != "app/de" {
+		t.Errorf("Targets = %v", targets)
+	}
+}
+
+func TestOpenDropsTruncatedLastLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	data := `{"target":"app/de","key":"a","source":"Hello","translation":"Hallo"}` + "\n" + `{"target":"app/de","key":"b","sou`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if j.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", j.Len())
+	}
+	if err := j.Record(Entry{Target: "app/de", Key: "c", Source: "Bye", Translation: "Tschüss"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open after append: %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", reopened.Len())
+	}
+}
+
+func TestOpenRejectsCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	data := "not json\n" + `{"target":"app/de","key":"a","source":"Hello","translation":"Hallo"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Open(dir); err == nil {
+		t.Fatal("expected error for a corrupt line before the end")
+	}
+}
+
+func TestForgetAndClose(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := j.Record(
+		Entry{Target: "app/de", Key: "a", Source: "Hello", Translation: "Hallo"},
+		Entry{Target: "app/fr", Key: "a", Source: "Hello", Translation: "Bonjour"},
+	); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if err := j.Forget("app/de", ""); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if reopened.Len() != 1 || reopened.Entries("app/fr", "") == nil {
+		t.Fatalf("entries after Forget = %d", reopened.Len())
+	}
+
+	if err := j.Forget("app/fr", ""); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(j.Path()); !os.IsNotExist(err) {
+		t.Fatalf("journal file still exists: %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package langmeta
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestCanonicalize(t *testing.T) {
 	cases := []struct {
@@ -50,3 +53,24 @@ func TestResolve(t *testing.T) {
 		}
 	})
 }
+
+func TestPluralCategories(t *testing.T) {
+	cases := []struct {
+		lang string
+		want string
+	}{
+		{lang: "en", want: "one,other"},
+		{lang: "ru", want: "one,few,many,other"},
+		{lang: "pt_BR", want: "one,many,other"},
+		{lang: "ar", want: "zero,one,two,few,many,other"},
+		{lang: "ja", want: "other"},
+		{lang: "xx", want: "one,other"},
+	}
+
+	for _, tc := range cases {
+		got := strings.Join(PluralCategories(tc.lang), ",")
+		if got != tc.want {
+			t.Fatalf("PluralCategories(%q) = %q, want %q", tc.lang, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package langmeta
+
+import "strings"
+
+// CLDR plural categories in canonical order.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// PluralCategoryOrder lists all CLDR plural categories in canonical order.
+var PluralCategoryOrder = []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}
+
+// pluralRules maps base language codes to their CLDR cardinal plural
+// categories (as reported by Intl.PluralRules). Languages not listed use
+// one/other.
+var pluralRules = map[string][]string{
+	// other only
+	"ja": {PluralOther}, "zh": {PluralOther}, "ko": {PluralOther},
+	"vi": {PluralOther}, "th": {PluralOther}, "id": {PluralOther},
+	"ms": {PluralOther}, "lo": {PluralOther}, "my": {PluralOther},
+	"km": {PluralOther}, "yo": {PluralOther},
+
+	// one/many/other
+	"fr": {PluralOne, PluralMany, PluralOther}, "es": {PluralOne, PluralMany, PluralOther},
+	"it": {PluralOne, PluralMany, PluralOther}, "pt": {PluralOne, PluralMany, PluralOther},
+	"ca": {PluralOne, PluralMany, PluralOther},
+
+	// one/few/many/other
+	"ru": {PluralOne, PluralFew, PluralMany, PluralOther}, "uk": {PluralOne, PluralFew, PluralMany, PluralOther},
+	"be": {PluralOne, PluralFew, PluralMany, PluralOther}, "pl": {PluralOne, PluralFew, PluralMany, PluralOther},
+	"lt": {PluralOne, PluralFew, PluralMany, PluralOther}, "cs": {PluralOne, PluralFew, PluralMany, PluralOther},
+	"sk": {PluralOne, PluralFew, PluralMany, PluralOther},
+
+	// one/few/other
+	"hr": {PluralOne, PluralFew, PluralOther}, "sr": {PluralOne, PluralFew, PluralOther},
+	"bs": {PluralOne, PluralFew, PluralOther}, "ro": {PluralOne, PluralFew, PluralOther},
+
+	"sl": {PluralOne, PluralTwo, PluralFew, PluralOther},
+	"he": {PluralOne, PluralTwo, PluralOther},
+	"lv": {PluralZero, PluralOne, PluralOther},
+	"ga": {PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther},
+	"mt": {PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther},
+	"ar": PluralCategoryOrder,
+	"cy": PluralCategoryOrder,
+}
+
+// PluralCategories returns the CLDR cardinal plural categories used by
+// lang, in canonical order. Locale variants resolve to their base language.
+func PluralCategories(lang string) []string {
+	base := strings.SplitN(canonicalize(lang), "-", 2)[0]
+	cats, ok := pluralRules[base]
+	if !ok {
+		cats = []string{PluralOne, PluralOther}
+	}
+	out := make([]string, len(cats))
+	copy(out, cats)
+	return out
+}
+
+// IsPluralCategory reports whether s is a CLDR plural category name.
+func IsPluralCategory(s string) bool {
+	for _, c := range PluralCategoryOrder {
+		if s == c {
+			return true
+		}
+	}
+	return false
+}
@@ -33,8 +33,9 @@ type LockFile struct {
 	Version   int                          `yaml:"version"`
 	Checksums map[string]map[string]string `yaml:"checksums"` // target -> key -> md5
 
-	mu   sync.Mutex `yaml:"-"`
-	path string     `yaml:"-"`
+	mu      sync.Mutex `yaml:"-"`
+	path    string     `yaml:"-"`
+	updates int        `yaml:"-"`
 }
 
 // ---------------------------------------------------------------------------
@@ -138,6 +139,15 @@ func (lf *LockFile) Update(target, key, sourceContent string) {
 		lf.Checksums[target] = make(map[string]string)
 	}
 	lf.Checksums[target][key] = Hash(sourceContent)
+	lf.updates++
+}
+
+// Updates returns the number of calls to Update since the lock file was
+// loaded, i.e. the number of strings translated in this run.
+func (lf *LockFile) Updates() int {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.updates
 }
 
 // Has reports whether an exact target/key entry exists in the lock file.
@@ -0,0 +1,146 @@
+package merge
+
+import (
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// fuzzyThreshold is the minimum similarity (0..1) between a new msgid and an
+// existing one for the existing translation to be offered as a fuzzy
+// suggestion. The value matches the spirit of msgmerge: a changed character,
+// word or punctuation mark matches, while unrelated short strings do not.
+const fuzzyThreshold = 0.7
+
+// fuzzyCandidate is a translated entry that may serve as a fuzzy suggestion
+// for a new template entry.
+type fuzzyCandidate struct {
+	entry   *po.Entry
+	runes   []rune
+	bigrams map[[2]rune]int
+}
+
+// fuzzyIndex holds translated entries from the PO file (both active and
+// obsolete) that can be used to pre-fill new template entries.
+type fuzzyIndex struct {
+	candidates []fuzzyCandidate
+}
+
+// newFuzzyIndex collects translated, non-fuzzy entries from poFile.
+func newFuzzyIndex(poFile *po.File) *fuzzyIndex {
+	idx := &fuzzyIndex{}
+	for _, e := range poFile.Entries {
+		if e.MsgID == "" || !e.IsTranslated() {
+			continue
+		}
+		runes := []rune(e.MsgID)
+		idx.candidates = append(idx.candidates, fuzzyCandidate{
+			entry:   e,
+			runes:   runes,
+			bigrams: bigramCounts(runes),
+		})
+	}
+	return idx
+}
+
+// bestMatch returns the most similar candidate for potEntry, or nil if no
+// candidate reaches fuzzyThreshold. Candidates must have the same msgctxt
+// and the same plurality as potEntry. Ties are resolved in file order.
+func (idx *fuzzyIndex) bestMatch(potEntry *po.Entry) *po.Entry {
+	if len(idx.candidates) == 0 {
+		return nil
+	}
+	runes := []rune(potEntry.MsgID)
+	bigrams := bigramCounts(runes)
+
+	var best *po.Entry
+	bestScore := fuzzyThreshold
+	for _, c := range idx.candidates {
+		if c.entry.MsgCtxt != potEntry.MsgCtxt {
+			continue
+		}
+		if (c.entry.MsgIDPlural == "") != (potEntry.MsgIDPlural == "") {
+			continue
+		}
+		score := similarity(runes, bigrams, c.runes, c.bigrams, bestScore)
+		if score > bestScore || (best == nil && score >= bestScore) {
+			best = c.entry
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// similarity returns 1 - levenshtein(a, b) / max(len(a), len(b)), or 0 if the
+// result is certainly below minScore. Two cheap filters run before the edit
+// distance: the length difference and the q-gram lemma (strings within edit
+// distance k share at least max(len) - 1 - 2k bigrams).
+func similarity(a []rune, aBigrams map[[2]rune]int, b []rune, bBigrams map[[2]rune]int, minScore float64) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 0
+	}
+	maxDist := int(float64(longest) * (1 - minScore))
+	if abs(len(a)-len(b)) > maxDist {
+		return 0
+	}
+	if sharedBigrams(aBigrams, bBigrams) < longest-1-2*maxDist {
+		return 0
+	}
+	dist := levenshtein(a, b, maxDist)
+	if dist > maxDist {
+		return 0
+	}
+	return 1 - float64(dist)/float64(longest)
+}
+
+func bigramCounts(r []rune) map[[2]rune]int {
+	counts := make(map[[2]rune]int, len(r))
+	for i := 0; i+1 < len(r); i++ {
+		counts[[2]rune{r[i], r[i+1]}]++
+	}
+	return counts
+}
+
+func sharedBigrams(a, b map[[2]rune]int) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for g, n := range a {
+		shared += min(n, b[g])
+	}
+	return shared
+}
+
+// levenshtein computes the edit distance between a and b. Once every value
+// in a row exceeds limit, it stops early and returns limit+1.
+func levenshtein(a, b []rune, limit int) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
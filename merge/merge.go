@@ -3,7 +3,7 @@
 package merge
 
 import (
-	po "github.com/minios-linux/lokit/internal/format/po"
+	po "github.com/minios-linux/lokit/format/po"
 )
 
 // poKey builds a compound map key from msgid and msgctxt so that entries
@@ -18,6 +18,7 @@ func poKey(msgID, msgCtxt string) string {
 
 // Merge updates a PO file with entries from a POT template.
 // - New entries from the template are added with empty translations.
+// - New entries similar to a translated msgid get a fuzzy suggestion (msgmerge-style).
 // - Existing entries that are still in the template are kept.
 // - Entries that are no longer in the template are marked obsolete.
 // - References and flags are updated from the template.
@@ -50,6 +51,8 @@ func Merge(poFile, potFile *po.File) *po.File {
 		existingByKey[k] = e
 	}
 
+	fuzzy := newFuzzyIndex(poFile)
+
 	// Track which existing entries were matched
 	matched := make(map[string]bool)
 
@@ -93,6 +96,22 @@ func Merge(poFile, potFile *po.File) *po.File {
 			}
 			result.Entries = append(result.Entries, restored)
 			matched[k] = true
+		} else if similar := fuzzy.bestMatch(potEntry); similar != nil {
+			// New entry close to an existing translation — pre-fill it as a
+			// fuzzy suggestion so reviewed wording is not lost.
+			suggested := &po.Entry{
+				TranslatorComments: similar.TranslatorComments,
+				ExtractedComments:  potEntry.ExtractedComments,
+				References:         potEntry.References,
+				Flags:              mergeFlags([]string{"fuzzy"}, potEntry.Flags),
+				PreviousMsgID:      similar.MsgID,
+				MsgCtxt:            potEntry.MsgCtxt,
+				MsgID:              potEntry.MsgID,
+				MsgIDPlural:        potEntry.MsgIDPlural,
+				MsgStr:             similar.MsgStr,
+				MsgStrPlural:       copyPlural(similar.MsgStrPlural),
+			}
+			result.Entries = append(result.Entries, suggested)
 		} else {
 			// New entry from template
 			newEntry := &po.Entry{
@@ -154,3 +173,11 @@ func mergeFlags(poFlags, potFlags []string) []string {
 
 	return result
 }
+
+func copyPlural(m map[int]string) map[int]string {
+	out := make(map[int]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
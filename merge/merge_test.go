@@ -3,7 +3,7 @@ package merge
 import (
 	"testing"
 
-	po "github.com/minios-linux/lokit/internal/format/po"
+	po "github.com/minios-linux/lokit/format/po"
 )
 
 func TestMergeKeepNewObsoleteAndHeaderUpdate(t *testing.T) {
@@ -205,3 +205,96 @@ func TestMergeMsgCtxtObsoleteRestore(t *testing.T) {
 		t.Error("neither entry should be obsolete after merge")
 	}
 }
+
+func TestMergeSuggestsFuzzyForSimilarMsgID(t *testing.T) {
+	poFile := po.NewFile()
+	poFile.Entries = []*po.Entry{
+		{MsgID: "Save the file", MsgStr: "Сохранить файл", TranslatorComments: []string{"reviewed"}},
+	}
+
+	potFile := po.NewFile()
+	potFile.Entries = []*po.Entry{
+		{MsgID: "Save the file.", Flags: []string{"c-format"}},
+	}
+
+	merged := Merge(poFile, potFile)
+	if len(merged.Entries) != 2 {
+		t.Fatalf("entries len = %d, want 2", len(merged.Entries))
+	}
+	entry := merged.Entries[0]
+	if entry.MsgID != "Save the file." {
+		t.Fatalf("first entry msgid = %q", entry.MsgID)
+	}
+	if entry.MsgStr != "Сохранить файл" {
+		t.Fatalf("msgstr = %q, want suggestion from similar entry", entry.MsgStr)
+	}
+	if !entry.IsFuzzy() || !entry.HasFlag("c-format") {
+		t.Fatalf("flags = %v, want fuzzy and c-format", entry.Flags)
+	}
+	if entry.PreviousMsgID != "Save the file" {
+		t.Fatalf("previous msgid = %q", entry.PreviousMsgID)
+	}
+	if !merged.Entries[1].Obsolete {
+		t.Fatal("old entry should become obsolete")
+	}
+}
+
+func TestMergeSuggestsFuzzyFromObsoleteEntry(t *testing.T) {
+	poFile := po.NewFile()
+	poFile.Entries = []*po.Entry{
+		{MsgID: "Connecting to server", MsgStr: "Подключение к серверу", Obsolete: true},
+	}
+
+	potFile := po.NewFile()
+	potFile.Entries = []*po.Entry{
+		{MsgID: "Connecting to server..."},
+	}
+
+	merged := Merge(poFile, potFile)
+	entry := merged.Entries[0]
+	if entry.MsgStr != "Подключение к серверу" || !entry.IsFuzzy() {
+		t.Fatalf("entry = %+v, want fuzzy suggestion from obsolete entry", entry)
+	}
+}
+
+func TestMergeNoFuzzyForUnrelatedOrDifferentContext(t *testing.T) {
+	poFile := po.NewFile()
+	poFile.Entries = []*po.Entry{
+		{MsgID: "Open", MsgStr: "Открыть"},
+		{MsgID: "Delete file", MsgCtxt: "menu", MsgStr: "Удалить файл"},
+		{MsgID: "%d item", MsgIDPlural: "%d items", MsgStrPlural: map[int]string{0: "%d элемент", 1: "%d элемента", 2: "%d элементов"}},
+	}
+
+	potFile := po.NewFile()
+	potFile.Entries = []*po.Entry{
+		{MsgID: "Close"},
+		{MsgID: "Delete files", MsgCtxt: "toolbar"},
+		{MsgID: "%d items"},
+	}
+
+	merged := Merge(poFile, potFile)
+	for _, e := range merged.Entries[:3] {
+		if e.MsgStr != "" || e.IsFuzzy() || e.PreviousMsgID != "" {
+			t.Errorf("entry %q got unexpected suggestion: msgstr=%q flags=%v", e.MsgID, e.MsgStr, e.Flags)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Save the file", "Save the file.", true},
+		{"Open", "Close", false},
+		{"Remove selected items", "Remove selected item", true},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		a, b := []rune(tt.a), []rune(tt.b)
+		got := similarity(a, bigramCounts(a), b, bigramCounts(b), fuzzyThreshold) >= fuzzyThreshold
+		if got != tt.want {
+			t.Errorf("similarity(%q, %q) matched = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
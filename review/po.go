@@ -0,0 +1,84 @@
+package review
+
+import (
+	"strings"
+
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// ---------------------------------------------------------------------------
+// Gettext provenance comments
+// ---------------------------------------------------------------------------
+
+// poCommentPrefix starts the translator comment of machine-translated
+// entries: "# lokit: machine, model=copilot/gpt-4.1".
+const poCommentPrefix = "lokit: machine"
+
+// MarkPO adds the machine translation comment to e, replacing an earlier
+// one. With fuzzy set the entry is also flagged "#, fuzzy", which keeps it
+// out of compiled catalogs until a translator accepts it.
+func MarkPO(e *po.Entry, model string, fuzzy bool) {
+	removePOComment(e)
+	e.TranslatorComments = append(e.TranslatorComments, poCommentPrefix+", model="+model)
+	if fuzzy {
+		e.SetFuzzy(true)
+	}
+}
+
+// POModel returns the model recorded in the machine translation comment of
+// e. ok is false for entries without the comment.
+func POModel(e *po.Entry) (model string, ok bool) {
+	for _, c := range e.TranslatorComments {
+		if rest, found := strings.CutPrefix(c, poCommentPrefix); found {
+			return strings.TrimPrefix(rest, ", model="), true
+		}
+	}
+	return "", false
+}
+
+// IsPOComment reports whether the translator comment c is the machine
+// translation comment.
+func IsPOComment(c string) bool {
+	return strings.HasPrefix(c, poCommentPrefix)
+}
+
+// AcceptPO removes the machine translation comment and the fuzzy flag of e.
+// It returns false if e was not machine-translated.
+func AcceptPO(e *po.Entry) bool {
+	if !removePOComment(e) {
+		return false
+	}
+	e.SetFuzzy(false)
+	return true
+}
+
+// RejectPO clears the translation of a machine-translated entry so the
+// next run translates it again. It returns false if e was not
+// machine-translated.
+func RejectPO(e *po.Entry) bool {
+	if !AcceptPO(e) {
+		return false
+	}
+	e.MsgStr = ""
+	for form := range e.MsgStrPlural {
+		e.MsgStrPlural[form] = ""
+	}
+	return true
+}
+
+func removePOComment(e *po.Entry) bool {
+	kept := e.TranslatorComments[:0]
+	removed := false
+	for _, c := range e.TranslatorComments {
+		if IsPOComment(c) {
+			removed = true
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	e.TranslatorComments = kept
+	return removed
+}
@@ -0,0 +1,231 @@
+// Package review implements lokit.review — the review state of machine
+// translations in key-value targets. Every translation written by an AI
+// provider (or reused from the translation memory) is recorded as pending
+// until it is accepted or rejected with `lokit review`. Rejected keys are
+// translated again on the next run.
+//
+// Gettext catalogs do not use this file: machine-translated PO entries carry
+// a "# lokit: machine" translator comment instead (see MarkPO).
+//
+// The review file is stored alongside lokit.yaml as lokit.review.
+package review
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the default review state file name.
+const FileName = "lokit.review"
+
+// Version is the review state file format version.
+const Version = 1
+
+// MemoryModel is recorded as the model of translations reused from the
+// translation memory when the model that produced them is not known.
+const MemoryModel = "lokit.tm"
+
+// MemoryModelOf returns the model recorded for a translation reused from
+// the translation memory: the model that produced it, noted as reused, or
+// MemoryModel if model is empty.
+func MemoryModelOf(model string) string {
+	if model == "" {
+		return MemoryModel
+	}
+	return model + " (" + MemoryModel + ")"
+}
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+// Entry is one machine translation awaiting review.
+type Entry struct {
+	Source      string `yaml:"source"`
+	Translation string `yaml:"translation"`
+	// Model is "provider/model" of the translation; see MemoryModelOf for
+	// translations reused from the translation memory.
+	Model string `yaml:"model"`
+	// Rejected marks translations that must be redone on the next run.
+	Rejected bool `yaml:"rejected,omitempty"`
+}
+
+// State represents the lokit.review file structure.
+type State struct {
+	Version int                         `yaml:"version"`
+	Entries map[string]map[string]Entry `yaml:"entries"` // target/lang -> key -> entry
+
+	mu   sync.Mutex `yaml:"-"`
+	path string     `yaml:"-"`
+}
+
+// New returns an empty review state that is not backed by a file. Save on
+// such a state is a no-op.
+func New() *State {
+	return &State{
+		Version: Version,
+		Entries: make(map[string]map[string]Entry),
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Loading and saving
+// ---------------------------------------------------------------------------
+
+// Load reads the review state from the given directory.
+// Returns an empty state if the file doesn't exist.
+func Load(dir string) (*State, error) {
+	path := filepath.Join(dir, FileName)
+	s := New()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	s.path = path
+
+	if s.Entries == nil {
+		s.Entries = make(map[string]map[string]Entry)
+	}
+
+	return s, nil
+}
+
+// Save writes the review state to disk. An empty state is not written
+// unless the file already exists.
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return nil
+	}
+
+	if len(s.Entries) == 0 {
+		if _, err := os.Stat(s.path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshaling review state: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", s.path, err)
+	}
+
+	return nil
+}
+
+// Path returns the review state file path.
+func (s *State) Path() string {
+	return s.path
+}
+
+// ---------------------------------------------------------------------------
+// Review operations
+// ---------------------------------------------------------------------------
+
+// Mark records a machine translation of key as pending review, replacing
+// any previous entry (including a rejected one). target is the lock target
+// key ("target/language").
+func (s *State) Mark(target, key, source, translation, model string) {
+	if translation == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Entries[target] == nil {
+		s.Entries[target] = make(map[string]Entry)
+	}
+	s.Entries[target][key] = Entry{Source: source, Translation: translation, Model: model}
+}
+
+// Get returns the review entry of key.
+func (s *State) Get(target, key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.Entries[target][key]
+	return e, ok
+}
+
+// IsRejected reports whether the translation of key was rejected.
+func (s *State) IsRejected(target, key string) bool {
+	e, ok := s.Get(target, key)
+	return ok && e.Rejected
+}
+
+// Accept removes key from the review state. It returns false if key was
+// not pending review.
+func (s *State) Accept(target, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.Entries[target][key]; !ok {
+		return false
+	}
+	delete(s.Entries[target], key)
+	if len(s.Entries[target]) == 0 {
+		delete(s.Entries, target)
+	}
+	return true
+}
+
+// Reject marks the translation of key as rejected. It returns false if key
+// was not pending review.
+func (s *State) Reject(target, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.Entries[target][key]
+	if !ok {
+		return false
+	}
+	e.Rejected = true
+	s.Entries[target][key] = e
+	return true
+}
+
+// Keys returns the keys of target with a review entry, sorted.
+func (s *State) Keys(target string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.Entries[target]))
+	for key := range s.Entries[target] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HasRejected reports whether target has a rejected translation.
+func (s *State) HasRejected(target string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.Entries[target] {
+		if e.Rejected {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	s.Mark("web/de", "greeting", "Hello", "Hallo", "openai/gpt-4o")
+	s.Mark("web/de", "farewell", "Bye", "Tschüss", MemoryModel)
+	s.Reject("web/de", "farewell")
+
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	s2, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load after save: %v", err)
+	}
+	e, ok := s2.Get("web/de", "greeting")
+	if !ok || e.Translation != "Hallo" || e.Model != "openai/gpt-4o" || e.Rejected {
+		t.Errorf("greeting = %+v, %v", e, ok)
+	}
+	if !s2.IsRejected("web/de", "farewell") {
+		t.Error("farewell should be rejected after reload")
+	}
+	if got := s2.Keys("web/de"); len(got) != 2 || got[0] != "farewell" || got[1] != "greeting" {
+		t.Errorf("Keys = %v, want [farewell greeting]", got)
+	}
+}
+
+func TestSaveEmptyDoesNotCreateFile(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Errorf("empty review state should not create %s", FileName)
+	}
+}
+
+func TestAcceptRemovesEntry(t *testing.T) {
+	s := New()
+	s.Mark("web/de", "greeting", "Hello", "Hallo", "openai/gpt-4o")
+
+	if !s.Accept("web/de", "greeting") {
+		t.Fatal("Accept returned false for a pending key")
+	}
+	if _, ok := s.Get("web/de", "greeting"); ok {
+		t.Error("accepted key is still pending")
+	}
+	if len(s.Entries) != 0 {
+		t.Errorf("empty target not removed: %v", s.Entries)
+	}
+	if s.Accept("web/de", "greeting") || s.Reject("web/de", "greeting") {
+		t.Error("Accept/Reject should return false for unknown keys")
+	}
+}
+
+func TestMarkClearsRejection(t *testing.T) {
+	s := New()
+	s.Mark("web/de", "greeting", "Hello", "Hallo", "openai/gpt-4o")
+	s.Reject("web/de", "greeting")
+	if !s.HasRejected("web/de") {
+		t.Fatal("HasRejected = false after Reject")
+	}
+	s.Mark("web/de", "greeting", "Hello", "Guten Tag", "openai/gpt-4o")
+
+	if s.IsRejected("web/de", "greeting") || s.HasRejected("web/de") {
+		t.Error("retranslated key should no longer be rejected")
+	}
+}
+
+func TestMarkPO(t *testing.T) {
+	e := &po.Entry{MsgID: "Open", MsgStr: "Öffnen", TranslatorComments: []string{"keep me"}}
+
+	MarkPO(e, "openai/gpt-4o", false)
+	MarkPO(e, "openai/gpt-4.1", true)
+
+	if len(e.TranslatorComments) != 2 {
+		t.Fatalf("TranslatorComments = %q, want one user comment and one marker", e.TranslatorComments)
+	}
+	if model, ok := POModel(e); !ok || model != "openai/gpt-4.1" {
+		t.Errorf("POModel = %q, %v", model, ok)
+	}
+	if !e.IsFuzzy() {
+		t.Error("entry should be fuzzy")
+	}
+
+	if !AcceptPO(e) {
+		t.Fatal("AcceptPO returned false for a marked entry")
+	}
+	if _, ok := POModel(e); ok || e.IsFuzzy() || e.MsgStr != "Öffnen" {
+		t.Errorf("after accept: comments=%q fuzzy=%v msgstr=%q", e.TranslatorComments, e.IsFuzzy(), e.MsgStr)
+	}
+	if AcceptPO(e) {
+		t.Error("AcceptPO should return false for unmarked entries")
+	}
+}
+
+func TestRejectPOClearsTranslation(t *testing.T) {
+	e := &po.Entry{
+		MsgID:        "%d file",
+		MsgIDPlural:  "%d files",
+		MsgStrPlural: map[int]string{0: "%d Datei", 1: "%d Dateien"},
+	}
+	MarkPO(e, "openai/gpt-4o", false)
+
+	if !RejectPO(e) {
+		t.Fatal("RejectPO returned false for a marked entry")
+	}
+	for form, v := range e.MsgStrPlural {
+		if v != "" {
+			t.Errorf("msgstr[%d] = %q, want empty", form, v)
+		}
+	}
+	if e.TranslatorComments != nil {
+		t.Errorf("TranslatorComments = %q, want nil", e.TranslatorComments)
+	}
+}
@@ -13,7 +13,7 @@
 // discriminated union on the "type" field:
 //
 //   - "oauth"  — OAuth tokens (copilot, gemini, openai)
-//   - "api"    — API keys (google, groq, opencode, openai, custom-openai)
+//   - "api"    — API keys (google, anthropic, groq, opencode, openai, custom-openai)
 //
 // File permissions are 0600 (owner read/write only).
 //
@@ -299,6 +299,8 @@ func EnvVarForProvider(providerID string) string {
 	switch providerID {
 	case "google":
 		return "GOOGLE_API_KEY"
+	case "anthropic":
+		return "ANTHROPIC_API_KEY"
 	case "groq":
 		return "GROQ_API_KEY"
 	case "opencode":
@@ -307,7 +309,7 @@ func EnvVarForProvider(providerID string) string {
 		return "OPENAI_API_KEY"
 	case "custom-openai":
 		return "CUSTOM_OPENAI_API_KEY"
-	case "ollama", "copilot", "gemini":
+	case "ollama", "copilot", "gemini", "mock":
 		return "" // no API key needed
 	default:
 		return ""
@@ -106,6 +106,7 @@ func TestResolveAPIKeyPriority(t *testing.T) {
 func TestEnvVarForProviderAndMaskKey(t *testing.T) {
 	cases := map[string]string{
 		"google":        "GOOGLE_API_KEY",
+		"anthropic":     "ANTHROPIC_API_KEY",
 		"groq":          "GROQ_API_KEY",
 		"opencode":      "OPENCODE_API_KEY",
 		"openai":        "OPENAI_API_KEY",
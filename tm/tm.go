@@ -0,0 +1,256 @@
+// Package tm implements lokit.tm — a translation memory that maps a source
+// string and target language to an accepted translation. Translations are
+// shared across targets and runs: a string already translated for one target
+// (for example a gettext catalog) is reused verbatim by every other target
+// instead of being sent to the AI provider again.
+//
+// The memory file is stored alongside lokit.yaml as lokit.tm.
+package tm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the default translation memory file name.
+const FileName = "lokit.tm"
+
+// Version is the translation memory file format version.
+const Version = 1
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+// Memory represents the lokit.tm file structure.
+type Memory struct {
+	Version int                          `yaml:"version"`
+	Entries map[string]map[string]string `yaml:"entries"` // language -> source -> translation
+	// Models records the "provider/model" that produced a translation, when
+	// known (language -> source -> model). Entries seeded from existing
+	// files have none.
+	Models map[string]map[string]string `yaml:"models,omitempty"`
+
+	mu   sync.Mutex `yaml:"-"`
+	path string     `yaml:"-"`
+}
+
+// New returns an empty in-memory translation memory that is not backed by a
+// file. Save on such a memory is a no-op.
+func New() *Memory {
+	return &Memory{
+		Version: Version,
+		Entries: make(map[string]map[string]string),
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Loading and saving
+// ---------------------------------------------------------------------------
+
+// Load reads a translation memory from the given directory.
+// Returns an empty memory if the file doesn't exist.
+func Load(dir string) (*Memory, error) {
+	path := filepath.Join(dir, FileName)
+	m := New()
+	m.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	m.path = path
+
+	if m.Entries == nil {
+		m.Entries = make(map[string]map[string]string)
+	}
+
+	return m, nil
+}
+
+// Save writes the translation memory to disk. An empty memory is not written
+// unless the file already exists, so projects that never translate anything
+// do not get an empty lokit.tm.
+func (m *Memory) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.path == "" {
+		return nil
+	}
+
+	if len(m.Entries) == 0 {
+		if _, err := os.Stat(m.path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshaling translation memory: %w", err)
+	}
+
+	if err := os.WriteFile(m.path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", m.path, err)
+	}
+
+	return nil
+}
+
+// Path returns the translation memory file path.
+func (m *Memory) Path() string {
+	return m.path
+}
+
+// ---------------------------------------------------------------------------
+// Lookup and update
+// ---------------------------------------------------------------------------
+
+// SourceKey builds the memory key for a source string. Strings with a
+// gettext context are stored as "msgctxt\x04msgid" (the same separator
+// gettext uses in MO files), so they only match entries with the same context.
+func SourceKey(source, context string) string {
+	if context != "" {
+		return context + "\x04" + source
+	}
+	return source
+}
+
+// normalizeLang maps equivalent language spellings to one key so that
+// gettext ("pt_BR") and web/mobile ("pt-BR") targets share entries.
+func normalizeLang(lang string) string {
+	return strings.ReplaceAll(lang, "_", "-")
+}
+
+// Lookup returns the stored translation of source for lang.
+func (m *Memory) Lookup(lang, source string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries, ok := m.Entries[normalizeLang(lang)]
+	if !ok {
+		return "", false
+	}
+	translation, ok := entries[source]
+	if !ok || translation == "" {
+		return "", false
+	}
+	return translation, true
+}
+
+// Add records an accepted translation, replacing any previous one.
+// Empty source or translation strings are ignored.
+func (m *Memory) Add(lang, source, translation string) {
+	m.put(lang, source, translation, "", true)
+}
+
+// AddFrom is like Add and also records the "provider/model" that produced
+// the translation.
+func (m *Memory) AddFrom(lang, source, translation, model string) {
+	m.put(lang, source, translation, model, true)
+}
+
+// Seed records a translation only if the memory has no entry for source yet.
+// It is used to populate the memory from existing translated files without
+// overriding translations accepted during earlier runs.
+func (m *Memory) Seed(lang, source, translation string) bool {
+	return m.put(lang, source, translation, "", false)
+}
+
+// Model returns the "provider/model" that produced the stored translation
+// of source for lang, or "" if it is not known.
+func (m *Memory) Model(lang, source string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.Models[normalizeLang(lang)][source]
+}
+
+// Forget removes the stored translation of source for lang if it equals
+// translation. It is used when a machine translation is rejected in review,
+// so the rejected text is not reused.
+func (m *Memory) Forget(lang, source, translation string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lang = normalizeLang(lang)
+	if old, ok := m.Entries[lang][source]; !ok || old != translation {
+		return false
+	}
+	delete(m.Entries[lang], source)
+	if len(m.Entries[lang]) == 0 {
+		delete(m.Entries, lang)
+	}
+	m.setModel(lang, source, "")
+	return true
+}
+
+func (m *Memory) put(lang, source, translation, model string, replace bool) bool {
+	if strings.TrimSpace(source) == "" || strings.TrimSpace(translation) == "" {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lang = normalizeLang(lang)
+	if m.Entries[lang] == nil {
+		m.Entries[lang] = make(map[string]string)
+	}
+	if old, ok := m.Entries[lang][source]; ok && (!replace || old == translation) {
+		if replace && model != "" {
+			m.setModel(lang, source, model)
+		}
+		return false
+	}
+	m.Entries[lang][source] = translation
+	m.setModel(lang, source, model)
+	return true
+}
+
+// setModel records the model of a translation; an empty model removes it.
+// The caller holds m.mu.
+func (m *Memory) setModel(lang, source, model string) {
+	if model == "" {
+		delete(m.Models[lang], source)
+		if len(m.Models[lang]) == 0 {
+			delete(m.Models, lang)
+		}
+		return
+	}
+	if m.Models == nil {
+		m.Models = make(map[string]map[string]string)
+	}
+	if m.Models[lang] == nil {
+		m.Models[lang] = make(map[string]string)
+	}
+	m.Models[lang][source] = model
+}
+
+// ---------------------------------------------------------------------------
+// Stats
+// ---------------------------------------------------------------------------
+
+// Stats returns the number of languages and total entries in the memory.
+func (m *Memory) Stats() (languages, entries int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	languages = len(m.Entries)
+	for _, e := range m.Entries {
+		entries += len(e)
+	}
+	return
+}
@@ -0,0 +1,145 @@
+package tm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadNonExistent(t *testing.T) {
+	m, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load returned error for non-existent file: %v", err)
+	}
+	if m.Version != Version {
+		t.Errorf("Version = %d, want %d", m.Version, Version)
+	}
+	if _, entries := m.Stats(); entries != 0 {
+		t.Errorf("entries = %d, want 0", entries)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	m.Add("ru", "Cancel", "Отмена")
+	m.Add("de", SourceKey("Open", "menu"), "Öffnen")
+
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	m2, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load after save: %v", err)
+	}
+	if got, ok := m2.Lookup("ru", "Cancel"); !ok || got != "Отмена" {
+		t.Errorf("Lookup(ru, Cancel) = %q, %v", got, ok)
+	}
+	if got, ok := m2.Lookup("de", SourceKey("Open", "menu")); !ok || got != "Öffnen" {
+		t.Errorf("Lookup(de, menu/Open) = %q, %v", got, ok)
+	}
+	if _, ok := m2.Lookup("de", "Open"); ok {
+		t.Error("context-free lookup matched an entry with msgctxt")
+	}
+}
+
+func TestSaveEmptyDoesNotCreateFile(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Fatalf("empty memory created %s", FileName)
+	}
+}
+
+func TestSeedDoesNotOverride(t *testing.T) {
+	m := New()
+	m.Add("ru", "Save", "Сохранить")
+	if m.Seed("ru", "Save", "Записать") {
+		t.Error("Seed replaced an existing entry")
+	}
+	if !m.Seed("ru", "Open", "Открыть") {
+		t.Error("Seed did not add a new entry")
+	}
+	if got, _ := m.Lookup("ru", "Save"); got != "Сохранить" {
+		t.Errorf("Lookup(ru, Save) = %q", got)
+	}
+}
+
+func TestLanguageSpellingsShareEntries(t *testing.T) {
+	m := New()
+	m.Add("pt_BR", "Cancel", "Cancelar")
+	if got, ok := m.Lookup("pt-BR", "Cancel"); !ok || got != "Cancelar" {
+		t.Errorf("Lookup(pt-BR, Cancel) = %q, %v", got, ok)
+	}
+}
+
+func TestAddIgnoresEmptyStrings(t *testing.T) {
+	m := New()
+	m.Add("ru", "", "X")
+	m.Add("ru", "Save", "  ")
+	if _, entries := m.Stats(); entries != 0 {
+		t.Errorf("entries = %d, want 0", entries)
+	}
+}
+
+func TestForgetOnlyMatchingTranslation(t *testing.T) {
+	m := New()
+	m.Add("pt_BR", "Cancel", "Cancelar")
+
+	if m.Forget("pt-BR", "Cancel", "Outro") {
+		t.Error("Forget removed an entry with a different translation")
+	}
+	if !m.Forget("pt-BR", "Cancel", "Cancelar") {
+		t.Fatal("Forget returned false for a matching entry")
+	}
+	if _, ok := m.Lookup("pt_BR", "Cancel"); ok {
+		t.Error("forgotten entry is still returned by Lookup")
+	}
+	if languages, _ := m.Stats(); languages != 0 {
+		t.Errorf("languages = %d, want 0", languages)
+	}
+}
+
+func TestAddFromRecordsModel(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	m.AddFrom("pt_BR", "Cancel", "Cancelar", "openai/gpt-4o")
+	m.Seed("pt-BR", "Open", "Abrir")
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	m2, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load after save: %v", err)
+	}
+	if got := m2.Model("pt-BR", "Cancel"); got != "openai/gpt-4o" {
+		t.Errorf("Model(Cancel) = %q", got)
+	}
+	if got := m2.Model("pt-BR", "Open"); got != "" {
+		t.Errorf("Model(Open) = %q, want none for a seeded entry", got)
+	}
+
+	m2.Add("pt-BR", "Cancel", "Anular")
+	if got := m2.Model("pt-BR", "Cancel"); got != "" {
+		t.Errorf("Model after Add = %q, want the old model dropped", got)
+	}
+	m2.AddFrom("pt-BR", "Cancel", "Cancelar", "mock/pseudo")
+	m2.Forget("pt-BR", "Cancel", "Cancelar")
+	if len(m2.Models) != 0 {
+		t.Errorf("Models = %v, want empty after Forget", m2.Models)
+	}
+}
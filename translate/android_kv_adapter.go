@@ -3,7 +3,8 @@ package translate
 import (
 	"fmt"
 
-	"github.com/minios-linux/lokit/internal/format/android"
+	formatfile "github.com/minios-linux/lokit/format"
+	"github.com/minios-linux/lokit/format/android"
 )
 
 type androidUnitKind int
@@ -29,11 +30,11 @@ type androidKVFile struct {
 	index  map[string]androidKVUnit
 }
 
-func newAndroidKVFile(target, source *android.File) *androidKVFile {
+func newAndroidKVFile(target, source *android.File, lang string) *androidKVFile {
 	if source == nil {
 		source = target
 	}
-	units := buildAndroidKVUnits(source)
+	units := buildAndroidKVUnits(source, lang)
 	index := make(map[string]androidKVUnit, len(units))
 	for _, unit := range units {
 		index[unit.key] = unit
@@ -41,7 +42,15 @@ func newAndroidKVFile(target, source *android.File) *androidKVFile {
 	return &androidKVFile{target: target, source: source, units: units, index: index}
 }
 
-func buildAndroidKVUnits(f *android.File) []androidKVUnit {
+// NewAndroidKVFile returns target as the flat key-value file the translation
+// pipeline works on: strings by name, array items as "name[i]" and plural
+// quantities as "name#quantity", with source values taken from source.
+// Plurals have a key for each quantity of lang, whether or not target has it.
+func NewAndroidKVFile(target, source *android.File, lang string) formatfile.KVFile {
+	return newAndroidKVFile(target, source, lang)
+}
+
+func buildAndroidKVUnits(f *android.File, lang string) []androidKVUnit {
 	if f == nil {
 		return nil
 	}
@@ -63,7 +72,7 @@ func buildAndroidKVUnits(f *android.File) []androidKVUnit {
 				})
 			}
 		case android.KindPlurals:
-			for _, q := range e.PluralOrder {
+			for _, q := range android.Quantities(lang) {
 				units = append(units, androidKVUnit{
 					key:      fmt.Sprintf("%s#%s", e.Name, q),
 					name:     e.Name,
@@ -84,6 +93,37 @@ func (f *androidKVFile) Keys() []string {
 	return keys
 }
 
+// Notes returns the source comments of resources for each of their keys.
+func (f *androidKVFile) Notes() map[string]string {
+	byName := f.source.Notes()
+	if len(byName) == 0 {
+		return nil
+	}
+	notes := make(map[string]string)
+	for _, unit := range f.units {
+		if note := byName[unit.name]; note != "" {
+			notes[unit.key] = note
+		}
+	}
+	return notes
+}
+
+// MaxLengths returns the maxLength of source resources for each of their
+// keys.
+func (f *androidKVFile) MaxLengths() map[string]int {
+	byName := f.source.MaxLengths()
+	if len(byName) == 0 {
+		return nil
+	}
+	limits := make(map[string]int)
+	for _, unit := range f.units {
+		if n := byName[unit.name]; n > 0 {
+			limits[unit.key] = n
+		}
+	}
+	return limits
+}
+
 func (f *androidKVFile) UntranslatedKeys() []string {
 	keys := make([]string, 0)
 	for _, unit := range f.units {
@@ -94,6 +134,29 @@ func (f *androidKVFile) UntranslatedKeys() []string {
 	return keys
 }
 
+func (f *androidKVFile) Get(key string) (string, bool) {
+	unit, ok := f.index[key]
+	if !ok {
+		return "", false
+	}
+	return f.valueForUnit(f.target, unit), true
+}
+
+// PluralKey reports the plurals resource and quantity of key.
+func (f *androidKVFile) PluralKey(key string) (unit, category string, ok bool) {
+	u, ok := f.index[key]
+	if !ok || u.kind != androidUnitPlural {
+		return "", "", false
+	}
+	return u.name, u.quantity, true
+}
+
+// PluralFormKey returns the key holding the given quantity of a plurals
+// resource.
+func (f *androidKVFile) PluralFormKey(unit, category string) string {
+	return unit + "#" + category
+}
+
 func (f *androidKVFile) Set(key, value string) bool {
 	unit, ok := f.index[key]
 	if !ok {
@@ -139,10 +202,17 @@ func (f *androidKVFile) Stats() (total int, translated int, pct float64) {
 	return
 }
 
+// SourceValues returns the source text of each key. Quantities the source
+// resource does not have are left out; the pipeline sends them with the
+// source "other" form.
 func (f *androidKVFile) SourceValues() map[string]string {
 	vals := make(map[string]string, len(f.units))
 	for _, unit := range f.units {
-		vals[unit.key] = f.valueForUnit(f.source, unit)
+		v := f.valueForUnit(f.source, unit)
+		if v == "" && unit.kind == androidUnitPlural {
+			continue
+		}
+		vals[unit.key] = v
 	}
 	return vals
 }
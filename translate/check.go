@@ -0,0 +1,148 @@
+package translate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// ---------------------------------------------------------------------------
+// Offline QA checks (lokit check)
+// ---------------------------------------------------------------------------
+
+// Check rule identifiers.
+const (
+	CheckPlaceholders       = "placeholders"
+	CheckWhitespace         = "whitespace"
+	CheckIdentical          = "identical"
+	CheckMarkdownHeading    = "markdown-heading"
+	CheckTags               = "tags"
+	CheckAndroidApostrophes = "android-apostrophe"
+	CheckICU                = "icu"
+)
+
+// Check severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// CheckIssue is a problem found in an existing translation without calling
+// any provider.
+type CheckIssue struct {
+	Rule     string
+	Severity string
+	Message  string
+}
+
+// CheckPOTranslation checks one translated form of a PO entry. source is the
+// msgid (or msgid_plural for plural forms > 0). Placeholders are checked
+// according to the entry's *-format flags, like during translation.
+func CheckPOTranslation(entry *po.Entry, source, translation string) []CheckIssue {
+	var issues []CheckIssue
+	if err := validatePOPlaceholders(entry, source, translation); err != nil {
+		issues = append(issues, CheckIssue{Rule: CheckPlaceholders, Severity: SeverityError, Message: err.Error()})
+	}
+	return append(issues, checkCommon(source, translation)...)
+}
+
+// CheckKVTranslation checks one translated key-value pair. When markdown is
+// true, the leading heading level must also match.
+func CheckKVTranslation(source, translation string, markdown bool) []CheckIssue {
+	var issues []CheckIssue
+	if err := validateKVPlaceholders(source, translation); err != nil {
+		issues = append(issues, CheckIssue{Rule: CheckPlaceholders, Severity: SeverityError, Message: err.Error()})
+	}
+	if markdown {
+		level, ok := leadingMarkdownHeadingLevel(source)
+		dLevel, dOK := leadingMarkdownHeadingLevel(translation)
+		if ok != dOK || level != dLevel {
+			issues = append(issues, CheckIssue{
+				Rule:     CheckMarkdownHeading,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("heading level changed: expected %s, got %s", headingLevelString(level, ok), headingLevelString(dLevel, dOK)),
+			})
+		}
+	}
+	return append(issues, checkCommon(source, translation)...)
+}
+
+// CheckARBTranslation checks one translated Flutter ARB value. ICU
+// MessageFormat structure, arguments and the plural categories of lang
+// replace the generic placeholder check.
+func CheckARBTranslation(source, translation, lang string) []CheckIssue {
+	var issues []CheckIssue
+	if err := validateICUTranslation(source, translation, lang, nil); err != nil {
+		issues = append(issues, CheckIssue{Rule: CheckICU, Severity: SeverityError, Message: err.Error()})
+	}
+	return append(issues, checkCommon(source, translation)...)
+}
+
+func headingLevelString(level int, ok bool) string {
+	if !ok {
+		return "no heading"
+	}
+	return strings.Repeat("#", level)
+}
+
+func checkCommon(source, translation string) []CheckIssue {
+	var issues []CheckIssue
+	if msg := whitespaceMismatch(source, translation); msg != "" {
+		issues = append(issues, CheckIssue{Rule: CheckWhitespace, Severity: SeverityWarning, Message: msg})
+	}
+	if translation == source && strings.IndexFunc(source, unicode.IsLetter) >= 0 {
+		issues = append(issues, CheckIssue{Rule: CheckIdentical, Severity: SeverityWarning, Message: "translation is identical to source"})
+	}
+	if tagsBalanced(source) && !tagsBalanced(translation) {
+		issues = append(issues, CheckIssue{Rule: CheckTags, Severity: SeverityError, Message: "unbalanced HTML/XML tags"})
+	}
+	return issues
+}
+
+// whitespaceMismatch describes differences in leading or trailing
+// whitespace (including newlines) between source and translation.
+func whitespaceMismatch(source, translation string) string {
+	srcLead := source[:len(source)-len(strings.TrimLeftFunc(source, unicode.IsSpace))]
+	dstLead := translation[:len(translation)-len(strings.TrimLeftFunc(translation, unicode.IsSpace))]
+	if srcLead != dstLead {
+		return fmt.Sprintf("leading whitespace differs: expected %q, got %q", srcLead, dstLead)
+	}
+	srcTrail := source[len(strings.TrimRightFunc(source, unicode.IsSpace)):]
+	dstTrail := translation[len(strings.TrimRightFunc(translation, unicode.IsSpace)):]
+	if srcTrail != dstTrail {
+		return fmt.Sprintf("trailing whitespace differs: expected %q, got %q", srcTrail, dstTrail)
+	}
+	return ""
+}
+
+var markupTag = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9:._-]*)(?:\s[^<>]*?)?(/?)>`)
+
+// voidElements are HTML elements that never have a closing tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// tagsBalanced reports whether every opening tag in text has a matching
+// closing tag in the right order.
+func tagsBalanced(text string) bool {
+	var stack []string
+	for _, m := range markupTag.FindAllStringSubmatch(text, -1) {
+		closing, name, selfClosing := m[1] == "/", m[2], m[3] == "/"
+		if selfClosing || voidElements[strings.ToLower(name)] {
+			continue
+		}
+		if !closing {
+			stack = append(stack, name)
+			continue
+		}
+		if len(stack) == 0 || stack[len(stack)-1] != name {
+			return false
+		}
+		stack = stack[:len(stack)-1]
+	}
+	return len(stack) == 0
+}
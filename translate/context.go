@@ -0,0 +1,162 @@
+package translate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// ---------------------------------------------------------------------------
+// Translator-facing context
+// ---------------------------------------------------------------------------
+
+const (
+	// maxSnippetReferences limits how many references of one entry get a
+	// source snippet; further references are listed by path only.
+	maxSnippetReferences = 2
+	// maxSnippetLineLen truncates long source lines in snippets.
+	maxSnippetLineLen = 160
+)
+
+// contextNoteLines is the system prompt addition explaining the notes sent
+// after source strings.
+const contextNoteLines = `
+- Indented lines in parentheses after a source string are context for the translator (message context, notes, source references or code). Use them to pick the right meaning and form, e.g. "Open" as a verb on a button vs. an adjective. Never translate them or include them in the response.`
+
+// writePOEntryContext writes the context of a PO entry below its source
+// line: the message context, extracted comments (xgettext TRANSLATORS:
+// notes), references and, with opts.SourceContextLines, the source code
+// around the first references. files caches the source files read for the
+// prompt being built.
+func writePOEntryContext(b *strings.Builder, e *po.Entry, opts Options, files sourceFiles) {
+	if e.MsgCtxt != "" {
+		fmt.Fprintf(b, "   (message context: %s)\n", escapeForPrompt(e.MsgCtxt))
+	}
+	if note := poTranslatorNote(e); note != "" {
+		fmt.Fprintf(b, "   (note: %s)\n", note)
+	}
+	if len(e.References) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "   (context: %s)\n", strings.Join(e.References, ", "))
+	if opts.SourceContextLines <= 0 {
+		return
+	}
+	n := 0
+	for _, ref := range e.References {
+		if n == maxSnippetReferences {
+			break
+		}
+		if snippet := files.snippet(opts.SourceRoot, ref, opts.SourceContextLines); snippet != "" {
+			fmt.Fprintf(b, "   (code at %s:\n%s   )\n", ref, snippet)
+			n++
+		}
+	}
+}
+
+// poTranslatorNote joins the extracted comments of e, dropping the
+// "TRANSLATORS:" tag xgettext keeps.
+func poTranslatorNote(e *po.Entry) string {
+	var parts []string
+	for _, c := range e.ExtractedComments {
+		c = strings.TrimSpace(c)
+		if rest, ok := strings.CutPrefix(c, "TRANSLATORS:"); ok {
+			c = strings.TrimSpace(rest)
+		}
+		if c != "" {
+			parts = append(parts, c)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// sourceFiles caches the lines of the source files read for snippets, so
+// that a file referenced by several entries of a chunk is read once. Files
+// that cannot be read are cached as nil.
+type sourceFiles map[string][]string
+
+// snippet returns the lines around a "path:line" reference, resolved
+// against root, each prefixed with its line number. Returns "" when the
+// reference has no line number, the file cannot be read or it lies outside
+// root: references come from PO files and must not expose other files to
+// the provider.
+func (c sourceFiles) snippet(root, ref string, radius int) string {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 {
+		return ""
+	}
+	line, err := strconv.Atoi(ref[i+1:])
+	if err != nil || line <= 0 {
+		return ""
+	}
+	path := ref[:i]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	lines, ok := c[path]
+	if !ok {
+		lines = readSourceLines(root, path)
+		c[path] = lines
+	}
+	if line > len(lines) {
+		return ""
+	}
+	from := max(line-radius, 1)
+	to := line + radius
+	if to > len(lines) {
+		to = len(lines)
+	}
+	var b strings.Builder
+	for n := from; n <= to; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "   %s%5d | %s\n", marker, n, truncate(strings.TrimRight(lines[n-1], " \t\r"), maxSnippetLineLen))
+	}
+	return b.String()
+}
+
+// readSourceLines returns the lines of the file at path, or nil if it
+// cannot be read or resolves outside root. Symbolic links are
+// resolved first, so a link cannot lead outside root either.
+func readSourceLines(root, path string) []string {
+	if root == "" {
+		root = "."
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil
+	}
+	if realRoot, err = filepath.Abs(realRoot); err != nil {
+		return nil
+	}
+	if realPath, err = filepath.Abs(realPath); err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(realRoot, realPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	data, err := os.ReadFile(realPath)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// writeKVNote writes the translator note of a key-value entry (Android
+// comment, ARB description) below its source line.
+func writeKVNote(b *strings.Builder, key string, notes map[string]string) {
+	if note := strings.TrimSpace(notes[key]); note != "" {
+		fmt.Fprintf(b, "   (note: %s)\n", strings.Join(strings.Fields(note), " "))
+	}
+}
@@ -0,0 +1,147 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ---------------------------------------------------------------------------
+// Provider errors
+// ---------------------------------------------------------------------------
+
+// ErrAuthentication is wrapped by errors returned when a provider's OAuth
+// token cannot be obtained or refreshed.
+var ErrAuthentication = errors.New("authentication failed")
+
+// APIError is returned when a provider answers with an HTTP status that is
+// not (or no longer) retried, including rate limits after all retries.
+type APIError struct {
+	// StatusCode is the HTTP status of the last response.
+	StatusCode int
+	msg        string
+}
+
+func newAPIError(status int, format string, args ...any) *APIError {
+	return &APIError{StatusCode: status, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *APIError) Error() string {
+	return e.msg
+}
+
+// IsFallbackError reports whether err means the provider cannot serve the
+// request now and another provider should be tried: authentication
+// failures (401/403), exhausted billing or quota (402/429) and server
+// errors that persisted through all retries.
+func IsFallbackError(err error) bool {
+	if errors.Is(err, ErrAuthentication) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	}
+	return apiErr.StatusCode >= 500
+}
+
+// IsAuthError reports whether err means the provider rejected or lacks
+// credentials: an OAuth token that cannot be obtained, or a 401/403 answer.
+func IsAuthError(err error) bool {
+	if errors.Is(err, ErrAuthentication) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden
+}
+
+// ---------------------------------------------------------------------------
+// Provider fallback chain
+// ---------------------------------------------------------------------------
+
+// ProviderChain is an ordered list of providers used in place of
+// Options.Provider. Requests go to the active provider; when it fails with
+// a fallback error (see IsFallbackError) or keeps returning invalid
+// responses, the next provider becomes active for the rest of the run.
+// A chain is safe for concurrent use and is meant to be shared by all
+// targets using the same providers, so a failed provider is not retried.
+type ProviderChain struct {
+	mu        sync.Mutex
+	providers []Provider
+	active    int
+
+	// OnSwitch is called when the chain moves on to the next provider.
+	OnSwitch func(from, to Provider, reason error)
+}
+
+// NewProviderChain returns a chain trying providers in the given order.
+func NewProviderChain(providers ...Provider) *ProviderChain {
+	return &ProviderChain{providers: providers}
+}
+
+// Active returns the provider currently in use.
+func (c *ProviderChain) Active() Provider {
+	_, prov := c.current()
+	return prov
+}
+
+// Providers returns all providers of the chain in order.
+func (c *ProviderChain) Providers() []Provider {
+	return append([]Provider(nil), c.providers...)
+}
+
+func (c *ProviderChain) current() (int, Provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active, c.providers[c.active]
+}
+
+// advance makes the provider after index from active. If another request
+// already moved past from, the chain is left unchanged. Reports whether
+// a different provider is now active.
+func (c *ProviderChain) advance(from int, reason error) bool {
+	c.mu.Lock()
+	if c.active != from {
+		c.mu.Unlock()
+		return true
+	}
+	if from+1 >= len(c.providers) {
+		c.mu.Unlock()
+		return false
+	}
+	c.active = from + 1
+	prev, next := c.providers[from], c.providers[c.active]
+	c.mu.Unlock()
+	if c.OnSwitch != nil {
+		c.OnSwitch(prev, next, reason)
+	}
+	return true
+}
+
+// activeProvider returns the provider requests should go to and its index
+// in the fallback chain (0 without a chain).
+func (o *Options) activeProvider() (int, Provider) {
+	if o.Providers == nil || len(o.Providers.providers) == 0 {
+		return 0, o.Provider
+	}
+	return o.Providers.current()
+}
+
+// fallbackAfterInvalidResponses switches to the provider after index from,
+// the one that kept returning responses that could not be used. Reports
+// whether the request should be retried with another provider.
+func (o *Options) fallbackAfterInvalidResponses(ctx context.Context, from int, err error) bool {
+	if o.Providers == nil || ctx.Err() != nil {
+		return false
+	}
+	return o.Providers.advance(from, fmt.Errorf("repeated invalid responses: %w", err))
+}
@@ -0,0 +1,245 @@
+package translate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// Glossary is the terminology enforced on AI translations. It is injected
+// into the system prompt and checked against every translated chunk. A
+// do-not-translate term missing from a translation is treated like a
+// malformed response and retried; a term translated differently than
+// mapped is only reported, since the mapped form may be inflected.
+type Glossary struct {
+	// DoNotTranslate lists terms that must be kept verbatim.
+	DoNotTranslate []string
+	// Terms maps a language code to source term -> required translation.
+	Terms map[string]map[string]string
+}
+
+// termsFor returns the term mappings for lang. Language codes are compared
+// case-insensitively with "_" and "-" treated as equal, so a "pt-BR"
+// glossary applies to a gettext "pt_BR" catalog.
+func (g *Glossary) termsFor(lang string) map[string]string {
+	if g == nil {
+		return nil
+	}
+	if terms, ok := g.Terms[lang]; ok {
+		return terms
+	}
+	want := normalizeGlossaryLang(lang)
+	for l, terms := range g.Terms {
+		if normalizeGlossaryLang(l) == want {
+			return terms
+		}
+	}
+	return nil
+}
+
+func normalizeGlossaryLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+}
+
+// glossaryPrompt returns the glossary section appended to the system prompt,
+// or "" if no glossary applies to the target language.
+func (o *Options) glossaryPrompt() string {
+	terms := o.Glossary.termsFor(o.Language)
+	if o.Glossary == nil || (len(o.Glossary.DoNotTranslate) == 0 && len(terms) == 0) {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("GLOSSARY (mandatory terminology):\n")
+	if len(o.Glossary.DoNotTranslate) > 0 {
+		b.WriteString("- Never translate, transliterate or inflect these terms; copy them exactly as written:\n")
+		for _, term := range o.Glossary.DoNotTranslate {
+			fmt.Fprintf(&b, "  %q\n", term)
+		}
+	}
+	if len(terms) > 0 {
+		b.WriteString("- Always translate these terms as follows, inflected as the grammar requires:\n")
+		sources := make([]string, 0, len(terms))
+		for src := range terms {
+			sources = append(sources, src)
+		}
+		sort.Strings(sources)
+		for _, src := range sources {
+			fmt.Fprintf(&b, "  %q → %q\n", src, terms[src])
+		}
+	}
+	return b.String()
+}
+
+// checkGlossary reports the first do-not-translate term of source that
+// translation does not keep verbatim.
+func (o *Options) checkGlossary(source, translation string) error {
+	if o.Glossary == nil {
+		return nil
+	}
+	for _, term := range o.Glossary.DoNotTranslate {
+		if containsTerm(source, term, false) && !containsTerm(translation, term, false) {
+			return fmt.Errorf("glossary term %q must be kept untranslated", term)
+		}
+	}
+	return nil
+}
+
+// glossaryMismatches lists the mapped terms of source whose translation is
+// missing from translation. Inflected forms are accepted: each word of the
+// mapped translation only has to start a word of translation, ignoring its
+// last two letters.
+func (o *Options) glossaryMismatches(source, translation string) []string {
+	terms := o.Glossary.termsFor(o.Language)
+	sources := make([]string, 0, len(terms))
+	for src := range terms {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+	var out []string
+	for _, src := range sources {
+		if containsTerm(source, src, true) && !containsTermStem(translation, terms[src]) {
+			out = append(out, fmt.Sprintf("glossary term %q should be translated as %q", src, terms[src]))
+		}
+	}
+	return out
+}
+
+// warnGlossary logs the glossary mismatches of one translation.
+func (o *Options) warnGlossary(what, source, translation string) {
+	for _, msg := range o.glossaryMismatches(source, translation) {
+		o.log("Warning: %s: %s", what, msg)
+	}
+}
+
+// containsTerm reports whether text contains term as a whole word (the
+// characters around the match are not letters or digits).
+func containsTerm(text, term string, foldCase bool) bool {
+	if term == "" {
+		return false
+	}
+	if foldCase {
+		text = strings.ToLower(text)
+		term = strings.ToLower(term)
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// containsTermStem reports whether every word of term starts a word of
+// text, case-insensitively. Words longer than three letters lose up to two
+// trailing letters first, so inflected forms ("папку" for "папка") match.
+func containsTermStem(text, term string) bool {
+	words := strings.FieldsFunc(strings.ToLower(term), func(r rune) bool { return !isWordRune(r) })
+	if len(words) == 0 {
+		return false
+	}
+	text = strings.ToLower(text)
+	for _, word := range words {
+		stem := []rune(word)
+		if n := len(stem) - 2; n >= 3 {
+			stem = stem[:n]
+		} else if len(stem) > 3 {
+			stem = stem[:3]
+		}
+		if !containsWordPrefix(text, string(stem)) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsWordPrefix reports whether prefix occurs in text at the start of
+// a word.
+func containsWordPrefix(text, prefix string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], prefix)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		if before, _ := utf8.DecodeLastRuneInString(text[:start]); !isWordRune(before) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func validatePOGlossary(entries []*po.Entry, translations []string, opts Options) error {
+	for i, entry := range entries {
+		if i >= len(translations) {
+			break
+		}
+		if err := opts.checkGlossary(entry.MsgID, translations[i]); err != nil {
+			return fmt.Errorf("entry %q: %w", entry.MsgID, err)
+		}
+		opts.warnGlossary(fmt.Sprintf("entry %q", entry.MsgID), entry.MsgID, translations[i])
+	}
+	return nil
+}
+
+func validatePOPluralGlossary(entries []*po.Entry, translations []pluralTranslation, opts Options) error {
+	for i, entry := range entries {
+		if i >= len(translations) {
+			break
+		}
+		if entry.MsgIDPlural == "" {
+			if err := opts.checkGlossary(entry.MsgID, translations[i].singular); err != nil {
+				return fmt.Errorf("entry %q: %w", entry.MsgID, err)
+			}
+			opts.warnGlossary(fmt.Sprintf("entry %q", entry.MsgID), entry.MsgID, translations[i].singular)
+			continue
+		}
+		for form, value := range translations[i].plural {
+			source := entry.MsgIDPlural
+			if form == 0 {
+				source = entry.MsgID
+			}
+			if err := opts.checkGlossary(source, value); err != nil {
+				return fmt.Errorf("entry %q plural form %d: %w", entry.MsgID, form, err)
+			}
+			opts.warnGlossary(fmt.Sprintf("entry %q plural form %d", entry.MsgID, form), source, value)
+		}
+	}
+	return nil
+}
+
+func validateKVGlossary(keys []string, srcVals map[string]string, translations []string, opts Options) error {
+	for i, key := range keys {
+		if i >= len(translations) {
+			break
+		}
+		source := key
+		if value := srcVals[key]; value != "" {
+			source = value
+		}
+		if err := opts.checkGlossary(source, translations[i]); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		opts.warnGlossary(fmt.Sprintf("key %q", key), source, translations[i])
+	}
+	return nil
+}
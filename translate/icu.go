@@ -0,0 +1,182 @@
+package translate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/langmeta"
+)
+
+// ---------------------------------------------------------------------------
+// ICU MessageFormat (Flutter ARB)
+// ---------------------------------------------------------------------------
+
+// kvChunkValidator is implemented by chunk translators that replace the
+// generic placeholder check with format-specific validation.
+type kvChunkValidator interface {
+	validateTranslations(keys []string, srcVals map[string]string, translations []string, opts Options) error
+}
+
+// arbChunkTranslator sends ARB values with ICU MessageFormat instructions
+// and validates the ICU structure of each translation.
+type arbChunkTranslator struct {
+	// placeholders maps keys to the names declared in "@key" metadata.
+	placeholders map[string][]string
+}
+
+func newARBChunkTranslator(files ...*arbfile.File) arbChunkTranslator {
+	t := arbChunkTranslator{placeholders: make(map[string][]string)}
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+		for _, key := range f.Keys() {
+			if names := f.Placeholders(key); len(names) > 0 {
+				t.placeholders[key] = names
+			}
+		}
+	}
+	return t
+}
+
+func (arbChunkTranslator) BuildUserPrompt(keys []string, srcVals, notes map[string]string, opts Options) string {
+	prompt := buildKVUserPrompt(keys, srcVals, notes, opts.SourceLanguageName, opts.LanguageName)
+	for _, key := range keys {
+		if isICUSelectMessage(srcVals[key]) {
+			return prompt + "\n\n" + icuPromptNote(opts.Language)
+		}
+	}
+	return prompt
+}
+
+func (arbChunkTranslator) DefaultChunkSize() int { return 0 }
+
+func icuPromptNote(lang string) string {
+	return fmt.Sprintf("Some strings are ICU MessageFormat messages. Keep their structure: do not translate argument names or the keywords plural, select, selectordinal, offset, the branch selectors (=0, one, other, male, ...) or #; translate only the text inside the branches. Every plural message must have branches for all plural categories of the target language: %s (exact =N branches may be kept).",
+		strings.Join(langmeta.PluralCategories(lang), ", "))
+}
+
+func (t arbChunkTranslator) validateTranslations(keys []string, srcVals map[string]string, translations []string, opts Options) error {
+	if len(translations) != len(keys) {
+		return fmt.Errorf("got %d translations, expected %d", len(translations), len(keys))
+	}
+	for i, key := range keys {
+		if err := validateICUTranslation(srcVals[key], translations[i], opts.Language, t.placeholders[key]); err != nil {
+			return fmt.Errorf("key %q %w", key, err)
+		}
+	}
+	return nil
+}
+
+var icuSelectArgument = regexp.MustCompile(`\{\s*[^{},\s]+\s*,\s*(?:plural|select|selectordinal)\s*,`)
+
+func isICUSelectMessage(s string) bool {
+	return icuSelectArgument.MatchString(s)
+}
+
+// validateICUTranslation checks an ICU MessageFormat translation against its
+// source: it must parse, keep every plural and select argument with
+// untranslated selectors, cover all plural categories of lang, and keep the
+// source arguments (using no arguments other than source or declared ones).
+// Sources that are not valid ICU use the generic placeholder check.
+func validateICUTranslation(source, translation, lang string, declared []string) error {
+	src, err := arbfile.ParseICU(source)
+	if err != nil {
+		return validateKVPlaceholders(source, translation)
+	}
+	dst, err := arbfile.ParseICU(translation)
+	if err != nil {
+		return fmt.Errorf("invalid ICU message: %v", err)
+	}
+
+	dstSelectors := make(map[string][]arbfile.ICUPart)
+	for _, part := range dst.Selectors() {
+		dstSelectors[part.Arg] = append(dstSelectors[part.Arg], part)
+	}
+	for _, want := range src.Selectors() {
+		parts := dstSelectors[want.Arg]
+		if len(parts) == 0 {
+			return fmt.Errorf("%s argument {%s} missing", icuKindName(want.Kind), want.Arg)
+		}
+		for _, got := range parts {
+			if got.Kind != want.Kind {
+				return fmt.Errorf("argument {%s} changed from %s to %s", want.Arg, icuKindName(want.Kind), icuKindName(got.Kind))
+			}
+			if err := validateICUBranches(want, got, lang); err != nil {
+				return err
+			}
+		}
+	}
+
+	allowed := make(map[string]bool)
+	for _, name := range declared {
+		allowed[name] = true
+	}
+	have := make(map[string]bool)
+	for _, name := range dst.Arguments() {
+		have[name] = true
+	}
+	for _, name := range src.Arguments() {
+		allowed[name] = true
+		if !have[name] {
+			return fmt.Errorf("placeholder {%s} missing", name)
+		}
+	}
+	for _, name := range dst.Arguments() {
+		if !allowed[name] {
+			return fmt.Errorf("unknown placeholder {%s}", name)
+		}
+	}
+	return nil
+}
+
+func validateICUBranches(src, dst arbfile.ICUPart, lang string) error {
+	have := make(map[string]bool, len(dst.Branches))
+	for _, b := range dst.Branches {
+		have[b.Selector] = true
+	}
+	switch src.Kind {
+	case arbfile.ICUSelect:
+		srcSel := make(map[string]bool, len(src.Branches))
+		for _, b := range src.Branches {
+			srcSel[b.Selector] = true
+			if !have[b.Selector] {
+				return fmt.Errorf("select {%s} is missing branch %q", src.Arg, b.Selector)
+			}
+		}
+		for _, b := range dst.Branches {
+			if !srcSel[b.Selector] {
+				return fmt.Errorf("select {%s} has unknown branch %q", src.Arg, b.Selector)
+			}
+		}
+	case arbfile.ICUPlural, arbfile.ICUSelectOrdinal:
+		for _, b := range dst.Branches {
+			if !strings.HasPrefix(b.Selector, "=") && !langmeta.IsPluralCategory(b.Selector) {
+				return fmt.Errorf("%s {%s} has unknown branch %q", icuKindName(src.Kind), src.Arg, b.Selector)
+			}
+		}
+		if src.Kind == arbfile.ICUPlural {
+			for _, cat := range langmeta.PluralCategories(lang) {
+				if !have[cat] {
+					return fmt.Errorf("plural {%s} is missing the %q category", src.Arg, cat)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func icuKindName(k arbfile.ICUKind) string {
+	switch k {
+	case arbfile.ICUPlural:
+		return "plural"
+	case arbfile.ICUSelectOrdinal:
+		return "selectordinal"
+	case arbfile.ICUSelect:
+		return "select"
+	default:
+		return "argument"
+	}
+}
@@ -0,0 +1,168 @@
+package translate
+
+import (
+	formatfile "github.com/minios-linux/lokit/format"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/journal"
+	"github.com/minios-linux/lokit/lockfile"
+)
+
+// ---------------------------------------------------------------------------
+// Checkpoint journal
+// ---------------------------------------------------------------------------
+
+// journalTarget is the journal target key of the current language.
+func (o *Options) journalTarget() string {
+	return lockfile.LockTargetKey(o.LockTarget, o.Language)
+}
+
+// useJournal reports whether journal entries from an interrupted run should
+// be applied before translating.
+func (o *Options) useJournal() bool {
+	return o.Journal != nil && o.Resume
+}
+
+// recordJournal appends entries to the journal. A failing journal does not
+// stop the run: the translations are still saved with their file.
+func recordJournal(entries []journal.Entry, opts Options) {
+	if err := opts.Journal.Record(entries...); err != nil {
+		opts.logError("Could not write journal: %v", err)
+	}
+}
+
+// forgetJournal drops the journal entries of the current language once its
+// file has been saved.
+func forgetJournal(lockKeyPrefix string, opts Options) {
+	if opts.Journal == nil {
+		return
+	}
+	if err := opts.Journal.Forget(opts.journalTarget(), lockKeyPrefix); err != nil {
+		opts.logError("Could not update journal: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// PO entries
+// ---------------------------------------------------------------------------
+
+// recordJournalForPO records the translations of a completed chunk along
+// with the model that translated it.
+func recordJournalForPO(entries []*po.Entry, model string, opts Options) {
+	if opts.Journal == nil {
+		return
+	}
+	target := opts.journalTarget()
+	var records []journal.Entry
+	for _, e := range entries {
+		if e == nil || e.MsgID == "" || !hasPOTranslation(e) {
+			continue
+		}
+		rec := journal.Entry{
+			Target:       target,
+			Key:          lockfile.POEntryKey(e.MsgID, e.MsgCtxt),
+			Source:       e.MsgID,
+			SourcePlural: e.MsgIDPlural,
+			Model:        model,
+		}
+		if e.MsgIDPlural == "" {
+			rec.Translation = e.MsgStr
+		} else {
+			n := 0
+			for i := range e.MsgStrPlural {
+				n = max(n, i+1)
+			}
+			rec.Plural = make([]string, n)
+			for i, form := range e.MsgStrPlural {
+				rec.Plural[i] = form
+			}
+		}
+		records = append(records, rec)
+	}
+	recordJournal(records, opts)
+}
+
+// resumeJournalForPO fills entries translated by an interrupted run and
+// returns the entries that still need a provider translation along with the
+// resumed ones. Journal entries whose source changed since are ignored.
+func resumeJournalForPO(entries []*po.Entry, opts Options) (remaining, resumed []*po.Entry) {
+	if !opts.useJournal() {
+		return entries, nil
+	}
+	recorded := opts.Journal.Entries(opts.journalTarget(), "")
+	if len(recorded) == 0 {
+		return entries, nil
+	}
+	for _, e := range entries {
+		rec, ok := recorded[lockfile.POEntryKey(e.MsgID, e.MsgCtxt)]
+		if !ok || rec.Source != e.MsgID || rec.SourcePlural != e.MsgIDPlural {
+			remaining = append(remaining, e)
+			continue
+		}
+		applyPluralTranslations([]*po.Entry{e}, []pluralTranslation{{singular: rec.Translation, plural: rec.Plural}}, opts.TranslateFuzzy)
+		if !hasPOTranslation(e) {
+			remaining = append(remaining, e)
+			continue
+		}
+		markReviewForPO([]*po.Entry{e}, rec.Model, opts)
+		updateMemoryForPO([]*po.Entry{e}, rec.Model, opts)
+		resumed = append(resumed, e)
+	}
+	return remaining, resumed
+}
+
+// ---------------------------------------------------------------------------
+// Key-value entries
+// ---------------------------------------------------------------------------
+
+// recordJournalForKV records the translations of keys set by a completed
+// chunk along with the model that translated them.
+func recordJournalForKV(keys, values []string, srcVals map[string]string, lockKeyPrefix, model string, opts Options) {
+	if opts.Journal == nil {
+		return
+	}
+	target := opts.journalTarget()
+	records := make([]journal.Entry, 0, len(keys))
+	for i, key := range keys {
+		records = append(records, journal.Entry{
+			Target:      target,
+			Scope:       lockKeyPrefix,
+			Key:         key,
+			Source:      kvSource(key, srcVals),
+			Translation: values[i],
+			Model:       model,
+		})
+	}
+	recordJournal(records, opts)
+}
+
+// resumeJournalForKV sets keys translated by an interrupted run and returns
+// the keys that still need a provider translation along with the resumed
+// ones. Journal entries whose source changed since are ignored.
+func resumeJournalForKV(file formatfile.KVFile, keys []string, srcVals map[string]string, lockKeyPrefix string, opts Options) (remaining, resumed []string) {
+	if !opts.useJournal() {
+		return keys, nil
+	}
+	recorded := opts.Journal.Entries(opts.journalTarget(), lockKeyPrefix)
+	if len(recorded) == 0 {
+		return keys, nil
+	}
+	for _, key := range keys {
+		rec, ok := recorded[key]
+		if !ok || rec.Source != kvSource(key, srcVals) || !file.Set(key, rec.Translation) {
+			remaining = append(remaining, key)
+			continue
+		}
+		markReviewForKV(file, []string{key}, srcVals, lockKeyPrefix, rec.Model, opts)
+		updateMemoryForKV(file, []string{key}, srcVals, []string{rec.Translation}, rec.Model, opts)
+		resumed = append(resumed, key)
+	}
+	return remaining, resumed
+}
+
+// kvSource returns the source text of key.
+func kvSource(key string, srcVals map[string]string) string {
+	if v := srcVals[key]; v != "" {
+		return v
+	}
+	return key
+}
@@ -3,13 +3,14 @@ package translate
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
 
-	formatfile "github.com/minios-linux/lokit/internal/format"
+	formatfile "github.com/minios-linux/lokit/format"
 )
 
 type KVLangTask struct {
@@ -21,22 +22,31 @@ type KVLangTask struct {
 	LockKeyPrefix string
 }
 
+// KVChunkTranslator builds the user prompt for a chunk of keys. notes holds
+// translator-facing notes by key (Android comments, ARB descriptions) and
+// may be nil.
 type KVChunkTranslator interface {
-	BuildUserPrompt(keys []string, srcVals map[string]string, opts Options) string
+	BuildUserPrompt(keys []string, srcVals, notes map[string]string, opts Options) string
 	DefaultChunkSize() int
 }
 
+// kvNoter is implemented by key-value files that carry notes for
+// translators.
+type kvNoter interface {
+	Notes() map[string]string
+}
+
 type defaultKVChunkTranslator struct{}
 
-func (defaultKVChunkTranslator) BuildUserPrompt(keys []string, srcVals map[string]string, opts Options) string {
-	return buildKVUserPrompt(keys, srcVals, opts.SourceLanguageName, opts.LanguageName)
+func (defaultKVChunkTranslator) BuildUserPrompt(keys []string, srcVals, notes map[string]string, opts Options) string {
+	return buildKVUserPrompt(keys, srcVals, notes, opts.SourceLanguageName, opts.LanguageName)
 }
 
 func (defaultKVChunkTranslator) DefaultChunkSize() int { return 0 }
 
 type i18nextChunkTranslator struct{}
 
-func (i18nextChunkTranslator) BuildUserPrompt(keys []string, _ map[string]string, opts Options) string {
+func (i18nextChunkTranslator) BuildUserPrompt(keys []string, _, _ map[string]string, opts Options) string {
 	return buildI18NextUserPrompt(keys, opts.SourceLanguageName, opts.LanguageName)
 }
 
@@ -46,7 +56,7 @@ type markdownChunkTranslator struct{}
 
 var markdownFencedCode = regexp.MustCompile("(?ms)^```[^\n]*\n.*?^```[ \t]*$|^~~~[^\n]*\n.*?^~~~[ \t]*$")
 
-func (markdownChunkTranslator) BuildUserPrompt(keys []string, srcVals map[string]string, opts Options) string {
+func (markdownChunkTranslator) BuildUserPrompt(keys []string, srcVals, _ map[string]string, opts Options) string {
 	return buildMarkdownUserPrompt(keys, srcVals, opts.SourceLanguageName, opts.LanguageName)
 }
 
@@ -75,10 +85,14 @@ KEY-VALUE RESPONSE CONTRACT:
 - Return ONLY a JSON array of objects with exactly two fields: "id" and "translation".
 - Copy every ID exactly. Do not omit, duplicate, rename, or invent IDs.
 - "translation" must be a non-empty string.
-- This contract replaces any earlier instruction to return a bare array of strings.`
+- This contract replaces any earlier instruction to return a bare array of strings.` + contextNoteLines
 }
 
 func TranslateAllKV(ctx context.Context, langTasks []KVLangTask, opts Options, translator KVChunkTranslator) error {
+	for i, task := range langTasks {
+		langTasks[i].SourceValues = WithPluralSourceValues(task.File, task.SourceValues)
+		seedMemoryFromKV(task.File, langTasks[i].SourceValues, task.Lang, task.LockKeyPrefix, opts)
+	}
 	if opts.ParallelMode == ParallelFullParallel {
 		return translateKVFullParallel(ctx, langTasks, opts, translator)
 	}
@@ -86,7 +100,7 @@ func TranslateAllKV(ctx context.Context, langTasks []KVLangTask, opts Options, t
 }
 
 func translateKVSequential(ctx context.Context, langTasks []KVLangTask, opts Options, translator KVChunkTranslator) error {
-	var failedLangs []string
+	failed := &LanguageErrors{}
 	for _, task := range langTasks {
 		select {
 		case <-ctx.Done():
@@ -109,33 +123,44 @@ func translateKVSequential(ctx context.Context, langTasks []KVLangTask, opts Opt
 		keysToTranslate = filterExcludedKeys(keysToTranslate, taskOpts)
 		keysToTranslate = filterKeysWithSourceValues(keysToTranslate, task.SourceValues, taskOpts)
 		keysToTranslate = filterChangedKeys(keysToTranslate, task.SourceValues, task.LockKeyPrefix, taskOpts)
+		keysToTranslate, resumed := resumeJournalForKV(task.File, keysToTranslate, task.SourceValues, task.LockKeyPrefix, taskOpts)
+		if len(resumed) > 0 {
+			opts.log("Journal: resumed %d keys for %s", len(resumed), task.Lang)
+			updateLockFileForKV(resumed, task.SourceValues, task.LockKeyPrefix, taskOpts)
+		}
+		keysToTranslate, reused := reuseMemoryForKV(task.File, keysToTranslate, task.SourceValues, task.LockKeyPrefix, taskOpts)
+		if len(reused) > 0 {
+			opts.log("Translation memory: reused %d keys for %s", len(reused), task.Lang)
+			updateLockFileForKV(reused, task.SourceValues, task.LockKeyPrefix, taskOpts)
+		}
+		keysToTranslate = withRejectedKeys(keysToTranslate, task.File, task.LockKeyPrefix, taskOpts)
 
 		if len(keysToTranslate) == 0 {
+			if len(resumed) > 0 || len(reused) > 0 {
+				saveKVFile(task.File, task.FilePath, task.LockKeyPrefix, taskOpts)
+			}
 			continue
 		}
 
 		opts.log("Translating %s (%s) — %d keys...", task.Lang, task.LangName, len(keysToTranslate))
 
-		translatedKeys, err := translateKVFile(ctx, task.File, task.SourceValues, keysToTranslate, taskOpts, translator)
+		translatedKeys, err := translateKVFile(ctx, task.File, task.SourceValues, keysToTranslate, task.LockKeyPrefix, taskOpts, translator)
+		updateLockFileForKV(translatedKeys, task.SourceValues, task.LockKeyPrefix, taskOpts)
+		saveKVFile(task.File, task.FilePath, task.LockKeyPrefix, taskOpts)
 		if err != nil {
 			if ctx.Err() != nil {
-				saveKVFile(task.File, task.FilePath, opts)
 				return ctx.Err()
 			}
-			saveKVFile(task.File, task.FilePath, opts)
+			if errors.Is(err, ErrTokenBudgetExceeded) {
+				return err
+			}
 			opts.logError("Error translating %s: %v", task.Lang, err)
-			failedLangs = append(failedLangs, task.Lang)
+			failed.add(task.Lang, err)
 			continue
 		}
-
-		updateLockFileForKV(translatedKeys, task.SourceValues, task.LockKeyPrefix, taskOpts)
-		saveKVFile(task.File, task.FilePath, opts)
 	}
 
-	if len(failedLangs) > 0 {
-		return fmt.Errorf("%d language(s) failed: %s", len(failedLangs), strings.Join(failedLangs, ", "))
-	}
-	return nil
+	return failed.orNil()
 }
 
 func translateKVFullParallel(ctx context.Context, langTasks []KVLangTask, opts Options, translator KVChunkTranslator) error {
@@ -168,8 +193,22 @@ func translateKVFullParallel(ctx context.Context, langTasks []KVLangTask, opts O
 		keys = filterExcludedKeys(keys, taskOpts)
 		keys = filterKeysWithSourceValues(keys, lt.SourceValues, taskOpts)
 		keys = filterChangedKeys(keys, lt.SourceValues, lt.LockKeyPrefix, taskOpts)
+		keys, resumed := resumeJournalForKV(lt.File, keys, lt.SourceValues, lt.LockKeyPrefix, taskOpts)
+		if len(resumed) > 0 {
+			opts.log("Journal: resumed %d keys for %s", len(resumed), lt.Lang)
+			updateLockFileForKV(resumed, lt.SourceValues, lt.LockKeyPrefix, taskOpts)
+		}
+		keys, reused := reuseMemoryForKV(lt.File, keys, lt.SourceValues, lt.LockKeyPrefix, taskOpts)
+		if len(reused) > 0 {
+			opts.log("Translation memory: reused %d keys for %s", len(reused), lt.Lang)
+			updateLockFileForKV(reused, lt.SourceValues, lt.LockKeyPrefix, taskOpts)
+		}
+		keys = withRejectedKeys(keys, lt.File, lt.LockKeyPrefix, taskOpts)
 
 		if len(keys) == 0 {
+			if len(resumed) > 0 || len(reused) > 0 {
+				saveKVFile(lt.File, lt.FilePath, lt.LockKeyPrefix, taskOpts)
+			}
 			continue
 		}
 
@@ -195,17 +234,11 @@ func translateKVFullParallel(ctx context.Context, langTasks []KVLangTask, opts O
 		taskOpts.SourceLanguageName = taskOpts.resolvedSourceLangName()
 
 		opts.log("Translating %s (%s) — %d keys...", t.lang, t.langName, len(t.keys))
-		translatedKeys, err := translateKVFileWithRL(ctx, t.file, t.sourceValues, t.keys, taskOpts, translator, rl)
-		if err != nil {
-			if ctx.Err() == nil {
-				saveKVFile(t.file, t.filePath, opts)
-			}
-			return err
-		}
-
+		translatedKeys, err := translateKVFileWithRL(ctx, t.file, t.sourceValues, t.keys, t.lockKeyPrefix, taskOpts, translator, rl)
+		// Partial results are saved too, including after an interrupt.
 		updateLockFileForKV(translatedKeys, t.sourceValues, t.lockKeyPrefix, taskOpts)
-		saveKVFile(t.file, t.filePath, opts)
-		return nil
+		saveKVFile(t.file, t.filePath, t.lockKeyPrefix, taskOpts)
+		return err
 	})
 	if err != nil {
 		return err
@@ -213,12 +246,14 @@ func translateKVFullParallel(ctx context.Context, langTasks []KVLangTask, opts O
 	return nil
 }
 
-func translateKVFile(ctx context.Context, file formatfile.KVFile, srcVals map[string]string, keys []string, opts Options, translator KVChunkTranslator) ([]string, error) {
+func translateKVFile(ctx context.Context, file formatfile.KVFile, srcVals map[string]string, keys []string, lockKeyPrefix string, opts Options, translator KVChunkTranslator) ([]string, error) {
 	rl := &rateLimitState{}
-	return translateKVFileWithRL(ctx, file, srcVals, keys, opts, translator, rl)
+	return translateKVFileWithRL(ctx, file, srcVals, keys, lockKeyPrefix, opts, translator, rl)
 }
 
-func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals map[string]string, keys []string, opts Options, translator KVChunkTranslator, rl *rateLimitState) ([]string, error) {
+func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals map[string]string, keys []string, lockKeyPrefix string, opts Options, translator KVChunkTranslator, rl *rateLimitState) ([]string, error) {
+	total := len(keys)
+	units, keys := splitKVPluralUnits(file, keys, srcVals, opts.SourceLanguage)
 	chunkSize := opts.effectiveChunkSize()
 	if chunkSize <= 0 {
 		chunkSize = translator.DefaultChunkSize()
@@ -228,9 +263,17 @@ func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals
 	}
 
 	systemPrompt := opts.resolvedPrompt()
-	chunks := splitStrings(keys, chunkSize)
+	var notes map[string]string
+	if n, ok := file.(kvNoter); ok {
+		notes = n.Notes()
+	}
+	opts.lengthHints = KVMaxLengths(file)
+	var chunks [][]string
+	if len(keys) > 0 {
+		chunks = splitStrings(keys, chunkSize)
+	}
 	done := 0
-	translatedKeys := make([]string, 0, len(keys))
+	translatedKeys := make([]string, 0, total)
 	validateMarkdown := isMarkdownTranslator(translator)
 
 	for i, chunk := range chunks {
@@ -244,7 +287,7 @@ func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals
 			opts.log("  Chunk %d/%d (%d keys)", i+1, len(chunks), len(chunk))
 		}
 
-		translations, err := translateKVChunk(ctx, chunk, srcVals, systemPrompt, opts, translator, rl)
+		translations, model, err := translateKVChunk(ctx, chunk, srcVals, notes, systemPrompt, opts, translator, rl)
 		if err != nil {
 			return translatedKeys, fmt.Errorf("translating chunk %d/%d: %w", i+1, len(chunks), err)
 		}
@@ -265,9 +308,9 @@ func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals
 				}
 				attempt++
 				if len(chunk) == 1 {
-					translations, err = translateMarkdownSingleRetry(ctx, chunk[0], srcVals, systemPrompt, opts, rl)
+					translations, model, err = translateMarkdownSingleRetry(ctx, chunk[0], srcVals, systemPrompt, opts, rl)
 				} else {
-					translations, err = translateKVChunk(ctx, chunk, srcVals, systemPrompt, opts, translator, rl)
+					translations, model, err = translateKVChunk(ctx, chunk, srcVals, notes, systemPrompt, opts, translator, rl)
 				}
 				if err != nil {
 					return translatedKeys, fmt.Errorf("translating chunk %d/%d: %w", i+1, len(chunks), err)
@@ -275,19 +318,72 @@ func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals
 			}
 		}
 
+		var setKeys, setValues []string
 		for j, key := range chunk {
 			if j < len(translations) && translations[j] != "" {
 				file.Set(key, translations[j])
-				translatedKeys = append(translatedKeys, key)
+				setKeys = append(setKeys, key)
+				setValues = append(setValues, translations[j])
 			}
 		}
+		translatedKeys = append(translatedKeys, setKeys...)
+		markReviewForKV(file, setKeys, srcVals, lockKeyPrefix, model, opts)
+		updateMemoryForKV(file, chunk, srcVals, translations, model, opts)
+		recordJournalForKV(setKeys, setValues, srcVals, lockKeyPrefix, model, opts)
 
 		done += len(chunk)
 		if opts.OnProgress != nil {
-			opts.OnProgress(opts.Language, done, len(keys))
+			opts.OnProgress(opts.Language, done, total)
 		}
 
-		if i < len(chunks)-1 && opts.RequestDelay > 0 {
+		if (i < len(chunks)-1 || len(units) > 0) && opts.RequestDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return translatedKeys, ctx.Err()
+			case <-time.After(opts.RequestDelay):
+			}
+		}
+	}
+
+	pluralChunks := splitKVPluralChunks(units, chunkSize)
+	for i, chunk := range pluralChunks {
+		if len(chunk) == 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return translatedKeys, ctx.Err()
+		default:
+		}
+
+		if opts.Verbose {
+			opts.log("  Plural chunk %d/%d (%d units)", i+1, len(pluralChunks), len(chunk))
+		}
+
+		forms, model, err := translateKVPluralChunk(ctx, chunk, notes, systemPrompt, opts, rl)
+		if err != nil {
+			return translatedKeys, fmt.Errorf("translating plural chunk %d/%d: %w", i+1, len(pluralChunks), err)
+		}
+
+		var setKeys, setValues []string
+		for j, u := range chunk {
+			for k, key := range u.keys {
+				value := forms[j][u.categories[k]]
+				if file.Set(key, value) {
+					setKeys = append(setKeys, key)
+					setValues = append(setValues, value)
+				}
+			}
+			done += len(u.keys)
+		}
+		translatedKeys = append(translatedKeys, setKeys...)
+		markReviewForKV(file, setKeys, srcVals, lockKeyPrefix, model, opts)
+		recordJournalForKV(setKeys, setValues, srcVals, lockKeyPrefix, model, opts)
+		if opts.OnProgress != nil {
+			opts.OnProgress(opts.Language, done, total)
+		}
+
+		if i < len(pluralChunks)-1 && opts.RequestDelay > 0 {
 			select {
 			case <-ctx.Done():
 				return translatedKeys, ctx.Err()
@@ -299,7 +395,7 @@ func translateKVFileWithRL(ctx context.Context, file formatfile.KVFile, srcVals
 	return translatedKeys, nil
 }
 
-func translateKVChunk(ctx context.Context, keys []string, srcVals map[string]string, systemPrompt string, opts Options, translator KVChunkTranslator, rl *rateLimitState) ([]string, error) {
+func translateKVChunk(ctx context.Context, keys []string, srcVals, notes map[string]string, systemPrompt string, opts Options, translator KVChunkTranslator, rl *rateLimitState) ([]string, string, error) {
 	promptVals := srcVals
 	codeBlocksByKey := map[string][]string(nil)
 	if isMarkdownTranslator(translator) {
@@ -319,8 +415,9 @@ func translateKVChunk(ctx context.Context, keys []string, srcVals map[string]str
 		promptVals = masked
 	}
 
-	userPrompt := translator.BuildUserPrompt(keys, promptVals, opts)
 	ids := kvTranslationIDs(keys)
+	lengthItems := kvLengthItems(keys, ids, srcVals)
+	userPrompt := translator.BuildUserPrompt(keys, promptVals, notes, opts) + lengthPrompt(lengthItems, opts)
 	systemPrompt = identifiedKVSystemPrompt(systemPrompt)
 	validationVals := promptVals
 	if _, ok := translator.(i18nextChunkTranslator); ok {
@@ -328,21 +425,33 @@ func translateKVChunk(ctx context.Context, keys []string, srcVals map[string]str
 	}
 	maxRetries := opts.effectiveMaxRetries()
 	var translations []string
+	var model string
 	var lastErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		prompt := userPrompt
 		if lastErr != nil {
 			prompt += fmt.Sprintf("\n\nYour previous response was rejected: %v\nReturn a corrected complete response using the required IDs and JSON shape.", lastErr)
 		}
-		text, err := callProvider(ctx, opts.Provider, systemPrompt, prompt, rl, maxRetries, opts.Verbose)
+		text, idx, err := opts.call(ctx, systemPrompt, prompt, rl, maxRetries)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		translations, err = parseIdentifiedStringTranslations(text, ids)
 		if err == nil {
-			err = validateKVTranslations(keys, validationVals, translations)
+			if v, ok := translator.(kvChunkValidator); ok {
+				err = v.validateTranslations(keys, promptVals, translations, opts)
+			} else {
+				err = validateKVTranslations(keys, validationVals, translations)
+			}
+		}
+		if err == nil {
+			err = validateKVGlossary(keys, promptVals, translations, opts)
 		}
 		if err == nil {
+			err = keepOverLength(validateLengths(lengthItems, translations, opts), attempt, maxRetries, opts)
+		}
+		if err == nil {
+			model = opts.reviewModel(idx)
 			lastErr = nil
 			break
 		}
@@ -350,12 +459,16 @@ func translateKVChunk(ctx context.Context, keys []string, srcVals map[string]str
 		if attempt < maxRetries {
 			opts.log("  Invalid translation response, retrying (%d/%d): %v", attempt+1, maxRetries, err)
 			if err := waitBeforeParseRetry(ctx, attempt); err != nil {
-				return nil, err
+				return nil, "", err
 			}
+		} else if opts.fallbackAfterInvalidResponses(ctx, idx, err) {
+			// Start over with the next provider and a fresh prompt.
+			lastErr = nil
+			attempt = -1
 		}
 	}
 	if lastErr != nil {
-		return nil, lastErr
+		return nil, "", lastErr
 	}
 	if isMarkdownTranslator(translator) {
 		for i, key := range keys {
@@ -365,7 +478,7 @@ func translateKVChunk(ctx context.Context, keys []string, srcVals map[string]str
 			translations[i] = restoreMarkdownCodeBlocks(translations[i], codeBlocksByKey[key])
 		}
 	}
-	return translations, nil
+	return translations, model, nil
 }
 
 var kvBracePlaceholder = regexp.MustCompile(`\{\{[A-Za-z_][A-Za-z0-9_]*\}\}|\{[A-Za-z_][A-Za-z0-9_]*(?:![rsa])?(?::[^{}]*)?\}`)
@@ -379,27 +492,35 @@ func validateKVTranslations(keys []string, srcVals map[string]string, translatio
 		if value := srcVals[key]; value != "" {
 			source = value
 		}
-		sourcePlaceholders := append(printfPlaceholder.FindAllString(source, -1), kvBracePlaceholder.FindAllString(source, -1)...)
-		translatedPlaceholders := append(printfPlaceholder.FindAllString(translations[i], -1), kvBracePlaceholder.FindAllString(translations[i], -1)...)
-		sort.Strings(sourcePlaceholders)
-		sort.Strings(translatedPlaceholders)
-		if !slicesEqual(sourcePlaceholders, translatedPlaceholders) {
-			return fmt.Errorf("key %q placeholders changed: expected %v, got %v", key, sourcePlaceholders, translatedPlaceholders)
+		if err := validateKVPlaceholders(source, translations[i]); err != nil {
+			return fmt.Errorf("key %q %w", key, err)
 		}
 	}
 	return nil
 }
 
-func saveKVFile(file formatfile.KVFile, path string, opts Options) {
+func validateKVPlaceholders(source, translation string) error {
+	sourcePlaceholders := kvPlaceholders(source)
+	translatedPlaceholders := kvPlaceholders(translation)
+	sort.Strings(sourcePlaceholders)
+	sort.Strings(translatedPlaceholders)
+	if !slicesEqual(sourcePlaceholders, translatedPlaceholders) {
+		return fmt.Errorf("placeholders changed: expected %v, got %v", sourcePlaceholders, translatedPlaceholders)
+	}
+	return nil
+}
+
+func saveKVFile(file formatfile.KVFile, path, lockKeyPrefix string, opts Options) {
 	if err := file.WriteFile(path); err != nil {
 		opts.logError("Error saving %s: %v", path, err)
 		return
 	}
 	total, translated, _ := file.Stats()
 	opts.log("Saved %s (%d/%d translated)", path, translated, total)
+	forgetJournal(lockKeyPrefix, opts)
 }
 
-func buildKVUserPrompt(keys []string, srcVals map[string]string, sourceLangName, langName string) string {
+func buildKVUserPrompt(keys []string, srcVals, notes map[string]string, sourceLangName, langName string) string {
 	var userMsg strings.Builder
 	ids := kvTranslationIDs(keys)
 	if sourceLangName != "" {
@@ -415,6 +536,7 @@ func buildKVUserPrompt(keys []string, srcVals map[string]string, sourceLangName,
 			}
 		}
 		userMsg.WriteString(fmt.Sprintf("ID %s: %s\n", ids[i], escapeForPrompt(src)))
+		writeKVNote(&userMsg, key, notes)
 	}
 	userMsg.WriteString(fmt.Sprintf("\nReturn a JSON array with exactly %d objects in this form: ", len(keys)))
 	userMsg.WriteString(`{"id":"kv-...","translation":"..."}. Preserve every input ID exactly; the objects may be returned in any order.`)
@@ -564,7 +686,7 @@ func restoreMarkdownCodeBlocks(text string, blocks []string) string {
 	return out
 }
 
-func translateMarkdownSingleRetry(ctx context.Context, key string, srcVals map[string]string, systemPrompt string, opts Options, rl *rateLimitState) ([]string, error) {
+func translateMarkdownSingleRetry(ctx context.Context, key string, srcVals map[string]string, systemPrompt string, opts Options, rl *rateLimitState) ([]string, string, error) {
 	src := key
 	if srcVals != nil {
 		if v, ok := srcVals[key]; ok && v != "" {
@@ -592,16 +714,16 @@ func translateMarkdownSingleRetry(ctx context.Context, key string, srcVals map[s
 	userMsg.WriteString(escapeForPrompt(maskedSrc))
 	userMsg.WriteString(`\n\nReturn [{"id":"` + id + `","translation":"..."}].`)
 
-	text, err := callProvider(ctx, opts.Provider, systemPrompt, userMsg.String(), rl, opts.effectiveMaxRetries(), opts.Verbose)
+	text, idx, err := opts.call(ctx, systemPrompt, userMsg.String(), rl, opts.effectiveMaxRetries())
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	translations, err := parseIdentifiedStringTranslations(text, []string{id})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if len(translations) > 0 {
 		translations[0] = restoreMarkdownCodeBlocks(translations[0], blocks)
 	}
-	return translations, nil
+	return translations, opts.reviewModel(idx), nil
 }
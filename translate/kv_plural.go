@@ -0,0 +1,296 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	formatfile "github.com/minios-linux/lokit/format"
+	"github.com/minios-linux/lokit/langmeta"
+)
+
+// ---------------------------------------------------------------------------
+// Plural units in key-value files
+// ---------------------------------------------------------------------------
+
+// kvPluralUnit is one plural message whose forms are stored under separate
+// keys (e.g. i18next "item_one", "item_few", "item_other").
+type kvPluralUnit struct {
+	name string
+	// sources maps source-language CLDR categories to their source text.
+	sources map[string]string
+	// keys and categories list the target forms to translate.
+	keys       []string
+	categories []string
+}
+
+// WithPluralSourceValues returns srcVals extended with source values for
+// plural keys that exist only in the target language (e.g. "item_few" for
+// Russian when the English source has just "_one" and "_other"). Such keys
+// use the source "other" form, so lock hashes follow source changes.
+func WithPluralSourceValues(file formatfile.KVFile, srcVals map[string]string) map[string]string {
+	pf, ok := file.(formatfile.PluralKVFile)
+	if !ok || srcVals == nil {
+		return srcVals
+	}
+	var out map[string]string
+	for _, key := range file.Keys() {
+		if _, exists := srcVals[key]; exists {
+			continue
+		}
+		unit, _, ok := pf.PluralKey(key)
+		if !ok {
+			continue
+		}
+		other := srcVals[pf.PluralFormKey(unit, langmeta.PluralOther)]
+		if other == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string, len(srcVals)+1)
+			for k, v := range srcVals {
+				out[k] = v
+			}
+		}
+		out[key] = other
+	}
+	if out == nil {
+		return srcVals
+	}
+	return out
+}
+
+// splitKVPluralUnits groups plural keys into units and returns the
+// remaining plain keys in their original order.
+func splitKVPluralUnits(file formatfile.KVFile, keys []string, srcVals map[string]string, sourceLang string) ([]kvPluralUnit, []string) {
+	pf, ok := file.(formatfile.PluralKVFile)
+	if !ok {
+		return nil, keys
+	}
+	var units []kvPluralUnit
+	index := make(map[string]int)
+	plain := make([]string, 0, len(keys))
+	for _, key := range keys {
+		unit, cat, ok := pf.PluralKey(key)
+		if !ok {
+			plain = append(plain, key)
+			continue
+		}
+		i, seen := index[unit]
+		if !seen {
+			i = len(units)
+			index[unit] = i
+			units = append(units, kvPluralUnit{name: unit, sources: pluralSourceForms(pf, unit, srcVals, sourceLang)})
+		}
+		units[i].keys = append(units[i].keys, key)
+		units[i].categories = append(units[i].categories, cat)
+	}
+	return units, plain
+}
+
+func pluralSourceForms(pf formatfile.PluralKVFile, unit string, srcVals map[string]string, sourceLang string) map[string]string {
+	forms := make(map[string]string)
+	for _, cat := range langmeta.PluralCategories(sourceLang) {
+		if v := srcVals[pf.PluralFormKey(unit, cat)]; v != "" {
+			forms[cat] = v
+		}
+	}
+	if len(forms) == 0 {
+		if v := srcVals[pf.PluralFormKey(unit, langmeta.PluralOther)]; v != "" {
+			forms[langmeta.PluralOther] = v
+		}
+	}
+	return forms
+}
+
+func splitKVPluralChunks(units []kvPluralUnit, chunkSize int) [][]kvPluralUnit {
+	if chunkSize <= 0 || chunkSize >= len(units) {
+		return [][]kvPluralUnit{units}
+	}
+	var chunks [][]kvPluralUnit
+	for i := 0; i < len(units); i += chunkSize {
+		end := i + chunkSize
+		if end > len(units) {
+			end = len(units)
+		}
+		chunks = append(chunks, units[i:end])
+	}
+	return chunks
+}
+
+func identifiedKVPluralSystemPrompt(base string) string {
+	return base + `
+
+PLURAL RESPONSE CONTRACT:
+- The user message assigns an opaque ID to every plural message and lists its source forms by CLDR plural category.
+- Return ONLY a JSON array of objects with exactly two fields: "id" and "translation".
+- Copy every ID exactly. Do not omit, duplicate, rename, or invent IDs.
+- "translation" must be a JSON object mapping each requested CLDR category (zero, one, two, few, many, other) to a non-empty string.
+- Use the grammar of the target language for each category; keep placeholders such as {{count}} where the number is shown.
+- This contract replaces any earlier instruction to return a bare array of strings.` + contextNoteLines
+}
+
+func buildKVPluralUserPrompt(units []kvPluralUnit, ids []string, notes map[string]string, sourceLangName, langName string) string {
+	var userMsg strings.Builder
+	if sourceLangName != "" {
+		userMsg.WriteString(fmt.Sprintf("Translate these plural messages from %s to %s:\n\n", sourceLangName, langName))
+	} else {
+		userMsg.WriteString(fmt.Sprintf("Translate these plural messages to %s:\n\n", langName))
+	}
+	for i, u := range units {
+		var forms []string
+		for _, cat := range langmeta.PluralCategoryOrder {
+			if v, ok := u.sources[cat]; ok {
+				forms = append(forms, cat+": "+escapeForPrompt(v))
+			}
+		}
+		userMsg.WriteString(fmt.Sprintf("ID %s: %s\n", ids[i], strings.Join(forms, " | ")))
+		userMsg.WriteString(fmt.Sprintf("   (return the forms: %s)\n", strings.Join(u.categories, ", ")))
+		if len(u.keys) > 0 {
+			writeKVNote(&userMsg, u.keys[0], notes)
+		}
+	}
+	userMsg.WriteString(fmt.Sprintf("\nReturn a JSON array with exactly %d objects in this form: ", len(units)))
+	userMsg.WriteString(`{"id":"kv-...","translation":{"one":"...","other":"..."}}. Preserve every input ID exactly; the objects may be returned in any order.`)
+	return userMsg.String()
+}
+
+// translateKVPluralChunk translates plural units, one request per chunk.
+// The result holds the translated forms of each unit keyed by category.
+func translateKVPluralChunk(ctx context.Context, units []kvPluralUnit, notes map[string]string, systemPrompt string, opts Options, rl *rateLimitState) ([]map[string]string, string, error) {
+	names := make([]string, len(units))
+	for i, u := range units {
+		names[i] = u.name
+	}
+	ids := kvTranslationIDs(names)
+	systemPrompt = identifiedKVPluralSystemPrompt(systemPrompt)
+	userPrompt := buildKVPluralUserPrompt(units, ids, notes, opts.resolvedSourceLangName(), opts.LanguageName)
+	userPrompt += lengthPrompt(kvPluralLengthItems(units, ids), opts)
+
+	maxRetries := opts.effectiveMaxRetries()
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		prompt := userPrompt
+		if lastErr != nil {
+			prompt += fmt.Sprintf("\n\nYour previous response was rejected: %v\nReturn a corrected complete response using the required IDs and JSON shape.", lastErr)
+		}
+		text, idx, err := opts.call(ctx, systemPrompt, prompt, rl, maxRetries)
+		if err != nil {
+			return nil, "", err
+		}
+		forms, err := parseKVPluralTranslations(text, units, ids)
+		if err == nil {
+			err = validateKVPluralTranslations(units, forms, opts)
+		}
+		if err == nil {
+			items, values := kvPluralLengthValues(units, ids, forms)
+			err = keepOverLength(validateLengths(items, values, opts), attempt, maxRetries, opts)
+		}
+		if err == nil {
+			return forms, opts.reviewModel(idx), nil
+		}
+		lastErr = err
+		if attempt < maxRetries {
+			opts.log("  Invalid translation response, retrying (%d/%d): %v", attempt+1, maxRetries, err)
+			if err := waitBeforeParseRetry(ctx, attempt); err != nil {
+				return nil, "", err
+			}
+		} else if opts.fallbackAfterInvalidResponses(ctx, idx, err) {
+			// Start over with the next provider and a fresh prompt.
+			lastErr = nil
+			attempt = -1
+		}
+	}
+	return nil, "", lastErr
+}
+
+func parseKVPluralTranslations(content string, units []kvPluralUnit, ids []string) ([]map[string]string, error) {
+	raw, err := parseIdentifiedTranslations(content, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]string, len(raw))
+	for i, value := range raw {
+		if err := json.Unmarshal(value, &result[i]); err != nil {
+			return nil, fmt.Errorf("translation for id %q is not an object of plural forms", ids[i])
+		}
+		for _, cat := range units[i].categories {
+			if strings.TrimSpace(result[i][cat]) == "" {
+				return nil, fmt.Errorf("plural %q is missing the %q form", units[i].name, cat)
+			}
+		}
+	}
+	return result, nil
+}
+
+// validateKVPluralTranslations checks that no form introduces placeholders
+// absent from every source form. Forms may drop the count placeholder
+// (e.g. "one" in French), so only invented placeholders are rejected.
+func validateKVPluralTranslations(units []kvPluralUnit, forms []map[string]string, opts Options) error {
+	for i, u := range units {
+		allowed := make(map[string]bool)
+		for _, src := range u.sources {
+			for _, p := range kvPlaceholders(src) {
+				allowed[p] = true
+			}
+		}
+		for _, cat := range u.categories {
+			value := forms[i][cat]
+			for _, p := range kvPlaceholders(value) {
+				if !allowed[p] {
+					return fmt.Errorf("plural %q form %q has unknown placeholder %s", u.name, cat, p)
+				}
+			}
+			source := pluralSourceFor(u.sources, cat)
+			if err := opts.checkGlossary(source, value); err != nil {
+				return fmt.Errorf("plural %q form %q: %w", u.name, cat, err)
+			}
+			opts.warnGlossary(fmt.Sprintf("plural %q form %q", u.name, cat), source, value)
+		}
+	}
+	return nil
+}
+
+func kvPlaceholders(s string) []string {
+	placeholders := append(printfPlaceholder.FindAllString(s, -1), kvBracePlaceholder.FindAllString(s, -1)...)
+	return append(placeholders, applePlaceholder.FindAllString(s, -1)...)
+}
+
+// pluralSourceFor returns the source form matching category, falling back
+// to the "other" form.
+func pluralSourceFor(sources map[string]string, category string) string {
+	if v, ok := sources[category]; ok {
+		return v
+	}
+	return sources[langmeta.PluralOther]
+}
+
+// kvPluralLengthItems returns the length items of every requested form of
+// units, in unit and category order.
+func kvPluralLengthItems(units []kvPluralUnit, ids []string) []lengthItem {
+	var items []lengthItem
+	for i, u := range units {
+		for j, cat := range u.categories {
+			source := u.sources[cat]
+			if source == "" {
+				source = u.sources["other"]
+			}
+			items = append(items, lengthItem{id: ids[i] + " " + cat, key: u.keys[j], source: source})
+		}
+	}
+	return items
+}
+
+// kvPluralLengthValues pairs the length items of units with their
+// translated forms.
+func kvPluralLengthValues(units []kvPluralUnit, ids []string, forms []map[string]string) ([]lengthItem, []string) {
+	items := kvPluralLengthItems(units, ids)
+	values := make([]string, 0, len(items))
+	for i, u := range units {
+		for _, cat := range u.categories {
+			values = append(values, forms[i][cat])
+		}
+	}
+	return items, values
+}
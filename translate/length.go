@@ -0,0 +1,217 @@
+package translate
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	formatfile "github.com/minios-linux/lokit/format"
+	po "github.com/minios-linux/lokit/format/po"
+)
+
+// ---------------------------------------------------------------------------
+// Length limits
+// ---------------------------------------------------------------------------
+
+// CheckLength is the check rule reporting translations over their length
+// limit.
+const CheckLength = "length"
+
+// LengthLimits caps the length of translations, e.g. for menu entries and
+// buttons with little room. Limits are sent with each string; translations
+// exceeding them are rejected and retried with an instruction to shorten
+// them. After the last retry they are kept and reported by lokit check and
+// lokit status.
+type LengthLimits struct {
+	Rules []LengthRule
+}
+
+// LengthRule is one length limit. A translation must satisfy every rule
+// matching its key.
+type LengthRule struct {
+	// Pattern selects keys (msgids for PO entries); nil selects all keys.
+	Pattern *regexp.Regexp
+	// Max is the maximum number of characters (0 = none).
+	Max int
+	// Ratio is the maximum length relative to the source (0 = none).
+	Ratio float64
+}
+
+// Limit returns the maximum length in characters of the translation of key,
+// or 0 if it is not limited. hint is a limit declared by the file itself,
+// such as Android maxLength (0 = none); the strictest limit wins.
+func (l *LengthLimits) Limit(key, source string, hint int) int {
+	limit := hint
+	if l == nil {
+		return limit
+	}
+	for _, r := range l.Rules {
+		if r.Pattern != nil && !r.Pattern.MatchString(key) {
+			continue
+		}
+		if r.Max > 0 {
+			limit = minLimit(limit, r.Max)
+		}
+		if r.Ratio > 0 {
+			limit = minLimit(limit, int(math.Ceil(r.Ratio*float64(textLength(source)))))
+		}
+	}
+	return limit
+}
+
+func minLimit(a, b int) int {
+	if a == 0 || b < a {
+		return b
+	}
+	return a
+}
+
+// textLength returns the length of s in characters.
+func textLength(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// CheckTranslationLength reports a translation longer than limit (0 = no
+// limit).
+func CheckTranslationLength(translation string, limit int) []CheckIssue {
+	if limit <= 0 {
+		return nil
+	}
+	if n := textLength(translation); n > limit {
+		return []CheckIssue{{
+			Rule:     CheckLength,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("translation is %d characters, limit is %d", n, limit),
+		}}
+	}
+	return nil
+}
+
+// kvLengthHinter is implemented by key-value files that declare maximum
+// lengths of their strings (Android maxLength, ARB metadata).
+type kvLengthHinter interface {
+	MaxLengths() map[string]int
+}
+
+// KVMaxLengths returns the maximum lengths declared by file, keyed by key,
+// or nil if the format has none.
+func KVMaxLengths(file formatfile.KVFile) map[string]int {
+	if h, ok := file.(kvLengthHinter); ok {
+		return h.MaxLengths()
+	}
+	return nil
+}
+
+// lengthLimit returns the maximum length of the translation of key.
+func (o *Options) lengthLimit(key, source string) int {
+	return o.LengthLimits.Limit(key, source, o.lengthHints[key])
+}
+
+// lengthItem is one translated string checked against its limit.
+type lengthItem struct {
+	id, key, source string
+}
+
+// lengthPrompt returns the user prompt section listing the limits of items,
+// or "" if none is limited.
+func lengthPrompt(items []lengthItem, opts Options) string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, it := range items {
+		limit := opts.lengthLimit(it.key, it.source)
+		if limit == 0 || seen[it.id] {
+			continue
+		}
+		seen[it.id] = true
+		if b.Len() == 0 {
+			b.WriteString("\n\nLENGTH LIMITS (characters, including spaces and placeholders; abbreviate or rephrase to fit):\n")
+		}
+		fmt.Fprintf(&b, "- %s: at most %d\n", it.id, limit)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// lengthError lists translations exceeding their length limits.
+type lengthError struct {
+	over []string
+}
+
+func (e *lengthError) Error() string {
+	return "translations too long — shorten them to fit their limits, keeping the meaning (abbreviate or rephrase): " + strings.Join(e.over, "; ")
+}
+
+// validateLengths checks translations[i] against the limit of items[i].
+func validateLengths(items []lengthItem, translations []string, opts Options) error {
+	var over []string
+	for i, it := range items {
+		if i >= len(translations) {
+			break
+		}
+		limit := opts.lengthLimit(it.key, it.source)
+		if n := textLength(translations[i]); limit > 0 && n > limit {
+			over = append(over, fmt.Sprintf("%s is %d characters, limit %d", it.id, n, limit))
+		}
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	return &lengthError{over: over}
+}
+
+// keepOverLength accepts translations over their length limit on the last
+// attempt: a too-long translation is still better than none, and it is
+// reported by lokit check and lokit status.
+func keepOverLength(err error, attempt, maxRetries int, opts Options) error {
+	le, ok := err.(*lengthError)
+	if !ok || attempt < maxRetries {
+		return err
+	}
+	opts.log("  Keeping %d translation(s) over their length limit: %s", len(le.over), strings.Join(le.over, "; "))
+	return nil
+}
+
+// poLengthItems returns the length items of singular PO entries.
+func poLengthItems(entries []*po.Entry, ids []string) []lengthItem {
+	items := make([]lengthItem, len(entries))
+	for i, e := range entries {
+		items[i] = lengthItem{id: ids[i], key: e.MsgID, source: e.MsgID}
+	}
+	return items
+}
+
+// validatePOPluralLengths checks every form of PO entries that may be
+// plural; form 0 is limited relative to msgid, others to msgid_plural.
+func validatePOPluralLengths(entries []*po.Entry, ids []string, translations []pluralTranslation, opts Options) error {
+	var items []lengthItem
+	var values []string
+	for i, e := range entries {
+		if i >= len(translations) {
+			break
+		}
+		if e.MsgIDPlural == "" {
+			items = append(items, lengthItem{id: ids[i], key: e.MsgID, source: e.MsgID})
+			values = append(values, translations[i].singular)
+			continue
+		}
+		for form, value := range translations[i].plural {
+			source := e.MsgIDPlural
+			if form == 0 {
+				source = e.MsgID
+			}
+			items = append(items, lengthItem{id: fmt.Sprintf("%s[%d]", ids[i], form), key: e.MsgID, source: source})
+			values = append(values, value)
+		}
+	}
+	return validateLengths(items, values, opts)
+}
+
+// kvLengthItems returns the length items of key-value keys.
+func kvLengthItems(keys, ids []string, srcVals map[string]string) []lengthItem {
+	items := make([]lengthItem, len(keys))
+	for i, key := range keys {
+		items[i] = lengthItem{id: ids[i], key: key, source: kvSource(key, srcVals)}
+	}
+	return items
+}
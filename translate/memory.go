@@ -0,0 +1,174 @@
+package translate
+
+import (
+	formatfile "github.com/minios-linux/lokit/format"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/tm"
+)
+
+// kvValueGetter is implemented by key-value files that can report the current
+// translation of a key. It is used to seed the translation memory from
+// existing translated files.
+type kvValueGetter interface {
+	Get(key string) (string, bool)
+}
+
+// useMemory reports whether the translation memory should be consulted.
+// --all and --force ask for fresh provider translations, so the memory is
+// only updated, never read, in those modes.
+func (o *Options) useMemory() bool {
+	return o.Memory != nil && !o.RetranslateExisting && !o.ForceTranslate
+}
+
+// ---------------------------------------------------------------------------
+// PO entries
+// ---------------------------------------------------------------------------
+
+func poMemoryKey(e *po.Entry) (string, bool) {
+	if e == nil || e.MsgID == "" || e.MsgIDPlural != "" || e.Obsolete {
+		return "", false
+	}
+	return tm.SourceKey(e.MsgID, e.MsgCtxt), true
+}
+
+// seedMemoryFromPO adds every translated, non-fuzzy singular entry of a PO
+// file to the translation memory without overriding existing memory entries.
+func seedMemoryFromPO(poFile *po.File, lang string, opts Options) {
+	if opts.Memory == nil || poFile == nil {
+		return
+	}
+	for _, e := range poFile.Entries {
+		key, ok := poMemoryKey(e)
+		if !ok || !e.IsTranslated() || e.IsFuzzy() {
+			continue
+		}
+		opts.Memory.Seed(lang, key, e.MsgStr)
+	}
+}
+
+// reuseMemoryForPO fills entries that have a translation memory match and
+// returns the entries that still need a provider translation along with
+// the reused ones.
+func reuseMemoryForPO(entries []*po.Entry, opts Options) (remaining, reused []*po.Entry) {
+	if !opts.useMemory() {
+		return entries, nil
+	}
+	for _, e := range entries {
+		key, ok := poMemoryKey(e)
+		if !ok {
+			remaining = append(remaining, e)
+			continue
+		}
+		translation, ok := opts.Memory.Lookup(opts.Language, key)
+		if !ok {
+			remaining = append(remaining, e)
+			continue
+		}
+		applyTranslations([]*po.Entry{e}, []string{translation}, opts.TranslateFuzzy)
+		markReviewForPO([]*po.Entry{e}, review.MemoryModelOf(opts.Memory.Model(opts.Language, key)), opts)
+		reused = append(reused, e)
+	}
+	return remaining, reused
+}
+
+// updateMemoryForPO records freshly translated PO entries in the memory,
+// along with the model that translated them. Like key-value translations,
+// they are recorded whether or not review marking made them fuzzy.
+func updateMemoryForPO(entries []*po.Entry, model string, opts Options) {
+	if opts.Memory == nil {
+		return
+	}
+	for _, e := range entries {
+		key, ok := poMemoryKey(e)
+		if !ok || e.MsgStr == "" {
+			continue
+		}
+		opts.Memory.AddFrom(opts.Language, key, e.MsgStr, model)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Key-value entries
+// ---------------------------------------------------------------------------
+
+func kvMemoryKey(file formatfile.KVFile, key string, srcVals map[string]string) (string, bool) {
+	if f, ok := file.(formatfile.PluralKVFile); ok {
+		if _, _, plural := f.PluralKey(key); plural {
+			return "", false
+		}
+	}
+	source := key
+	if srcVals != nil {
+		source = srcVals[key]
+	}
+	if source == "" {
+		return "", false
+	}
+	return source, true
+}
+
+// seedMemoryFromKV adds existing translations of a key-value file to the
+// memory. Values identical to their source are skipped: they are usually
+// untranslated copies rather than accepted translations. Values rejected in
+// review are skipped too.
+func seedMemoryFromKV(file formatfile.KVFile, srcVals map[string]string, lang, lockKeyPrefix string, opts Options) {
+	getter, ok := file.(kvValueGetter)
+	if opts.Memory == nil || !ok {
+		return
+	}
+	opts.Language = lang
+	for _, key := range file.Keys() {
+		source, ok := kvMemoryKey(file, key, srcVals)
+		if !ok || isKVRejected(key, lockKeyPrefix, opts) {
+			continue
+		}
+		value, ok := getter.Get(key)
+		if !ok || value == "" || value == source {
+			continue
+		}
+		opts.Memory.Seed(lang, source, value)
+	}
+}
+
+// reuseMemoryForKV sets keys that have a translation memory match, marks
+// them for review and returns the keys that still need a provider
+// translation along with the reused ones.
+func reuseMemoryForKV(file formatfile.KVFile, keys []string, srcVals map[string]string, lockKeyPrefix string, opts Options) (remaining, reused []string) {
+	if !opts.useMemory() {
+		return keys, nil
+	}
+	for _, key := range keys {
+		source, ok := kvMemoryKey(file, key, srcVals)
+		if !ok {
+			remaining = append(remaining, key)
+			continue
+		}
+		translation, ok := opts.Memory.Lookup(opts.Language, source)
+		if !ok || !file.Set(key, translation) {
+			remaining = append(remaining, key)
+			continue
+		}
+		markReviewForKV(file, []string{key}, srcVals, lockKeyPrefix, review.MemoryModelOf(opts.Memory.Model(opts.Language, source)), opts)
+		reused = append(reused, key)
+	}
+	return remaining, reused
+}
+
+// updateMemoryForKV records freshly translated key-value pairs in the
+// memory, along with the model that translated them.
+func updateMemoryForKV(file formatfile.KVFile, keys []string, srcVals map[string]string, translations []string, model string, opts Options) {
+	if opts.Memory == nil {
+		return
+	}
+	for i, key := range keys {
+		if i >= len(translations) || translations[i] == "" {
+			continue
+		}
+		source, ok := kvMemoryKey(file, key, srcVals)
+		if !ok {
+			continue
+		}
+		opts.Memory.AddFrom(opts.Language, source, translations[i], model)
+	}
+}
@@ -0,0 +1,295 @@
+package translate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	arbfile "github.com/minios-linux/lokit/format/arb"
+)
+
+// ---------------------------------------------------------------------------
+// Mock provider (offline pseudo-translation)
+// ---------------------------------------------------------------------------
+
+// Mock provider models.
+const (
+	// MockModelPseudo produces bracketed, accented, length-expanded
+	// pseudo-translations ("[Śàvé ··]").
+	MockModelPseudo = "pseudo"
+	// MockModelEcho returns the source text unchanged.
+	MockModelEcho = "echo"
+)
+
+var (
+	mockIDLine          = regexp.MustCompile(`^ID ((?:kv|msg)-[0-9a-f]+(?:-\d+)?): (.*)$`)
+	mockPOPlural        = regexp.MustCompile(`^singular: "(.*)" \| plural: "(.*)"$`)
+	mockKVPluralForm    = regexp.MustCompile(`(zero|one|two|few|many|other): "(.*?)"(?: \| |$)`)
+	mockPOPluralCount   = regexp.MustCompile(`^\s+\(return an array of exactly (\d+) plural forms`)
+	mockKVPluralForms   = regexp.MustCompile(`^\s+\(return the forms: ([a-z, ]+)\)`)
+	mockMarkdownRetry   = regexp.MustCompile(`(?:\\n)*Return \[\{"id":.*$`)
+	mockICUCategories   = regexp.MustCompile(`plural categories of the target language: ([a-z, ]+) \(`)
+	mockGlossaryKeep    = regexp.MustCompile(`^  ("(?:[^"\\]|\\.)*")$`)
+	mockGlossaryTerm    = regexp.MustCompile(`^  ("(?:[^"\\]|\\.)*") → ("(?:[^"\\]|\\.)*")$`)
+	mockProtectedSpan   = regexp.MustCompile(`__LOKIT_CODE_BLOCK_\d+__|<[^<>]+>|&[A-Za-z0-9#]+;|https?://[^\s)]+|` + "`[^`]*`" + `|\{\{[^{}]*\}\}|\{[^{}]*\}|` + applePlaceholder.String() + `|` + printfPlaceholder.String() + `|` + qtPlaceholder.String())
+	mockMarkdownHeading = regexp.MustCompile(`^#{1,6}\s+`)
+)
+
+var mockAccents = map[rune]rune{
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'í',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ó', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'ú', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Á', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Í',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ó', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Ú', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// mockTranslator turns source strings into deterministic pseudo-translations.
+type mockTranslator struct {
+	echo bool
+	// keep lists glossary terms that must be copied verbatim.
+	keep []string
+	// terms maps glossary source terms to their required translations.
+	terms map[string]string
+	// icuCategories are the plural categories requested for ICU messages.
+	icuCategories []string
+}
+
+// callMock answers a translation request locally. It reads the IDs and
+// source strings from the user prompt and returns an identified JSON
+// response, so every pipeline can run without credentials or network.
+// Token usage is estimated at four characters per token.
+func callMock(prov Provider, systemPrompt, userPrompt string) (string, TokenUsage, error) {
+	m := newMockTranslator(prov.Model, systemPrompt, userPrompt)
+
+	var items []identifiedTranslation
+	lines := strings.Split(userPrompt, "\n")
+	for i, line := range lines {
+		match := mockIDLine.FindStringSubmatch(mockMarkdownRetry.ReplaceAllString(line, ""))
+		if match == nil {
+			continue
+		}
+		id, rest := match[1], match[2]
+		next := ""
+		if i+1 < len(lines) {
+			next = lines[i+1]
+		}
+
+		var value any
+		switch {
+		case mockPOPlural.MatchString(rest):
+			forms := mockPOPlural.FindStringSubmatch(rest)
+			n := 2
+			if c := mockPOPluralCount.FindStringSubmatch(next); c != nil {
+				n, _ = strconv.Atoi(c[1])
+			}
+			out := make([]string, n)
+			for j := range out {
+				src := forms[2]
+				if j == 0 {
+					src = forms[1]
+				}
+				out[j] = m.translate(unescapeMockPrompt(src))
+			}
+			value = out
+		case mockKVPluralForms.MatchString(next):
+			sources := make(map[string]string)
+			for _, f := range mockKVPluralForm.FindAllStringSubmatch(rest, -1) {
+				sources[f[1]] = unescapeMockPrompt(f[2])
+			}
+			out := make(map[string]string)
+			for _, cat := range strings.Split(mockKVPluralForms.FindStringSubmatch(next)[1], ", ") {
+				out[cat] = m.translate(pluralSourceFor(sources, cat))
+			}
+			value = out
+		default:
+			value = m.translate(unescapeMockPrompt(strings.TrimSuffix(strings.TrimPrefix(rest, `"`), `"`)))
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", TokenUsage{}, fmt.Errorf("mock provider: %w", err)
+		}
+		items = append(items, identifiedTranslation{ID: id, Translation: raw})
+	}
+	if len(items) == 0 {
+		return "", TokenUsage{}, fmt.Errorf("mock provider: no identified source strings in prompt")
+	}
+
+	content, err := json.Marshal(items)
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("mock provider: %w", err)
+	}
+	usage := TokenUsage{
+		PromptTokens:     (len(systemPrompt) + len(userPrompt) + 3) / 4,
+		CompletionTokens: (len(content) + 3) / 4,
+	}
+	return string(content), usage, nil
+}
+
+func newMockTranslator(model, systemPrompt, userPrompt string) *mockTranslator {
+	m := &mockTranslator{echo: model == MockModelEcho, terms: make(map[string]string)}
+	for _, line := range strings.Split(systemPrompt, "\n") {
+		if match := mockGlossaryTerm.FindStringSubmatch(line); match != nil {
+			src, err1 := strconv.Unquote(match[1])
+			dst, err2 := strconv.Unquote(match[2])
+			if err1 == nil && err2 == nil {
+				m.terms[src] = dst
+			}
+		} else if match := mockGlossaryKeep.FindStringSubmatch(line); match != nil {
+			if term, err := strconv.Unquote(match[1]); err == nil {
+				m.keep = append(m.keep, term)
+			}
+		}
+	}
+	if match := mockICUCategories.FindStringSubmatch(userPrompt); match != nil {
+		m.icuCategories = strings.Split(match[1], ", ")
+	}
+	return m
+}
+
+func unescapeMockPrompt(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(s)
+}
+
+// translate returns the pseudo-translation of one source string.
+func (m *mockTranslator) translate(src string) string {
+	if isICUSelectMessage(src) {
+		if msg, err := arbfile.ParseICU(src); err == nil {
+			m.translateICU(msg)
+			return m.wrap(msg.String(), m.expansion(src))
+		}
+	}
+	lead := len(src) - len(strings.TrimLeftFunc(src, unicode.IsSpace))
+	trail := len(strings.TrimRightFunc(src, unicode.IsSpace))
+	if trail <= lead {
+		return src
+	}
+	body := src[lead:trail]
+	heading := mockMarkdownHeading.FindString(body)
+	body = body[len(heading):]
+	return src[:lead] + heading + m.wrap(m.text(body), m.expansion(body)) + src[trail:]
+}
+
+func (m *mockTranslator) translateICU(msg *arbfile.ICUMessage) {
+	for i := range msg.Parts {
+		p := &msg.Parts[i]
+		if p.Kind == arbfile.ICUText {
+			p.Text = m.text(p.Text)
+		}
+		var other *arbfile.ICUMessage
+		have := make(map[string]bool)
+		for _, b := range p.Branches {
+			m.translateICU(b.Message)
+			have[b.Selector] = true
+			if b.Selector == "other" {
+				other = b.Message
+			}
+		}
+		if p.Kind != arbfile.ICUPlural || other == nil {
+			continue
+		}
+		for _, cat := range m.icuCategories {
+			if !have[cat] {
+				p.Branches = append(p.Branches, arbfile.ICUBranch{Selector: cat, Message: other})
+			}
+		}
+	}
+}
+
+// wrap brackets and pads a pseudo-translation; echo mode returns s as is.
+func (m *mockTranslator) wrap(s string, pad int) string {
+	if m.echo {
+		return s
+	}
+	if pad > 0 {
+		s += " " + strings.Repeat("·", pad)
+	}
+	return "[" + s + "]"
+}
+
+// expansion returns the padding length that makes a pseudo-translation
+// about 30% longer than its source, as many languages are.
+func (m *mockTranslator) expansion(src string) int {
+	return (utf8.RuneCountInString(src)*3 + 9) / 10
+}
+
+// text accents letters outside placeholders, tags and glossary terms, and
+// applies glossary term translations.
+func (m *mockTranslator) text(s string) string {
+	type span struct {
+		end         int
+		replacement string
+		replace     bool
+	}
+	spans := make(map[int]span)
+	for _, r := range mockProtectedSpan.FindAllStringIndex(s, -1) {
+		spans[r[0]] = span{end: r[1]}
+	}
+	for _, term := range m.keep {
+		for _, r := range termSpans(s, term) {
+			spans[r[0]] = span{end: r[1]}
+		}
+	}
+	for src, dst := range m.terms {
+		for _, r := range termSpans(s, src) {
+			spans[r[0]] = span{end: r[1], replacement: dst, replace: true}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if sp, ok := spans[i]; ok && sp.end > i {
+			if sp.replace {
+				b.WriteString(sp.replacement)
+			} else {
+				b.WriteString(s[i:sp.end])
+			}
+			i = sp.end
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if a, ok := mockAccents[c]; ok && !m.echo {
+			b.WriteRune(a)
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// termSpans returns the byte ranges of whole-word, case-insensitive
+// occurrences of term in s, matching the glossary check.
+func termSpans(s, term string) [][]int {
+	if term == "" {
+		return nil
+	}
+	text, needle := strings.ToLower(s), strings.ToLower(term)
+	if len(text) != len(s) {
+		// Case folding changed byte offsets; fall back to exact matching.
+		text, needle = s, term
+	}
+	var spans [][]int
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], needle)
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		end := start + len(needle)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			spans = append(spans, []int{start, end})
+			offset = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		offset = start + size
+	}
+	return spans
+}
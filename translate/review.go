@@ -0,0 +1,124 @@
+package translate
+
+import (
+	formatfile "github.com/minios-linux/lokit/format"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/review"
+)
+
+// ---------------------------------------------------------------------------
+// Review provenance
+// ---------------------------------------------------------------------------
+
+// reviewModel identifies the provider at index idx of the fallback chain
+// and its model in review records. idx is the index returned by call.
+func (o *Options) reviewModel(idx int) string {
+	prov := o.Provider
+	if o.Providers != nil && idx < len(o.Providers.providers) {
+		prov = o.Providers.providers[idx]
+	}
+	return prov.ID + "/" + prov.Model
+}
+
+// markReviewForPO adds the machine translation comment to translated
+// entries. It is a no-op unless opts.Review is set.
+func markReviewForPO(entries []*po.Entry, model string, opts Options) {
+	if opts.Review == nil {
+		return
+	}
+	for _, e := range entries {
+		if e == nil || e.MsgID == "" || !hasPOTranslation(e) {
+			continue
+		}
+		review.MarkPO(e, model, opts.ReviewFuzzy)
+	}
+}
+
+// awaitingReview reports whether e is a machine translation that review
+// marking made fuzzy. Such entries are not translated again before they
+// are reviewed, unless every entry is retranslated.
+func awaitingReview(e *po.Entry) bool {
+	if !e.IsFuzzy() || !hasPOTranslation(e) {
+		return false
+	}
+	_, ok := review.POModel(e)
+	return ok
+}
+
+// FuzzyEntries returns the fuzzy entries of f that a run with fuzzy
+// translation enabled sends to the provider: machine translations awaiting
+// review are left out.
+func FuzzyEntries(f *po.File) []*po.Entry {
+	var result []*po.Entry
+	for _, e := range f.FuzzyEntries() {
+		if !awaitingReview(e) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// hasPOTranslation reports whether e has a translation, ignoring the fuzzy
+// flag (which review marking may set).
+func hasPOTranslation(e *po.Entry) bool {
+	if e.MsgIDPlural == "" {
+		return e.MsgStr != ""
+	}
+	for _, v := range e.MsgStrPlural {
+		if v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// markReviewForKV records the current values of keys as machine
+// translations pending review.
+func markReviewForKV(file formatfile.KVFile, keys []string, srcVals map[string]string, lockKeyPrefix, model string, opts Options) {
+	getter, ok := file.(kvValueGetter)
+	if opts.Review == nil || !ok {
+		return
+	}
+	target := lockfile.LockTargetKey(opts.LockTarget, opts.Language)
+	for _, key := range keys {
+		value, ok := getter.Get(key)
+		if !ok || value == "" {
+			continue
+		}
+		source := key
+		if v := srcVals[key]; v != "" {
+			source = v
+		}
+		opts.Review.Mark(target, scopedLockKey(lockKeyPrefix, key), source, value, model)
+	}
+}
+
+// isKVRejected reports whether the machine translation of key was rejected
+// in review.
+func isKVRejected(key, lockKeyPrefix string, opts Options) bool {
+	if opts.Review == nil {
+		return false
+	}
+	return opts.Review.IsRejected(lockfile.LockTargetKey(opts.LockTarget, opts.Language), scopedLockKey(lockKeyPrefix, key))
+}
+
+// withRejectedKeys appends keys whose machine translation was rejected in
+// review. They are translated again even though they have a value and the
+// lock file considers their source unchanged.
+func withRejectedKeys(keys []string, file formatfile.KVFile, lockKeyPrefix string, opts Options) []string {
+	if opts.Review == nil {
+		return keys
+	}
+	have := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		have[key] = true
+	}
+	for _, key := range file.Keys() {
+		if have[key] || isKeyIgnored(key, opts) || isKeyLocked(key, opts) || !isKVRejected(key, lockKeyPrefix, opts) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -25,17 +26,20 @@ import (
 	"time"
 
 	"github.com/minios-linux/lokit/copilot"
+	"github.com/minios-linux/lokit/format/android"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/i18next"
+	mdfile "github.com/minios-linux/lokit/format/markdown"
+	po "github.com/minios-linux/lokit/format/po"
+	propfile "github.com/minios-linux/lokit/format/properties"
+	"github.com/minios-linux/lokit/format/vuei18n"
+	yamlfile "github.com/minios-linux/lokit/format/yaml"
 	"github.com/minios-linux/lokit/gemini"
-	"github.com/minios-linux/lokit/internal/format/android"
-	arbfile "github.com/minios-linux/lokit/internal/format/arb"
-	"github.com/minios-linux/lokit/internal/format/i18next"
-	mdfile "github.com/minios-linux/lokit/internal/format/markdown"
-	po "github.com/minios-linux/lokit/internal/format/po"
-	propfile "github.com/minios-linux/lokit/internal/format/properties"
-	"github.com/minios-linux/lokit/internal/format/vuei18n"
-	yamlfile "github.com/minios-linux/lokit/internal/format/yaml"
+	"github.com/minios-linux/lokit/journal"
 	"github.com/minios-linux/lokit/lockfile"
 	"github.com/minios-linux/lokit/openai"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/tm"
 )
 
 // ---------------------------------------------------------------------------
@@ -45,12 +49,14 @@ import (
 const (
 	ProviderGoogle       = "google"
 	ProviderGemini       = "gemini"
+	ProviderAnthropic    = "anthropic"
 	ProviderGroq         = "groq"
 	ProviderOpenCode     = "opencode"
 	ProviderCopilot      = "copilot"
 	ProviderOpenAI       = "openai"
 	ProviderCustomOpenAI = "custom-openai"
 	ProviderOllama       = "ollama"
+	ProviderMock         = "mock"
 	openAIAPIBaseURL     = "https://api.openai.com/v1"
 )
 
@@ -212,6 +218,13 @@ func DefaultProviders() map[string]Provider {
 			Model:   "",
 			Timeout: 120 * time.Second,
 		},
+		ProviderAnthropic: {
+			ID:      ProviderAnthropic,
+			Name:    "Anthropic",
+			BaseURL: "https://api.anthropic.com/v1",
+			Model:   "",
+			Timeout: 120 * time.Second,
+		},
 		ProviderGroq: {
 			ID:      ProviderGroq,
 			Name:    "Groq",
@@ -253,6 +266,11 @@ func DefaultProviders() map[string]Provider {
 			Model:   "",
 			Timeout: 120 * time.Second,
 		},
+		ProviderMock: {
+			ID:    ProviderMock,
+			Name:  "Mock (offline pseudo-translation)",
+			Model: MockModelPseudo,
+		},
 	}
 }
 
@@ -318,6 +336,42 @@ type Options struct {
 	IgnoredKeys []string
 	// LockedPatterns lists regex patterns; matching keys are treated as locked.
 	LockedPatterns []*regexp.Regexp
+	// Memory is the translation memory shared across targets and runs.
+	// Entries with a memory match are filled without calling the provider.
+	// If nil, the memory is neither consulted nor updated.
+	Memory *tm.Memory
+	// Review records machine translations for review: PO entries get a
+	// "# lokit: machine" translator comment and key-value translations are
+	// tracked in lokit.review. If nil, provenance is not recorded.
+	Review *review.State
+	// ReviewFuzzy also flags machine-translated PO entries as fuzzy.
+	ReviewFuzzy bool
+	// Glossary is the terminology injected into the system prompt and
+	// enforced on every translated chunk.
+	Glossary *Glossary
+	// Usage collects provider token usage per target and language and
+	// enforces its token budget. If nil, usage is not tracked.
+	Usage *UsageTracker
+	// Providers is an optional fallback chain used instead of Provider.
+	// It is shared across targets so a failed provider stays skipped.
+	Providers *ProviderChain
+	// LengthLimits caps translation lengths per key. If nil, only limits
+	// declared by the files themselves apply.
+	LengthLimits *LengthLimits
+	// lengthHints are the limits declared by the key-value file being
+	// translated, keyed by key.
+	lengthHints map[string]int
+	// SourceContextLines is the number of source lines sent on each side
+	// of a PO entry's references (0 = reference paths only).
+	SourceContextLines int
+	// SourceRoot is the directory PO references are relative to.
+	SourceRoot string
+	// Journal records every completed chunk until its file is saved, so an
+	// interrupted run can be resumed. If nil, nothing is recorded.
+	Journal *journal.Journal
+	// Resume applies journal entries left by an interrupted run before
+	// planning provider requests.
+	Resume bool
 }
 
 func (o *Options) log(format string, args ...any) {
@@ -338,8 +392,8 @@ func (o *Options) effectiveTimeout() time.Duration {
 	if o.Timeout > 0 {
 		return o.Timeout
 	}
-	if o.Provider.Timeout > 0 {
-		return o.Provider.Timeout
+	if _, prov := o.activeProvider(); prov.Timeout > 0 {
+		return prov.Timeout
 	}
 	return 120 * time.Second
 }
@@ -379,7 +433,8 @@ func (o *Options) resolvedSourceLangName() string {
 	return sourceLangName
 }
 
-// resolvedPrompt returns the system prompt with language placeholders replaced.
+// resolvedPrompt returns the system prompt with language placeholders replaced
+// and the glossary for the target language appended.
 func (o *Options) resolvedPrompt() string {
 	prompt := o.SystemPrompt
 	if prompt == "" {
@@ -395,7 +450,11 @@ func (o *Options) resolvedPrompt() string {
 	}
 	sourceLangName := o.resolvedSourceLangName()
 	prompt = strings.ReplaceAll(prompt, "{{targetLang}}", langName)
-	return strings.ReplaceAll(prompt, "{{sourceLang}}", sourceLangName)
+	prompt = strings.ReplaceAll(prompt, "{{sourceLang}}", sourceLangName)
+	if glossary := o.glossaryPrompt(); glossary != "" {
+		prompt += "\n\n" + glossary
+	}
+	return prompt
 }
 
 // ---------------------------------------------------------------------------
@@ -705,12 +764,13 @@ func extractResponseText(body []byte) (string, error) {
 	return "", fmt.Errorf("could not extract text from response: %s", truncate(string(body), 500))
 }
 
-func extractStreamingResponseText(body []byte) (string, error) {
+func extractStreamingResponseText(body []byte) (string, TokenUsage, error) {
 	scanner := bufio.NewScanner(bytes.NewReader(body))
 	// OpenAI/Codex chunks can be larger than Scanner's default token limit.
 	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 
 	var out strings.Builder
+	var usage TokenUsage
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if !strings.HasPrefix(line, "data:") {
@@ -739,21 +799,22 @@ func extractStreamingResponseText(body []byte) (string, error) {
 			if resp, ok := raw["response"]; ok {
 				respBody, err := json.Marshal(resp)
 				if err == nil {
+					usage = extractUsage(respBody)
 					text, err := extractResponseText(respBody)
 					if err == nil && text != "" {
-						return text, nil
+						return text, usage, nil
 					}
 				}
 			}
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("reading streaming response: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("reading streaming response: %w", err)
 	}
 	if text := out.String(); text != "" {
-		return text, nil
+		return text, usage, nil
 	}
-	return "", fmt.Errorf("could not extract text from streaming response: %s", truncate(string(body), 500))
+	return "", TokenUsage{}, fmt.Errorf("could not extract text from streaming response: %s", truncate(string(body), 500))
 }
 
 // ---------------------------------------------------------------------------
@@ -793,12 +854,80 @@ func parseRetryDelay(body []byte) time.Duration {
 	return defaultDelay
 }
 
+// statusOverloaded is the non-standard status Anthropic returns with an
+// overloaded_error when the API is temporarily over capacity.
+const statusOverloaded = 529
+
+// parseRetryAfter reads the standard Retry-After header (delay in seconds
+// or an HTTP date), as sent by Anthropic and most OpenAI-compatible APIs.
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// apiErrorDetail extracts the error type and message from an error response
+// body such as Anthropic's {"type":"error","error":{"type":...,"message":...}}
+// or the OpenAI/Google {"error":{"message":...}} shape.
+func apiErrorDetail(body []byte) (errType, message string) {
+	var errResp struct {
+		Error struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return "", ""
+	}
+	errType = errResp.Error.Type
+	if errType == "" {
+		errType = errResp.Error.Status
+	}
+	return errType, errResp.Error.Message
+}
+
+// describeAPIError formats an error response body for display, falling back
+// to the (truncated) raw body when it is not a recognized JSON error.
+func describeAPIError(body []byte) string {
+	errType, message := apiErrorDetail(body)
+	switch {
+	case errType != "" && message != "":
+		return errType + ": " + message
+	case message != "":
+		return message
+	default:
+		return truncate(string(body), 500)
+	}
+}
+
+// isOverloaded reports whether a response signals temporary provider
+// overload (Anthropic's 529 overloaded_error), which is worth retrying.
+func isOverloaded(status int, body []byte) bool {
+	if status == statusOverloaded {
+		return true
+	}
+	errType, _ := apiErrorDetail(body)
+	return errType == "overloaded_error"
+}
+
 // ---------------------------------------------------------------------------
 // Provider-specific API call dispatch
 // ---------------------------------------------------------------------------
 
 // callProvider sends a prompt to the configured provider and returns the response text.
-func callProvider(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callProvider(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	switch prov.ID {
 	case ProviderGoogle:
 		// Use OAuth if no API key but Gemini token is available
@@ -809,6 +938,8 @@ func callProvider(ctx context.Context, prov Provider, systemPrompt, userPrompt s
 	case ProviderGemini:
 		// Gemini OAuth (Code Assist) — always uses OAuth
 		return callGeminiOAuth(ctx, prov, systemPrompt, userPrompt, rl, maxRetries, verbose)
+	case ProviderAnthropic:
+		return callHTTPProvider(ctx, prov, systemPrompt, userPrompt, formatAnthropic, rl, maxRetries, verbose)
 	case ProviderGroq:
 		return callHTTPProvider(ctx, prov, systemPrompt, userPrompt, formatOpenAIChat, rl, maxRetries, verbose)
 	case ProviderOpenCode:
@@ -821,16 +952,18 @@ func callProvider(ctx context.Context, prov Provider, systemPrompt, userPrompt s
 		return callHTTPProvider(ctx, prov, systemPrompt, userPrompt, formatOpenAIChat, rl, maxRetries, verbose)
 	case ProviderOllama:
 		return callHTTPProvider(ctx, prov, systemPrompt, userPrompt, formatOllamaNative, rl, maxRetries, verbose)
+	case ProviderMock:
+		return callMock(prov, systemPrompt, userPrompt)
 	default:
 		// Fallback: treat as OpenAI-compatible
 		return callHTTPProvider(ctx, prov, systemPrompt, userPrompt, formatOpenAIChat, rl, maxRetries, verbose)
 	}
 }
 
-func callOpenAI(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callOpenAI(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	if prov.APIKey == "" {
 		if !openai.IsOAuthModel(prov.Model) {
-			return "", fmt.Errorf("OpenAI OAuth/device auth does not support model %q in this mode; choose an OAuth-compatible OpenAI model or use an API key", prov.Model)
+			return "", TokenUsage{}, fmt.Errorf("OpenAI OAuth/device auth does not support model %q in this mode; choose an OAuth-compatible OpenAI model or use an API key", prov.Model)
 		}
 		return callOpenAIOAuth(ctx, prov, systemPrompt, userPrompt, rl, maxRetries, verbose)
 	}
@@ -849,15 +982,15 @@ func callOpenAI(ctx context.Context, prov Provider, systemPrompt, userPrompt str
 	return callHTTPProvider(ctx, prov, systemPrompt, userPrompt, format, rl, maxRetries, verbose)
 }
 
-func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	info, err := openai.EnsureAuth(ctx)
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, err
 	}
 
 	body, err := buildOpenAIResponsesRequest(prov.Model, systemPrompt, userPrompt, true)
 	if err != nil {
-		return "", fmt.Errorf("building request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("building request: %w", err)
 	}
 
 	client := makeHTTPClient(prov.Proxy, prov.Timeout)
@@ -865,19 +998,19 @@ func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if rl != nil {
 			if err := rl.waitIfPaused(ctx); err != nil {
-				return "", err
+				return "", TokenUsage{}, err
 			}
 		}
 
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", TokenUsage{}, ctx.Err()
 		default:
 		}
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodPost, openai.CodexResponsesEndpoint, bytes.NewReader(body))
 		if err != nil {
-			return "", fmt.Errorf("creating request: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("creating request: %w", err)
 		}
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+info.Access)
@@ -895,12 +1028,12 @@ func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("API request failed: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("API request failed: %w", err)
 		}
 
 		respBody, _ := io.ReadAll(resp.Body)
@@ -923,7 +1056,7 @@ func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(retryDelay):
 				}
 				if rl != nil {
@@ -931,7 +1064,7 @@ func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 				}
 				continue
 			}
-			return "", fmt.Errorf("rate limited after %d retries: %s", maxRetries, string(respBody))
+			return "", TokenUsage{}, newAPIError(http.StatusTooManyRequests, "rate limited after %d retries: %s", maxRetries, string(respBody))
 		}
 
 		if resp.StatusCode != http.StatusOK {
@@ -939,32 +1072,28 @@ func callOpenAIOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
 		}
 
-		text, err := extractStreamingResponseText(respBody)
-		if err != nil {
-			return "", err
-		}
-		return text, nil
+		return extractStreamingResponseText(respBody)
 	}
 
-	return "", fmt.Errorf("exhausted all %d retries", maxRetries)
+	return "", TokenUsage{}, fmt.Errorf("exhausted all %d retries", maxRetries)
 }
 
 // ---------------------------------------------------------------------------
 // HTTP-based provider call (Google, Groq, Custom OpenAI, Ollama, generic)
 // ---------------------------------------------------------------------------
 
-func callHTTPProvider(ctx context.Context, prov Provider, systemPrompt, userPrompt string, format apiFormat, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callHTTPProvider(ctx context.Context, prov Provider, systemPrompt, userPrompt string, format apiFormat, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	endpoint, headers, body, err := buildHTTPRequest(prov, systemPrompt, userPrompt, format)
 	if err != nil {
-		return "", fmt.Errorf("building request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("building request: %w", err)
 	}
 
 	client := makeHTTPClient(prov.Proxy, prov.Timeout)
@@ -973,19 +1102,19 @@ func callHTTPProvider(ctx context.Context, prov Provider, systemPrompt, userProm
 		// Wait if globally paused (rate limit from another worker)
 		if rl != nil {
 			if err := rl.waitIfPaused(ctx); err != nil {
-				return "", err
+				return "", TokenUsage{}, err
 			}
 		}
 
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", TokenUsage{}, ctx.Err()
 		default:
 		}
 
 		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
 		if err != nil {
-			return "", fmt.Errorf("creating request: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("creating request: %w", err)
 		}
 		for k, v := range headers {
 			req.Header.Set(k, v)
@@ -1001,19 +1130,24 @@ func callHTTPProvider(ctx context.Context, prov Provider, systemPrompt, userProm
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("API request failed: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("API request failed: %w", err)
 		}
 
 		respBody, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 
 		if resp.StatusCode == http.StatusTooManyRequests {
-			retryDelay := parseRetryDelay(respBody)
+			// Prefer the Retry-After header (Anthropic, OpenAI-compatible
+			// APIs); fall back to Google's RetryInfo in the body.
+			retryDelay, ok := parseRetryAfter(resp.Header)
+			if !ok {
+				retryDelay = parseRetryDelay(respBody)
+			}
 			if verbose {
 				log.Printf("[WARN] 429 rate limited, waiting %v before retry (attempt %d/%d)", retryDelay, attempt+1, maxRetries)
 			}
@@ -1024,7 +1158,7 @@ func callHTTPProvider(ctx context.Context, prov Provider, systemPrompt, userProm
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(retryDelay):
 				}
 				if rl != nil {
@@ -1032,30 +1166,40 @@ func callHTTPProvider(ctx context.Context, prov Provider, systemPrompt, userProm
 				}
 				continue
 			}
-			return "", fmt.Errorf("rate limited after %d retries: %s", maxRetries, string(respBody))
+			return "", TokenUsage{}, newAPIError(http.StatusTooManyRequests, "rate limited after %d retries: %s", maxRetries, describeAPIError(respBody))
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			if attempt < maxRetries && resp.StatusCode >= 500 {
+			overloaded := isOverloaded(resp.StatusCode, respBody)
+			if attempt < maxRetries && (resp.StatusCode >= 500 || overloaded) {
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+				if d, ok := parseRetryAfter(resp.Header); ok {
+					wait = d
+				}
+				if verbose && overloaded {
+					log.Printf("[WARN] %s overloaded, waiting %v before retry (attempt %d/%d)", prov.Name, wait, attempt+1, maxRetries)
+				}
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+			if overloaded {
+				return "", TokenUsage{}, newAPIError(resp.StatusCode, "%s overloaded after %d retries: %s", prov.Name, maxRetries, describeAPIError(respBody))
+			}
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "API returned status %d: %s", resp.StatusCode, describeAPIError(respBody))
 		}
 
 		text, err := extractResponseText(respBody)
 		if err != nil {
-			return "", err
+			return "", TokenUsage{}, err
 		}
-		return text, nil
+		return text, extractUsage(respBody), nil
 	}
 
-	return "", fmt.Errorf("exhausted all %d retries", maxRetries)
+	return "", TokenUsage{}, fmt.Errorf("exhausted all %d retries", maxRetries)
 }
 
 // buildHTTPRequest constructs the endpoint, headers, and body for an HTTP provider.
@@ -1120,7 +1264,7 @@ func buildHTTPRequest(prov Provider, systemPrompt, userPrompt string, format api
 // OpenCode provider (multi-format dispatch based on model prefix)
 // ---------------------------------------------------------------------------
 
-func callOpenCode(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callOpenCode(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	// Determine API format based on model prefix
 	model := prov.Model
 	var format apiFormat
@@ -1151,7 +1295,7 @@ func callOpenCode(ctx context.Context, prov Provider, systemPrompt, userPrompt s
 }
 
 // callGeminiViaOpenCode handles the Gemini-format call through OpenCode.
-func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	headers := map[string]string{
 		"Content-Type": "application/json",
 	}
@@ -1161,7 +1305,7 @@ func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, use
 
 	body, err := buildGeminiRequest(systemPrompt, userPrompt, providerTemperature(prov))
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, err
 	}
 
 	// prov.BaseURL already contains the full endpoint (e.g., .../models/gemini-2.5-flash)
@@ -1172,19 +1316,19 @@ func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, use
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if rl != nil {
 			if err := rl.waitIfPaused(ctx); err != nil {
-				return "", err
+				return "", TokenUsage{}, err
 			}
 		}
 
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", TokenUsage{}, ctx.Err()
 		default:
 		}
 
 		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
 		if err != nil {
-			return "", fmt.Errorf("creating request: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("creating request: %w", err)
 		}
 		for k, v := range headers {
 			req.Header.Set(k, v)
@@ -1196,12 +1340,12 @@ func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, use
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("API request failed: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("API request failed: %w", err)
 		}
 
 		respBody, _ := io.ReadAll(resp.Body)
@@ -1215,7 +1359,7 @@ func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, use
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(retryDelay):
 				}
 				if rl != nil {
@@ -1223,17 +1367,21 @@ func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, use
 				}
 				continue
 			}
-			return "", fmt.Errorf("rate limited after %d retries", maxRetries)
+			return "", TokenUsage{}, newAPIError(http.StatusTooManyRequests, "rate limited after %d retries", maxRetries)
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
 		}
 
-		return extractResponseText(respBody)
+		text, err := extractResponseText(respBody)
+		if err != nil {
+			return "", TokenUsage{}, err
+		}
+		return text, extractUsage(respBody), nil
 	}
 
-	return "", fmt.Errorf("exhausted all %d retries", maxRetries)
+	return "", TokenUsage{}, fmt.Errorf("exhausted all %d retries", maxRetries)
 }
 
 // ---------------------------------------------------------------------------
@@ -1242,17 +1390,17 @@ func callGeminiViaOpenCode(ctx context.Context, prov Provider, systemPrompt, use
 
 // callCopilot authenticates with GitHub Copilot and calls the API.
 // Uses the OpenAI chat completions format against api.githubcopilot.com.
-func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	// Ensure we have a valid token (will prompt for auth if needed)
 	accessToken, err := copilot.EnsureAuth(ctx)
 	if err != nil {
-		return "", fmt.Errorf("Copilot authentication failed: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("Copilot %w: %w", ErrAuthentication, err)
 	}
 
 	// Build OpenAI chat completions request body
 	body, err := buildOpenAIChatRequest(prov.Model, systemPrompt, userPrompt, providerTemperature(prov))
 	if err != nil {
-		return "", fmt.Errorf("building request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("building request: %w", err)
 	}
 
 	endpoint := strings.TrimRight(prov.BaseURL, "/") + "/chat/completions"
@@ -1262,19 +1410,19 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 		// Wait if globally paused (rate limit from another worker)
 		if rl != nil {
 			if err := rl.waitIfPaused(ctx); err != nil {
-				return "", err
+				return "", TokenUsage{}, err
 			}
 		}
 
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", TokenUsage{}, ctx.Err()
 		default:
 		}
 
 		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
 		if err != nil {
-			return "", fmt.Errorf("creating request: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("creating request: %w", err)
 		}
 
 		// Set Copilot-specific headers
@@ -1291,12 +1439,12 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("Copilot API request failed: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("Copilot API request failed: %w", err)
 		}
 
 		respBody, _ := io.ReadAll(resp.Body)
@@ -1311,12 +1459,12 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 				_ = copilot.DeleteToken()
 				newToken, err := copilot.EnsureAuth(ctx)
 				if err != nil {
-					return "", fmt.Errorf("Copilot re-authentication failed: %w", err)
+					return "", TokenUsage{}, fmt.Errorf("Copilot re-%w: %w", ErrAuthentication, err)
 				}
 				accessToken = newToken
 				continue
 			}
-			return "", fmt.Errorf("Copilot authentication failed (401): %s", truncate(string(respBody), 300))
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "Copilot authentication failed (401): %s", truncate(string(respBody), 300))
 		}
 
 		if resp.StatusCode == http.StatusTooManyRequests {
@@ -1330,7 +1478,7 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(retryDelay):
 				}
 				if rl != nil {
@@ -1338,7 +1486,7 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 				}
 				continue
 			}
-			return "", fmt.Errorf("Copilot rate limited after %d retries: %s", maxRetries, string(respBody))
+			return "", TokenUsage{}, newAPIError(http.StatusTooManyRequests, "Copilot rate limited after %d retries: %s", maxRetries, string(respBody))
 		}
 
 		if resp.StatusCode != http.StatusOK {
@@ -1346,7 +1494,7 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
@@ -1354,31 +1502,31 @@ func callCopilot(ctx context.Context, prov Provider, systemPrompt, userPrompt st
 
 			// Special handling for 403 Forbidden - usually geographic restrictions or no subscription
 			if resp.StatusCode == http.StatusForbidden {
-				return "", fmt.Errorf("Copilot API returned 403 Forbidden: access denied\n\n" +
-					"Common causes:\n" +
-					"  1. Geographic restrictions - GitHub Copilot may be blocked in your region\n" +
-					"  2. Copilot access not enabled for this account\n" +
-					"  3. Invalid or expired authentication token\n\n" +
-					"Solutions:\n" +
-					"  - Re-authenticate: lokit auth logout --provider copilot && lokit auth login --provider copilot\n" +
-					"  - Use proxy/VPN if geographic restrictions apply\n" +
-					"  - Try another provider:\n" +
-					"      lokit auth login --provider gemini\n" +
-					"      lokit auth login --provider google\n" +
+				return "", TokenUsage{}, newAPIError(resp.StatusCode, "Copilot API returned 403 Forbidden: access denied\n\n"+
+					"Common causes:\n"+
+					"  1. Geographic restrictions - GitHub Copilot may be blocked in your region\n"+
+					"  2. Copilot access not enabled for this account\n"+
+					"  3. Invalid or expired authentication token\n\n"+
+					"Solutions:\n"+
+					"  - Re-authenticate: lokit auth logout --provider copilot && lokit auth login --provider copilot\n"+
+					"  - Use proxy/VPN if geographic restrictions apply\n"+
+					"  - Try another provider:\n"+
+					"      lokit auth login --provider gemini\n"+
+					"      lokit auth login --provider google\n"+
 					"      lokit translate --provider ollama --model MODEL_NAME")
 			}
 
-			return "", fmt.Errorf("Copilot API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "Copilot API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
 		}
 
 		text, err := extractResponseText(respBody)
 		if err != nil {
-			return "", err
+			return "", TokenUsage{}, err
 		}
-		return text, nil
+		return text, extractUsage(respBody), nil
 	}
 
-	return "", fmt.Errorf("Copilot: exhausted all %d retries", maxRetries)
+	return "", TokenUsage{}, fmt.Errorf("Copilot: exhausted all %d retries", maxRetries)
 }
 
 // ---------------------------------------------------------------------------
@@ -1406,24 +1554,24 @@ type caGenerateContentResponse struct {
 	TraceID  string          `json:"traceId,omitempty"`
 }
 
-func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, error) {
+func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int, verbose bool) (string, TokenUsage, error) {
 	// Ensure we have a valid token with Code Assist project ID
 	token, err := gemini.EnsureAuthWithSetup(ctx)
 	if err != nil {
-		return "", fmt.Errorf("Gemini authentication failed: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("Gemini %w: %w", ErrAuthentication, err)
 	}
 	accessToken := token.Access
 
 	// Build the inner Gemini-native request body (Vertex format)
 	innerBody, err := buildGeminiRequest(systemPrompt, userPrompt, providerTemperature(prov))
 	if err != nil {
-		return "", fmt.Errorf("building request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("building request: %w", err)
 	}
 
 	// Parse inner body to embed it in the Code Assist wrapper
 	var innerReq interface{}
 	if err := json.Unmarshal(innerBody, &innerReq); err != nil {
-		return "", fmt.Errorf("parsing inner request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("parsing inner request: %w", err)
 	}
 
 	// Model name: Code Assist expects bare model name (e.g. "gemini-2.5-flash"),
@@ -1439,7 +1587,7 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 	}
 	body, err := json.Marshal(caReq)
 	if err != nil {
-		return "", fmt.Errorf("marshaling Code Assist request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("marshaling Code Assist request: %w", err)
 	}
 
 	endpoint := fmt.Sprintf("%s/%s:generateContent",
@@ -1450,19 +1598,19 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 		// Wait if globally paused (rate limit from another worker)
 		if rl != nil {
 			if err := rl.waitIfPaused(ctx); err != nil {
-				return "", err
+				return "", TokenUsage{}, err
 			}
 		}
 
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", TokenUsage{}, ctx.Err()
 		default:
 		}
 
 		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
 		if err != nil {
-			return "", fmt.Errorf("creating request: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("creating request: %w", err)
 		}
 
 		// Set OAuth headers
@@ -1479,12 +1627,12 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("Gemini API request failed: %w", err)
+			return "", TokenUsage{}, fmt.Errorf("Gemini API request failed: %w", err)
 		}
 
 		respBody, _ := io.ReadAll(resp.Body)
@@ -1505,7 +1653,7 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 						_ = gemini.DeleteToken()
 						newTok, err := gemini.EnsureAuthWithSetup(ctx)
 						if err != nil {
-							return "", fmt.Errorf("Gemini re-authentication failed: %w", err)
+							return "", TokenUsage{}, fmt.Errorf("Gemini re-%w: %w", ErrAuthentication, err)
 						}
 						accessToken = newTok.Access
 					} else {
@@ -1515,13 +1663,13 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 					_ = gemini.DeleteToken()
 					newTok, err := gemini.EnsureAuthWithSetup(ctx)
 					if err != nil {
-						return "", fmt.Errorf("Gemini re-authentication failed: %w", err)
+						return "", TokenUsage{}, fmt.Errorf("Gemini re-%w: %w", ErrAuthentication, err)
 					}
 					accessToken = newTok.Access
 				}
 				continue
 			}
-			return "", fmt.Errorf("Gemini authentication failed (401): %s", truncate(string(respBody), 300))
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "Gemini authentication failed (401): %s", truncate(string(respBody), 300))
 		}
 
 		if resp.StatusCode == http.StatusTooManyRequests {
@@ -1535,7 +1683,7 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(retryDelay):
 				}
 				if rl != nil {
@@ -1543,7 +1691,7 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 				}
 				continue
 			}
-			return "", fmt.Errorf("Gemini rate limited after %d retries: %s", maxRetries, string(respBody))
+			return "", TokenUsage{}, newAPIError(http.StatusTooManyRequests, "Gemini rate limited after %d retries: %s", maxRetries, string(respBody))
 		}
 
 		if resp.StatusCode != http.StatusOK {
@@ -1551,12 +1699,12 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 				wait := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 				select {
 				case <-ctx.Done():
-					return "", ctx.Err()
+					return "", TokenUsage{}, ctx.Err()
 				case <-time.After(wait):
 				}
 				continue
 			}
-			return "", fmt.Errorf("Gemini API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+			return "", TokenUsage{}, newAPIError(resp.StatusCode, "Gemini API returned status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
 		}
 
 		// Unwrap Code Assist response envelope
@@ -1565,9 +1713,9 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 			// Fallback: try parsing as standard Vertex/Gemini response
 			text, err2 := extractResponseText(respBody)
 			if err2 != nil {
-				return "", fmt.Errorf("parsing Code Assist response: %w (raw: %s)", err, truncate(string(respBody), 300))
+				return "", TokenUsage{}, fmt.Errorf("parsing Code Assist response: %w (raw: %s)", err, truncate(string(respBody), 300))
 			}
-			return text, nil
+			return text, extractUsage(respBody), nil
 		}
 
 		// The inner "response" field contains standard Vertex AI format
@@ -1575,20 +1723,20 @@ func callGeminiOAuth(ctx context.Context, prov Provider, systemPrompt, userPromp
 		if len(caResp.Response) > 0 {
 			text, err := extractResponseText(caResp.Response)
 			if err != nil {
-				return "", fmt.Errorf("extracting text from Code Assist response: %w", err)
+				return "", TokenUsage{}, fmt.Errorf("extracting text from Code Assist response: %w", err)
 			}
-			return text, nil
+			return text, extractUsage(caResp.Response), nil
 		}
 
 		// If no "response" wrapper, try full body (backwards compat)
 		text, err := extractResponseText(respBody)
 		if err != nil {
-			return "", err
+			return "", TokenUsage{}, err
 		}
-		return text, nil
+		return text, extractUsage(respBody), nil
 	}
 
-	return "", fmt.Errorf("Gemini OAuth: exhausted all %d retries", maxRetries)
+	return "", TokenUsage{}, fmt.Errorf("Gemini OAuth: exhausted all %d retries", maxRetries)
 }
 
 // ---------------------------------------------------------------------------
@@ -1667,6 +1815,12 @@ func isGroffFontEscape(s string, slashIdx int) bool {
 	return next == '[' || next == '(' || next == 'B' || next == 'I' || next == 'R' || next == 'P' || next == 'C'
 }
 
+// NPlurals returns the number of plural forms of a PO catalog: from its
+// Plural-Forms header, or the default of lang.
+func NPlurals(poFile *po.File, lang string) int {
+	return npluralsFromFile(poFile, lang)
+}
+
 // npluralsFromFile returns the number of plural forms for a PO file by reading
 // the Plural-Forms header, falling back to the per-language default.
 func npluralsFromFile(poFile *po.File, lang string) int {
@@ -1707,7 +1861,7 @@ GETTEXT RESPONSE CONTRACT:
 - Return ONLY a JSON array of objects with exactly two fields: "id" and "translation".
 - Copy every ID exactly. Do not omit, duplicate, rename, or invent IDs.
 - "translation" must be a string, or an array of strings when plural forms are requested.
-- This contract replaces any earlier instruction to return a bare array of strings.`
+- This contract replaces any earlier instruction to return a bare array of strings.` + contextNoteLines
 }
 
 func entryTranslationIDs(entries []*po.Entry) []string {
@@ -1774,7 +1928,7 @@ func parseIdentifiedTranslations(content string, ids []string) ([]json.RawMessag
 // translateChunkWithPlurals translates a chunk of entries, correctly handling
 // plural forms. For entries that have a MsgIDPlural the AI is asked to return
 // all nplurals forms; singular entries produce a single string as before.
-func translateChunkWithPlurals(ctx context.Context, entries []*po.Entry, systemPrompt string, opts Options, rl *rateLimitState, nplurals int) ([]pluralTranslation, error) {
+func translateChunkWithPlurals(ctx context.Context, entries []*po.Entry, systemPrompt string, opts Options, rl *rateLimitState, nplurals int) ([]pluralTranslation, string, error) {
 	var userMsg strings.Builder
 	ids := entryTranslationIDs(entries)
 	systemPrompt = identifiedPOSystemPrompt(systemPrompt)
@@ -1784,6 +1938,7 @@ func translateChunkWithPlurals(ctx context.Context, entries []*po.Entry, systemP
 		userMsg.WriteString("Translate these entries:\n\n")
 	}
 
+	files := sourceFiles{}
 	for i, e := range entries {
 		if e.MsgIDPlural != "" {
 			userMsg.WriteString(fmt.Sprintf("ID %s: singular: %s | plural: %s\n",
@@ -1792,13 +1947,12 @@ func translateChunkWithPlurals(ctx context.Context, entries []*po.Entry, systemP
 		} else {
 			userMsg.WriteString(fmt.Sprintf("ID %s: %s\n", ids[i], escapeForPrompt(e.MsgID)))
 		}
-		if len(e.References) > 0 {
-			userMsg.WriteString(fmt.Sprintf("   (context: %s)\n", strings.Join(e.References, ", ")))
-		}
+		writePOEntryContext(&userMsg, e, opts, files)
 	}
 
 	userMsg.WriteString(fmt.Sprintf("\nReturn a JSON array with exactly %d objects. Each object must contain the exact input ID and a translation field. ", len(entries)))
 	userMsg.WriteString(`Use {"id":"msg-...","translation":"..."} for singular entries and {"id":"msg-...","translation":["...","..."]} for plural entries. Preserve every ID exactly; do not omit, duplicate, or invent IDs.`)
+	userMsg.WriteString(lengthPrompt(poLengthItems(entries, ids), opts))
 
 	maxRetries := opts.effectiveMaxRetries()
 	var lastErr error
@@ -1807,26 +1961,36 @@ func translateChunkWithPlurals(ctx context.Context, entries []*po.Entry, systemP
 		if lastErr != nil {
 			prompt += fmt.Sprintf("\n\nYour previous response was rejected: %v\nReturn a corrected complete response using the required IDs and JSON shape.", lastErr)
 		}
-		text, err := callProvider(ctx, opts.Provider, systemPrompt, prompt, rl, maxRetries, opts.Verbose)
+		text, idx, err := opts.call(ctx, systemPrompt, prompt, rl, maxRetries)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		translations, err := parseIdentifiedPluralTranslations(text, entries, ids, nplurals)
 		if err == nil {
 			err = validatePOPluralTranslations(entries, translations)
 		}
 		if err == nil {
-			return translations, nil
+			err = validatePOPluralGlossary(entries, translations, opts)
+		}
+		if err == nil {
+			err = keepOverLength(validatePOPluralLengths(entries, ids, translations, opts), attempt, maxRetries, opts)
+		}
+		if err == nil {
+			return translations, opts.reviewModel(idx), nil
 		}
 		lastErr = err
 		if attempt < maxRetries {
 			opts.log("  Invalid translation response, retrying (%d/%d): %v", attempt+1, maxRetries, err)
 			if err := waitBeforeParseRetry(ctx, attempt); err != nil {
-				return nil, err
+				return nil, "", err
 			}
+		} else if opts.fallbackAfterInvalidResponses(ctx, idx, err) {
+			// Start over with the next provider and a fresh prompt.
+			lastErr = nil
+			attempt = -1
 		}
 	}
-	return nil, lastErr
+	return nil, "", lastErr
 }
 
 func parseIdentifiedPluralTranslations(content string, entries []*po.Entry, ids []string, nplurals int) ([]pluralTranslation, error) {
@@ -2039,6 +2203,7 @@ type translationTask struct {
 	entries    []*po.Entry
 	poFile     *po.File
 	poPath     string
+	save       func(*po.File) error
 	lockTarget string
 }
 
@@ -2080,23 +2245,30 @@ func Translate(ctx context.Context, poFile *po.File, opts Options) error {
 			opts.log("  Chunk %d/%d (%d entries)", i+1, len(chunks), len(chunk))
 		}
 
+		var model string
 		if hasPluralEntries(chunk) {
 			// Use plural-aware path when any entry in the chunk has a plural form
-			translations, err := translateChunkWithPlurals(ctx, chunk, systemPrompt, opts, rl, nplurals)
+			translations, m, err := translateChunkWithPlurals(ctx, chunk, systemPrompt, opts, rl, nplurals)
 			if err != nil {
 				return fmt.Errorf("translating chunk %d/%d: %w", i+1, len(chunks), err)
 			}
 			applyPluralTranslations(chunk, translations, opts.TranslateFuzzy)
+			model = m
 		} else {
-			translations, err := translateChunk(ctx, chunk, systemPrompt, opts, rl)
+			translations, m, err := translateChunk(ctx, chunk, systemPrompt, opts, rl)
 			if err != nil {
 				return fmt.Errorf("translating chunk %d/%d: %w", i+1, len(chunks), err)
 			}
 			applyTranslations(chunk, translations, opts.TranslateFuzzy)
+			model = m
 		}
+		markReviewForPO(chunk, model, opts)
 
-		// Update lock file checksums for successfully translated entries
+		// Update lock file checksums and translation memory for successfully
+		// translated entries
 		updateLockFileForPO(chunk, opts)
+		updateMemoryForPO(chunk, model, opts)
+		recordJournalForPO(chunk, model, opts)
 
 		done += len(chunk)
 		if opts.OnProgress != nil {
@@ -2133,7 +2305,7 @@ func collectEntries(poFile *po.File, opts Options) []*po.Entry {
 		}
 		if opts.RetranslateExisting || opts.ForceTranslate {
 			toTranslate = append(toTranslate, e)
-		} else if opts.TranslateFuzzy && e.IsFuzzy() {
+		} else if opts.TranslateFuzzy && e.IsFuzzy() && !awaitingReview(e) {
 			toTranslate = append(toTranslate, e)
 		} else if !e.IsTranslated() && !e.IsFuzzy() {
 			toTranslate = append(toTranslate, e)
@@ -2178,8 +2350,9 @@ func collectEntries(poFile *po.File, opts Options) []*po.Entry {
 			// workflow can produce entries that are locked (source unchanged) yet
 			// have no MsgStr — e.g. SeedPO filled some strings but left others empty.
 			// Without this guard the lockfile would suppress those empty entries and
-			// they would never be sent to the AI provider.
-			if opts.LockFile.IsChanged(lockTarget, key, content) || !e.IsTranslated() {
+			// they would never be sent to the AI provider. Machine translations
+			// awaiting review count as translated.
+			if opts.LockFile.IsChanged(lockTarget, key, content) || (!e.IsTranslated() && !awaitingReview(e)) {
 				changed = append(changed, e)
 			}
 		}
@@ -2369,7 +2542,7 @@ func splitEntries(entries []*po.Entry, chunkSize int) [][]*po.Entry {
 
 // translateChunk translates a single chunk of entries (singular only, no plural).
 // Kept for backward compatibility with non-PO callers.
-func translateChunk(ctx context.Context, entries []*po.Entry, systemPrompt string, opts Options, rl *rateLimitState) ([]string, error) {
+func translateChunk(ctx context.Context, entries []*po.Entry, systemPrompt string, opts Options, rl *rateLimitState) ([]string, string, error) {
 	// Build the user prompt
 	var userMsg strings.Builder
 	ids := entryTranslationIDs(entries)
@@ -2379,14 +2552,15 @@ func translateChunk(ctx context.Context, entries []*po.Entry, systemPrompt strin
 	} else {
 		userMsg.WriteString("Translate these entries:\n\n")
 	}
+	files := sourceFiles{}
 	for i, e := range entries {
 		userMsg.WriteString(fmt.Sprintf("ID %s: %s\n", ids[i], escapeForPrompt(e.MsgID)))
-		if len(e.References) > 0 {
-			userMsg.WriteString(fmt.Sprintf("   (context: %s)\n", strings.Join(e.References, ", ")))
-		}
+		writePOEntryContext(&userMsg, e, opts, files)
 	}
 	userMsg.WriteString(fmt.Sprintf("\nReturn a JSON array with exactly %d objects in this form: ", len(entries)))
 	userMsg.WriteString(`{"id":"msg-...","translation":"..."}. Preserve every input ID exactly; do not omit, duplicate, or invent IDs. The objects may be returned in any order.`)
+	lengthItems := poLengthItems(entries, ids)
+	userMsg.WriteString(lengthPrompt(lengthItems, opts))
 
 	maxRetries := opts.effectiveMaxRetries()
 	var lastErr error
@@ -2395,26 +2569,36 @@ func translateChunk(ctx context.Context, entries []*po.Entry, systemPrompt strin
 		if lastErr != nil {
 			prompt += fmt.Sprintf("\n\nYour previous response was rejected: %v\nReturn a corrected complete response using the required IDs and JSON shape.", lastErr)
 		}
-		text, err := callProvider(ctx, opts.Provider, systemPrompt, prompt, rl, maxRetries, opts.Verbose)
+		text, idx, err := opts.call(ctx, systemPrompt, prompt, rl, maxRetries)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		translations, err := parseIdentifiedStringTranslations(text, ids)
 		if err == nil {
 			err = validatePOTranslations(entries, translations)
 		}
 		if err == nil {
-			return translations, nil
+			err = validatePOGlossary(entries, translations, opts)
+		}
+		if err == nil {
+			err = keepOverLength(validateLengths(lengthItems, translations, opts), attempt, maxRetries, opts)
+		}
+		if err == nil {
+			return translations, opts.reviewModel(idx), nil
 		}
 		lastErr = err
 		if attempt < maxRetries {
 			opts.log("  Invalid translation response, retrying (%d/%d): %v", attempt+1, maxRetries, err)
 			if err := waitBeforeParseRetry(ctx, attempt); err != nil {
-				return nil, err
+				return nil, "", err
 			}
+		} else if opts.fallbackAfterInvalidResponses(ctx, idx, err) {
+			// Start over with the next provider and a fresh prompt.
+			lastErr = nil
+			attempt = -1
 		}
 	}
-	return nil, lastErr
+	return nil, "", lastErr
 }
 
 func parseIdentifiedStringTranslations(content string, ids []string) ([]string, error) {
@@ -2437,7 +2621,11 @@ func parseIdentifiedStringTranslations(content string, ids []string) ([]string,
 var (
 	pythonBracePlaceholder = regexp.MustCompile(`\{[A-Za-z_][A-Za-z0-9_]*(?:![rsa])?(?::[^{}]*)?\}`)
 	printfPlaceholder      = regexp.MustCompile(`%(?:\([^)]+\))?(?:[1-9][0-9]*\$)?[#0\- +'I]*\*?(?:\.\*?|\.[0-9]+)?[hlLjztq]*[diouxXeEfFgGaAcrspn]`)
-	qtPlaceholder          = regexp.MustCompile(`%(?:[1-9][0-9]*|n)`)
+	qtPlaceholder          = regexp.MustCompile(`%L?(?:[1-9][0-9]*|n)`)
+	// applePlaceholder matches the object specifier %@ and the %#@name@
+	// variable references of Apple localized format strings, and the %arg
+	// argument of string catalog substitutions.
+	applePlaceholder = regexp.MustCompile(`%(?:[1-9][0-9]*\$)?(?:@|#@[A-Za-z_][A-Za-z0-9_]*@)|%arg\b`)
 )
 
 func validatePOTranslations(entries []*po.Entry, translations []string) error {
@@ -2706,9 +2894,39 @@ func trailingNewlineCount(s string) int {
 // Parallelization modes
 // ---------------------------------------------------------------------------
 
+// LanguageErrors is returned when some languages failed to translate; the
+// others were translated and saved. It wraps the error of each failed
+// language, so errors.Is finds e.g. ErrAuthentication through it.
+type LanguageErrors struct {
+	// Langs are the failed languages; Errs[i] is the error of Langs[i].
+	Langs []string
+	Errs  []error
+}
+
+func (e *LanguageErrors) add(lang string, err error) {
+	e.Langs = append(e.Langs, lang)
+	e.Errs = append(e.Errs, err)
+}
+
+// orNil returns e if a language failed, or nil.
+func (e *LanguageErrors) orNil() error {
+	if len(e.Langs) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *LanguageErrors) Error() string {
+	return fmt.Sprintf("%d language(s) failed: %s", len(e.Langs), strings.Join(e.Langs, ", "))
+}
+
+func (e *LanguageErrors) Unwrap() []error {
+	return e.Errs
+}
+
 // translateSequential processes languages one at a time, chunks sequentially.
 func translateSequential(ctx context.Context, tasks []translationTask, opts Options) error {
-	var failedLangs []string
+	failed := &LanguageErrors{}
 	for _, task := range tasks {
 		select {
 		case <-ctx.Done():
@@ -2732,21 +2950,21 @@ func translateSequential(ctx context.Context, tasks []translationTask, opts Opti
 
 		if err := Translate(ctx, task.poFile, taskOpts); err != nil {
 			if ctx.Err() != nil {
-				savePOFile(task.poFile, task.poPath, opts)
+				savePOFile(task.poFile, task.poPath, task.save, taskOpts)
 				return ctx.Err()
 			}
-			savePOFile(task.poFile, task.poPath, opts)
+			savePOFile(task.poFile, task.poPath, task.save, taskOpts)
+			if errors.Is(err, ErrTokenBudgetExceeded) {
+				return err
+			}
 			opts.logError("Error translating %s: %v", task.lang, err)
-			failedLangs = append(failedLangs, task.lang)
+			failed.add(task.lang, err)
 			continue
 		}
 
-		savePOFile(task.poFile, task.poPath, opts)
+		savePOFile(task.poFile, task.poPath, task.save, taskOpts)
 	}
-	if len(failedLangs) > 0 {
-		return fmt.Errorf("%d language(s) failed: %s", len(failedLangs), strings.Join(failedLangs, ", "))
-	}
-	return nil
+	return failed.orNil()
 }
 
 // translateFullParallel flattens all lang/chunk combinations and runs them all
@@ -2759,6 +2977,7 @@ func translateFullParallel(ctx context.Context, tasks []translationTask, opts Op
 		chunk        []*po.Entry
 		poFile       *po.File
 		poPath       string
+		save         func(*po.File) error
 		lockTarget   string
 		systemPrompt string
 		nplurals     int
@@ -2798,6 +3017,7 @@ func translateFullParallel(ctx context.Context, tasks []translationTask, opts Op
 				chunk:        chunk,
 				poFile:       task.poFile,
 				poPath:       task.poPath,
+				save:         task.save,
 				lockTarget:   taskOpts.LockTarget,
 				systemPrompt: systemPrompt,
 				nplurals:     nplurals,
@@ -2829,22 +3049,28 @@ func translateFullParallel(ctx context.Context, tasks []translationTask, opts Op
 
 		mu := fileMu[ft.poPath]
 		if hasPluralEntries(ft.chunk) {
-			translations, err := translateChunkWithPlurals(ctx, ft.chunk, ft.systemPrompt, taskOpts, rl, ft.nplurals)
+			translations, model, err := translateChunkWithPlurals(ctx, ft.chunk, ft.systemPrompt, taskOpts, rl, ft.nplurals)
 			if err != nil {
 				return err
 			}
 			mu.Lock()
 			applyPluralTranslations(ft.chunk, translations, opts.TranslateFuzzy)
+			markReviewForPO(ft.chunk, model, taskOpts)
 			updateLockFileForPO(ft.chunk, taskOpts)
+			updateMemoryForPO(ft.chunk, model, taskOpts)
+			recordJournalForPO(ft.chunk, model, taskOpts)
 			mu.Unlock()
 		} else {
-			translations, err := translateChunk(ctx, ft.chunk, ft.systemPrompt, taskOpts, rl)
+			translations, model, err := translateChunk(ctx, ft.chunk, ft.systemPrompt, taskOpts, rl)
 			if err != nil {
 				return err
 			}
 			mu.Lock()
 			applyTranslations(ft.chunk, translations, opts.TranslateFuzzy)
+			markReviewForPO(ft.chunk, model, taskOpts)
 			updateLockFileForPO(ft.chunk, taskOpts)
+			updateMemoryForPO(ft.chunk, model, taskOpts)
+			recordJournalForPO(ft.chunk, model, taskOpts)
 			mu.Unlock()
 		}
 
@@ -2855,11 +3081,16 @@ func translateFullParallel(ctx context.Context, tasks []translationTask, opts Op
 		return nil
 	})
 
-	// Save all PO files
+	// Save all PO files, including partial results of an interrupted run
 	saved := make(map[string]bool)
 	for _, ft := range flatTasks {
 		if !saved[ft.poPath] {
-			savePOFile(ft.poFile, ft.poPath, opts)
+			taskOpts := opts
+			taskOpts.Language = ft.lang
+			if ft.lockTarget != "" {
+				taskOpts.LockTarget = ft.lockTarget
+			}
+			savePOFile(ft.poFile, ft.poPath, ft.save, taskOpts)
 			saved[ft.poPath] = true
 		}
 	}
@@ -2923,20 +3154,31 @@ func runParallelGeneric[T any](ctx context.Context, tasks []T, maxConcurrent int
 
 // LangTask is a language translation task exposed to main.go.
 type LangTask struct {
-	Lang       string
-	POFile     *po.File
-	POPath     string
+	Lang   string
+	POFile *po.File
+	POPath string
+	// Save, if set, saves POFile instead of writing it to POPath. It lets
+	// formats translated as PO entries (Qt .ts files) write their own file.
+	Save       func(*po.File) error
 	LockTarget string
 }
 
 // TranslateAll translates multiple languages according to opts.ParallelMode.
+// When opts.Memory is set, the memory is first seeded from the translated
+// entries of every task, and entries with a memory match are filled in
+// before any provider call.
 func TranslateAll(ctx context.Context, langTasks []LangTask, opts Options) error {
+	for _, lt := range langTasks {
+		seedMemoryFromPO(lt.POFile, lt.Lang, opts)
+	}
+
 	tasks := make([]translationTask, len(langTasks))
 	for i, lt := range langTasks {
 		tasks[i] = translationTask{
 			lang:       lt.Lang,
 			poFile:     lt.POFile,
 			poPath:     lt.POPath,
+			save:       lt.Save,
 			lockTarget: lt.LockTarget,
 		}
 
@@ -2946,6 +3188,19 @@ func TranslateAll(ctx context.Context, langTasks []LangTask, opts Options) error
 			taskOpts.LockTarget = lt.LockTarget
 		}
 		entries := collectEntries(lt.POFile, taskOpts)
+		entries, resumed := resumeJournalForPO(entries, taskOpts)
+		if len(resumed) > 0 {
+			opts.log("Journal: resumed %d entries for %s", len(resumed), lt.Lang)
+			updateLockFileForPO(resumed, taskOpts)
+		}
+		entries, reused := reuseMemoryForPO(entries, taskOpts)
+		if len(reused) > 0 {
+			opts.log("Translation memory: reused %d entries for %s", len(reused), lt.Lang)
+			updateLockFileForPO(reused, taskOpts)
+		}
+		if len(resumed) > 0 || len(reused) > 0 {
+			savePOFile(lt.POFile, lt.POPath, lt.Save, taskOpts)
+		}
 		tasks[i].entries = entries
 	}
 
@@ -3025,14 +3280,19 @@ func splitStrings(items []string, chunkSize int) [][]string {
 // Helpers
 // ---------------------------------------------------------------------------
 
-// savePOFile saves a PO file and logs the result.
-func savePOFile(poFile *po.File, poPath string, opts Options) {
+// savePOFile saves a PO file (with save, if set), logs the result and drops
+// the journal entries of its language once they are on disk.
+func savePOFile(poFile *po.File, poPath string, save func(*po.File) error, opts Options) {
 	poFile.SetHeaderField("PO-Revision-Date", time.Now().UTC().Format("2006-01-02 15:04+0000"))
-	if err := poFile.WriteFile(poPath); err != nil {
+	if save == nil {
+		save = func(f *po.File) error { return f.WriteFile(poPath) }
+	}
+	if err := save(poFile); err != nil {
 		opts.logError("Error saving %s: %v", poPath, err)
 	} else {
 		total, translated, _, _ := poFile.Stats()
 		opts.log("Saved %s (%d/%d translated)", poPath, translated, total)
+		forgetJournal("", opts)
 	}
 }
 
@@ -3103,7 +3363,7 @@ type AndroidLangTask struct {
 func TranslateAllAndroid(ctx context.Context, langTasks []AndroidLangTask, opts Options) error {
 	tasks := make([]KVLangTask, 0, len(langTasks))
 	for _, task := range langTasks {
-		androidFile := newAndroidKVFile(task.File, task.SourceFile)
+		androidFile := newAndroidKVFile(task.File, task.SourceFile, task.Lang)
 		tasks = append(tasks, KVLangTask{
 			Lang:         task.Lang,
 			LangName:     task.LangName,
@@ -3235,10 +3495,14 @@ type ARBLangTask struct {
 	SourceFile *arbfile.File
 }
 
-// TranslateAllARB translates ARB files for all language tasks.
+// TranslateAllARB translates ARB files for all language tasks. ICU
+// MessageFormat values are validated against their source structure and
+// the target language's plural categories.
 func TranslateAllARB(ctx context.Context, langTasks []ARBLangTask, opts Options) error {
 	tasks := make([]KVLangTask, 0, len(langTasks))
+	sources := make([]*arbfile.File, 0, len(langTasks))
 	for _, task := range langTasks {
+		sources = append(sources, task.SourceFile)
 		tasks = append(tasks, KVLangTask{
 			Lang:         task.Lang,
 			LangName:     task.LangName,
@@ -3247,7 +3511,7 @@ func TranslateAllARB(ctx context.Context, langTasks []ARBLangTask, opts Options)
 			SourceValues: task.SourceFile.SourceValues(),
 		})
 	}
-	return TranslateAllKV(ctx, tasks, opts, DefaultKVChunkTranslator())
+	return TranslateAllKV(ctx, tasks, opts, newARBChunkTranslator(sources...))
 }
 
 // ---------------------------------------------------------------------------
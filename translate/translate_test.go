@@ -4,18 +4,27 @@ package translate
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
-	"github.com/minios-linux/lokit/internal/format/i18next"
-	po "github.com/minios-linux/lokit/internal/format/po"
+	"github.com/minios-linux/lokit/format/android"
+	arbfile "github.com/minios-linux/lokit/format/arb"
+	"github.com/minios-linux/lokit/format/i18next"
+	po "github.com/minios-linux/lokit/format/po"
+	"github.com/minios-linux/lokit/journal"
 	"github.com/minios-linux/lokit/lockfile"
+	"github.com/minios-linux/lokit/review"
+	"github.com/minios-linux/lokit/tm"
 )
 
 type testKVFile struct {
@@ -537,6 +546,80 @@ func TestBuildHTTPRequest_OllamaNativeEndpoint(t *testing.T) {
 	}
 }
 
+func TestCallProvider_AnthropicRetriesOverloadedAndRateLimit(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %q, want /v1/messages", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statusOverloaded)
+			_, _ = io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"type":"error","error":{"type":"rate_limit_error","message":"Too many requests"}}`)
+		default:
+			_, _ = io.WriteString(w, `{"type":"message","content":[{"type":"text","text":"[\"Hallo\"]"}],"usage":{"input_tokens":12,"output_tokens":3}}`)
+		}
+	}))
+	defer ts.Close()
+
+	prov := DefaultProviders()[ProviderAnthropic]
+	prov.BaseURL = ts.URL + "/v1"
+	prov.APIKey = "test-key"
+	prov.Model = "claude-test"
+
+	text, usage, err := callProvider(context.Background(), prov, "system", "user", &rateLimitState{}, 3, false)
+	if err != nil {
+		t.Fatalf("callProvider: %v", err)
+	}
+	if text != `["Hallo"]` || calls != 3 {
+		t.Fatalf("text = %q after %d calls", text, calls)
+	}
+	if usage.PromptTokens != 12 || usage.CompletionTokens != 3 {
+		t.Fatalf("usage = %+v", usage)
+	}
+}
+
+func TestCallProvider_AnthropicErrorMessage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"model: not found"}}`)
+	}))
+	defer ts.Close()
+
+	prov := Provider{ID: ProviderAnthropic, Name: "Anthropic", BaseURL: ts.URL, APIKey: "k", Model: "nope"}
+	_, _, err := callProvider(context.Background(), prov, "system", "user", nil, 3, false)
+	if err == nil || !strings.Contains(err.Error(), "status 400: invalid_request_error: model: not found") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	h := http.Header{}
+	if _, ok := parseRetryAfter(h); ok {
+		t.Fatal("missing header reported as present")
+	}
+	h.Set("Retry-After", "7")
+	if d, ok := parseRetryAfter(h); !ok || d != 7*time.Second {
+		t.Fatalf("delay = %v, %v", d, ok)
+	}
+	h.Set("Retry-After", "soon")
+	if _, ok := parseRetryAfter(h); ok {
+		t.Fatal("invalid header reported as present")
+	}
+}
+
 // Ensure json.RawMessage can handle both strings and arrays (sanity check).
 func TestJSONRawMessage_Mixed(t *testing.T) {
 	raw := `["str", ["a", "b", "c"], "another"]`
@@ -560,7 +643,7 @@ func TestJSONRawMessage_Mixed(t *testing.T) {
 func TestBuildKVUserPrompt_UsesSourceValuesAndFallbackToKey(t *testing.T) {
 	keys := []string{"home.title", "menu.help"}
 	srcVals := map[string]string{"home.title": "Home"}
-	prompt := buildKVUserPrompt(keys, srcVals, "English", "Russian")
+	prompt := buildKVUserPrompt(keys, srcVals, nil, "English", "Russian")
 
 	if !strings.Contains(prompt, "Translate these strings from English to Russian") {
 		t.Fatalf("prompt missing language header: %q", prompt)
@@ -573,6 +656,101 @@ func TestBuildKVUserPrompt_UsesSourceValuesAndFallbackToKey(t *testing.T) {
 	}
 }
 
+func TestBuildKVUserPrompt_IncludesNotes(t *testing.T) {
+	keys := []string{"open", "close"}
+	notes := map[string]string{"open": "Verb:\n  button that opens a file"}
+	prompt := buildKVUserPrompt(keys, nil, notes, "English", "German")
+
+	ids := kvTranslationIDs(keys)
+	want := `ID ` + ids[0] + `: "open"` + "\n   (note: Verb: button that opens a file)\nID " + ids[1]
+	if !strings.Contains(prompt, want) {
+		t.Fatalf("prompt missing note below its source line: %q", prompt)
+	}
+	if strings.Count(prompt, "(note:") != 1 {
+		t.Fatalf("prompt has notes for keys without one: %q", prompt)
+	}
+}
+
+func TestWritePOEntryContext(t *testing.T) {
+	root := t.TempDir()
+	src := "def build(menu):\n    menu.add(_(\"Open\"))\n    menu.add(_(\"Close\"))\n"
+	if err := os.WriteFile(filepath.Join(root, "menu.py"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := &po.Entry{
+		MsgID:             "Open",
+		MsgCtxt:           "menu",
+		ExtractedComments: []string{"TRANSLATORS: verb, opens a file"},
+		References:        []string{"menu.py:2", "missing.py:7"},
+	}
+
+	var b strings.Builder
+	writePOEntryContext(&b, e, Options{}, sourceFiles{})
+	got := b.String()
+	for _, want := range []string{
+		"   (message context: \"menu\")\n",
+		"   (note: verb, opens a file)\n",
+		"   (context: menu.py:2, missing.py:7)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("context missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "code at") {
+		t.Errorf("snippet sent without SourceContextLines:\n%s", got)
+	}
+
+	b.Reset()
+	writePOEntryContext(&b, e, Options{SourceContextLines: 1, SourceRoot: root}, sourceFiles{})
+	got = b.String()
+	if !strings.Contains(got, "(code at menu.py:2:\n") || !strings.Contains(got, ">    2 |     menu.add(_(\"Open\"))") {
+		t.Errorf("snippet missing or unmarked:\n%s", got)
+	}
+	if !strings.Contains(got, "    1 | def build(menu):") || !strings.Contains(got, "    3 |     menu.add(_(\"Close\"))") {
+		t.Errorf("snippet missing surrounding lines:\n%s", got)
+	}
+	if strings.Contains(got, "code at missing.py") {
+		t.Errorf("snippet for unreadable file:\n%s", got)
+	}
+}
+
+func TestSourceSnippetStaysInsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(dir, "secret.txt")
+	if err := os.WriteFile(secret, []byte("password\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	inside := filepath.Join(root, "main.c")
+	if err := os.WriteFile(inside, []byte("puts(_(\"Hi\"));\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := sourceFiles{}
+	for _, ref := range []string{secret + ":1", "../secret.txt:1", "link.txt:1"} {
+		if got := files.snippet(root, ref, 1); got != "" {
+			t.Errorf("snippet(%q) = %q, want none outside the root", ref, got)
+		}
+	}
+	if got := files.snippet(root, inside+":1", 1); !strings.Contains(got, "puts") {
+		t.Errorf("snippet of an absolute path inside the root = %q", got)
+	}
+
+	// Later references to a file use the lines read first.
+	if err := os.WriteFile(inside, []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := files.snippet(root, "main.c:1", 0); !strings.Contains(got, "puts") {
+		t.Errorf("snippet read the file again: %q", got)
+	}
+}
+
 func TestBuildI18NextUserPrompt_UsesKeysAsSource(t *testing.T) {
 	keys := []string{"Save", "Cancel"}
 	prompt := buildI18NextUserPrompt(keys, "English", "German")
@@ -739,6 +917,22 @@ func TestValidatePOTranslationsAcceptsPreservedPlaceholders(t *testing.T) {
 	}
 }
 
+func TestValidatePOTranslationsChecksQtPlaceholders(t *testing.T) {
+	entries := []*po.Entry{
+		{MsgID: "Copy %1 to %L2", Flags: []string{"qt-format"}},
+		{MsgID: "%n file(s)", MsgIDPlural: "%n file(s)", Flags: []string{"qt-plural-format"}},
+	}
+	if err := validatePOTranslations(entries, []string{"%L2 nach %1 kopieren", "%n Datei(en)"}); err != nil {
+		t.Fatalf("preserved Qt placeholders rejected: %v", err)
+	}
+	if err := validatePOTranslations(entries[:1], []string{"%2 nach %1 kopieren"}); err == nil {
+		t.Fatal("expected changed %L2 placeholder to be rejected")
+	}
+	if err := validatePOTranslations(entries[1:], []string{"Dateien"}); err == nil {
+		t.Fatal("expected missing %n placeholder to be rejected")
+	}
+}
+
 func TestValidateKVTranslationsRejectsMissingPlaceholders(t *testing.T) {
 	keys := []string{"welcome", "progress"}
 	sources := map[string]string{
@@ -762,6 +956,23 @@ func TestValidateKVTranslationsAcceptsReorderedTextWithPlaceholders(t *testing.T
 	}
 }
 
+func TestValidateKVTranslationsChecksApplePlaceholders(t *testing.T) {
+	keys := []string{"saved", "selected"}
+	sources := map[string]string{
+		"saved":    "Saved %@ to %2$@",
+		"selected": "%#@n@ selected",
+	}
+	if err := validateKVTranslations(keys, sources, []string{"Gespeichert", "%#@n@ ausgewählt"}); err == nil {
+		t.Fatal("expected missing %@ placeholders to be rejected")
+	}
+	if err := validateKVTranslations(keys, sources, []string{"%@ in %2$@ gespeichert", "%#@n@ ausgewählt"}); err != nil {
+		t.Fatalf("preserved Apple placeholders rejected: %v", err)
+	}
+	if err := validateKVTranslations([]string{"files"}, map[string]string{"files": "%arg files"}, []string{"Dateien"}); err == nil {
+		t.Fatal("expected missing %arg placeholder to be rejected")
+	}
+}
+
 func TestNormalizePOTranslationNewlines_RestoresGroffFontEscapes(t *testing.T) {
 	source := `\f[B]MENU_LANG\f[R]: \[lq]multilang\[rq]\fR`
 	translation := "\f[B]MENU_LANG\f[R]: \\[lq]multilang\\[rq]\fR"
@@ -847,7 +1058,7 @@ func TestTranslateMarkdownSingleRetry_RestoresMaskedCodeBlocks(t *testing.T) {
 	}
 
 	src := "### H\n\n```python\nx = \"`hello`\"\n```\n\nText"
-	translations, err := translateMarkdownSingleRetry(
+	translations, _, err := translateMarkdownSingleRetry(
 		context.Background(),
 		"sec:0",
 		map[string]string{"sec:0": src},
@@ -891,7 +1102,7 @@ func TestTranslateMarkdownSingleRetry_RejectsRawResponseWithoutID(t *testing.T)
 	}
 
 	src := "### H\n\n```python\nx = \"`hello`\"\n```\n\nText"
-	_, err := translateMarkdownSingleRetry(
+	_, _, err := translateMarkdownSingleRetry(
 		context.Background(),
 		"sec:0",
 		map[string]string{"sec:0": src},
@@ -978,6 +1189,115 @@ func TestTranslateAllKVSequential_TranslatesAndSaves(t *testing.T) {
 	}
 }
 
+func TestTranslateAllKVFallsBackOnAuthError(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":{"message":"invalid api key"}}`)
+	}))
+	defer ts.Close()
+
+	chain := NewProviderChain(
+		Provider{ID: ProviderCustomOpenAI, Name: "Broken", BaseURL: ts.URL, Model: "test-model"},
+		Provider{ID: ProviderMock, Name: "Mock", Model: MockModelEcho},
+	)
+	var switched []string
+	chain.OnSwitch = func(from, to Provider, reason error) {
+		switched = append(switched, from.Name+"->"+to.Name)
+	}
+
+	for _, lang := range []string{"de", "fr"} {
+		f := newTestKVFile([]string{"a"}, map[string]string{"a": ""})
+		tasks := []KVLangTask{{Lang: lang, FilePath: lang + ".yaml", File: f, SourceValues: map[string]string{"a": "Hello"}}}
+		opts := Options{Provider: chain.Providers()[0], Providers: chain, ParallelMode: ParallelSequential, MaxRetries: 1}
+		if err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator()); err != nil {
+			t.Fatalf("TranslateAllKV(%s): %v", lang, err)
+		}
+		if got := f.Value("a"); got != "Hello" {
+			t.Fatalf("value[a] for %s = %q, want mock echo", lang, got)
+		}
+	}
+	if calls != 1 || len(switched) != 1 || switched[0] != "Broken->Mock" {
+		t.Fatalf("calls = %d, switched = %v; failed provider must not be retried", calls, switched)
+	}
+	if chain.Active().ID != ProviderMock {
+		t.Fatalf("active provider = %s", chain.Active().ID)
+	}
+}
+
+func TestTranslateAllKVFallsBackOnRepeatedInvalidResponses(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"Sorry, I cannot help with that."}}]}`)
+	}))
+	defer ts.Close()
+
+	chain := NewProviderChain(
+		Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		Provider{ID: ProviderMock, Model: MockModelPseudo},
+	)
+	f := newTestKVFile([]string{"a"}, map[string]string{"a": ""})
+	tasks := []KVLangTask{{Lang: "de", FilePath: "de.yaml", File: f, SourceValues: map[string]string{"a": "Hello"}}}
+	opts := Options{Provider: chain.Providers()[0], Providers: chain, ParallelMode: ParallelSequential, MaxRetries: 1}
+	if err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (one retry before falling back)", calls)
+	}
+	if got := f.Value("a"); got == "" || got == "Hello" {
+		t.Fatalf("value[a] = %q, want pseudo-translation", got)
+	}
+}
+
+func TestFallbackAfterInvalidResponsesUsesAnsweringProvider(t *testing.T) {
+	chain := NewProviderChain(
+		Provider{ID: ProviderCustomOpenAI, Model: "first"},
+		Provider{ID: ProviderCustomOpenAI, Model: "second"},
+		Provider{ID: ProviderMock, Model: MockModelPseudo},
+	)
+	opts := Options{Provider: chain.Providers()[0], Providers: chain}
+
+	// Another request already moved on from the first provider.
+	if !chain.advance(0, errors.New("unauthorized")) {
+		t.Fatal("advance(0) = false")
+	}
+	// A request answered by the first provider must not skip the second,
+	// which never failed.
+	if !opts.fallbackAfterInvalidResponses(context.Background(), 0, errors.New("bad JSON")) {
+		t.Fatal("fallbackAfterInvalidResponses = false, want retry with the active provider")
+	}
+	if got := chain.Active().Model; got != "second" {
+		t.Fatalf("active model = %q, want second", got)
+	}
+	if got := opts.reviewModel(0); got != ProviderCustomOpenAI+"/first" {
+		t.Fatalf("reviewModel(0) = %q", got)
+	}
+}
+
+func TestIsFallbackError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{newAPIError(http.StatusTooManyRequests, "rate limited after 3 retries"), true},
+		{newAPIError(http.StatusForbidden, "forbidden"), true},
+		{newAPIError(statusOverloaded, "overloaded"), true},
+		{fmt.Errorf("Copilot %w: %w", ErrAuthentication, errors.New("no token")), true},
+		{newAPIError(http.StatusBadRequest, "bad request"), false},
+		{ErrTokenBudgetExceeded, false},
+		{context.Canceled, false},
+	}
+	for _, c := range cases {
+		if got := IsFallbackError(c.err); got != c.want {
+			t.Errorf("IsFallbackError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
 func TestTranslateAllKVSequentialMapsReorderedResponseByID(t *testing.T) {
 	keys := []string{"first", "second", "third"}
 	ids := kvTranslationIDs(keys)
@@ -1182,7 +1502,7 @@ func TestCallOpenAI_RejectsNonOAuthModel(t *testing.T) {
 		Model: "gpt-4o",
 	}
 
-	_, err := callOpenAI(context.Background(), prov, "system", "user", nil, 0, false)
+	_, _, err := callOpenAI(context.Background(), prov, "system", "user", nil, 0, false)
 	if err == nil {
 		t.Fatal("expected error for non-OAuth model without API key")
 	}
@@ -1339,3 +1659,1044 @@ func TestCollectEntries_UntranslatedPassesThroughLock(t *testing.T) {
 		t.Fatalf("collected entry MsgID=%q, want Goodbye", entries[0].MsgID)
 	}
 }
+
+func TestTranslateAllKVSequential_ReusesTranslationMemory(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if strings.Contains(string(body), "Hello") {
+			t.Errorf("memory hit was sent to the provider: %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(identifiedKVProviderResponse([]string{"b"}, []string{"Пока"})))
+	}))
+	defer ts.Close()
+
+	mem := tm.New()
+	mem.Add("ru", "Hello", "Привет")
+
+	f := newTestKVFile([]string{"a", "b"}, map[string]string{"a": "", "b": ""})
+	tasks := []KVLangTask{{
+		Lang:         "ru",
+		LangName:     "Russian",
+		FilePath:     "ru.json",
+		File:         f,
+		SourceValues: map[string]string{"a": "Hello", "b": "Bye"},
+	}}
+	opts := Options{
+		Provider:     Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode: ParallelSequential,
+		Memory:       mem,
+	}
+
+	if err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("provider requests = %d, want 1", requests)
+	}
+	if got := f.Value("a"); got != "Привет" {
+		t.Fatalf("value[a] = %q, want memory translation", got)
+	}
+	if got, ok := mem.Lookup("ru", "Bye"); !ok || got != "Пока" {
+		t.Fatalf("memory was not updated with new translation: %q, %v", got, ok)
+	}
+}
+
+func TestReuseMemoryForPO_SkipsPluralsAndRespectsContext(t *testing.T) {
+	mem := tm.New()
+	mem.Add("ru", "Open", "Открыть")
+	mem.Add("ru", "%d file", "%d файл")
+
+	plain := &po.Entry{MsgID: "Open"}
+	withCtx := &po.Entry{MsgID: "Open", MsgCtxt: "menu"}
+	plural := &po.Entry{MsgID: "%d file", MsgIDPlural: "%d files"}
+
+	remaining, reused := reuseMemoryForPO([]*po.Entry{plain, withCtx, plural}, Options{Language: "ru", Memory: mem})
+	if len(reused) != 1 || reused[0] != plain {
+		t.Fatalf("reused = %v, want only the context-free singular entry", reused)
+	}
+	if len(remaining) != 2 {
+		t.Fatalf("remaining = %d entries, want 2", len(remaining))
+	}
+	if plain.MsgStr != "Открыть" {
+		t.Fatalf("MsgStr = %q", plain.MsgStr)
+	}
+}
+
+func TestReuseMemoryMarksOriginatingModel(t *testing.T) {
+	mem := tm.New()
+	opts := Options{Language: "ru", Memory: mem, Review: review.New(), LockTarget: "app"}
+	updateMemoryForPO([]*po.Entry{{MsgID: "Open", MsgStr: "Открыть"}}, "openai/gpt-4o", opts)
+	mem.Seed("ru", "Close", "Закрыть")
+
+	open := &po.Entry{MsgID: "Open"}
+	closeEntry := &po.Entry{MsgID: "Close"}
+	if _, reused := reuseMemoryForPO([]*po.Entry{open, closeEntry}, opts); len(reused) != 2 {
+		t.Fatalf("reused = %v, want both entries", reused)
+	}
+	if model, _ := review.POModel(open); model != "openai/gpt-4o (lokit.tm)" {
+		t.Errorf("Open model = %q, want the model that produced the memory entry", model)
+	}
+	if model, _ := review.POModel(closeEntry); model != review.MemoryModel {
+		t.Errorf("Close model = %q, want %q for a seeded entry", model, review.MemoryModel)
+	}
+
+	f, err := i18next.Parse([]byte(`{"translations": {"Open": ""}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, reused := reuseMemoryForKV(f, []string{"Open"}, f.SourceValues(), "", opts); len(reused) != 1 {
+		t.Fatalf("reused = %v, want the key", reused)
+	}
+	if e, ok := opts.Review.Get("app/ru", "Open"); !ok || e.Model != "openai/gpt-4o (lokit.tm)" {
+		t.Errorf("review entry = %+v, %v", e, ok)
+	}
+}
+
+func TestReuseMemoryForPO_ForceBypassesMemory(t *testing.T) {
+	mem := tm.New()
+	mem.Add("ru", "Open", "Открыть")
+	e := &po.Entry{MsgID: "Open"}
+
+	remaining, reused := reuseMemoryForPO([]*po.Entry{e}, Options{Language: "ru", Memory: mem, ForceTranslate: true})
+	if len(reused) != 0 || len(remaining) != 1 {
+		t.Fatalf("force mode reused %d entries", len(reused))
+	}
+}
+
+func TestResolvedPromptIncludesGlossary(t *testing.T) {
+	opts := Options{
+		Language:     "pt_BR",
+		SystemPrompt: "Translate to {{targetLang}}.",
+		Glossary: &Glossary{
+			DoNotTranslate: []string{"MiniOS"},
+			Terms:          map[string]map[string]string{"pt-BR": {"folder": "pasta"}},
+		},
+	}
+	prompt := opts.resolvedPrompt()
+	for _, want := range []string{"GLOSSARY", `"MiniOS"`, `"folder" → "pasta"`} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	opts.Language = "de"
+	opts.Glossary.DoNotTranslate = nil
+	if prompt := opts.resolvedPrompt(); strings.Contains(prompt, "GLOSSARY") {
+		t.Fatalf("prompt for language without glossary contains glossary:\n%s", prompt)
+	}
+}
+
+func TestCheckGlossary(t *testing.T) {
+	opts := Options{
+		Language: "ru",
+		Glossary: &Glossary{
+			DoNotTranslate: []string{"MiniOS"},
+			Terms:          map[string]map[string]string{"ru": {"folder": "папка"}},
+		},
+	}
+	tests := []struct {
+		source, translation string
+		wantErr             bool
+	}{
+		{"Welcome to MiniOS", "Добро пожаловать в MiniOS", false},
+		{"Welcome to MiniOS", "Добро пожаловать в МиниОС", true},
+		{"Open Folder", "Открыть каталог", false}, // term mappings only warn
+		{"Save", "Сохранить", false},
+	}
+	for _, tt := range tests {
+		err := opts.checkGlossary(tt.source, tt.translation)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkGlossary(%q, %q) error = %v, wantErr %v", tt.source, tt.translation, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGlossaryMismatches(t *testing.T) {
+	opts := Options{
+		Language: "ru",
+		Glossary: &Glossary{Terms: map[string]map[string]string{
+			"ru": {"folder": "папка", "file manager": "файловый менеджер"},
+			"de": {"folder": "Ordner"},
+		}},
+	}
+	tests := []struct {
+		lang, source, translation string
+		want                      bool
+	}{
+		{"ru", "Open folder", "Открыть папка", false},
+		{"ru", "Open folder", "Откройте папку", false},
+		{"ru", "No folders", "Нет папок", false},
+		{"ru", "Open Folder", "Открыть каталог", true},
+		{"ru", "Open folders", "Открыть каталоги", false}, // whole-word source match only
+		{"ru", "Start the file manager", "Запустите файловый менеджер", false},
+		{"ru", "Start the file manager", "Запустите менеджер", true},
+		{"ru", "Open folder", "Открыть напапку", true}, // stem must start a word
+		{"de", "Open folder", "In Ordnern suchen", false},
+		{"de", "Open folder", "Verzeichnis öffnen", true},
+	}
+	for _, tt := range tests {
+		opts.Language = tt.lang
+		got := opts.glossaryMismatches(tt.source, tt.translation)
+		if (len(got) > 0) != tt.want {
+			t.Errorf("glossaryMismatches(%s, %q, %q) = %v, want mismatch %v", tt.lang, tt.source, tt.translation, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateAllKVSequential_RetriesGlossaryViolation(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = io.Copy(io.Discard, r.Body)
+		_ = r.Body.Close()
+		translation := "Добро пожаловать в МиниОС"
+		if requests > 1 {
+			translation = "Добро пожаловать в MiniOS"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(identifiedKVProviderResponse([]string{"welcome"}, []string{translation})))
+	}))
+	defer ts.Close()
+
+	f := newTestKVFile([]string{"welcome"}, map[string]string{"welcome": ""})
+	tasks := []KVLangTask{{
+		Lang:         "ru",
+		LangName:     "Russian",
+		FilePath:     "ru.json",
+		File:         f,
+		SourceValues: map[string]string{"welcome": "Welcome to MiniOS"},
+	}}
+	opts := Options{
+		Provider:     Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode: ParallelSequential,
+		Glossary:     &Glossary{DoNotTranslate: []string{"MiniOS"}},
+	}
+
+	if err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("provider requests = %d, want 2 (one retry)", requests)
+	}
+	if got := f.Value("welcome"); got != "Добро пожаловать в MiniOS" {
+		t.Fatalf("value[welcome] = %q", got)
+	}
+}
+
+func TestLengthLimitsLimit(t *testing.T) {
+	limits := &LengthLimits{Rules: []LengthRule{
+		{Pattern: regexp.MustCompile(`^menu\.`), Max: 12},
+		{Ratio: 1.5},
+	}}
+	tests := []struct {
+		key, source string
+		hint, want  int
+	}{
+		{key: "menu.open", source: "Open", want: 6},
+		{key: "menu.preferences", source: "Preferences", want: 12},
+		{key: "title", source: "Preferences", want: 17},
+		{key: "title", source: "Preferences", hint: 10, want: 10},
+	}
+	for _, tt := range tests {
+		if got := limits.Limit(tt.key, tt.source, tt.hint); got != tt.want {
+			t.Errorf("Limit(%q, %q, %d) = %d, want %d", tt.key, tt.source, tt.hint, got, tt.want)
+		}
+	}
+	var none *LengthLimits
+	if got := none.Limit("title", "Preferences", 0); got != 0 {
+		t.Errorf("nil limits = %d, want 0", got)
+	}
+}
+
+func TestTranslateAllKVSequential_RetriesAndKeepsOverLengthLimit(t *testing.T) {
+	var prompts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		prompts = append(prompts, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(identifiedKVProviderResponse([]string{"menu.settings"}, []string{"Einstellungen öffnen"})))
+	}))
+	defer ts.Close()
+
+	f := newTestKVFile([]string{"menu.settings"}, map[string]string{"menu.settings": ""})
+	tasks := []KVLangTask{{
+		Lang:         "de",
+		LangName:     "German",
+		FilePath:     "de.json",
+		File:         f,
+		SourceValues: map[string]string{"menu.settings": "Open settings"},
+	}}
+	opts := Options{
+		Provider:     Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode: ParallelSequential,
+		MaxRetries:   1,
+		LengthLimits: &LengthLimits{Rules: []LengthRule{{Pattern: regexp.MustCompile(`^menu\.`), Max: 15}}},
+	}
+
+	if err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("provider requests = %d, want 2 (one retry)", len(prompts))
+	}
+	if !strings.Contains(prompts[0], "LENGTH LIMITS") || !strings.Contains(prompts[0], "at most 15") {
+		t.Fatalf("first prompt missing length limit: %s", prompts[0])
+	}
+	if !strings.Contains(prompts[1], "shorten them") || !strings.Contains(prompts[1], "is 20 characters, limit 15") {
+		t.Fatalf("retry prompt missing shorten instruction: %s", prompts[1])
+	}
+	if got := f.Value("menu.settings"); got != "Einstellungen öffnen" {
+		t.Fatalf("over-long translation not kept after the last retry: %q", got)
+	}
+}
+
+func TestCheckTranslationLength(t *testing.T) {
+	if issues := CheckTranslationLength("Öffnen", 6); len(issues) != 0 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+	issues := CheckTranslationLength("Einstellungen", 10)
+	if len(issues) != 1 || issues[0].Rule != CheckLength || issues[0].Message != "translation is 13 characters, limit is 10" {
+		t.Fatalf("issues = %+v", issues)
+	}
+}
+
+func TestCheckKVTranslation(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		translation string
+		markdown    bool
+		want        []string
+	}{
+		{name: "ok", source: "Hello, {{name}}", translation: "Hallo, {{name}}"},
+		{name: "placeholder", source: "Hello, %s", translation: "Hallo", want: []string{CheckPlaceholders}},
+		{name: "whitespace", source: "Hello\n", translation: "Hallo", want: []string{CheckWhitespace}},
+		{name: "identical", source: "Hello", translation: "Hello", want: []string{CheckIdentical}},
+		{name: "identical without letters", source: "42", translation: "42"},
+		{name: "tags", source: "<b>Hello</b>", translation: "<b>Hallo", want: []string{CheckTags}},
+		{name: "void tags", source: "Line<br>break", translation: "Zeile<br>Umbruch"},
+		{name: "heading", source: "## Title", translation: "# Titel", markdown: true, want: []string{CheckMarkdownHeading}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := CheckKVTranslation(tt.source, tt.translation, tt.markdown)
+			var got []string
+			for _, issue := range issues {
+				got = append(got, issue.Rule)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("rules = %v, want %v (%+v)", got, tt.want, issues)
+			}
+		})
+	}
+}
+
+func TestCheckPOTranslationUsesFormatFlags(t *testing.T) {
+	entry := &po.Entry{MsgID: "%d files", Flags: []string{"c-format"}}
+	if issues := CheckPOTranslation(entry, entry.MsgID, "%d Dateien"); len(issues) != 0 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+	issues := CheckPOTranslation(entry, entry.MsgID, "Dateien")
+	if len(issues) != 1 || issues[0].Rule != CheckPlaceholders {
+		t.Fatalf("issues = %+v, want placeholders", issues)
+	}
+}
+
+func TestExtractUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want TokenUsage
+	}{
+		{name: "openai chat", body: `{"usage":{"prompt_tokens":10,"completion_tokens":5}}`, want: TokenUsage{10, 5}},
+		{name: "anthropic", body: `{"usage":{"input_tokens":7,"output_tokens":3}}`, want: TokenUsage{7, 3}},
+		{name: "gemini", body: `{"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2}}`, want: TokenUsage{4, 2}},
+		{name: "ollama", body: `{"prompt_eval_count":6,"eval_count":1}`, want: TokenUsage{6, 1}},
+		{name: "none", body: `{"choices":[]}`},
+		{name: "invalid", body: `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractUsage([]byte(tt.body)); got != tt.want {
+				t.Fatalf("extractUsage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateAllKVSequential_StopsAtTokenBudget(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = io.Copy(io.Discard, r.Body)
+		_ = r.Body.Close()
+		var resp map[string]any
+		_ = json.Unmarshal([]byte(identifiedKVProviderResponse([]string{"a"}, []string{"A"})), &resp)
+		resp["usage"] = map[string]int{"prompt_tokens": 8, "completion_tokens": 4}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	f := newTestKVFile([]string{"a", "b"}, map[string]string{"a": "", "b": ""})
+	tasks := []KVLangTask{{
+		Lang:         "de",
+		LangName:     "German",
+		FilePath:     "de.json",
+		File:         f,
+		SourceValues: map[string]string{"a": "a", "b": "b"},
+	}}
+	usage := NewUsageTracker(10)
+	opts := Options{
+		Provider:     Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode: ParallelSequential,
+		ChunkSize:    1,
+		LockTarget:   "app",
+		Usage:        usage,
+	}
+
+	err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator())
+	if !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Fatalf("TranslateAllKV error = %v, want ErrTokenBudgetExceeded", err)
+	}
+	if requests != 1 {
+		t.Fatalf("provider requests = %d, want 1", requests)
+	}
+	if got := f.Value("a"); got != "A" {
+		t.Fatalf("value[a] = %q, want A", got)
+	}
+	entries := usage.Entries()
+	if len(entries) != 1 || entries[0].Target != "app" || entries[0].Lang != "de" || entries[0].Requests != 1 || entries[0].Total() != 12 {
+		t.Fatalf("usage entries = %+v", entries)
+	}
+}
+
+func TestTranslateAllKV_I18NextPluralUnit(t *testing.T) {
+	src, err := i18next.Parse([]byte(`{"translations": {
+    "Hello": "",
+    "item_one": "{{count}} item",
+    "item_other": "{{count}} items"
+}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ru := i18next.NewTranslationFile(src, "ru")
+
+	var pluralPrompt string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(string(body), "plural messages") {
+			_, _ = io.WriteString(w, identifiedKVProviderResponse([]string{"Hello"}, []string{"Привет"}))
+			return
+		}
+		pluralPrompt = string(body)
+		forms, _ := json.Marshal(map[string]string{
+			"one":   "{{count}} элемент",
+			"few":   "{{count}} элемента",
+			"many":  "{{count}} элементов",
+			"other": "{{count}} элемента",
+		})
+		content, _ := json.Marshal([]identifiedTranslation{{ID: kvTranslationIDs([]string{"item"})[0], Translation: forms}})
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"content": string(content)}}},
+		})
+	}))
+	defer ts.Close()
+
+	tasks := []KVLangTask{{
+		Lang:         "ru",
+		LangName:     "Russian",
+		FilePath:     filepath.Join(t.TempDir(), "ru.json"),
+		File:         ru,
+		SourceValues: src.SourceValues(),
+	}}
+	opts := Options{
+		Provider:       Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode:   ParallelSequential,
+		SourceLanguage: "en",
+	}
+
+	if err := TranslateAllKV(context.Background(), tasks, opts, I18NextChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+	if !strings.Contains(pluralPrompt, "few, many") || !strings.Contains(pluralPrompt, "{{count}} items") {
+		t.Fatalf("plural prompt does not list categories and source forms: %s", pluralPrompt)
+	}
+	want := map[string]string{
+		"Hello":      "Привет",
+		"item_one":   "{{count}} элемент",
+		"item_few":   "{{count}} элемента",
+		"item_many":  "{{count}} элементов",
+		"item_other": "{{count}} элемента",
+	}
+	for key, value := range want {
+		if got, _ := ru.Get(key); got != value {
+			t.Fatalf("ru[%s] = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestValidateKVPluralTranslations_RejectsUnknownPlaceholder(t *testing.T) {
+	units := []kvPluralUnit{{
+		name:       "item",
+		sources:    map[string]string{"one": "{{count}} item", "other": "{{count}} items"},
+		keys:       []string{"item_one", "item_other"},
+		categories: []string{"one", "other"},
+	}}
+	ok := []map[string]string{{"one": "un élément", "other": "{{count}} éléments"}}
+	if err := validateKVPluralTranslations(units, ok, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := []map[string]string{{"one": "{{n}} élément", "other": "{{count}} éléments"}}
+	if err := validateKVPluralTranslations(units, bad, Options{}); err == nil {
+		t.Fatal("expected unknown placeholder error")
+	}
+}
+
+func TestValidateICUTranslation(t *testing.T) {
+	source := "{count, plural, =0{No files} one{# file} other{# files in {dir}}}"
+	cases := []struct {
+		name        string
+		translation string
+		lang        string
+		declared    []string
+		wantErr     string
+	}{
+		{name: "valid ru", lang: "ru", translation: "{count, plural, =0{Нет файлов} one{# файл} few{# файла} many{# файлов} other{# файла в {dir}}}"},
+		{name: "missing category", lang: "ru", translation: "{count, plural, one{# файл} other{# файла в {dir}}}", wantErr: `missing the "few" category`},
+		{name: "translated keyword", lang: "de", translation: "{count, Mehrzahl, one{# Datei} other{# Dateien in {dir}}}", wantErr: "plural argument {count} missing"},
+		{name: "translated selector", lang: "de", translation: "{count, plural, eins{# Datei} other{# Dateien in {dir}}}", wantErr: `unknown branch "eins"`},
+		{name: "broken structure", lang: "de", translation: "{count, plural, one{# Datei} other{# Dateien in {dir}}", wantErr: "invalid ICU message"},
+		{name: "missing placeholder", lang: "de", translation: "{count, plural, one{# Datei} other{# Dateien}}", wantErr: "placeholder {dir} missing"},
+		{name: "unknown placeholder", lang: "de", translation: "{count, plural, one{# Datei in {ordner}} other{# Dateien in {dir}}}", wantErr: "unknown placeholder {ordner}"},
+		{name: "declared placeholder", lang: "de", declared: []string{"user"}, translation: "{count, plural, one{# Datei} other{# Dateien in {dir} von {user}}}"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateICUTranslation(source, tc.translation, tc.lang, tc.declared)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	err := validateICUTranslation("{gender, select, male{He} female{She} other{They}}", "{gender, select, männlich{Er} female{Sie} other{Sie}}", "de", nil)
+	if err == nil || !strings.Contains(err.Error(), `missing branch "male"`) {
+		t.Fatalf("select error = %v", err)
+	}
+}
+
+func TestTranslateAllARB_RetriesInvalidICU(t *testing.T) {
+	src, err := arbfile.Parse([]byte(`{
+  "@@locale": "en",
+  "files": "{count, plural, one{# file} other{# files}}",
+  "@files": {"placeholders": {"count": {"type": "int"}}}
+}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	target := arbfile.NewTranslationFile(src, "ru")
+
+	responses := []string{
+		"{count, plural, one{# файл} other{# файла}}",
+		"{count, plural, one{# файл} few{# файла} many{# файлов} other{# файла}}",
+	}
+	var prompts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		prompts = append(prompts, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		response := responses[len(responses)-1]
+		if len(prompts) <= len(responses) {
+			response = responses[len(prompts)-1]
+		}
+		_, _ = io.WriteString(w, identifiedKVProviderResponse([]string{"files"}, []string{response}))
+	}))
+	defer ts.Close()
+
+	tasks := []ARBLangTask{{Lang: "ru", LangName: "Russian", FilePath: filepath.Join(t.TempDir(), "app_ru.arb"), File: target, SourceFile: src}}
+	opts := Options{
+		Provider:     Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode: ParallelSequential,
+		MaxRetries:   1,
+	}
+	if err := TranslateAllARB(context.Background(), tasks, opts); err != nil {
+		t.Fatalf("TranslateAllARB error: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("provider requests = %d, want 2", len(prompts))
+	}
+	if !strings.Contains(prompts[0], "one, few, many, other") {
+		t.Fatalf("prompt does not list Russian plural categories: %s", prompts[0])
+	}
+	if got, _ := target.Get("files"); got != responses[1] {
+		t.Fatalf("files = %q, want %q", got, responses[1])
+	}
+}
+
+func TestCallMock_PseudoTranslatesIdentifiedPrompt(t *testing.T) {
+	ids := entryTranslationIDs([]*po.Entry{{MsgID: "Save %d files"}, {MsgID: "%d file", MsgIDPlural: "%d files"}})
+	system := "Translate.\n\nGLOSSARY:\n  \"lokit\"\n  \"file\" → \"Datei\""
+	user := "Translate these entries:\n\n" +
+		"ID " + ids[0] + `: "Open <b>{name}</b> with lokit"` + "\n" +
+		"ID " + ids[1] + `: singular: "%d file" | plural: "%d files"` + "\n" +
+		"   (return an array of exactly 3 plural forms for the target language)\n"
+
+	content, usage, err := callMock(Provider{ID: ProviderMock, Model: MockModelPseudo}, system, user)
+	if err != nil {
+		t.Fatalf("callMock error: %v", err)
+	}
+	if usage.PromptTokens == 0 || usage.CompletionTokens == 0 {
+		t.Fatalf("usage not estimated: %+v", usage)
+	}
+	raw, err := parseIdentifiedTranslations(content, ids)
+	if err != nil {
+		t.Fatalf("response does not follow the identified contract: %v\n%s", err, content)
+	}
+	var single string
+	if err := json.Unmarshal(raw[0], &single); err != nil {
+		t.Fatalf("singular translation is not a string: %s", raw[0])
+	}
+	if want := "[Óþéñ <b>{name}</b> ŵíţĥ lokit ·········]"; single != want {
+		t.Fatalf("singular = %q, want %q", single, want)
+	}
+	var forms []string
+	if err := json.Unmarshal(raw[1], &forms); err != nil || len(forms) != 3 {
+		t.Fatalf("plural translation = %s, want 3 forms", raw[1])
+	}
+	if forms[0] != "[%d Datei ···]" || forms[2] != "[%d ƒíļéš ···]" {
+		t.Fatalf("plural forms = %q", forms)
+	}
+}
+
+func TestCallMock_EchoReturnsSource(t *testing.T) {
+	ids := kvTranslationIDs([]string{"greeting"})
+	user := buildKVUserPrompt([]string{"greeting"}, map[string]string{"greeting": "Hello,\n\"world\""}, nil, "English", "German")
+	content, _, err := callMock(Provider{ID: ProviderMock, Model: MockModelEcho}, identifiedKVSystemPrompt("Translate."), user)
+	if err != nil {
+		t.Fatalf("callMock error: %v", err)
+	}
+	got, err := parseIdentifiedStringTranslations(content, ids)
+	if err != nil {
+		t.Fatalf("parse error: %v\n%s", err, content)
+	}
+	if got[0] != "Hello,\n\"world\"" {
+		t.Fatalf("echo = %q", got[0])
+	}
+}
+
+func TestTranslateAllKV_MockProviderFillsPluralsAndICU(t *testing.T) {
+	src, err := i18next.Parse([]byte(`{"translations": {
+    "Hello, {{name}}!": "",
+    "item_one": "{{count}} item",
+    "item_other": "{{count}} items"
+}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ru := i18next.NewTranslationFile(src, "ru")
+	opts := Options{
+		Provider:       Provider{ID: ProviderMock, Model: MockModelPseudo},
+		ParallelMode:   ParallelSequential,
+		SourceLanguage: "en",
+	}
+	tasks := []KVLangTask{{Lang: "ru", LangName: "Russian", FilePath: filepath.Join(t.TempDir(), "ru.json"), File: ru, SourceValues: src.SourceValues()}}
+	if err := TranslateAllKV(context.Background(), tasks, opts, I18NextChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+	if got, _ := ru.Get("Hello, {{name}}!"); got != "[Ĥéļļó, {{name}}! ·····]" {
+		t.Fatalf("greeting = %q", got)
+	}
+	if got, _ := ru.Get("item_many"); got != "[{{count}} íţéɱš ·····]" {
+		t.Fatalf("item_many = %q", got)
+	}
+
+	arbSrc, err := arbfile.Parse([]byte(`{"@@locale": "en", "files": "{count, plural, one{# file} other{# files}}"}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	arbRu := arbfile.NewTranslationFile(arbSrc, "ru")
+	arbTasks := []ARBLangTask{{Lang: "ru", LangName: "Russian", FilePath: filepath.Join(t.TempDir(), "app_ru.arb"), File: arbRu, SourceFile: arbSrc}}
+	if err := TranslateAllARB(context.Background(), arbTasks, opts); err != nil {
+		t.Fatalf("TranslateAllARB error: %v", err)
+	}
+	got, _ := arbRu.Get("files")
+	if err := validateICUTranslation("{count, plural, one{# file} other{# files}}", got, "ru", nil); err != nil {
+		t.Fatalf("mock ICU translation %q is invalid: %v", got, err)
+	}
+}
+
+func TestTranslateAllAndroid_FillsLanguageQuantities(t *testing.T) {
+	src, err := android.Parse([]byte(`<resources>
+    <string name="title">Songs</string>
+    <plurals name="songs">
+        <item quantity="one">%d song</item>
+        <item quantity="other">%d songs</item>
+    </plurals>
+</resources>`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ru := android.NewTranslationFile(src, "ru")
+	kv := NewAndroidKVFile(ru, src, "ru")
+	if total, translated, _ := kv.Stats(); total != 5 || translated != 0 {
+		t.Fatalf("Stats() = %d, %d; want 5, 0", total, translated)
+	}
+	// few and many have no source text of their own.
+	if vals := kv.SourceValues(); len(vals) != 3 || vals["songs#other"] != "%d songs" {
+		t.Fatalf("SourceValues() = %q", vals)
+	}
+
+	opts := Options{
+		Provider:       Provider{ID: ProviderMock, Model: MockModelPseudo},
+		ParallelMode:   ParallelSequential,
+		SourceLanguage: "en",
+	}
+	tasks := []AndroidLangTask{{Lang: "ru", LangName: "Russian", File: ru, FilePath: filepath.Join(t.TempDir(), "strings.xml"), SourceFile: src}}
+	if err := TranslateAllAndroid(context.Background(), tasks, opts); err != nil {
+		t.Fatalf("TranslateAllAndroid error: %v", err)
+	}
+	if got := ru.GetEntry("songs").Plurals["many"]; got != "[%d šóñĝš ···]" {
+		t.Fatalf("songs#many = %q", got)
+	}
+	if _, translated, _ := kv.Stats(); translated != 5 {
+		t.Fatalf("translated = %d, want 5", translated)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Review provenance
+// ---------------------------------------------------------------------------
+
+func TestTranslateAllKV_ReviewMarksAndRetranslatesRejected(t *testing.T) {
+	src, err := i18next.Parse([]byte(`{"translations": {"Hello": "", "Bye": ""}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	de := i18next.NewTranslationFile(src, "de")
+	state := review.New()
+	opts := Options{
+		Provider:       Provider{ID: ProviderMock, Model: MockModelPseudo},
+		ParallelMode:   ParallelSequential,
+		SourceLanguage: "en",
+		LockTarget:     "web",
+		Review:         state,
+	}
+	tasks := []KVLangTask{{Lang: "de", LangName: "German", FilePath: filepath.Join(t.TempDir(), "de.json"), File: de, SourceValues: src.SourceValues()}}
+	if err := TranslateAllKV(context.Background(), tasks, opts, I18NextChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+
+	e, ok := state.Get("web/de", "Hello")
+	if !ok || e.Model != "mock/pseudo" || e.Source != "Hello" {
+		t.Fatalf("review entry = %+v, %v", e, ok)
+	}
+	if got := state.Keys("web/de"); len(got) != 2 {
+		t.Fatalf("review keys = %v, want 2", got)
+	}
+
+	// A rejected key is translated again although it has a value; the
+	// untouched key stays as it is.
+	state.Reject("web/de", "Hello")
+	de.Set("Hello", "Hallo?!")
+	de.Set("Bye", "Tschüss")
+	if err := TranslateAllKV(context.Background(), tasks, opts, I18NextChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV error: %v", err)
+	}
+	if got, _ := de.Get("Hello"); got == "Hallo?!" {
+		t.Error("rejected key was not retranslated")
+	}
+	if got, _ := de.Get("Bye"); got != "Tschüss" {
+		t.Errorf("Bye = %q, want unchanged", got)
+	}
+	if state.IsRejected("web/de", "Hello") {
+		t.Error("retranslated key is still rejected")
+	}
+}
+
+func TestTranslateAll_ReviewMarksPOEntries(t *testing.T) {
+	f := po.NewFile()
+	f.Entries = append(f.Entries,
+		&po.Entry{MsgID: "Open"},
+		&po.Entry{MsgID: "Close", MsgStr: "Schließen"},
+	)
+	opts := Options{
+		Provider:       Provider{ID: ProviderMock, Model: MockModelPseudo},
+		ParallelMode:   ParallelSequential,
+		SourceLanguage: "en",
+		Review:         review.New(),
+		ReviewFuzzy:    true,
+	}
+	tasks := []LangTask{{Lang: "de", POFile: f, POPath: filepath.Join(t.TempDir(), "de.po")}}
+	if err := TranslateAll(context.Background(), tasks, opts); err != nil {
+		t.Fatalf("TranslateAll error: %v", err)
+	}
+
+	open := f.EntryByMsgID("Open")
+	if model, ok := review.POModel(open); !ok || model != "mock/pseudo" {
+		t.Errorf("Open model = %q, %v", model, ok)
+	}
+	if !open.IsFuzzy() {
+		t.Error("Open should be fuzzy with ReviewFuzzy")
+	}
+	if _, ok := review.POModel(f.EntryByMsgID("Close")); ok {
+		t.Error("existing translation must not be marked")
+	}
+}
+
+func TestTranslateAll_ReviewMarksModelThatAnswered(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"Sorry, I cannot help with that."}}]}`)
+	}))
+	defer ts.Close()
+
+	chain := NewProviderChain(
+		Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		Provider{ID: ProviderMock, Model: MockModelPseudo},
+	)
+	f := po.NewFile()
+	f.Entries = append(f.Entries, &po.Entry{MsgID: "Open"})
+	opts := Options{
+		Provider:       chain.Providers()[0],
+		Providers:      chain,
+		ParallelMode:   ParallelFullParallel,
+		MaxRetries:     1,
+		SourceLanguage: "en",
+		Review:         review.New(),
+	}
+	tasks := []LangTask{{Lang: "de", POFile: f, POPath: filepath.Join(t.TempDir(), "de.po")}}
+	if err := TranslateAll(context.Background(), tasks, opts); err != nil {
+		t.Fatalf("TranslateAll error: %v", err)
+	}
+	if model, ok := review.POModel(f.EntryByMsgID("Open")); !ok || model != "mock/pseudo" {
+		t.Errorf("Open model = %q, %v; want the provider that answered", model, ok)
+	}
+}
+
+func TestTranslateAll_SkipsPOEntriesAwaitingReview(t *testing.T) {
+	f := po.NewFile()
+	f.Entries = append(f.Entries, &po.Entry{MsgID: "Open"})
+	mem := tm.New()
+	opts := Options{
+		Provider:       Provider{ID: ProviderMock, Model: MockModelPseudo},
+		ParallelMode:   ParallelSequential,
+		SourceLanguage: "en",
+		TranslateFuzzy: true,
+		Review:         review.New(),
+		ReviewFuzzy:    true,
+		Memory:         mem,
+		LockFile:       &lockfile.LockFile{Version: lockfile.Version, Checksums: map[string]map[string]string{}},
+		LockTarget:     "app",
+	}
+	tasks := []LangTask{{Lang: "de", POFile: f, POPath: filepath.Join(t.TempDir(), "de.po")}}
+	if err := TranslateAll(context.Background(), tasks, opts); err != nil {
+		t.Fatalf("TranslateAll error: %v", err)
+	}
+	open := f.EntryByMsgID("Open")
+	if !open.IsFuzzy() || open.MsgStr == "" {
+		t.Fatalf("Open = %+v", open)
+	}
+	if got, ok := mem.Lookup("de", tm.SourceKey("Open", "")); !ok || got != open.MsgStr {
+		t.Errorf("memory = %q, %v; want %q", got, ok, open.MsgStr)
+	}
+
+	// The second run must not send the entry again.
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+	opts.Provider = Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"}
+	if err := TranslateAll(context.Background(), tasks, opts); err != nil {
+		t.Fatalf("second TranslateAll error: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("second run sent %d requests, want 0", requests)
+	}
+	if got := collectEntries(f, Options{TranslateFuzzy: true}); len(got) != 0 {
+		t.Errorf("collectEntries() = %d entries, want 0", len(got))
+	}
+	if got := collectEntries(f, Options{TranslateFuzzy: true, RetranslateExisting: true}); len(got) != 1 {
+		t.Errorf("collectEntries() with --all = %d entries, want 1", len(got))
+	}
+}
+
+func TestTranslateKVFileRecordsCompletedChunksInJournal(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":{"message":"bad request"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, identifiedKVProviderResponse([]string{"a"}, []string{"Hallo"}))
+	}))
+	defer ts.Close()
+
+	jr, err := journal.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("journal.Open: %v", err)
+	}
+	f := newTestKVFile([]string{"a", "b"}, map[string]string{"a": "", "b": ""})
+	opts := Options{
+		Provider:   Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ChunkSize:  1,
+		MaxRetries: 0,
+		Language:   "de",
+		LockTarget: "app",
+		Journal:    jr,
+	}
+	translated, err := translateKVFile(context.Background(), f, map[string]string{"a": "Hello", "b": "Bye"}, []string{"a", "b"}, "ns", opts, DefaultKVChunkTranslator())
+	if err == nil {
+		t.Fatal("expected error from the second chunk")
+	}
+	if len(translated) != 1 {
+		t.Fatalf("translated = %v", translated)
+	}
+
+	reopened, err := journal.Open(filepath.Dir(jr.Path()))
+	if err != nil {
+		t.Fatalf("journal.Open: %v", err)
+	}
+	entries := reopened.Entries("app/de", "ns")
+	if len(entries) != 1 || entries["a"].Translation != "Hallo" || entries["a"].Source != "Hello" || entries["a"].Model != "custom-openai/test-model" {
+		t.Fatalf("journal entries = %+v", entries)
+	}
+}
+
+func TestTranslateAllKVResumesFromJournal(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if strings.Contains(string(body), "Hello") {
+			t.Errorf("journaled key was sent to the provider: %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(identifiedKVProviderResponse([]string{"b"}, []string{"Tschüss"})))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	jr, err := journal.Open(dir)
+	if err != nil {
+		t.Fatalf("journal.Open: %v", err)
+	}
+	if err := jr.Record(
+		journal.Entry{Target: "app/de", Key: "a", Source: "Hello", Translation: "Hallo", Model: "mock/pseudo"},
+		journal.Entry{Target: "app/de", Key: "b", Source: "Bye (old)", Translation: "Tschüss (alt)"},
+	); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	lf := &lockfile.LockFile{Version: lockfile.Version, Checksums: make(map[string]map[string]string)}
+	state := review.New()
+	f := newTestKVFile([]string{"a", "b"}, map[string]string{"a": "", "b": ""})
+	tasks := []KVLangTask{{Lang: "de", FilePath: "de.json", File: f, SourceValues: map[string]string{"a": "Hello", "b": "Bye"}}}
+	opts := Options{
+		Provider:     Provider{ID: ProviderCustomOpenAI, BaseURL: ts.URL, Model: "test-model"},
+		ParallelMode: ParallelSequential,
+		LockFile:     lf,
+		LockTarget:   "app",
+		Review:       state,
+		Journal:      jr,
+		Resume:       true,
+	}
+	if err := TranslateAllKV(context.Background(), tasks, opts, DefaultKVChunkTranslator()); err != nil {
+		t.Fatalf("TranslateAllKV: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("provider requests = %d, want 1", requests)
+	}
+	if got := f.Value("a"); got != "Hallo" {
+		t.Fatalf("value[a] = %q, want journal translation", got)
+	}
+	if got := f.Value("b"); got != "Tschüss" {
+		t.Fatalf("value[b] = %q, want fresh translation for changed source", got)
+	}
+	if lf.IsChanged("app/de", "a", lockfile.KVEntryContent("a", "Hello")) {
+		t.Fatal("lock file was not updated for the resumed key")
+	}
+	if jr.Len() != 0 {
+		t.Fatalf("journal still holds %d entries after the file was saved", jr.Len())
+	}
+	reopened, err := journal.Open(dir)
+	if err != nil || reopened.Len() != 0 {
+		t.Fatalf("journal on disk after save: %v, %d entries", err, reopened.Len())
+	}
+}
+
+func TestResumeJournalForPO(t *testing.T) {
+	jr, err := journal.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("journal.Open: %v", err)
+	}
+	if err := jr.Record(
+		journal.Entry{Target: "ui/ru", Key: lockfile.POEntryKey("Open", "menu"), Source: "Open", Translation: "Открыть"},
+		journal.Entry{Target: "ui/ru", Key: lockfile.POEntryKey("%d file", ""), Source: "%d file", SourcePlural: "%d files", Plural: []string{"%d файл", "%d файла", "%d файлов"}},
+		journal.Entry{Target: "ui/ru", Key: lockfile.POEntryKey("Open", ""), Source: "Open", Translation: "Открыть"},
+	); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	withCtx := &po.Entry{MsgID: "Open", MsgCtxt: "menu"}
+	plural := &po.Entry{MsgID: "%d file", MsgIDPlural: "%d files"}
+	other := &po.Entry{MsgID: "Close"}
+	opts := Options{Language: "ru", LockTarget: "ui", Journal: jr}
+
+	remaining, resumed := resumeJournalForPO([]*po.Entry{withCtx, plural, other}, opts)
+	if len(resumed) != 0 || len(remaining) != 3 {
+		t.Fatal("journal applied without Resume")
+	}
+
+	opts.Resume = true
+	remaining, resumed = resumeJournalForPO([]*po.Entry{withCtx, plural, other}, opts)
+	if len(resumed) != 2 || len(remaining) != 1 || remaining[0] != other {
+		t.Fatalf("resumed = %d, remaining = %v", len(resumed), remaining)
+	}
+	if withCtx.MsgStr != "Открыть" || plural.MsgStrPlural[2] != "%d файлов" {
+		t.Fatalf("entries = %+v, %+v", withCtx, plural)
+	}
+}
+
+func TestLanguageErrorsAuth(t *testing.T) {
+	failed := &LanguageErrors{}
+	if failed.orNil() != nil {
+		t.Fatal("orNil() != nil without failures")
+	}
+	failed.add("de", errors.New("timeout"))
+	failed.add("fr", newAPIError(http.StatusUnauthorized, "bad key"))
+
+	err := failed.orNil()
+	if err == nil || err.Error() != "2 language(s) failed: de, fr" {
+		t.Fatalf("err = %v", err)
+	}
+	if !IsAuthError(err) {
+		t.Fatal("IsAuthError() = false for a 401 answer")
+	}
+	if IsAuthError(newAPIError(http.StatusTooManyRequests, "slow down")) {
+		t.Fatal("IsAuthError() = true for a 429 answer")
+	}
+	if !IsAuthError(fmt.Errorf("login: %w", ErrAuthentication)) {
+		t.Fatal("IsAuthError() = false for ErrAuthentication")
+	}
+}
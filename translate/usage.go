@@ -0,0 +1,177 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ---------------------------------------------------------------------------
+// Token usage accounting
+// ---------------------------------------------------------------------------
+
+// ErrTokenBudgetExceeded is returned for chunks that were not sent to the
+// provider because the run's token budget was already used up.
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// TokenUsage is the token count reported by a provider.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total returns the sum of prompt and completion tokens.
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func (u TokenUsage) add(o TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+	}
+}
+
+// UsageEntry is the aggregated usage of one target and language.
+type UsageEntry struct {
+	Target   string
+	Lang     string
+	Requests int
+	TokenUsage
+}
+
+// UsageTracker aggregates token usage per target and language across a
+// translation run. It is safe for concurrent use; a nil tracker ignores
+// all updates.
+type UsageTracker struct {
+	// Budget is the maximum number of tokens for the run (0 = unlimited).
+	Budget int
+
+	mu      sync.Mutex
+	total   TokenUsage
+	entries map[[2]string]*UsageEntry
+}
+
+// NewUsageTracker returns a tracker with the given token budget
+// (0 = unlimited).
+func NewUsageTracker(budget int) *UsageTracker {
+	return &UsageTracker{Budget: budget, entries: make(map[[2]string]*UsageEntry)}
+}
+
+// Add records one provider request.
+func (t *UsageTracker) Add(target, lang string, u TokenUsage) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := [2]string{target, lang}
+	e, ok := t.entries[key]
+	if !ok {
+		e = &UsageEntry{Target: target, Lang: lang}
+		t.entries[key] = e
+	}
+	e.Requests++
+	e.TokenUsage = e.TokenUsage.add(u)
+	t.total = t.total.add(u)
+}
+
+// Total returns the usage summed over all targets and languages.
+func (t *UsageTracker) Total() TokenUsage {
+	if t == nil {
+		return TokenUsage{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total
+}
+
+// Entries returns the per-target, per-language usage sorted by target
+// and language.
+func (t *UsageTracker) Entries() []UsageEntry {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]UsageEntry, 0, len(t.entries))
+	for _, e := range t.entries {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Target != out[j].Target {
+			return out[i].Target < out[j].Target
+		}
+		return out[i].Lang < out[j].Lang
+	})
+	return out
+}
+
+// Exceeded reports whether the token budget has been used up.
+func (t *UsageTracker) Exceeded() bool {
+	if t == nil || t.Budget <= 0 {
+		return false
+	}
+	return t.Total().Total() >= t.Budget
+}
+
+// call sends one request to the provider and records its token usage.
+// No request is sent once the token budget is exceeded. With a provider
+// chain, a request failing with a fallback error is repeated with the
+// next provider. The returned index identifies the provider that answered
+// in the chain (0 without a chain).
+func (o *Options) call(ctx context.Context, systemPrompt, userPrompt string, rl *rateLimitState, maxRetries int) (string, int, error) {
+	for {
+		if o.Usage.Exceeded() {
+			return "", 0, ErrTokenBudgetExceeded
+		}
+		idx, prov := o.activeProvider()
+		text, usage, err := callProvider(ctx, prov, systemPrompt, userPrompt, rl, maxRetries, o.Verbose)
+		o.Usage.Add(o.LockTarget, o.Language, usage)
+		if err != nil && o.Providers != nil && ctx.Err() == nil && IsFallbackError(err) && o.Providers.advance(idx, err) {
+			continue
+		}
+		return text, idx, err
+	}
+}
+
+// extractUsage reads the token counts from a provider response body.
+// Supported shapes: OpenAI chat (usage.prompt_tokens/completion_tokens),
+// OpenAI responses and Anthropic (usage.input_tokens/output_tokens),
+// Gemini (usageMetadata) and Ollama (prompt_eval_count/eval_count).
+// Unknown shapes yield zero usage.
+func extractUsage(body []byte) TokenUsage {
+	var raw struct {
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			InputTokens      int `json:"input_tokens"`
+			OutputTokens     int `json:"output_tokens"`
+		} `json:"usage"`
+		UsageMetadata *struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+		PromptEvalCount int `json:"prompt_eval_count"`
+		EvalCount       int `json:"eval_count"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return TokenUsage{}
+	}
+	switch {
+	case raw.Usage != nil:
+		return TokenUsage{
+			PromptTokens:     raw.Usage.PromptTokens + raw.Usage.InputTokens,
+			CompletionTokens: raw.Usage.CompletionTokens + raw.Usage.OutputTokens,
+		}
+	case raw.UsageMetadata != nil:
+		return TokenUsage{
+			PromptTokens:     raw.UsageMetadata.PromptTokenCount,
+			CompletionTokens: raw.UsageMetadata.CandidatesTokenCount,
+		}
+	default:
+		return TokenUsage{PromptTokens: raw.PromptEvalCount, CompletionTokens: raw.EvalCount}
+	}
+}